@@ -0,0 +1,262 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWaitCanceled is the error of the results of jobs that were canceled by a Group, or never started,
+// because the group finished before them (e.g. fail fast or WaitAny).
+var ErrWaitCanceled = errors.New("wait canceled")
+
+// Job is an async action that can be waited for in a Group, together with async actions of other types.
+type Job struct {
+	name string
+	wait func(ctx context.Context) (any, error)
+}
+
+// NewJob initializes a Job that waits for the async action handled by h.
+// The name identifies the job in results and progress reports.
+func NewJob[T any](name string, h *AsyncActionHandler[T]) Job {
+	return Job{
+		name: name,
+		wait: func(ctx context.Context) (any, error) {
+			res, err := h.WaitWithContext(ctx)
+			if res == nil {
+				// Avoid returning a non-nil interface holding a nil pointer
+				return nil, err
+			}
+			return res, err
+		},
+	}
+}
+
+// Name returns the name of the job.
+func (j Job) Name() string {
+	return j.name
+}
+
+// Result is the outcome of a job waited for in a Group.
+type Result struct {
+	Name string
+	// Response is the response of the AsyncActionHandler of the job, i.e. a *T for a handler of type AsyncActionHandler[T]
+	Response any
+	Err      error
+	Duration time.Duration
+}
+
+// ResponseAs returns the response of the result as *T, or nil if it has a different type.
+func ResponseAs[T any](r Result) *T {
+	res, _ := r.Response.(*T)
+	return res
+}
+
+// Progress reports the state of a Group after one of its jobs finished.
+type Progress struct {
+	Total     int
+	Running   int
+	Succeeded int
+	Failed    int
+	// Canceled counts the jobs canceled by the group and the ones skipped because it stopped before they started, see Group.SetFailFast
+	Canceled int
+	// Last is the result of the job that just finished
+	Last Result
+}
+
+// ProgressFunc is called every time a job of a Group finishes, including the jobs skipped because the group stopped before they started.
+// Calls are never concurrent.
+type ProgressFunc func(p Progress)
+
+// GroupError is returned when jobs of a Group failed.
+type GroupError struct {
+	// Results holds the results of all jobs, in the order they were added to the group
+	Results []Result
+}
+
+func (e *GroupError) Error() string {
+	failures := []string{}
+	for _, r := range e.Failed() {
+		failures = append(failures, fmt.Sprintf("%s: %v", r.Name, r.Err))
+	}
+	return fmt.Sprintf("%d of %d async actions failed: %s", len(failures), len(e.Results), strings.Join(failures, "; "))
+}
+
+// Failed returns the results of the jobs that failed.
+func (e *GroupError) Failed() []Result {
+	failed := []Result{}
+	for _, r := range e.Results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Group waits for several async actions concurrently.
+type Group struct {
+	jobs        []Job
+	concurrency int
+	failFast    bool
+	progressFn  ProgressFunc
+}
+
+// NewGroup initializes a Group with the given jobs
+func NewGroup(jobs ...Job) *Group {
+	return &Group{
+		jobs: jobs,
+	}
+}
+
+// Add adds jobs to the group.
+func (g *Group) Add(jobs ...Job) *Group {
+	g.jobs = append(g.jobs, jobs...)
+	return g
+}
+
+// SetConcurrency sets the maximum number of jobs waited for at the same time. If n <= 0, all jobs are waited for at the same time.
+func (g *Group) SetConcurrency(n int) *Group {
+	g.concurrency = n
+	return g
+}
+
+// SetFailFast sets whether the group stops waiting as soon as a job fails.
+// Jobs that are still running are canceled and jobs that haven't started are skipped, their results have ErrWaitCanceled as error.
+// Jobs that fail on their own while the group stops keep their own error.
+func (g *Group) SetFailFast(failFast bool) *Group {
+	g.failFast = failFast
+	return g
+}
+
+// SetProgressFunc sets the function called every time a job finishes.
+func (g *Group) SetProgressFunc(fn ProgressFunc) *Group {
+	g.progressFn = fn
+	return g
+}
+
+// WaitAll waits for all jobs to finish and returns their results, in the order they were added to the group.
+// If any job fails, a *GroupError holding the results is returned as well.
+func (g *Group) WaitAll(ctx context.Context) ([]Result, error) {
+	results, _ := g.run(ctx, func(r Result) bool {
+		return g.failFast && r.Err != nil
+	})
+	for _, r := range results {
+		if r.Err != nil {
+			return results, &GroupError{Results: results}
+		}
+	}
+	return results, nil
+}
+
+// WaitAny waits for the first job to finish successfully and returns its result, all other jobs are canceled.
+// If fail fast is set, it returns as soon as the first job finishes, even if unsuccessfully.
+// If no job finishes successfully, a *GroupError holding the results of all jobs is returned.
+func (g *Group) WaitAny(ctx context.Context) (Result, error) {
+	results, stoppedBy := g.run(ctx, func(r Result) bool {
+		return g.failFast || r.Err == nil
+	})
+	if stoppedBy < 0 {
+		return Result{}, &GroupError{Results: results}
+	}
+	r := results[stoppedBy]
+	if r.Err != nil {
+		return r, &GroupError{Results: results}
+	}
+	return r, nil
+}
+
+// run waits for the jobs of the group until all of them finished or stop returns true for a result.
+// It returns the results of all jobs and the index of the job whose result stopped the group, or -1.
+func (g *Group) run(ctx context.Context, stop func(r Result) bool) (results []Result, stoppedBy int) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results = make([]Result, len(g.jobs))
+	for i, j := range g.jobs {
+		results[i] = Result{Name: j.name, Err: ErrWaitCanceled}
+	}
+
+	concurrency := g.concurrency
+	if concurrency <= 0 || concurrency > len(g.jobs) {
+		concurrency = len(g.jobs)
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	progress := Progress{Total: len(g.jobs)}
+	stoppedBy = -1
+
+	started := 0
+	for ; started < len(g.jobs); started++ {
+		i := started
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		mu.Lock()
+		progress.Running++
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			start := time.Now()
+			res, err := g.jobs[i].wait(ctx)
+			r := Result{
+				Name:     g.jobs[i].name,
+				Response: res,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if stoppedBy >= 0 && errors.Is(err, context.Canceled) {
+				// The job was canceled by the group, its error is due to the cancellation
+				r.Err = ErrWaitCanceled
+			}
+			results[i] = r
+			progress.Running--
+			switch {
+			case r.Err == nil:
+				progress.Succeeded++
+			case errors.Is(r.Err, ErrWaitCanceled):
+				progress.Canceled++
+			default:
+				progress.Failed++
+			}
+			progress.Last = r
+			if g.progressFn != nil {
+				g.progressFn(progress)
+			}
+			if stoppedBy < 0 && stop(r) {
+				stoppedBy = i
+				cancel()
+			}
+		}(i)
+	}
+
+	// The jobs that haven't started are skipped, they keep ErrWaitCanceled as error
+	mu.Lock()
+	for i := started; i < len(g.jobs); i++ {
+		progress.Canceled++
+		progress.Last = results[i]
+		if g.progressFn != nil {
+			g.progressFn(progress)
+		}
+	}
+	mu.Unlock()
+
+	wg.Wait()
+	return results, stoppedBy
+}
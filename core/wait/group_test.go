@@ -0,0 +1,244 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type groupTracker struct {
+	mu            sync.Mutex
+	running       int
+	maxConcurrent int
+}
+
+// job returns a Job that finishes after the given number of checks, with an error if fail is set
+func (tr *groupTracker) job(name string, checks int, fail bool) Job {
+	calls := 0
+	started := false
+	handler := New(func() (waitFinished bool, response *string, err error) {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		if !started {
+			started = true
+			tr.running++
+			if tr.running > tr.maxConcurrent {
+				tr.maxConcurrent = tr.running
+			}
+		}
+		calls++
+		if calls < checks {
+			return false, nil, nil
+		}
+		tr.running--
+		if fail {
+			return true, nil, fmt.Errorf("%s failed", name)
+		}
+		return true, &name, nil
+	})
+	handler.SetThrottle(time.Millisecond).SetTimeout(time.Second)
+	return NewJob(name, handler)
+}
+
+func TestGroupWaitAll(t *testing.T) {
+	tests := []struct {
+		desc              string
+		failing           []bool
+		concurrency       int
+		failFast          bool
+		wantErr           bool
+		wantSucceeded     int
+		wantFailed        int
+		wantCanceled      int
+		wantMaxConcurrent int
+	}{
+		{
+			desc:              "all_succeed",
+			failing:           []bool{false, false, false},
+			wantSucceeded:     3,
+			wantMaxConcurrent: 3,
+		},
+		{
+			desc:              "bounded_concurrency",
+			failing:           []bool{false, false, false, false, false},
+			concurrency:       2,
+			wantSucceeded:     5,
+			wantMaxConcurrent: 2,
+		},
+		{
+			desc:              "collect_all",
+			failing:           []bool{true, false, true},
+			wantErr:           true,
+			wantSucceeded:     1,
+			wantFailed:        2,
+			wantMaxConcurrent: 3,
+		},
+		{
+			desc:              "fail_fast",
+			failing:           []bool{true, false, false},
+			concurrency:       1,
+			failFast:          true,
+			wantErr:           true,
+			wantFailed:        1,
+			wantCanceled:      2,
+			wantMaxConcurrent: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tr := &groupTracker{}
+			g := NewGroup().SetConcurrency(tt.concurrency).SetFailFast(tt.failFast)
+			for i, fail := range tt.failing {
+				g.Add(tr.job(fmt.Sprintf("job-%d", i), 3, fail))
+			}
+			progressCalls := 0
+			var lastProgress Progress
+			g.SetProgressFunc(func(p Progress) {
+				progressCalls++
+				lastProgress = p
+				if p.Total != len(tt.failing) {
+					t.Errorf("progress total = %d, want %d", p.Total, len(tt.failing))
+				}
+			})
+
+			results, err := g.WaitAll(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitAll error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				groupErr := &GroupError{}
+				if !errors.As(err, &groupErr) {
+					t.Fatalf("WaitAll error is not a GroupError: %v", err)
+				}
+				if len(groupErr.Failed()) != tt.wantFailed+tt.wantCanceled {
+					t.Fatalf("GroupError has %d failed results, want %d", len(groupErr.Failed()), tt.wantFailed+tt.wantCanceled)
+				}
+			}
+			if len(results) != len(tt.failing) {
+				t.Fatalf("WaitAll returned %d results, want %d", len(results), len(tt.failing))
+			}
+
+			succeeded, failed, canceled := 0, 0, 0
+			for i, r := range results {
+				if r.Name != fmt.Sprintf("job-%d", i) {
+					t.Fatalf("result %d has name %s", i, r.Name)
+				}
+				switch {
+				case r.Err == nil:
+					succeeded++
+					if got := ResponseAs[string](r); got == nil || *got != r.Name {
+						t.Fatalf("result %d has response %v", i, got)
+					}
+				case errors.Is(r.Err, ErrWaitCanceled):
+					canceled++
+				default:
+					failed++
+					if r.Response != nil {
+						t.Fatalf("failed result %d has response %v", i, r.Response)
+					}
+				}
+			}
+			if succeeded != tt.wantSucceeded || failed != tt.wantFailed || canceled != tt.wantCanceled {
+				t.Fatalf("got %d succeeded, %d failed, %d canceled, want %d, %d, %d", succeeded, failed, canceled, tt.wantSucceeded, tt.wantFailed, tt.wantCanceled)
+			}
+			if progressCalls != len(tt.failing) {
+				t.Fatalf("progress func called %d times, want %d", progressCalls, len(tt.failing))
+			}
+			wantProgress := Progress{Total: len(tt.failing), Succeeded: tt.wantSucceeded, Failed: tt.wantFailed, Canceled: tt.wantCanceled}
+			lastProgress.Last = Result{}
+			if lastProgress != wantProgress {
+				t.Fatalf("last progress = %+v, want %+v", lastProgress, wantProgress)
+			}
+			if tr.maxConcurrent != tt.wantMaxConcurrent {
+				t.Fatalf("max concurrent jobs = %d, want %d", tr.maxConcurrent, tt.wantMaxConcurrent)
+			}
+		})
+	}
+}
+
+func TestGroupWaitAny(t *testing.T) {
+	tests := []struct {
+		desc     string
+		checks   []int
+		failing  []bool
+		failFast bool
+		wantName string
+		wantErr  bool
+	}{
+		{
+			desc:     "first_success",
+			checks:   []int{10, 2, 5},
+			failing:  []bool{false, false, false},
+			wantName: "job-1",
+		},
+		{
+			desc:     "failure_is_skipped",
+			checks:   []int{10, 2, 5},
+			failing:  []bool{false, true, false},
+			wantName: "job-2",
+		},
+		{
+			desc:     "fail_fast",
+			checks:   []int{10, 2, 5},
+			failing:  []bool{false, true, false},
+			failFast: true,
+			wantName: "job-1",
+			wantErr:  true,
+		},
+		{
+			desc:    "all_fail",
+			checks:  []int{1, 2},
+			failing: []bool{true, true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tr := &groupTracker{}
+			g := NewGroup().SetFailFast(tt.failFast)
+			for i := range tt.checks {
+				g.Add(tr.job(fmt.Sprintf("job-%d", i), tt.checks[i], tt.failing[i]))
+			}
+
+			r, err := g.WaitAny(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitAny error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if r.Name != tt.wantName {
+				t.Fatalf("WaitAny returned result of %q, want %q", r.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestGroupFailFastKeepsOwnErrors(t *testing.T) {
+	ownErr := fmt.Errorf("own failure")
+	// Both jobs finish once the group is canceled, one with an error due to the cancellation and one with its own error
+	canceledJob := Job{name: "canceled", wait: func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, fmt.Errorf("stopped: %w", ctx.Err())
+	}}
+	failingJob := Job{name: "failing", wait: func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ownErr
+	}}
+	tr := &groupTracker{}
+	g := NewGroup(tr.job("first", 1, true), canceledJob, failingJob, tr.job("slow", 1000, false)).SetFailFast(true)
+
+	results, err := g.WaitAll(context.Background())
+	if err == nil {
+		t.Fatalf("WaitAll succeeded")
+	}
+	if !errors.Is(results[1].Err, ErrWaitCanceled) {
+		t.Fatalf("canceled job has error %v, want %v", results[1].Err, ErrWaitCanceled)
+	}
+	if !errors.Is(results[2].Err, ownErr) {
+		t.Fatalf("failing job has error %v, want %v", results[2].Err, ownErr)
+	}
+	if !errors.Is(results[3].Err, ErrWaitCanceled) {
+		t.Fatalf("slow job has error %v, want %v", results[3].Err, ErrWaitCanceled)
+	}
+}
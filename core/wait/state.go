@@ -0,0 +1,144 @@
+package wait
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+)
+
+// GetFunc fetches the current version of the resource targeted by an async action.
+type GetFunc[T any] func() (*T, error)
+
+// StateFunc extracts the state from a resource.
+// It should return an error if the resource is not valid, e.g. because the state is missing.
+type StateFunc[T any] func(res *T) (state string, err error)
+
+// StateHandlerBuilder builds an AsyncActionHandler that waits for a resource to reach one of a set of states.
+type StateHandlerBuilder[T any] struct {
+	getFn            GetFunc[T]
+	stateFn          StateFunc[T]
+	successStates    []string
+	failureStates    []string
+	transientStates  []string
+	goneStatusCodes  []int
+	failureErrFn     func(res *T, state string) error
+	unexpectedErrFn  func(res *T, state string) error
+	keepLastResponse bool
+}
+
+// ForState initializes a StateHandlerBuilder that gets the resource with getFn and extracts its state with stateFn.
+// If stateFn is nil, the resource is considered to have no state and the handler only finishes once it's gone (see GoneIsSuccess).
+func ForState[T any](getFn GetFunc[T], stateFn StateFunc[T]) *StateHandlerBuilder[T] {
+	return &StateHandlerBuilder[T]{
+		getFn:   getFn,
+		stateFn: stateFn,
+	}
+}
+
+// Success sets the states in which the async action has finished successfully.
+func (b *StateHandlerBuilder[T]) Success(states ...string) *StateHandlerBuilder[T] {
+	b.successStates = append(b.successStates, states...)
+	return b
+}
+
+// Failure sets the states in which the async action has finished unsuccessfully.
+func (b *StateHandlerBuilder[T]) Failure(states ...string) *StateHandlerBuilder[T] {
+	b.failureStates = append(b.failureStates, states...)
+	return b
+}
+
+// Transient sets the states in which the async action is still in progress.
+// If transient states are set, any state that is not a success, failure or transient state makes the handler fail.
+// Otherwise, the handler keeps waiting on unknown states.
+func (b *StateHandlerBuilder[T]) Transient(states ...string) *StateHandlerBuilder[T] {
+	b.transientStates = append(b.transientStates, states...)
+	return b
+}
+
+// GoneIsSuccess makes the handler finish successfully once getting the resource fails with one of the given HTTP status codes.
+// If no status code is given, http.StatusNotFound is used.
+func (b *StateHandlerBuilder[T]) GoneIsSuccess(statusCodes ...int) *StateHandlerBuilder[T] {
+	if len(statusCodes) == 0 {
+		statusCodes = []int{http.StatusNotFound}
+	}
+	b.goneStatusCodes = append(b.goneStatusCodes, statusCodes...)
+	return b
+}
+
+// OnFailure sets the function that builds the error returned when the resource reaches a failure state.
+func (b *StateHandlerBuilder[T]) OnFailure(fn func(res *T, state string) error) *StateHandlerBuilder[T] {
+	b.failureErrFn = fn
+	return b
+}
+
+// OnUnexpected sets the function that builds the error returned when the resource reaches a state that is not a success, failure or
+// transient state, if transient states are set.
+func (b *StateHandlerBuilder[T]) OnUnexpected(fn func(res *T, state string) error) *StateHandlerBuilder[T] {
+	b.unexpectedErrFn = fn
+	return b
+}
+
+// KeepLastResponse makes the handler return the resource it last got while the async action is still in progress, e.g. when it times out.
+// By default, the resource is only returned once the async action has finished.
+func (b *StateHandlerBuilder[T]) KeepLastResponse() *StateHandlerBuilder[T] {
+	b.keepLastResponse = true
+	return b
+}
+
+// Handler returns an AsyncActionHandler that waits as configured in the builder.
+func (b *StateHandlerBuilder[T]) Handler() *AsyncActionHandler[T] {
+	return New(b.check)
+}
+
+func (b *StateHandlerBuilder[T]) check() (waitFinished bool, response *T, err error) {
+	res, err := b.getFn()
+	if err != nil {
+		if b.isGone(err) {
+			return true, nil, nil
+		}
+		return false, nil, err
+	}
+	if b.stateFn == nil {
+		if b.keepLastResponse {
+			return false, res, nil
+		}
+		return false, nil, nil
+	}
+	state, err := b.stateFn(res)
+	if err != nil {
+		return false, nil, err
+	}
+
+	switch {
+	case utils.Contains(b.successStates, state):
+		return true, res, nil
+	case utils.Contains(b.failureStates, state):
+		if b.failureErrFn != nil {
+			return true, res, b.failureErrFn(res, state)
+		}
+		return true, res, fmt.Errorf("received failure state %s", state)
+	case len(b.transientStates) == 0 || utils.Contains(b.transientStates, state):
+		if b.keepLastResponse {
+			return false, res, nil
+		}
+		return false, nil, nil
+	default:
+		if b.unexpectedErrFn != nil {
+			return true, res, b.unexpectedErrFn(res, state)
+		}
+		return true, res, fmt.Errorf("received unexpected state %s", state)
+	}
+}
+
+func (b *StateHandlerBuilder[T]) isGone(err error) bool {
+	if len(b.goneStatusCodes) == 0 {
+		return false
+	}
+	oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
+	if !ok {
+		return false
+	}
+	return utils.Contains(b.goneStatusCodes, oapiErr.StatusCode)
+}
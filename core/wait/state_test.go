@@ -0,0 +1,207 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+)
+
+type stateResource struct {
+	State *string
+}
+
+func TestForState(t *testing.T) {
+	stateFn := func(res *stateResource) (string, error) {
+		if res.State == nil {
+			return "", fmt.Errorf("state is missing")
+		}
+		return *res.State, nil
+	}
+	failureErr := fmt.Errorf("custom failure")
+	unexpectedErr := fmt.Errorf("custom unexpected state")
+
+	tests := []struct {
+		desc          string
+		states        []string
+		getErrCode    int
+		nilState      bool
+		noStateFn     bool
+		transient     bool
+		goneCodes     []int
+		goneIsSuccess bool
+		onFailure     bool
+		onUnexpected  bool
+		keepLast      bool
+		wantErr       error
+		wantAnyErr    bool
+		wantResp      bool
+	}{
+		{
+			desc:     "success",
+			states:   []string{"PENDING", "PENDING", "READY"},
+			wantResp: true,
+		},
+		{
+			desc:       "failure",
+			states:     []string{"PENDING", "FAILED"},
+			wantAnyErr: true,
+			wantResp:   true,
+		},
+		{
+			desc:       "custom_failure",
+			states:     []string{"FAILED"},
+			onFailure:  true,
+			wantErr:    failureErr,
+			wantAnyErr: true,
+			wantResp:   true,
+		},
+		{
+			desc:      "transient",
+			states:    []string{"PENDING", "UPDATING", "READY"},
+			transient: true,
+			wantResp:  true,
+		},
+		{
+			desc:       "unexpected_state",
+			states:     []string{"PENDING", "UNKNOWN"},
+			transient:  true,
+			wantAnyErr: true,
+			wantResp:   true,
+		},
+		{
+			desc:         "custom_unexpected_state",
+			states:       []string{"UNKNOWN"},
+			transient:    true,
+			onUnexpected: true,
+			wantErr:      unexpectedErr,
+			wantAnyErr:   true,
+			wantResp:     true,
+		},
+		{
+			desc:       "unknown_state_waits_until_timeout",
+			states:     []string{"UNKNOWN"},
+			wantAnyErr: true,
+			wantResp:   false,
+		},
+		{
+			desc:       "keep_last_response_until_timeout",
+			states:     []string{"UNKNOWN"},
+			keepLast:   true,
+			wantAnyErr: true,
+			wantResp:   true,
+		},
+		{
+			desc:       "keep_last_response_without_state",
+			states:     []string{"DELETING"},
+			noStateFn:  true,
+			keepLast:   true,
+			wantAnyErr: true,
+			wantResp:   true,
+		},
+		{
+			desc:       "invalid_resource",
+			nilState:   true,
+			wantAnyErr: true,
+			wantResp:   false,
+		},
+		{
+			desc:          "gone",
+			states:        []string{"DELETING"},
+			getErrCode:    http.StatusNotFound,
+			goneIsSuccess: true,
+			wantResp:      false,
+		},
+		{
+			desc:          "gone_custom_status_codes",
+			states:        []string{"DELETING"},
+			getErrCode:    http.StatusGone,
+			goneIsSuccess: true,
+			goneCodes:     []int{http.StatusNotFound, http.StatusGone},
+			wantResp:      false,
+		},
+		{
+			desc:          "gone_without_state",
+			getErrCode:    http.StatusNotFound,
+			noStateFn:     true,
+			goneIsSuccess: true,
+			wantResp:      false,
+		},
+		{
+			desc:       "not_found_without_gone_is_success",
+			states:     []string{"DELETING"},
+			getErrCode: http.StatusNotFound,
+			wantAnyErr: true,
+			wantResp:   false,
+		},
+		{
+			desc:          "get_fails",
+			states:        []string{"DELETING"},
+			getErrCode:    http.StatusInternalServerError,
+			goneIsSuccess: true,
+			wantAnyErr:    true,
+			wantResp:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			calls := 0
+			getFn := func() (*stateResource, error) {
+				calls++
+				if tt.getErrCode != 0 && calls > len(tt.states) {
+					return nil, &oapierror.GenericOpenAPIError{
+						StatusCode: tt.getErrCode,
+					}
+				}
+				if tt.nilState {
+					return &stateResource{}, nil
+				}
+				i := calls - 1
+				if i >= len(tt.states) {
+					i = len(tt.states) - 1
+				}
+				return &stateResource{State: &tt.states[i]}, nil
+			}
+
+			var builder *StateHandlerBuilder[stateResource]
+			if tt.noStateFn {
+				builder = ForState(getFn, nil)
+			} else {
+				builder = ForState(getFn, stateFn)
+			}
+			builder.Success("READY").Failure("FAILED")
+			if tt.transient {
+				builder.Transient("PENDING", "UPDATING")
+			}
+			if tt.goneIsSuccess {
+				builder.GoneIsSuccess(tt.goneCodes...)
+			}
+			if tt.onFailure {
+				builder.OnFailure(func(_ *stateResource, _ string) error { return failureErr })
+			}
+			if tt.onUnexpected {
+				builder.OnUnexpected(func(_ *stateResource, _ string) error { return unexpectedErr })
+			}
+			if tt.keepLast {
+				builder.KeepLastResponse()
+			}
+
+			handler := builder.Handler().SetThrottle(time.Millisecond).SetTimeout(20 * time.Millisecond)
+			gotRes, err := handler.WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantAnyErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantAnyErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("handler error = %v, want %v", err, tt.wantErr)
+			}
+			if (gotRes != nil) != tt.wantResp {
+				t.Fatalf("handler gotRes = %+v, wantResp %v", gotRes, tt.wantResp)
+			}
+		})
+	}
+}
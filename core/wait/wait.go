@@ -93,7 +93,7 @@ func (h *AsyncActionHandler[T]) WaitWithContext(ctx context.Context) (res *T, er
 
 		select {
 		case <-ctx.Done():
-			return res, fmt.Errorf("WaitWithContext() has timed out")
+			return res, fmt.Errorf("WaitWithContext() has timed out: %w", ctx.Err())
 		case <-ticker.C:
 			continue
 		}
@@ -41,6 +41,15 @@ for service_dir in ${SERVICES_PATH}/*; do
 done
 
 if [ "${SKIP_NON_GENERATED_FILES}" = false ]; then
+    for module_dir in ${SERVICES_PATH}/*/*/; do
+        if [ ! -f "${module_dir}/go.mod" ]; then
+            continue
+        fi
+        echo ">> Linting services/$(basename $(dirname ${module_dir}))/$(basename ${module_dir})"
+        cd ${module_dir}
+        golangci-lint run ${GOLANG_CI_ARGS}
+    done
+
     for example_dir in ${EXAMPLES_PATH}/*; do
         example=$(basename ${example_dir})
         echo ">> Linting example ${example}"
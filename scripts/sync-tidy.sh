@@ -18,6 +18,13 @@ for service_dir in ${SERVICES_PATH}/*; do
     go mod tidy
 done
 
+for module_dir in ${SERVICES_PATH}/*/*/; do
+    if [ -f "${module_dir}/go.mod" ]; then
+        cd ${module_dir}
+        go mod tidy
+    fi
+done
+
 for example_dir in ${EXAMPLES_PATH}/*; do
     cd ${example_dir}
     go mod tidy
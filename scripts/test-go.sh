@@ -45,3 +45,15 @@ for service_dir in ${SERVICES_PATH}/*; do
         go test ./... ${GOTEST_ARGS}
     fi
 done
+
+# Some services have additional modules, e.g. integrations with third-party libraries
+if [ "${SKIP_NON_GENERATED_FILES}" = false ]; then
+    for module_dir in ${SERVICES_PATH}/*/*/; do
+        if [ ! -f "${module_dir}/go.mod" ]; then
+            continue
+        fi
+        echo ">> Testing services/$(basename $(dirname ${module_dir}))/$(basename ${module_dir})"
+        cd ${module_dir}
+        go test ./... ${GOTEST_ARGS}
+    done
+fi
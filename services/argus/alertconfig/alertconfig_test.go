@@ -0,0 +1,427 @@
+package alertconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+const fixtureConfig = `
+global:
+  resolve_timeout: 5m
+  smtp_from: alerts@example.com
+  smtp_smarthost: smtp.example.com:587
+route:
+  receiver: team
+  group_by: [alertname, cluster]
+  group_wait: 30s
+  matchers:
+    - severity=~"warning|critical"
+  routes:
+    - receiver: oncall
+      match:
+        severity: critical
+      repeat_interval: 1h
+      routes:
+        - receiver: teams
+          match_re:
+            service: ^(api|db)$
+receivers:
+  - name: team
+    email_configs:
+      - to: team@example.com
+        send_resolved: false
+  - name: oncall
+    opsgenie_configs:
+      - api_key: key
+        tags: prod,critical
+  - name: teams
+    webhook_configs:
+      - url: https://hooks.example.com/alerts
+    msteams_configs:
+      - webhook_url: https://example.webhook.office.com/hook
+inhibit_rules:
+  - source_match:
+      severity: critical
+    target_match:
+      severity: warning
+    equal: [alertname]
+`
+
+func TestImport(t *testing.T) {
+	got, err := Import([]byte(fixtureConfig))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := &argus.UpdateAlertConfigsPayload{
+		Global: &argus.UpdateAlertConfigsPayloadGlobal{
+			ResolveTimeout: utils.Ptr("5m"),
+			SmtpFrom:       utils.Ptr("alerts@example.com"),
+			SmtpSmarthost:  utils.Ptr("smtp.example.com:587"),
+		},
+		Route: &argus.UpdateAlertConfigsPayloadRoute{
+			Receiver:  utils.Ptr("team"),
+			GroupBy:   &[]string{"alertname", "cluster"},
+			GroupWait: utils.Ptr("30s"),
+			Matchers:  &[]string{`severity=~"warning|critical"`},
+			Routes: &[]argus.CreateAlertConfigRoutePayloadRoutesInner{{
+				Receiver:       utils.Ptr("oncall"),
+				Match:          &map[string]interface{}{"severity": "critical"},
+				RepeatInterval: utils.Ptr("1h"),
+				Routes: &[]map[string]interface{}{{
+					"receiver": "teams",
+					"matchRe":  map[string]interface{}{"service": "^(api|db)$"},
+				}},
+			}},
+		},
+		Receivers: &[]argus.UpdateAlertConfigsPayloadReceiversInner{
+			{
+				Name: utils.Ptr("team"),
+				EmailConfigs: &[]argus.CreateAlertConfigReceiverPayloadEmailConfigsInner{{
+					To: utils.Ptr("team@example.com"),
+				}},
+			},
+			{
+				Name: utils.Ptr("oncall"),
+				OpsgenieConfigs: &[]argus.CreateAlertConfigReceiverPayloadOpsgenieConfigsInner{{
+					ApiKey: utils.Ptr("key"),
+					Tags:   utils.Ptr("prod,critical"),
+				}},
+			},
+			{
+				Name: utils.Ptr("teams"),
+				WebHookConfigs: &[]argus.CreateAlertConfigReceiverPayloadWebHookConfigsInner{
+					{Url: utils.Ptr("https://hooks.example.com/alerts")},
+					{Url: utils.Ptr("https://example.webhook.office.com/hook"), MsTeams: utils.Ptr(true)},
+				},
+			},
+		},
+		InhibitRules: &argus.UpdateAlertConfigsPayloadInhibitRules{
+			SourceMatch: &map[string]interface{}{"severity": "critical"},
+			TargetMatch: &map[string]interface{}{"severity": "warning"},
+			Equal:       &[]string{"alertname"},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected payload (-got +want): %s", diff)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		desc            string
+		config          string
+		wantUnsupported string
+	}{
+		{
+			desc: "unsupported_receiver",
+			config: `
+route:
+  receiver: team
+receivers:
+  - name: team
+    slack_configs:
+      - channel: alerts
+`,
+			wantUnsupported: "receivers[0].slack_configs",
+		},
+		{
+			desc: "unsupported_top_level",
+			config: `
+templates: [/etc/alertmanager/*.tmpl]
+route:
+  receiver: team
+receivers:
+  - name: team
+`,
+			wantUnsupported: "templates",
+		},
+		{
+			desc: "continue_in_child_route",
+			config: `
+route:
+  receiver: team
+  routes:
+    - receiver: team
+      continue: true
+receivers:
+  - name: team
+`,
+			wantUnsupported: "route.routes[0].continue",
+		},
+		{
+			desc: "send_resolved_not_default",
+			config: `
+route:
+  receiver: team
+receivers:
+  - name: team
+    webhook_configs:
+      - url: https://hooks.example.com
+        send_resolved: false
+`,
+			wantUnsupported: "receivers[0].webhook_configs[0].send_resolved",
+		},
+		{
+			desc: "multiple_inhibit_rules",
+			config: `
+route:
+  receiver: team
+receivers:
+  - name: team
+inhibit_rules:
+  - equal: [alertname]
+  - equal: [cluster]
+`,
+			wantUnsupported: "inhibit_rules[1]",
+		},
+		{
+			desc: "inhibit_rule_matchers",
+			config: `
+route:
+  receiver: team
+receivers:
+  - name: team
+inhibit_rules:
+  - source_matchers: [severity="critical"]
+`,
+			wantUnsupported: "inhibit_rules[0].source_matchers",
+		},
+		{
+			desc: "unknown_root_receiver",
+			config: `
+route:
+  receiver: nobody
+receivers:
+  - name: team
+`,
+		},
+		{
+			desc: "unknown_nested_receiver",
+			config: `
+route:
+  receiver: team
+  routes:
+    - match:
+        severity: critical
+      routes:
+        - receiver: nobody
+receivers:
+  - name: team
+`,
+		},
+		{
+			desc: "missing_route",
+			config: `
+receivers:
+  - name: team
+`,
+		},
+		{
+			desc: "duplicate_receiver",
+			config: `
+route:
+  receiver: team
+receivers:
+  - name: team
+  - name: team
+`,
+		},
+		{
+			desc: "invalid_receiver_name",
+			config: `
+route:
+  receiver: team_a
+receivers:
+  - name: team_a
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := Import([]byte(tt.config))
+			if err == nil {
+				t.Fatalf("Import returned no error")
+			}
+			var unsupportedErr *UnsupportedFieldError
+			isUnsupported := errors.As(err, &unsupportedErr)
+			if isUnsupported != (tt.wantUnsupported != "") {
+				t.Fatalf("Import error = %v, want unsupported field %q", err, tt.wantUnsupported)
+			}
+			if isUnsupported && unsupportedErr.Path != tt.wantUnsupported {
+				t.Fatalf("unsupported field = %q, want %q", unsupportedErr.Path, tt.wantUnsupported)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	resp := &argus.GetAlertConfigsResponse{
+		Message: utils.Ptr("Successfully got alert config"),
+		Data: &argus.Alert{
+			Global: &argus.Global{ResolveTimeout: utils.Ptr("5m")},
+			Route: &argus.Route{
+				Receiver: utils.Ptr("team"),
+				GroupBy:  &[]string{"alertname"},
+				Routes: &[]argus.RouteSerializer{{
+					Receiver: utils.Ptr("teams"),
+					Continue: utils.Ptr(true),
+					Matchers: &[]string{`severity="critical"`},
+					Routes:   &[]map[string]string{{"receiver": "team", "groupWait": "10s"}},
+				}},
+			},
+			Receivers: &[]argus.Receivers{
+				{
+					Name:         utils.Ptr("team"),
+					EmailConfigs: &[]argus.EmailConfig{{To: utils.Ptr("team@example.com"), SendResolved: utils.Ptr(false)}},
+				},
+				{
+					Name: utils.Ptr("teams"),
+					WebHookConfigs: &[]argus.WebHook{
+						{Url: utils.Ptr("https://hooks.example.com/alerts"), SendResolved: utils.Ptr(true)},
+						{Url: utils.Ptr("https://example.webhook.office.com/hook"), MsTeams: utils.Ptr(true)},
+					},
+				},
+			},
+			InhibitRules: &[]argus.InhibitRules{{
+				SourceMatchers: &[]string{`severity="critical"`},
+				TargetMatchers: &[]string{`severity="warning"`},
+				Equal:          &[]string{"alertname"},
+			}},
+		},
+	}
+	got, err := Export(resp)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := `global:
+  resolve_timeout: 5m
+route:
+  receiver: team
+  group_by:
+    - alertname
+  routes:
+    - receiver: teams
+      continue: true
+      matchers:
+        - severity="critical"
+      routes:
+        - receiver: team
+          group_wait: 10s
+receivers:
+  - name: team
+    email_configs:
+      - send_resolved: false
+        to: team@example.com
+  - name: teams
+    webhook_configs:
+      - send_resolved: true
+        url: https://hooks.example.com/alerts
+    msteams_configs:
+      - webhook_url: https://example.webhook.office.com/hook
+inhibit_rules:
+  - source_matchers:
+      - severity="critical"
+    target_matchers:
+      - severity="warning"
+    equal:
+      - alertname
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("unexpected YAML (-got +want): %s", diff)
+	}
+
+	// The exported configuration is valid Alertmanager configuration, even where the update payload can't express it
+	cfg, err := Unmarshal(got)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestExportUnreadableNestedRoute(t *testing.T) {
+	resp := &argus.GetAlertConfigsResponse{
+		Data: &argus.Alert{
+			Route: &argus.Route{
+				Receiver: utils.Ptr("team"),
+				Routes: &[]argus.RouteSerializer{{
+					Receiver: utils.Ptr("team"),
+					Routes:   &[]map[string]string{{"receiver": "team", "match": "severity=critical"}},
+				}},
+			},
+			Receivers: &[]argus.Receivers{{Name: utils.Ptr("team")}},
+		},
+	}
+	if _, err := Export(resp); err == nil {
+		t.Fatalf("Export returned no error")
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	config := `
+route:
+  receiver: team
+  group_by: [alertname]
+  matchers:
+    - severity=~"warning|critical"
+  routes:
+    - receiver: oncall
+      group_by: [alertname, cluster]
+      match:
+        severity: critical
+      routes:
+        - receiver: db
+          group_by: [service]
+          match:
+            team: db
+          match_re:
+            service: ^(postgres|redis)$
+          group_wait: 10s
+          routes:
+            - receiver: team
+              match:
+                env: dev
+              repeat_interval: 12h
+receivers:
+  - name: team
+    email_configs:
+      - to: team@example.com
+  - name: oncall
+    opsgenie_configs:
+      - api_key: key
+  - name: db
+    webhook_configs:
+      - url: https://hooks.example.com/db
+`
+	payload, err := Import([]byte(config))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	// The API returns the alert configs as they were set with the payload
+	body, err := json.Marshal(map[string]interface{}{"message": "Successfully got alert config", "data": payload})
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	exported, err := ExportJSON(body)
+	if err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+
+	got, err := Unmarshal(exported)
+	if err != nil {
+		t.Fatalf("Unmarshal exported: %v", err)
+	}
+	want, err := Unmarshal([]byte(config))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected configuration (-got +want): %s", diff)
+	}
+}
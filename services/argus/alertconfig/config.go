@@ -0,0 +1,198 @@
+// Package alertconfig converts between Alertmanager configuration YAML and the alert configs of the Argus API,
+// to import existing alertmanager.yml files into Argus and to export the alert configs of an instance for review.
+package alertconfig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is an Alertmanager configuration, restricted to the settings supported by Argus
+type Config struct {
+	Global       *GlobalConfig `yaml:"global,omitempty"`
+	Route        *Route        `yaml:"route"`
+	Receivers    []Receiver    `yaml:"receivers"`
+	InhibitRules []InhibitRule `yaml:"inhibit_rules,omitempty"`
+}
+
+// GlobalConfig holds the defaults of the receivers and the resolve timeout of alerts
+type GlobalConfig struct {
+	ResolveTimeout   string `yaml:"resolve_timeout,omitempty"`
+	SmtpFrom         string `yaml:"smtp_from,omitempty"`
+	SmtpSmarthost    string `yaml:"smtp_smarthost,omitempty"`
+	SmtpAuthUsername string `yaml:"smtp_auth_username,omitempty"`
+	SmtpAuthPassword string `yaml:"smtp_auth_password,omitempty"`
+	SmtpAuthIdentity string `yaml:"smtp_auth_identity,omitempty"`
+	OpsgenieApiKey   string `yaml:"opsgenie_api_key,omitempty"`
+	OpsgenieApiUrl   string `yaml:"opsgenie_api_url,omitempty"`
+}
+
+// Route is a node of the routing tree, matching alerts and sending them to a receiver
+type Route struct {
+	Receiver       string            `yaml:"receiver,omitempty"`
+	GroupBy        []string          `yaml:"group_by,omitempty"`
+	Continue       bool              `yaml:"continue,omitempty"`
+	Match          map[string]string `yaml:"match,omitempty"`
+	MatchRe        map[string]string `yaml:"match_re,omitempty"`
+	Matchers       []string          `yaml:"matchers,omitempty"`
+	GroupWait      string            `yaml:"group_wait,omitempty"`
+	GroupInterval  string            `yaml:"group_interval,omitempty"`
+	RepeatInterval string            `yaml:"repeat_interval,omitempty"`
+	Routes         []Route           `yaml:"routes,omitempty"`
+}
+
+// Receiver is a named set of notification integrations
+type Receiver struct {
+	Name            string           `yaml:"name"`
+	EmailConfigs    []EmailConfig    `yaml:"email_configs,omitempty"`
+	OpsgenieConfigs []OpsgenieConfig `yaml:"opsgenie_configs,omitempty"`
+	WebhookConfigs  []WebhookConfig  `yaml:"webhook_configs,omitempty"`
+	// MsteamsConfigs are sent by Argus as webhooks with MsTeams set
+	MsteamsConfigs []MsteamsConfig `yaml:"msteams_configs,omitempty"`
+}
+
+// EmailConfig sends notifications by email
+type EmailConfig struct {
+	SendResolved *bool  `yaml:"send_resolved,omitempty"`
+	To           string `yaml:"to"`
+	From         string `yaml:"from,omitempty"`
+	Smarthost    string `yaml:"smarthost,omitempty"`
+	AuthUsername string `yaml:"auth_username,omitempty"`
+	AuthPassword string `yaml:"auth_password,omitempty"`
+	AuthIdentity string `yaml:"auth_identity,omitempty"`
+}
+
+// OpsgenieConfig sends notifications to Opsgenie
+type OpsgenieConfig struct {
+	SendResolved *bool  `yaml:"send_resolved,omitempty"`
+	ApiKey       string `yaml:"api_key,omitempty"`
+	ApiUrl       string `yaml:"api_url,omitempty"`
+	Priority     string `yaml:"priority,omitempty"`
+	// Tags is a comma separated list of tags
+	Tags string `yaml:"tags,omitempty"`
+}
+
+// WebhookConfig sends notifications to a generic webhook
+type WebhookConfig struct {
+	SendResolved *bool  `yaml:"send_resolved,omitempty"`
+	Url          string `yaml:"url"`
+}
+
+// MsteamsConfig sends notifications to a Microsoft Teams webhook
+type MsteamsConfig struct {
+	SendResolved *bool  `yaml:"send_resolved,omitempty"`
+	WebhookUrl   string `yaml:"webhook_url"`
+}
+
+// InhibitRule mutes the alerts matching the target matchers while alerts matching the source matchers are firing
+type InhibitRule struct {
+	SourceMatch    map[string]string `yaml:"source_match,omitempty"`
+	SourceMatchRe  map[string]string `yaml:"source_match_re,omitempty"`
+	SourceMatchers []string          `yaml:"source_matchers,omitempty"`
+	TargetMatch    map[string]string `yaml:"target_match,omitempty"`
+	TargetMatchRe  map[string]string `yaml:"target_match_re,omitempty"`
+	TargetMatchers []string          `yaml:"target_matchers,omitempty"`
+	Equal          []string          `yaml:"equal,omitempty"`
+}
+
+// UnsupportedFieldError is returned when the configuration uses a setting Argus doesn't support
+type UnsupportedFieldError struct {
+	// Path of the setting, e.g. "receivers[0].slack_configs"
+	Path string
+}
+
+func (e *UnsupportedFieldError) Error() string {
+	return fmt.Sprintf("%s: not supported by Argus", e.Path)
+}
+
+// Unmarshal parses an Alertmanager configuration file.
+// It returns an UnsupportedFieldError if the configuration uses a setting that isn't supported by Argus.
+func Unmarshal(data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	if len(doc.Content) > 0 {
+		if err := checkFields(doc.Content[0], reflect.TypeOf(Config{}), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{}
+	if err := doc.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	return cfg, nil
+}
+
+// Marshal renders the configuration as YAML
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkFields checks that the keys of the YAML mappings in node are fields of the type t, recursively.
+// Nodes not matching the kind of t are left for the decoder to report.
+func checkFields(node *yaml.Node, t reflect.Type, path string) error {
+	node = resolveAlias(node)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, elem := range node.Content {
+			if err := checkFields(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			fieldPath := strings.TrimPrefix(path+"."+key, ".")
+			field, ok := fieldByYAMLName(t, key)
+			if !ok {
+				return &UnsupportedFieldError{Path: fieldPath}
+			}
+			if err := checkFields(node.Content[i+1], field.Type, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tagName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tagName == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
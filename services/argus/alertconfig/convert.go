@@ -0,0 +1,609 @@
+package alertconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+var receiverNameRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// Default values of send_resolved in Alertmanager, which Argus applies as it doesn't support setting them
+const (
+	defaultEmailSendResolved    = false
+	defaultOpsgenieSendResolved = true
+	defaultWebhookSendResolved  = true
+)
+
+// Import parses an Alertmanager configuration file and returns the payload to update the alert configs of an Argus instance
+func Import(data []byte) (*argus.UpdateAlertConfigsPayload, error) {
+	cfg, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Payload()
+}
+
+// Export renders the alert configs of an Argus instance as an Alertmanager configuration file.
+// The response models don't hold the matchers of the root route, nor the settings of the routes below the child routes
+// other than the receiver, intervals and continue: Export returns an error for the latter, use ExportJSON to export them.
+func Export(resp *argus.GetAlertConfigsResponse) ([]byte, error) {
+	if resp == nil || resp.Data == nil {
+		return nil, fmt.Errorf("response is missing the alert configs")
+	}
+	cfg, err := FromAlert(resp.Data)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Marshal()
+}
+
+// ExportJSON renders the alert configs of an Argus instance as an Alertmanager configuration file,
+// given the JSON body of the GetAlertConfigs response, e.g. captured with runtime.WithCaptureHTTPResponse.
+// Unlike Export, it keeps all settings of the routing tree.
+func ExportJSON(body []byte) ([]byte, error) {
+	var resp struct {
+		Data *struct {
+			argus.Alert
+			Route *apiRoute `json:"route"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if resp.Data == nil {
+		return nil, fmt.Errorf("response is missing the alert configs")
+	}
+	cfg := fromAlert(&resp.Data.Alert)
+	if resp.Data.Route != nil {
+		route := resp.Data.Route.route()
+		cfg.Route = &route
+	}
+	return cfg.Marshal()
+}
+
+// apiRoute is a route of the alert configs as returned by the API, including the settings the response models don't hold
+type apiRoute struct {
+	Receiver       string            `json:"receiver"`
+	GroupBy        []string          `json:"groupBy"`
+	Continue       bool              `json:"continue"`
+	Match          map[string]string `json:"match"`
+	MatchRe        map[string]string `json:"matchRe"`
+	Matchers       []string          `json:"matchers"`
+	GroupWait      string            `json:"groupWait"`
+	GroupInterval  string            `json:"groupInterval"`
+	RepeatInterval string            `json:"repeatInterval"`
+	Routes         []apiRoute        `json:"routes"`
+}
+
+func (r *apiRoute) route() Route {
+	route := Route{
+		Receiver:       r.Receiver,
+		GroupBy:        sliceOf(&r.GroupBy),
+		Continue:       r.Continue,
+		Match:          mapOf(&r.Match),
+		MatchRe:        mapOf(&r.MatchRe),
+		Matchers:       sliceOf(&r.Matchers),
+		GroupWait:      r.GroupWait,
+		GroupInterval:  r.GroupInterval,
+		RepeatInterval: r.RepeatInterval,
+	}
+	for i := range r.Routes {
+		route.Routes = append(route.Routes, r.Routes[i].route())
+	}
+	return route
+}
+
+// Validate checks that the configuration has a root route with a receiver, that the receiver names are unique
+// and valid in Argus, and that every route references an existing receiver
+func (c *Config) Validate() error {
+	receivers := map[string]bool{}
+	for i := range c.Receivers {
+		name := c.Receivers[i].Name
+		if !receiverNameRegex.MatchString(name) {
+			return fmt.Errorf("receivers[%d]: name %q must only contain the characters a-zA-Z0-9-", i, name)
+		}
+		if receivers[name] {
+			return fmt.Errorf("receivers[%d]: duplicate name %q", i, name)
+		}
+		receivers[name] = true
+	}
+	if c.Route == nil {
+		return fmt.Errorf("route is required")
+	}
+	if c.Route.Receiver == "" {
+		return fmt.Errorf("route: receiver is required")
+	}
+	return validateRoute(c.Route, "route", receivers)
+}
+
+func validateRoute(route *Route, path string, receivers map[string]bool) error {
+	if route.Receiver != "" && !receivers[route.Receiver] {
+		return fmt.Errorf("%s: receiver %q doesn't exist", path, route.Receiver)
+	}
+	for i := range route.Routes {
+		if err := validateRoute(&route.Routes[i], fmt.Sprintf("%s.routes[%d]", path, i), receivers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Payload validates the configuration and returns the payload to update the alert configs of an Argus instance.
+// It returns an UnsupportedFieldError for settings the payload can't express, e.g. continue and matchers in child routes,
+// matchers in inhibit rules, more than one inhibit rule, or send_resolved values different from the Alertmanager defaults.
+func (c *Config) Payload() (*argus.UpdateAlertConfigsPayload, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	route, err := c.Route.rootPayload()
+	if err != nil {
+		return nil, err
+	}
+	payload := &argus.UpdateAlertConfigsPayload{
+		Global: c.Global.payload(),
+		Route:  route,
+	}
+
+	receivers := make([]argus.UpdateAlertConfigsPayloadReceiversInner, 0, len(c.Receivers))
+	for i := range c.Receivers {
+		receiver, err := c.Receivers[i].payload(fmt.Sprintf("receivers[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		receivers = append(receivers, receiver)
+	}
+	payload.Receivers = &receivers
+
+	switch len(c.InhibitRules) {
+	case 0:
+	case 1:
+		inhibitRules, err := c.InhibitRules[0].payload("inhibit_rules[0]")
+		if err != nil {
+			return nil, err
+		}
+		payload.InhibitRules = inhibitRules
+	default:
+		return nil, &UnsupportedFieldError{Path: "inhibit_rules[1]"}
+	}
+	return payload, nil
+}
+
+func (g *GlobalConfig) payload() *argus.UpdateAlertConfigsPayloadGlobal {
+	if g == nil {
+		return nil
+	}
+	return &argus.UpdateAlertConfigsPayloadGlobal{
+		ResolveTimeout:   stringPtr(g.ResolveTimeout),
+		SmtpFrom:         stringPtr(g.SmtpFrom),
+		SmtpSmarthost:    stringPtr(g.SmtpSmarthost),
+		SmtpAuthUsername: stringPtr(g.SmtpAuthUsername),
+		SmtpAuthPassword: stringPtr(g.SmtpAuthPassword),
+		SmtpAuthIdentity: stringPtr(g.SmtpAuthIdentity),
+		OpsgenieApiKey:   stringPtr(g.OpsgenieApiKey),
+		OpsgenieApiUrl:   stringPtr(g.OpsgenieApiUrl),
+	}
+}
+
+func (r *Route) rootPayload() (*argus.UpdateAlertConfigsPayloadRoute, error) {
+	if r.Continue {
+		return nil, &UnsupportedFieldError{Path: "route.continue"}
+	}
+	payload := &argus.UpdateAlertConfigsPayloadRoute{
+		Receiver:       stringPtr(r.Receiver),
+		GroupBy:        slicePtr(r.GroupBy),
+		GroupWait:      stringPtr(r.GroupWait),
+		GroupInterval:  stringPtr(r.GroupInterval),
+		RepeatInterval: stringPtr(r.RepeatInterval),
+		Match:          matchPayload(r.Match),
+		MatchRe:        matchPayload(r.MatchRe),
+		Matchers:       slicePtr(r.Matchers),
+	}
+	if len(r.Routes) > 0 {
+		routes := make([]argus.CreateAlertConfigRoutePayloadRoutesInner, 0, len(r.Routes))
+		for i := range r.Routes {
+			child, err := r.Routes[i].childPayload(fmt.Sprintf("route.routes[%d]", i))
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, child)
+		}
+		payload.Routes = &routes
+	}
+	return payload, nil
+}
+
+func (r *Route) childPayload(path string) (argus.CreateAlertConfigRoutePayloadRoutesInner, error) {
+	if err := r.checkChild(path); err != nil {
+		return argus.CreateAlertConfigRoutePayloadRoutesInner{}, err
+	}
+	payload := argus.CreateAlertConfigRoutePayloadRoutesInner{
+		Receiver:       stringPtr(r.Receiver),
+		GroupBy:        slicePtr(r.GroupBy),
+		GroupWait:      stringPtr(r.GroupWait),
+		GroupInterval:  stringPtr(r.GroupInterval),
+		RepeatInterval: stringPtr(r.RepeatInterval),
+		Match:          matchPayload(r.Match),
+		MatchRe:        matchPayload(r.MatchRe),
+	}
+	if len(r.Routes) > 0 {
+		routes, err := nestedRoutesPayload(r.Routes, path)
+		if err != nil {
+			return argus.CreateAlertConfigRoutePayloadRoutesInner{}, err
+		}
+		payload.Routes = &routes
+	}
+	return payload, nil
+}
+
+// nestedRoutesPayload returns the routes below the child routes, which the payload only holds as untyped JSON objects
+func nestedRoutesPayload(routes []Route, path string) ([]map[string]interface{}, error) {
+	payload := make([]map[string]interface{}, 0, len(routes))
+	for i := range routes {
+		r := &routes[i]
+		routePath := fmt.Sprintf("%s.routes[%d]", path, i)
+		if err := r.checkChild(routePath); err != nil {
+			return nil, err
+		}
+		route := map[string]interface{}{}
+		setIfNotEmpty(route, "receiver", r.Receiver)
+		setIfNotEmpty(route, "groupWait", r.GroupWait)
+		setIfNotEmpty(route, "groupInterval", r.GroupInterval)
+		setIfNotEmpty(route, "repeatInterval", r.RepeatInterval)
+		if len(r.GroupBy) > 0 {
+			route["groupBy"] = append([]string{}, r.GroupBy...)
+		}
+		if len(r.Match) > 0 {
+			route["match"] = *matchPayload(r.Match)
+		}
+		if len(r.MatchRe) > 0 {
+			route["matchRe"] = *matchPayload(r.MatchRe)
+		}
+		if len(r.Routes) > 0 {
+			children, err := nestedRoutesPayload(r.Routes, routePath)
+			if err != nil {
+				return nil, err
+			}
+			route["routes"] = children
+		}
+		payload = append(payload, route)
+	}
+	return payload, nil
+}
+
+// checkChild checks that the child route only uses settings supported in the payload
+func (r *Route) checkChild(path string) error {
+	if r.Continue {
+		return &UnsupportedFieldError{Path: path + ".continue"}
+	}
+	if len(r.Matchers) > 0 {
+		return &UnsupportedFieldError{Path: path + ".matchers"}
+	}
+	return nil
+}
+
+func (r *Receiver) payload(path string) (argus.UpdateAlertConfigsPayloadReceiversInner, error) {
+	name := r.Name
+	payload := argus.UpdateAlertConfigsPayloadReceiversInner{Name: &name}
+
+	if len(r.EmailConfigs) > 0 {
+		configs := make([]argus.CreateAlertConfigReceiverPayloadEmailConfigsInner, 0, len(r.EmailConfigs))
+		for i := range r.EmailConfigs {
+			c := &r.EmailConfigs[i]
+			if err := checkSendResolved(c.SendResolved, defaultEmailSendResolved, fmt.Sprintf("%s.email_configs[%d]", path, i)); err != nil {
+				return argus.UpdateAlertConfigsPayloadReceiversInner{}, err
+			}
+			configs = append(configs, argus.CreateAlertConfigReceiverPayloadEmailConfigsInner{
+				To:           stringPtr(c.To),
+				From:         stringPtr(c.From),
+				Smarthost:    stringPtr(c.Smarthost),
+				AuthUsername: stringPtr(c.AuthUsername),
+				AuthPassword: stringPtr(c.AuthPassword),
+				AuthIdentity: stringPtr(c.AuthIdentity),
+			})
+		}
+		payload.EmailConfigs = &configs
+	}
+
+	if len(r.OpsgenieConfigs) > 0 {
+		configs := make([]argus.CreateAlertConfigReceiverPayloadOpsgenieConfigsInner, 0, len(r.OpsgenieConfigs))
+		for i := range r.OpsgenieConfigs {
+			c := &r.OpsgenieConfigs[i]
+			configPath := fmt.Sprintf("%s.opsgenie_configs[%d]", path, i)
+			if err := checkSendResolved(c.SendResolved, defaultOpsgenieSendResolved, configPath); err != nil {
+				return argus.UpdateAlertConfigsPayloadReceiversInner{}, err
+			}
+			if c.Priority != "" {
+				return argus.UpdateAlertConfigsPayloadReceiversInner{}, &UnsupportedFieldError{Path: configPath + ".priority"}
+			}
+			configs = append(configs, argus.CreateAlertConfigReceiverPayloadOpsgenieConfigsInner{
+				ApiKey: stringPtr(c.ApiKey),
+				ApiUrl: stringPtr(c.ApiUrl),
+				Tags:   stringPtr(c.Tags),
+			})
+		}
+		payload.OpsgenieConfigs = &configs
+	}
+
+	if len(r.WebhookConfigs)+len(r.MsteamsConfigs) > 0 {
+		configs := make([]argus.CreateAlertConfigReceiverPayloadWebHookConfigsInner, 0, len(r.WebhookConfigs)+len(r.MsteamsConfigs))
+		for i := range r.WebhookConfigs {
+			c := &r.WebhookConfigs[i]
+			if err := checkSendResolved(c.SendResolved, defaultWebhookSendResolved, fmt.Sprintf("%s.webhook_configs[%d]", path, i)); err != nil {
+				return argus.UpdateAlertConfigsPayloadReceiversInner{}, err
+			}
+			configs = append(configs, argus.CreateAlertConfigReceiverPayloadWebHookConfigsInner{Url: stringPtr(c.Url)})
+		}
+		for i := range r.MsteamsConfigs {
+			c := &r.MsteamsConfigs[i]
+			if err := checkSendResolved(c.SendResolved, defaultWebhookSendResolved, fmt.Sprintf("%s.msteams_configs[%d]", path, i)); err != nil {
+				return argus.UpdateAlertConfigsPayloadReceiversInner{}, err
+			}
+			msTeams := true
+			configs = append(configs, argus.CreateAlertConfigReceiverPayloadWebHookConfigsInner{Url: stringPtr(c.WebhookUrl), MsTeams: &msTeams})
+		}
+		payload.WebHookConfigs = &configs
+	}
+	return payload, nil
+}
+
+func (r *InhibitRule) payload(path string) (*argus.UpdateAlertConfigsPayloadInhibitRules, error) {
+	if len(r.SourceMatchers) > 0 {
+		return nil, &UnsupportedFieldError{Path: path + ".source_matchers"}
+	}
+	if len(r.TargetMatchers) > 0 {
+		return nil, &UnsupportedFieldError{Path: path + ".target_matchers"}
+	}
+	return &argus.UpdateAlertConfigsPayloadInhibitRules{
+		SourceMatch:   matchPayload(r.SourceMatch),
+		SourceMatchRe: matchPayload(r.SourceMatchRe),
+		TargetMatch:   matchPayload(r.TargetMatch),
+		TargetMatchRe: matchPayload(r.TargetMatchRe),
+		Equal:         slicePtr(r.Equal),
+	}, nil
+}
+
+// checkSendResolved returns an UnsupportedFieldError if send_resolved is set to another value than the Alertmanager default
+func checkSendResolved(sendResolved *bool, defaultValue bool, path string) error {
+	if sendResolved != nil && *sendResolved != defaultValue {
+		return &UnsupportedFieldError{Path: path + ".send_resolved"}
+	}
+	return nil
+}
+
+// FromAlert returns the Alertmanager configuration of the alert configs of an Argus instance.
+// It returns an error if a route below a child route has settings that can't be read from the response models, see Export.
+func FromAlert(alert *argus.Alert) (*Config, error) {
+	cfg := fromAlert(alert)
+	if alert.Route != nil {
+		route, err := fromRoute(alert.Route)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Route = route
+	}
+	return cfg, nil
+}
+
+// fromAlert returns the Alertmanager configuration of the alert configs, without the route
+func fromAlert(alert *argus.Alert) *Config {
+	cfg := &Config{Receivers: []Receiver{}}
+	if alert.Global != nil {
+		cfg.Global = &GlobalConfig{
+			ResolveTimeout:   valueOf(alert.Global.ResolveTimeout),
+			SmtpFrom:         valueOf(alert.Global.SmtpFrom),
+			SmtpSmarthost:    valueOf(alert.Global.SmtpSmarthost),
+			SmtpAuthUsername: valueOf(alert.Global.SmtpAuthUsername),
+			SmtpAuthPassword: valueOf(alert.Global.SmtpAuthPassword),
+			SmtpAuthIdentity: valueOf(alert.Global.SmtpAuthIdentity),
+			OpsgenieApiKey:   valueOf(alert.Global.OpsgenieApiKey),
+			OpsgenieApiUrl:   valueOf(alert.Global.OpsgenieApiUrl),
+		}
+	}
+	if alert.Receivers != nil {
+		for i := range *alert.Receivers {
+			cfg.Receivers = append(cfg.Receivers, fromReceiver(&(*alert.Receivers)[i]))
+		}
+	}
+	if alert.InhibitRules != nil {
+		for _, r := range *alert.InhibitRules {
+			cfg.InhibitRules = append(cfg.InhibitRules, InhibitRule{
+				SourceMatch:    mapOf(r.SourceMatch),
+				SourceMatchRe:  mapOf(r.SourceMatchRe),
+				SourceMatchers: sliceOf(r.SourceMatchers),
+				TargetMatch:    mapOf(r.TargetMatch),
+				TargetMatchRe:  mapOf(r.TargetMatchRe),
+				TargetMatchers: sliceOf(r.TargetMatchers),
+				Equal:          sliceOf(r.Equal),
+			})
+		}
+	}
+	return cfg
+}
+
+func fromRoute(r *argus.Route) (*Route, error) {
+	route := &Route{
+		Receiver:       valueOf(r.Receiver),
+		GroupBy:        sliceOf(r.GroupBy),
+		Continue:       valueOf(r.Continue),
+		Match:          mapOf(r.Match),
+		MatchRe:        mapOf(r.MatchRe),
+		GroupWait:      valueOf(r.GroupWait),
+		GroupInterval:  valueOf(r.GroupInterval),
+		RepeatInterval: valueOf(r.RepeatInterval),
+	}
+	if r.Routes != nil {
+		for i := range *r.Routes {
+			child, err := fromRouteSerializer(&(*r.Routes)[i], fmt.Sprintf("route.routes[%d]", i))
+			if err != nil {
+				return nil, err
+			}
+			route.Routes = append(route.Routes, child)
+		}
+	}
+	return route, nil
+}
+
+func fromRouteSerializer(r *argus.RouteSerializer, path string) (Route, error) {
+	route := Route{
+		Receiver:       valueOf(r.Receiver),
+		GroupBy:        sliceOf(r.GroupBy),
+		Continue:       valueOf(r.Continue),
+		Match:          mapOf(r.Match),
+		MatchRe:        mapOf(r.MatchRe),
+		Matchers:       sliceOf(r.Matchers),
+		GroupWait:      valueOf(r.GroupWait),
+		GroupInterval:  valueOf(r.GroupInterval),
+		RepeatInterval: valueOf(r.RepeatInterval),
+	}
+	if r.Routes != nil {
+		for i, nested := range *r.Routes {
+			nestedRoute, err := fromNestedRoute(nested, fmt.Sprintf("%s.routes[%d]", path, i))
+			if err != nil {
+				return Route{}, err
+			}
+			route.Routes = append(route.Routes, nestedRoute)
+		}
+	}
+	return route, nil
+}
+
+// fromNestedRoute returns the route below a child route, which the response models only hold as string settings.
+// It returns an error for any other setting, instead of dropping it.
+func fromNestedRoute(r map[string]string, path string) (Route, error) {
+	route := Route{}
+	for key, value := range r {
+		switch key {
+		case "receiver":
+			route.Receiver = value
+		case "groupWait":
+			route.GroupWait = value
+		case "groupInterval":
+			route.GroupInterval = value
+		case "repeatInterval":
+			route.RepeatInterval = value
+		case "continue":
+			var err error
+			if route.Continue, err = strconv.ParseBool(value); err != nil {
+				return Route{}, fmt.Errorf("%s: invalid continue %q", path, value)
+			}
+		default:
+			return Route{}, fmt.Errorf("%s: %s can't be read from the response models, use ExportJSON", path, key)
+		}
+	}
+	return route, nil
+}
+
+func fromReceiver(r *argus.Receivers) Receiver {
+	receiver := Receiver{Name: valueOf(r.Name)}
+	if r.EmailConfigs != nil {
+		for _, c := range *r.EmailConfigs {
+			receiver.EmailConfigs = append(receiver.EmailConfigs, EmailConfig{
+				SendResolved: copyPtr(c.SendResolved),
+				To:           valueOf(c.To),
+				From:         valueOf(c.From),
+				Smarthost:    valueOf(c.Smarthost),
+				AuthUsername: valueOf(c.AuthUsername),
+				AuthPassword: valueOf(c.AuthPassword),
+				AuthIdentity: valueOf(c.AuthIdentity),
+			})
+		}
+	}
+	if r.OpsgenieConfigs != nil {
+		for _, c := range *r.OpsgenieConfigs {
+			receiver.OpsgenieConfigs = append(receiver.OpsgenieConfigs, OpsgenieConfig{
+				SendResolved: copyPtr(c.SendResolved),
+				ApiKey:       valueOf(c.ApiKey),
+				ApiUrl:       valueOf(c.ApiUrl),
+				Priority:     valueOf(c.Priority),
+				Tags:         valueOf(c.Tags),
+			})
+		}
+	}
+	if r.WebHookConfigs != nil {
+		for _, c := range *r.WebHookConfigs {
+			if valueOf(c.MsTeams) {
+				receiver.MsteamsConfigs = append(receiver.MsteamsConfigs, MsteamsConfig{
+					SendResolved: copyPtr(c.SendResolved),
+					WebhookUrl:   valueOf(c.Url),
+				})
+				continue
+			}
+			receiver.WebhookConfigs = append(receiver.WebhookConfigs, WebhookConfig{
+				SendResolved: copyPtr(c.SendResolved),
+				Url:          valueOf(c.Url),
+			})
+		}
+	}
+	return receiver
+}
+
+func matchPayload(m map[string]string) *map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	payload := map[string]interface{}{}
+	for k, v := range m {
+		payload[k] = v
+	}
+	return &payload
+}
+
+func setIfNotEmpty(m map[string]interface{}, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func slicePtr(s []string) *[]string {
+	if len(s) == 0 {
+		return nil
+	}
+	c := append([]string{}, s...)
+	return &c
+}
+
+func sliceOf(p *[]string) []string {
+	if p == nil || len(*p) == 0 {
+		return nil
+	}
+	return append([]string{}, *p...)
+}
+
+func mapOf(p *map[string]string) map[string]string {
+	if p == nil || len(*p) == 0 {
+		return nil
+	}
+	m := map[string]string{}
+	for k, v := range *p {
+		m[k] = v
+	}
+	return m
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,479 @@
+// Package reconcile syncs the scrape configs of an Argus instance with a desired set of jobs.
+package reconcile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus/scrapeconfig"
+)
+
+// Default values applied by Argus, and Prometheus, to settings that are not set
+const (
+	defaultScheme             = "http"
+	defaultMetricsPath        = "/metrics"
+	defaultHonorTimeStamps    = true
+	defaultRelabelSeparator   = ";"
+	defaultRelabelRegex       = "(.*)"
+	defaultRelabelReplacement = "$1"
+	defaultRelabelAction      = "replace"
+)
+
+// ChangeType is the kind of change applied to a scrape config
+type ChangeType string
+
+const (
+	// ChangeCreate creates a new scrape config
+	ChangeCreate ChangeType = "create"
+	// ChangeUpdate updates a scrape config with UpdateScrapeConfig
+	ChangeUpdate ChangeType = "update"
+	// ChangeReplace deletes and recreates a scrape config, for changes UpdateScrapeConfig can't express,
+	// i.e. when the current or desired job has HTTP service discovery or OAuth2 configured
+	ChangeReplace ChangeType = "replace"
+	// ChangeDelete deletes a scrape config
+	ChangeDelete ChangeType = "delete"
+)
+
+// Change is a change needed to reconcile a scrape config
+type Change struct {
+	Type    ChangeType
+	JobName string
+	// Payload is the desired scrape config, nil for ChangeDelete
+	Payload *argus.CreateScrapeConfigPayload
+	// Differences holds the settings that differ, for ChangeUpdate and ChangeReplace
+	Differences []string
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s", c.Type, c.JobName)
+	if len(c.Differences) > 0 {
+		s = fmt.Sprintf("%s (%s)", s, strings.Join(c.Differences, ", "))
+	}
+	return s
+}
+
+// Plan is the list of changes needed to reconcile the scrape configs of an instance, in the order they are applied:
+// creates, then updates and replaces, then deletes
+type Plan struct {
+	Changes []Change
+}
+
+// IsEmpty reports whether the scrape configs are already reconciled
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns the changes of the plan, one per line
+func (p *Plan) String() string {
+	if p.IsEmpty() {
+		return "no changes"
+	}
+	lines := make([]string, len(p.Changes))
+	for i, c := range p.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Options configures how scrape configs are reconciled
+type Options struct {
+	// JobNamePrefix restricts the scrape configs managed by Sync to the jobs whose name starts with the prefix.
+	// Desired jobs must have the prefix. If empty, all scrape configs of the instance are managed.
+	JobNamePrefix string
+	// DryRun makes Sync compute the plan without applying it
+	DryRun bool
+}
+
+// owns reports whether the job is managed by Sync
+func (o *Options) owns(jobName string) bool {
+	return strings.HasPrefix(jobName, o.JobNamePrefix)
+}
+
+// ComputePlan computes the changes needed to turn the current jobs of an instance, as returned by ListScrapeConfigs, into the desired ones.
+// Jobs are compared semantically: durations are compared by their value, e.g. "60s" equals "1m", and unset settings equal their default
+// values. Managed jobs (see Options.JobNamePrefix) that are not desired are deleted.
+func ComputePlan(current []argus.Job, desired []argus.CreateScrapeConfigPayload, opts Options) (*Plan, error) {
+	existing := map[string]*argus.Job{}
+	for i := range current {
+		job := &current[i]
+		if job.JobName == nil {
+			return nil, fmt.Errorf("job is missing its name")
+		}
+		existing[*job.JobName] = job
+	}
+
+	creates, updates := []Change{}, []Change{}
+	seen := map[string]bool{}
+	for i := range desired {
+		d := &desired[i]
+		if d.JobName == nil || *d.JobName == "" {
+			return nil, fmt.Errorf("desired job %d is missing its name", i)
+		}
+		jobName := *d.JobName
+		if !opts.owns(jobName) {
+			return nil, fmt.Errorf("desired job %s doesn't have the prefix %q", jobName, opts.JobNamePrefix)
+		}
+		if seen[jobName] {
+			return nil, fmt.Errorf("desired job %s is duplicated", jobName)
+		}
+		seen[jobName] = true
+
+		want, err := normalizePayload(d)
+		if err != nil {
+			return nil, fmt.Errorf("desired job %s: %w", jobName, err)
+		}
+		cur, ok := existing[jobName]
+		if !ok {
+			creates = append(creates, Change{Type: ChangeCreate, JobName: jobName, Payload: d})
+			continue
+		}
+		got, err := normalizeJob(cur)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", jobName, err)
+		}
+		differences := diff(got, want)
+		if len(differences) == 0 {
+			continue
+		}
+		changeType := ChangeUpdate
+		if got.needsReplace() || want.needsReplace() {
+			changeType = ChangeReplace
+		}
+		updates = append(updates, Change{Type: changeType, JobName: jobName, Payload: d, Differences: differences})
+	}
+
+	deletes := []Change{}
+	for jobName := range existing {
+		if seen[jobName] || !opts.owns(jobName) {
+			continue
+		}
+		deletes = append(deletes, Change{Type: ChangeDelete, JobName: jobName})
+	}
+	sort.Slice(deletes, func(i, j int) bool { return deletes[i].JobName < deletes[j].JobName })
+
+	plan := &Plan{}
+	plan.Changes = append(plan.Changes, creates...)
+	plan.Changes = append(plan.Changes, updates...)
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// normalizedJob holds the settings of a job with defaults applied, in a form that can be compared with reflect.DeepEqual.
+// The diff tags name the settings in Change.Differences.
+type normalizedJob struct {
+	Scheme                string               `diff:"scheme"`
+	MetricsPath           string               `diff:"metricsPath"`
+	ScrapeInterval        time.Duration        `diff:"scrapeInterval"`
+	ScrapeTimeout         time.Duration        `diff:"scrapeTimeout"`
+	HonorLabels           bool                 `diff:"honorLabels"`
+	HonorTimeStamps       bool                 `diff:"honorTimeStamps"`
+	SampleLimit           int64                `diff:"sampleLimit"`
+	Params                map[string][]string  `diff:"params"`
+	BearerToken           string               `diff:"bearerToken"`
+	BasicAuth             *normalizedBasicAuth `diff:"basicAuth"`
+	Oauth2                *normalizedOauth2    `diff:"oauth2"`
+	TlsInsecure           bool                 `diff:"tlsConfig"`
+	StaticConfigs         []normalizedStatic   `diff:"staticConfigs"`
+	HttpSdConfigs         []normalizedHttpSd   `diff:"httpSdConfigs"`
+	MetricsRelabelConfigs []normalizedRelabel  `diff:"metricsRelabelConfigs"`
+}
+
+type normalizedBasicAuth struct {
+	Username string
+	Password string
+}
+
+type normalizedOauth2 struct {
+	ClientId     string
+	ClientSecret string
+	TokenUrl     string
+	Scopes       []string
+	TlsInsecure  bool
+}
+
+type normalizedStatic struct {
+	Targets []string
+	Labels  map[string]string
+}
+
+type normalizedHttpSd struct {
+	Url             string
+	RefreshInterval time.Duration
+	BasicAuth       *normalizedBasicAuth
+	Oauth2          *normalizedOauth2
+	TlsInsecure     bool
+}
+
+type normalizedRelabel struct {
+	SourceLabels []string
+	Separator    string
+	Regex        string
+	Modulus      int64
+	TargetLabel  string
+	Replacement  string
+	Action       string
+}
+
+// needsReplace reports whether the job has settings UpdateScrapeConfigPayload can't hold
+func (j *normalizedJob) needsReplace() bool {
+	return j.Oauth2 != nil || len(j.HttpSdConfigs) > 0
+}
+
+// diff returns the names of the settings that differ between the jobs
+func diff(a, b *normalizedJob) []string {
+	differences := []string{}
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			differences = append(differences, t.Field(i).Tag.Get("diff"))
+		}
+	}
+	return differences
+}
+
+func normalizeJob(job *argus.Job) (*normalizedJob, error) {
+	n := &normalizedJob{
+		Scheme:          stringOrDefault(job.Scheme, defaultScheme),
+		MetricsPath:     stringOrDefault(job.MetricsPath, defaultMetricsPath),
+		HonorLabels:     boolOrDefault(job.HonorLabels, false),
+		HonorTimeStamps: boolOrDefault(job.HonorTimeStamps, defaultHonorTimeStamps),
+		BearerToken:     stringOrDefault(job.BearerToken, ""),
+		TlsInsecure:     tlsInsecure(job.TlsConfig),
+	}
+	var err error
+	if n.ScrapeInterval, err = parseDuration(job.ScrapeInterval); err != nil {
+		return nil, fmt.Errorf("scrapeInterval: %w", err)
+	}
+	if n.ScrapeTimeout, err = parseDuration(job.ScrapeTimeout); err != nil {
+		return nil, fmt.Errorf("scrapeTimeout: %w", err)
+	}
+	if job.SampleLimit != nil {
+		n.SampleLimit = *job.SampleLimit
+	}
+	if job.Params != nil && len(*job.Params) > 0 {
+		n.Params = map[string][]string{}
+		for k, v := range *job.Params {
+			n.Params[k] = append([]string{}, v...)
+		}
+	}
+	if job.BasicAuth != nil {
+		n.BasicAuth = &normalizedBasicAuth{Username: stringOrDefault(job.BasicAuth.Username, ""), Password: stringOrDefault(job.BasicAuth.Password, "")}
+	}
+	n.Oauth2 = normalizeOauth2(job.Oauth2)
+	if job.StaticConfigs != nil {
+		for _, c := range *job.StaticConfigs {
+			s := normalizedStatic{Targets: sortedStrings(c.Targets)}
+			if c.Labels != nil && len(*c.Labels) > 0 {
+				s.Labels = map[string]string{}
+				for k, v := range *c.Labels {
+					s.Labels[k] = v
+				}
+			}
+			n.StaticConfigs = append(n.StaticConfigs, s)
+		}
+	}
+	if job.HttpSdConfigs != nil {
+		for _, c := range *job.HttpSdConfigs {
+			h := normalizedHttpSd{
+				Url:         stringOrDefault(c.Url, ""),
+				Oauth2:      normalizeOauth2(c.Oauth2),
+				TlsInsecure: tlsInsecure(c.TlsConfig),
+			}
+			if c.RefreshInterval != nil {
+				if h.RefreshInterval, err = parseDuration(c.RefreshInterval); err != nil {
+					return nil, fmt.Errorf("httpSdConfigs refreshInterval: %w", err)
+				}
+			}
+			if c.BasicAuth != nil {
+				h.BasicAuth = &normalizedBasicAuth{Username: stringOrDefault(c.BasicAuth.Username, ""), Password: stringOrDefault(c.BasicAuth.Password, "")}
+			}
+			n.HttpSdConfigs = append(n.HttpSdConfigs, h)
+		}
+	}
+	if job.MetricsRelabelConfigs != nil {
+		for _, c := range *job.MetricsRelabelConfigs {
+			r := normalizedRelabel{
+				SourceLabels: sliceOf(c.SourceLabels),
+				Separator:    stringOrDefault(c.Separator, defaultRelabelSeparator),
+				Regex:        stringOrDefault(c.Regex, defaultRelabelRegex),
+				TargetLabel:  stringOrDefault(c.TargetLabel, ""),
+				Replacement:  stringOrDefault(c.Replacement, defaultRelabelReplacement),
+				Action:       strings.ToLower(stringOrDefault(c.Action, defaultRelabelAction)),
+			}
+			if c.Modulus != nil {
+				r.Modulus = *c.Modulus
+			}
+			n.MetricsRelabelConfigs = append(n.MetricsRelabelConfigs, r)
+		}
+	}
+	return n, nil
+}
+
+func normalizePayload(payload *argus.CreateScrapeConfigPayload) (*normalizedJob, error) {
+	n := &normalizedJob{
+		Scheme:          stringOrDefault(payload.Scheme, defaultScheme),
+		MetricsPath:     stringOrDefault(payload.MetricsPath, defaultMetricsPath),
+		HonorLabels:     boolOrDefault(payload.HonorLabels, false),
+		HonorTimeStamps: boolOrDefault(payload.HonorTimeStamps, defaultHonorTimeStamps),
+		BearerToken:     stringOrDefault(payload.BearerToken, ""),
+		TlsInsecure:     payloadTlsInsecure(payload.TlsConfig),
+	}
+	var err error
+	if n.ScrapeInterval, err = parseDuration(payload.ScrapeInterval); err != nil {
+		return nil, fmt.Errorf("scrapeInterval: %w", err)
+	}
+	if n.ScrapeTimeout, err = parseDuration(payload.ScrapeTimeout); err != nil {
+		return nil, fmt.Errorf("scrapeTimeout: %w", err)
+	}
+	if payload.SampleLimit != nil {
+		n.SampleLimit = int64(*payload.SampleLimit)
+	}
+	if payload.Params != nil && len(*payload.Params) > 0 {
+		n.Params = map[string][]string{}
+		for k, v := range *payload.Params {
+			n.Params[k] = stringValues(v)
+		}
+	}
+	if payload.BasicAuth != nil {
+		n.BasicAuth = &normalizedBasicAuth{Username: stringOrDefault(payload.BasicAuth.Username, ""), Password: stringOrDefault(payload.BasicAuth.Password, "")}
+	}
+	n.Oauth2 = normalizePayloadOauth2(payload.Oauth2)
+	if payload.StaticConfigs != nil {
+		for _, c := range *payload.StaticConfigs {
+			s := normalizedStatic{Targets: sortedStrings(c.Targets)}
+			if c.Labels != nil && len(*c.Labels) > 0 {
+				s.Labels = map[string]string{}
+				for k, v := range *c.Labels {
+					s.Labels[k] = fmt.Sprint(v)
+				}
+			}
+			n.StaticConfigs = append(n.StaticConfigs, s)
+		}
+	}
+	if payload.HttpSdConfigs != nil {
+		for _, c := range *payload.HttpSdConfigs {
+			h := normalizedHttpSd{
+				Url:         stringOrDefault(c.Url, ""),
+				Oauth2:      normalizePayloadOauth2(c.Oauth2),
+				TlsInsecure: payloadTlsInsecure(c.TlsConfig),
+			}
+			if c.RefreshInterval != nil {
+				if h.RefreshInterval, err = parseDuration(c.RefreshInterval); err != nil {
+					return nil, fmt.Errorf("httpSdConfigs refreshInterval: %w", err)
+				}
+			}
+			if c.BasicAuth != nil {
+				h.BasicAuth = &normalizedBasicAuth{Username: stringOrDefault(c.BasicAuth.Username, ""), Password: stringOrDefault(c.BasicAuth.Password, "")}
+			}
+			n.HttpSdConfigs = append(n.HttpSdConfigs, h)
+		}
+	}
+	if payload.MetricsRelabelConfigs != nil {
+		for _, c := range *payload.MetricsRelabelConfigs {
+			r := normalizedRelabel{
+				SourceLabels: sliceOf(c.SourceLabels),
+				Separator:    stringOrDefault(c.Separator, defaultRelabelSeparator),
+				Regex:        stringOrDefault(c.Regex, defaultRelabelRegex),
+				TargetLabel:  stringOrDefault(c.TargetLabel, ""),
+				Replacement:  stringOrDefault(c.Replacement, defaultRelabelReplacement),
+				Action:       strings.ToLower(stringOrDefault(c.Action, defaultRelabelAction)),
+			}
+			if c.Modulus != nil {
+				r.Modulus = int64(*c.Modulus)
+			}
+			n.MetricsRelabelConfigs = append(n.MetricsRelabelConfigs, r)
+		}
+	}
+	return n, nil
+}
+
+func normalizeOauth2(oauth2 *argus.OAuth2) *normalizedOauth2 {
+	if oauth2 == nil {
+		return nil
+	}
+	return &normalizedOauth2{
+		ClientId:     stringOrDefault(oauth2.ClientId, ""),
+		ClientSecret: stringOrDefault(oauth2.ClientSecret, ""),
+		TokenUrl:     stringOrDefault(oauth2.TokenUrl, ""),
+		Scopes:       sortedStrings(oauth2.Scopes),
+		TlsInsecure:  tlsInsecure(oauth2.TlsConfig),
+	}
+}
+
+func normalizePayloadOauth2(oauth2 *argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2) *normalizedOauth2 {
+	if oauth2 == nil {
+		return nil
+	}
+	return &normalizedOauth2{
+		ClientId:     stringOrDefault(oauth2.ClientId, ""),
+		ClientSecret: stringOrDefault(oauth2.ClientSecret, ""),
+		TokenUrl:     stringOrDefault(oauth2.TokenUrl, ""),
+		Scopes:       sortedStrings(oauth2.Scopes),
+		TlsInsecure:  payloadTlsInsecure(oauth2.TlsConfig),
+	}
+}
+
+func tlsInsecure(tlsConfig *argus.TLSConfig) bool {
+	return tlsConfig != nil && boolOrDefault(tlsConfig.InsecureSkipVerify, false)
+}
+
+func payloadTlsInsecure(tlsConfig *argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2TlsConfig) bool {
+	return tlsConfig != nil && boolOrDefault(tlsConfig.InsecureSkipVerify, false)
+}
+
+// parseDuration parses a duration in the Prometheus format, e.g. "1h30m", as used by the scrape configs.
+// An unset duration is zero, the API applies its default.
+func parseDuration(s *string) (time.Duration, error) {
+	if s == nil {
+		return 0, nil
+	}
+	return scrapeconfig.ParseDuration(*s)
+}
+
+// stringValues returns the values of a param of the payload, which the API accepts as a string or a list of strings
+func stringValues(v interface{}) []string {
+	switch values := v.(type) {
+	case []string:
+		return append([]string{}, values...)
+	case []interface{}:
+		s := make([]string, len(values))
+		for i := range values {
+			s[i] = fmt.Sprint(values[i])
+		}
+		return s
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func sortedStrings(p *[]string) []string {
+	s := sliceOf(p)
+	sort.Strings(s)
+	return s
+}
+
+func sliceOf(p *[]string) []string {
+	if p == nil || len(*p) == 0 {
+		return nil
+	}
+	return append([]string{}, *p...)
+}
+
+func stringOrDefault(p *string, defaultValue string) string {
+	if p == nil || *p == "" {
+		return defaultValue
+	}
+	return *p
+}
+
+func boolOrDefault(p *bool, defaultValue bool) bool {
+	if p == nil {
+		return defaultValue
+	}
+	return *p
+}
@@ -0,0 +1,155 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+func fixtureJob(name, interval string, targets ...string) argus.Job {
+	return argus.Job{
+		JobName:        utils.Ptr(name),
+		Scheme:         utils.Ptr("http"),
+		MetricsPath:    utils.Ptr("/metrics"),
+		ScrapeInterval: utils.Ptr(interval),
+		ScrapeTimeout:  utils.Ptr("10s"),
+		StaticConfigs:  &[]argus.StaticConfigs{{Targets: &targets}},
+	}
+}
+
+func fixturePayload(name, interval string, targets ...string) argus.CreateScrapeConfigPayload {
+	return argus.CreateScrapeConfigPayload{
+		JobName:        utils.Ptr(name),
+		Scheme:         utils.Ptr("http"),
+		ScrapeInterval: utils.Ptr(interval),
+		ScrapeTimeout:  utils.Ptr("10s"),
+		StaticConfigs:  &[]argus.CreateScrapeConfigPayloadStaticConfigsInner{{Targets: &targets}},
+	}
+}
+
+func TestComputePlan(t *testing.T) {
+	withOauth2 := fixtureJob("team-oauth", "1m", "a:80")
+	withOauth2.Oauth2 = &argus.OAuth2{ClientId: utils.Ptr("id"), ClientSecret: utils.Ptr("secret"), TokenUrl: utils.Ptr("https://auth.example.com")}
+	withRelabel := fixtureJob("team-relabel", "1m", "a:80")
+	withRelabel.MetricsRelabelConfigs = &[]argus.MetricsRelabelConfig{{
+		SourceLabels: &[]string{"__name__"},
+		Regex:        utils.Ptr("go_.*"),
+		Action:       utils.Ptr("drop"),
+		Separator:    utils.Ptr(";"),
+		Replacement:  utils.Ptr("$1"),
+	}}
+	current := []argus.Job{
+		fixtureJob("team-same", "60s", "b:80", "a:80"),
+		fixtureJob("team-interval", "1m", "a:80"),
+		withOauth2,
+		withRelabel,
+		fixtureJob("team-old", "1m", "a:80"),
+		fixtureJob("other", "1m", "a:80"),
+	}
+
+	relabelPayload := fixturePayload("team-relabel", "1m", "a:80")
+	relabelPayload.MetricsRelabelConfigs = &[]argus.CreateScrapeConfigPayloadMetricsRelabelConfigsInner{{
+		SourceLabels: &[]string{"__name__"},
+		Regex:        utils.Ptr("go_.*"),
+		Action:       utils.Ptr("drop"),
+	}}
+	labelsPayload := fixturePayload("team-interval", "5m", "a:80")
+	(*labelsPayload.StaticConfigs)[0].Labels = &map[string]interface{}{"env": "prod"}
+	oauth2Payload := fixturePayload("team-oauth", "1m", "a:80")
+	oauth2Payload.Oauth2 = &argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2{
+		ClientId:     utils.Ptr("id"),
+		ClientSecret: utils.Ptr("new-secret"),
+		TokenUrl:     utils.Ptr("https://auth.example.com"),
+	}
+	newPayload := fixturePayload("team-new", "1m", "a:80")
+
+	tests := []struct {
+		desc    string
+		desired []argus.CreateScrapeConfigPayload
+		opts    Options
+		want    *Plan
+		wantErr bool
+	}{
+		{
+			desc: "owned_by_prefix",
+			desired: []argus.CreateScrapeConfigPayload{
+				fixturePayload("team-same", "1m", "a:80", "b:80"),
+				labelsPayload,
+				oauth2Payload,
+				relabelPayload,
+				newPayload,
+			},
+			opts: Options{JobNamePrefix: "team-"},
+			want: &Plan{Changes: []Change{
+				{Type: ChangeCreate, JobName: "team-new", Payload: &newPayload},
+				{Type: ChangeUpdate, JobName: "team-interval", Payload: &labelsPayload, Differences: []string{"scrapeInterval", "staticConfigs"}},
+				{Type: ChangeReplace, JobName: "team-oauth", Payload: &oauth2Payload, Differences: []string{"oauth2"}},
+				{Type: ChangeDelete, JobName: "team-old"},
+			}},
+		},
+		{
+			desc: "everything_owned",
+			desired: []argus.CreateScrapeConfigPayload{
+				fixturePayload("team-same", "1m", "a:80", "b:80"),
+			},
+			want: &Plan{Changes: []Change{
+				{Type: ChangeDelete, JobName: "other"},
+				{Type: ChangeDelete, JobName: "team-interval"},
+				{Type: ChangeDelete, JobName: "team-oauth"},
+				{Type: ChangeDelete, JobName: "team-old"},
+				{Type: ChangeDelete, JobName: "team-relabel"},
+			}},
+		},
+		{
+			desc: "not_owned",
+			desired: []argus.CreateScrapeConfigPayload{
+				fixturePayload("other", "1m", "a:80"),
+			},
+			opts:    Options{JobNamePrefix: "team-"},
+			wantErr: true,
+		},
+		{
+			desc: "duplicate",
+			desired: []argus.CreateScrapeConfigPayload{
+				fixturePayload("team-new", "1m", "a:80"),
+				fixturePayload("team-new", "2m", "a:80"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid_duration",
+			desired: []argus.CreateScrapeConfigPayload{
+				fixturePayload("team-new", "one minute", "a:80"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := ComputePlan(current, tt.desired, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComputePlan error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected plan (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanString(t *testing.T) {
+	plan := &Plan{Changes: []Change{
+		{Type: ChangeCreate, JobName: "new"},
+		{Type: ChangeUpdate, JobName: "node", Differences: []string{"scrapeInterval", "params"}},
+		{Type: ChangeDelete, JobName: "old"},
+	}}
+	want := "create new\nupdate node (scrapeInterval, params)\ndelete old"
+	if got := plan.String(); got != want {
+		t.Fatalf("String = %q, want %q", got, want)
+	}
+	if got := (&Plan{}).String(); got != "no changes" {
+		t.Fatalf("String of empty plan = %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corewait "github.com/stackitcloud/stackit-sdk-go/core/wait"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus/wait"
+)
+
+// apiClient is the part of the Argus API used by Sync.
+type apiClient interface {
+	wait.APIClientInterface
+	createScrapeConfig(ctx context.Context, instanceId, projectId string, payload argus.CreateScrapeConfigPayload) error
+	updateScrapeConfig(ctx context.Context, instanceId, jobName, projectId string, payload argus.UpdateScrapeConfigPayload) error
+	deleteScrapeConfig(ctx context.Context, instanceId, jobName, projectId string) error
+}
+
+type apiClientAdapter struct {
+	*argus.APIClient
+}
+
+func (a apiClientAdapter) createScrapeConfig(ctx context.Context, instanceId, projectId string, payload argus.CreateScrapeConfigPayload) error {
+	_, err := a.CreateScrapeConfig(ctx, instanceId, projectId).CreateScrapeConfigPayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) updateScrapeConfig(ctx context.Context, instanceId, jobName, projectId string, payload argus.UpdateScrapeConfigPayload) error {
+	_, err := a.UpdateScrapeConfig(ctx, instanceId, jobName, projectId).UpdateScrapeConfigPayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) deleteScrapeConfig(ctx context.Context, instanceId, jobName, projectId string) error {
+	_, err := a.DeleteScrapeConfigExecute(ctx, instanceId, jobName, projectId)
+	return err
+}
+
+// Sync reconciles the scrape configs of an instance with the desired jobs.
+// It lists the current jobs, computes the plan with ComputePlan and applies the changes one after the other,
+// waiting for each of them before applying the next one.
+// The plan is returned even if applying it fails, with DryRun it is only computed.
+func Sync(ctx context.Context, client *argus.APIClient, instanceId, projectId string, desired []argus.CreateScrapeConfigPayload, opts Options) (*Plan, error) {
+	return sync(ctx, apiClientAdapter{client}, instanceId, projectId, desired, opts)
+}
+
+func sync(ctx context.Context, a apiClient, instanceId, projectId string, desired []argus.CreateScrapeConfigPayload, opts Options) (*Plan, error) {
+	resp, err := a.ListScrapeConfigsExecute(ctx, instanceId, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("list scrape configs: %w", err)
+	}
+	current := []argus.Job{}
+	if resp.Data != nil {
+		current = *resp.Data
+	}
+	plan, err := ComputePlan(current, desired, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	for _, c := range plan.Changes {
+		if err := apply(ctx, a, instanceId, projectId, c); err != nil {
+			return plan, fmt.Errorf("%s: %w", c, err)
+		}
+	}
+	return plan, nil
+}
+
+// apply calls the API for the change and waits until it is done
+func apply(ctx context.Context, a apiClient, instanceId, projectId string, c Change) error {
+	switch c.Type {
+	case ChangeCreate:
+		return create(ctx, a, instanceId, projectId, c.Payload)
+	case ChangeUpdate:
+		if err := a.updateScrapeConfig(ctx, instanceId, c.JobName, projectId, updatePayload(c.Payload)); err != nil {
+			return err
+		}
+		want, err := normalizePayload(c.Payload)
+		if err != nil {
+			return err
+		}
+		_, err = updateScrapeConfigWaitHandler(ctx, a, instanceId, c.JobName, projectId, want).WaitWithContext(ctx)
+		return err
+	case ChangeReplace:
+		if err := remove(ctx, a, instanceId, projectId, c.JobName); err != nil {
+			return err
+		}
+		return create(ctx, a, instanceId, projectId, c.Payload)
+	case ChangeDelete:
+		return remove(ctx, a, instanceId, projectId, c.JobName)
+	default:
+		return fmt.Errorf("unknown change type %s", c.Type)
+	}
+}
+
+func create(ctx context.Context, a apiClient, instanceId, projectId string, payload *argus.CreateScrapeConfigPayload) error {
+	if err := a.createScrapeConfig(ctx, instanceId, projectId, *payload); err != nil {
+		return err
+	}
+	_, err := wait.CreateScrapeConfigWaitHandler(ctx, a, instanceId, *payload.JobName, projectId).WaitWithContext(ctx)
+	return err
+}
+
+func remove(ctx context.Context, a apiClient, instanceId, projectId, jobName string) error {
+	if err := a.deleteScrapeConfig(ctx, instanceId, jobName, projectId); err != nil {
+		return err
+	}
+	_, err := wait.DeleteScrapeConfigWaitHandler(ctx, a, instanceId, jobName, projectId).WaitWithContext(ctx)
+	return err
+}
+
+// updateScrapeConfigWaitHandler waits until the job listed for the instance matches the desired one
+func updateScrapeConfigWaitHandler(ctx context.Context, a apiClient, instanceId, jobName, projectId string, want *normalizedJob) *corewait.AsyncActionHandler[argus.ListScrapeConfigsResponse] {
+	handler := corewait.New(func() (waitFinished bool, response *argus.ListScrapeConfigsResponse, err error) {
+		s, err := a.ListScrapeConfigsExecute(ctx, instanceId, projectId)
+		if err != nil {
+			return false, nil, err
+		}
+		if s.Data == nil {
+			return false, nil, nil
+		}
+		jobs := *s.Data
+		for i := range jobs {
+			if jobs[i].JobName == nil || *jobs[i].JobName != jobName {
+				continue
+			}
+			got, err := normalizeJob(&jobs[i])
+			if err != nil {
+				return false, nil, err
+			}
+			return len(diff(got, want)) == 0, s, nil
+		}
+		return false, nil, nil
+	})
+	handler.SetTimeout(5 * time.Minute)
+	return handler
+}
+
+// updatePayload returns the payload to update a scrape config to the desired one
+func updatePayload(p *argus.CreateScrapeConfigPayload) argus.UpdateScrapeConfigPayload {
+	payload := argus.UpdateScrapeConfigPayload{
+		BasicAuth:             p.BasicAuth,
+		BearerToken:           p.BearerToken,
+		HonorLabels:           p.HonorLabels,
+		HonorTimeStamps:       p.HonorTimeStamps,
+		MetricsPath:           p.MetricsPath,
+		MetricsRelabelConfigs: p.MetricsRelabelConfigs,
+		Params:                p.Params,
+		SampleLimit:           p.SampleLimit,
+		Scheme:                p.Scheme,
+		ScrapeInterval:        p.ScrapeInterval,
+		ScrapeTimeout:         p.ScrapeTimeout,
+		TlsConfig:             p.TlsConfig,
+	}
+	// UpdateScrapeConfig requires the metrics path
+	if payload.MetricsPath == nil {
+		metricsPath := defaultMetricsPath
+		payload.MetricsPath = &metricsPath
+	}
+	staticConfigs := []argus.UpdateScrapeConfigPayloadStaticConfigsInner{}
+	if p.StaticConfigs != nil {
+		for _, c := range *p.StaticConfigs {
+			staticConfigs = append(staticConfigs, argus.UpdateScrapeConfigPayloadStaticConfigsInner{Labels: c.Labels, Targets: c.Targets})
+		}
+	}
+	payload.StaticConfigs = &staticConfigs
+	return payload
+}
@@ -0,0 +1,132 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+type apiClientMocked struct {
+	jobs       []argus.Job
+	applyFails bool
+	calls      []string
+}
+
+func (a *apiClientMocked) GetInstanceExecute(_ context.Context, _, _ string) (*argus.GetInstanceResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) ListScrapeConfigsExecute(_ context.Context, _, _ string) (*argus.ListScrapeConfigsResponse, error) {
+	jobs := append([]argus.Job{}, a.jobs...)
+	return &argus.ListScrapeConfigsResponse{Data: &jobs}, nil
+}
+
+func (a *apiClientMocked) createScrapeConfig(_ context.Context, _, _ string, payload argus.CreateScrapeConfigPayload) error {
+	a.calls = append(a.calls, fmt.Sprintf("create %s", *payload.JobName))
+	if a.applyFails {
+		return &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	a.jobs = append(a.jobs, argus.Job{
+		JobName:        payload.JobName,
+		ScrapeInterval: payload.ScrapeInterval,
+		ScrapeTimeout:  payload.ScrapeTimeout,
+	})
+	return nil
+}
+
+func (a *apiClientMocked) updateScrapeConfig(_ context.Context, _, jobName, _ string, payload argus.UpdateScrapeConfigPayload) error {
+	a.calls = append(a.calls, fmt.Sprintf("update %s", jobName))
+	if a.applyFails {
+		return &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	for i := range a.jobs {
+		if *a.jobs[i].JobName == jobName {
+			a.jobs[i].ScrapeInterval = payload.ScrapeInterval
+			a.jobs[i].ScrapeTimeout = payload.ScrapeTimeout
+			a.jobs[i].MetricsPath = payload.MetricsPath
+		}
+	}
+	return nil
+}
+
+func (a *apiClientMocked) deleteScrapeConfig(_ context.Context, _, jobName, _ string) error {
+	a.calls = append(a.calls, fmt.Sprintf("delete %s", jobName))
+	if a.applyFails {
+		return &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	jobs := []argus.Job{}
+	for _, job := range a.jobs {
+		if *job.JobName != jobName {
+			jobs = append(jobs, job)
+		}
+	}
+	a.jobs = jobs
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	desired := []argus.CreateScrapeConfigPayload{
+		{JobName: utils.Ptr("node"), ScrapeInterval: utils.Ptr("5m"), ScrapeTimeout: utils.Ptr("10s")},
+		{JobName: utils.Ptr("new"), ScrapeInterval: utils.Ptr("1m"), ScrapeTimeout: utils.Ptr("10s")},
+	}
+
+	tests := []struct {
+		desc       string
+		dryRun     bool
+		applyFails bool
+		wantCalls  []string
+		wantJobs   []string
+		wantErr    bool
+	}{
+		{
+			desc:      "ok",
+			wantCalls: []string{"create new", "update node", "delete old"},
+			wantJobs:  []string{"node", "new"},
+		},
+		{
+			desc:     "dry_run",
+			dryRun:   true,
+			wantJobs: []string{"node", "old"},
+		},
+		{
+			desc:       "apply_fails",
+			applyFails: true,
+			wantCalls:  []string{"create new"},
+			wantJobs:   []string{"node", "old"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				jobs: []argus.Job{
+					{JobName: utils.Ptr("node"), ScrapeInterval: utils.Ptr("1m"), ScrapeTimeout: utils.Ptr("10s")},
+					{JobName: utils.Ptr("old"), ScrapeInterval: utils.Ptr("1m"), ScrapeTimeout: utils.Ptr("10s")},
+				},
+				applyFails: tt.applyFails,
+			}
+			plan, err := sync(context.Background(), a, "iid", "pid", desired, Options{DryRun: tt.dryRun})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sync error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if plan == nil || len(plan.Changes) != 3 {
+				t.Fatalf("sync returned plan %v, want 3 changes", plan)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+			jobs := []string{}
+			for _, job := range a.jobs {
+				jobs = append(jobs, *job.JobName)
+			}
+			if diff := cmp.Diff(jobs, tt.wantJobs); diff != "" {
+				t.Fatalf("unexpected jobs (-got +want): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package scrapeconfig converts between Prometheus scrape_configs YAML and the scrape configs of the Argus API,
+// to import existing Prometheus configurations into Argus and to export Argus jobs for review.
+package scrapeconfig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the part of a Prometheus configuration file describing scrape jobs.
+// Other sections of the file, such as rule_files or remote_write, are ignored.
+type Config struct {
+	Global        *GlobalConfig  `yaml:"global,omitempty"`
+	ScrapeConfigs []ScrapeConfig `yaml:"scrape_configs"`
+}
+
+// GlobalConfig holds the defaults of the scrape configs. Other global settings are ignored.
+type GlobalConfig struct {
+	ScrapeInterval string `yaml:"scrape_interval,omitempty"`
+	ScrapeTimeout  string `yaml:"scrape_timeout,omitempty"`
+}
+
+// ScrapeConfig is a Prometheus scrape config, restricted to the settings supported by Argus
+type ScrapeConfig struct {
+	JobName               string                 `yaml:"job_name"`
+	HonorLabels           *bool                  `yaml:"honor_labels,omitempty"`
+	HonorTimestamps       *bool                  `yaml:"honor_timestamps,omitempty"`
+	Params                map[string][]string    `yaml:"params,omitempty"`
+	ScrapeInterval        string                 `yaml:"scrape_interval,omitempty"`
+	ScrapeTimeout         string                 `yaml:"scrape_timeout,omitempty"`
+	MetricsPath           string                 `yaml:"metrics_path,omitempty"`
+	Scheme                string                 `yaml:"scheme,omitempty"`
+	SampleLimit           *int64                 `yaml:"sample_limit,omitempty"`
+	BasicAuth             *BasicAuth             `yaml:"basic_auth,omitempty"`
+	BearerToken           string                 `yaml:"bearer_token,omitempty"`
+	Oauth2                *Oauth2                `yaml:"oauth2,omitempty"`
+	TlsConfig             *TlsConfig             `yaml:"tls_config,omitempty"`
+	StaticConfigs         []StaticConfig         `yaml:"static_configs,omitempty"`
+	HttpSdConfigs         []HttpSdConfig         `yaml:"http_sd_configs,omitempty"`
+	MetricsRelabelConfigs []MetricsRelabelConfig `yaml:"metric_relabel_configs,omitempty"`
+}
+
+// BasicAuth is the basic authentication of scrape and service discovery requests
+type BasicAuth struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// Oauth2 is the OAuth 2.0 client credentials authentication of scrape and service discovery requests
+type Oauth2 struct {
+	ClientId     string     `yaml:"client_id"`
+	ClientSecret string     `yaml:"client_secret"`
+	Scopes       []string   `yaml:"scopes,omitempty"`
+	TokenUrl     string     `yaml:"token_url"`
+	TlsConfig    *TlsConfig `yaml:"tls_config,omitempty"`
+}
+
+// TlsConfig is the TLS configuration of scrape and service discovery requests
+type TlsConfig struct {
+	InsecureSkipVerify *bool `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// StaticConfig is a list of targets sharing a set of labels
+type StaticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+// HttpSdConfig is an HTTP service discovery endpoint providing targets
+type HttpSdConfig struct {
+	Url             string     `yaml:"url"`
+	RefreshInterval string     `yaml:"refresh_interval,omitempty"`
+	BasicAuth       *BasicAuth `yaml:"basic_auth,omitempty"`
+	Oauth2          *Oauth2    `yaml:"oauth2,omitempty"`
+	TlsConfig       *TlsConfig `yaml:"tls_config,omitempty"`
+}
+
+// MetricsRelabelConfig is a relabeling rule applied to the scraped samples
+type MetricsRelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,omitempty"`
+	Separator    *string  `yaml:"separator,omitempty"`
+	Regex        *string  `yaml:"regex,omitempty"`
+	Modulus      *int64   `yaml:"modulus,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  *string  `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+}
+
+// UnsupportedFieldError is returned when a scrape config uses a setting Argus doesn't support
+type UnsupportedFieldError struct {
+	// Path of the setting, e.g. "scrape_configs[0].kubernetes_sd_configs"
+	Path string
+}
+
+func (e *UnsupportedFieldError) Error() string {
+	return fmt.Sprintf("%s: not supported by Argus", e.Path)
+}
+
+// Unmarshal parses a Prometheus configuration file.
+// It returns an UnsupportedFieldError if a scrape config uses a setting that isn't supported by Argus.
+func Unmarshal(data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	if len(doc.Content) > 0 {
+		if err := checkScrapeConfigs(doc.Content[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{}
+	if err := doc.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	return cfg, nil
+}
+
+// Marshal renders the configuration as YAML
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkScrapeConfigs checks that the scrape configs of the document only use settings of ScrapeConfig
+func checkScrapeConfigs(root *yaml.Node) error {
+	root = resolveAlias(root)
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "scrape_configs" {
+			continue
+		}
+		return checkFields(root.Content[i+1], reflect.TypeOf([]ScrapeConfig{}), "scrape_configs")
+	}
+	return nil
+}
+
+// checkFields checks that the keys of the YAML mappings in node are fields of the type t, recursively.
+// Nodes not matching the kind of t are left for the decoder to report.
+func checkFields(node *yaml.Node, t reflect.Type, path string) error {
+	node = resolveAlias(node)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, elem := range node.Content {
+			if err := checkFields(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			field, ok := fieldByYAMLName(t, key)
+			if !ok {
+				return &UnsupportedFieldError{Path: path + "." + key}
+			}
+			if err := checkFields(node.Content[i+1], field.Type, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tagName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tagName == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,437 @@
+package scrapeconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+// Prometheus defaults of the settings required by the Argus API
+const (
+	DefaultScrapeInterval = "1m"
+	DefaultScrapeTimeout  = "10s"
+	DefaultScheme         = "http"
+	DefaultMetricsPath    = "/metrics"
+)
+
+// MinScrapeInterval is the minimum scrape interval, and service discovery refresh interval, accepted by Argus
+const MinScrapeInterval = time.Minute
+
+var (
+	jobNameRegex  = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+	durationRegex = regexp.MustCompile(`^(?:(\d+)y)?(?:(\d+)w)?(?:(\d+)d)?(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?(?:(\d+)ms)?$`)
+	durationUnits = []time.Duration{365 * 24 * time.Hour, 7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second, time.Millisecond}
+)
+
+// Import parses a Prometheus configuration file and returns the payloads to create its scrape configs in Argus
+func Import(data []byte) ([]argus.CreateScrapeConfigPayload, error) {
+	cfg, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Payloads()
+}
+
+// Export renders the Argus jobs as a Prometheus configuration file with a scrape_configs section
+func Export(jobs []argus.Job) ([]byte, error) {
+	return FromJobs(jobs).Marshal()
+}
+
+// Payloads returns the payloads to create the scrape configs in Argus.
+// Settings left to their Prometheus defaults, including the global scrape interval and timeout, are set explicitly,
+// as they are required by the API. It returns an error if a scrape config doesn't fulfill the constraints of Argus.
+func (c *Config) Payloads() ([]argus.CreateScrapeConfigPayload, error) {
+	defaults := GlobalConfig{ScrapeInterval: DefaultScrapeInterval, ScrapeTimeout: DefaultScrapeTimeout}
+	if c.Global != nil {
+		if c.Global.ScrapeInterval != "" {
+			defaults.ScrapeInterval = c.Global.ScrapeInterval
+		}
+		if c.Global.ScrapeTimeout != "" {
+			defaults.ScrapeTimeout = c.Global.ScrapeTimeout
+		}
+	}
+
+	payloads := make([]argus.CreateScrapeConfigPayload, 0, len(c.ScrapeConfigs))
+	jobNames := map[string]bool{}
+	for i := range c.ScrapeConfigs {
+		sc := &c.ScrapeConfigs[i]
+		payload, err := sc.payload(defaults)
+		if err != nil {
+			return nil, fmt.Errorf("scrape_configs[%d]: %w", i, err)
+		}
+		if jobNames[sc.JobName] {
+			return nil, fmt.Errorf("scrape_configs[%d]: duplicate job name %q", i, sc.JobName)
+		}
+		jobNames[sc.JobName] = true
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+func (sc *ScrapeConfig) payload(defaults GlobalConfig) (argus.CreateScrapeConfigPayload, error) {
+	if !jobNameRegex.MatchString(sc.JobName) {
+		return argus.CreateScrapeConfigPayload{}, fmt.Errorf("job name %q must only contain the characters a-zA-Z0-9-", sc.JobName)
+	}
+	scrapeInterval := valueOrDefault(sc.ScrapeInterval, defaults.ScrapeInterval)
+	scrapeTimeout := valueOrDefault(sc.ScrapeTimeout, defaults.ScrapeTimeout)
+	interval, err := ParseDuration(scrapeInterval)
+	if err != nil {
+		return argus.CreateScrapeConfigPayload{}, fmt.Errorf("scrape_interval: %w", err)
+	}
+	if interval < MinScrapeInterval {
+		return argus.CreateScrapeConfigPayload{}, fmt.Errorf("scrape_interval %s is lower than %s", scrapeInterval, MinScrapeInterval)
+	}
+	timeout, err := ParseDuration(scrapeTimeout)
+	if err != nil {
+		return argus.CreateScrapeConfigPayload{}, fmt.Errorf("scrape_timeout: %w", err)
+	}
+	if timeout >= interval {
+		return argus.CreateScrapeConfigPayload{}, fmt.Errorf("scrape_timeout %s must be lower than scrape_interval %s", scrapeTimeout, scrapeInterval)
+	}
+	scheme := valueOrDefault(sc.Scheme, DefaultScheme)
+	if scheme != "http" && scheme != "https" {
+		return argus.CreateScrapeConfigPayload{}, fmt.Errorf("scheme %q must be http or https", scheme)
+	}
+	if err := checkAuthentication(sc.BasicAuth, sc.BearerToken, sc.Oauth2); err != nil {
+		return argus.CreateScrapeConfigPayload{}, err
+	}
+	metricsPath := valueOrDefault(sc.MetricsPath, DefaultMetricsPath)
+	jobName := sc.JobName
+
+	payload := argus.CreateScrapeConfigPayload{
+		JobName:         &jobName,
+		Scheme:          &scheme,
+		ScrapeInterval:  &scrapeInterval,
+		ScrapeTimeout:   &scrapeTimeout,
+		MetricsPath:     &metricsPath,
+		HonorLabels:     copyPtr(sc.HonorLabels),
+		HonorTimeStamps: copyPtr(sc.HonorTimestamps),
+		BasicAuth:       basicAuthPayload(sc.BasicAuth),
+		Oauth2:          oauth2Payload(sc.Oauth2),
+		TlsConfig:       tlsConfigPayload(sc.TlsConfig),
+	}
+	if sc.BearerToken != "" {
+		bearerToken := sc.BearerToken
+		payload.BearerToken = &bearerToken
+	}
+	if sc.SampleLimit != nil {
+		sampleLimit := float64(*sc.SampleLimit)
+		payload.SampleLimit = &sampleLimit
+	}
+	if len(sc.Params) > 0 {
+		params := map[string]interface{}{}
+		for k, v := range sc.Params {
+			params[k] = append([]string{}, v...)
+		}
+		payload.Params = &params
+	}
+
+	staticConfigs := make([]argus.CreateScrapeConfigPayloadStaticConfigsInner, 0, len(sc.StaticConfigs))
+	for i := range sc.StaticConfigs {
+		staticConfig := &sc.StaticConfigs[i]
+		targets := append([]string{}, staticConfig.Targets...)
+		inner := argus.CreateScrapeConfigPayloadStaticConfigsInner{Targets: &targets}
+		if len(staticConfig.Labels) > 0 {
+			labels := map[string]interface{}{}
+			for k, v := range staticConfig.Labels {
+				labels[k] = v
+			}
+			inner.Labels = &labels
+		}
+		staticConfigs = append(staticConfigs, inner)
+	}
+	payload.StaticConfigs = &staticConfigs
+
+	if len(sc.HttpSdConfigs) > 0 {
+		httpSdConfigs := make([]argus.CreateScrapeConfigPayloadHttpSdConfigsInner, 0, len(sc.HttpSdConfigs))
+		for i := range sc.HttpSdConfigs {
+			inner, err := sc.HttpSdConfigs[i].payload()
+			if err != nil {
+				return argus.CreateScrapeConfigPayload{}, fmt.Errorf("http_sd_configs[%d]: %w", i, err)
+			}
+			httpSdConfigs = append(httpSdConfigs, inner)
+		}
+		payload.HttpSdConfigs = &httpSdConfigs
+	}
+
+	if len(sc.MetricsRelabelConfigs) > 0 {
+		relabelConfigs := make([]argus.CreateScrapeConfigPayloadMetricsRelabelConfigsInner, 0, len(sc.MetricsRelabelConfigs))
+		for i := range sc.MetricsRelabelConfigs {
+			inner, err := sc.MetricsRelabelConfigs[i].payload()
+			if err != nil {
+				return argus.CreateScrapeConfigPayload{}, fmt.Errorf("metric_relabel_configs[%d]: %w", i, err)
+			}
+			relabelConfigs = append(relabelConfigs, inner)
+		}
+		payload.MetricsRelabelConfigs = &relabelConfigs
+	}
+	return payload, nil
+}
+
+func (c *HttpSdConfig) payload() (argus.CreateScrapeConfigPayloadHttpSdConfigsInner, error) {
+	if c.Url == "" {
+		return argus.CreateScrapeConfigPayloadHttpSdConfigsInner{}, fmt.Errorf("url is required")
+	}
+	if err := checkAuthentication(c.BasicAuth, "", c.Oauth2); err != nil {
+		return argus.CreateScrapeConfigPayloadHttpSdConfigsInner{}, err
+	}
+	url := c.Url
+	inner := argus.CreateScrapeConfigPayloadHttpSdConfigsInner{
+		Url:       &url,
+		BasicAuth: basicAuthPayload(c.BasicAuth),
+		Oauth2:    oauth2Payload(c.Oauth2),
+		TlsConfig: tlsConfigPayload(c.TlsConfig),
+	}
+	if c.RefreshInterval != "" {
+		refreshInterval, err := ParseDuration(c.RefreshInterval)
+		if err != nil {
+			return argus.CreateScrapeConfigPayloadHttpSdConfigsInner{}, fmt.Errorf("refresh_interval: %w", err)
+		}
+		if refreshInterval < MinScrapeInterval {
+			return argus.CreateScrapeConfigPayloadHttpSdConfigsInner{}, fmt.Errorf("refresh_interval %s is lower than %s", c.RefreshInterval, MinScrapeInterval)
+		}
+		s := c.RefreshInterval
+		inner.RefreshInterval = &s
+	}
+	return inner, nil
+}
+
+func (c *MetricsRelabelConfig) payload() (argus.CreateScrapeConfigPayloadMetricsRelabelConfigsInner, error) {
+	action := c.Action
+	if action == "" {
+		action = "replace"
+	}
+	if action == "replace" && c.TargetLabel == "" {
+		return argus.CreateScrapeConfigPayloadMetricsRelabelConfigsInner{}, fmt.Errorf("target_label is required for the replace action")
+	}
+	inner := argus.CreateScrapeConfigPayloadMetricsRelabelConfigsInner{
+		Separator:   copyPtr(c.Separator),
+		Regex:       copyPtr(c.Regex),
+		Replacement: copyPtr(c.Replacement),
+	}
+	if c.Action != "" {
+		inner.Action = &action
+	}
+	if c.TargetLabel != "" {
+		targetLabel := c.TargetLabel
+		inner.TargetLabel = &targetLabel
+	}
+	if len(c.SourceLabels) > 0 {
+		sourceLabels := append([]string{}, c.SourceLabels...)
+		inner.SourceLabels = &sourceLabels
+	}
+	if c.Modulus != nil {
+		modulus := float64(*c.Modulus)
+		inner.Modulus = &modulus
+	}
+	return inner, nil
+}
+
+// checkAuthentication checks that at most one authentication method is configured, as required by Argus and Prometheus
+func checkAuthentication(basicAuth *BasicAuth, bearerToken string, oauth2 *Oauth2) error {
+	methods := 0
+	if basicAuth != nil {
+		methods++
+	}
+	if bearerToken != "" {
+		methods++
+	}
+	if oauth2 != nil {
+		methods++
+		if oauth2.ClientId == "" || oauth2.ClientSecret == "" || oauth2.TokenUrl == "" {
+			return fmt.Errorf("oauth2: client_id, client_secret and token_url are required")
+		}
+	}
+	if methods > 1 {
+		return fmt.Errorf("at most one of basic_auth, bearer_token and oauth2 can be configured")
+	}
+	return nil
+}
+
+func basicAuthPayload(basicAuth *BasicAuth) *argus.CreateScrapeConfigPayloadBasicAuth {
+	if basicAuth == nil {
+		return nil
+	}
+	username, password := basicAuth.Username, basicAuth.Password
+	return &argus.CreateScrapeConfigPayloadBasicAuth{Username: &username, Password: &password}
+}
+
+func oauth2Payload(oauth2 *Oauth2) *argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2 {
+	if oauth2 == nil {
+		return nil
+	}
+	clientId, clientSecret, tokenUrl := oauth2.ClientId, oauth2.ClientSecret, oauth2.TokenUrl
+	payload := &argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2{
+		ClientId:     &clientId,
+		ClientSecret: &clientSecret,
+		TokenUrl:     &tokenUrl,
+		TlsConfig:    tlsConfigPayload(oauth2.TlsConfig),
+	}
+	if len(oauth2.Scopes) > 0 {
+		scopes := append([]string{}, oauth2.Scopes...)
+		payload.Scopes = &scopes
+	}
+	return payload
+}
+
+func tlsConfigPayload(tlsConfig *TlsConfig) *argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2TlsConfig {
+	if tlsConfig == nil {
+		return nil
+	}
+	return &argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2TlsConfig{InsecureSkipVerify: copyPtr(tlsConfig.InsecureSkipVerify)}
+}
+
+// FromJobs returns the Prometheus configuration with the scrape configs of the Argus jobs
+func FromJobs(jobs []argus.Job) *Config {
+	cfg := &Config{ScrapeConfigs: make([]ScrapeConfig, 0, len(jobs))}
+	for i := range jobs {
+		cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, FromJob(&jobs[i]))
+	}
+	return cfg
+}
+
+// FromJob returns the Prometheus scrape config of the Argus job
+func FromJob(job *argus.Job) ScrapeConfig {
+	sc := ScrapeConfig{
+		JobName:         valueOf(job.JobName),
+		HonorLabels:     copyPtr(job.HonorLabels),
+		HonorTimestamps: copyPtr(job.HonorTimeStamps),
+		ScrapeInterval:  valueOf(job.ScrapeInterval),
+		ScrapeTimeout:   valueOf(job.ScrapeTimeout),
+		MetricsPath:     valueOf(job.MetricsPath),
+		Scheme:          valueOf(job.Scheme),
+		SampleLimit:     copyPtr(job.SampleLimit),
+		BasicAuth:       fromBasicAuth(job.BasicAuth),
+		BearerToken:     valueOf(job.BearerToken),
+		Oauth2:          fromOauth2(job.Oauth2),
+		TlsConfig:       fromTlsConfig(job.TlsConfig),
+	}
+	if job.Params != nil && len(*job.Params) > 0 {
+		sc.Params = map[string][]string{}
+		for k, v := range *job.Params {
+			sc.Params[k] = append([]string{}, v...)
+		}
+	}
+	if job.StaticConfigs != nil {
+		for _, staticConfig := range *job.StaticConfigs {
+			c := StaticConfig{Targets: []string{}}
+			if staticConfig.Targets != nil {
+				c.Targets = append(c.Targets, *staticConfig.Targets...)
+			}
+			if staticConfig.Labels != nil && len(*staticConfig.Labels) > 0 {
+				c.Labels = map[string]string{}
+				for k, v := range *staticConfig.Labels {
+					c.Labels[k] = v
+				}
+			}
+			sc.StaticConfigs = append(sc.StaticConfigs, c)
+		}
+	}
+	if job.HttpSdConfigs != nil {
+		for _, httpSdConfig := range *job.HttpSdConfigs {
+			sc.HttpSdConfigs = append(sc.HttpSdConfigs, HttpSdConfig{
+				Url:             valueOf(httpSdConfig.Url),
+				RefreshInterval: valueOf(httpSdConfig.RefreshInterval),
+				BasicAuth:       fromBasicAuth(httpSdConfig.BasicAuth),
+				Oauth2:          fromOauth2(httpSdConfig.Oauth2),
+				TlsConfig:       fromTlsConfig(httpSdConfig.TlsConfig),
+			})
+		}
+	}
+	if job.MetricsRelabelConfigs != nil {
+		for _, relabelConfig := range *job.MetricsRelabelConfigs {
+			c := MetricsRelabelConfig{
+				Separator:   copyPtr(relabelConfig.Separator),
+				Regex:       copyPtr(relabelConfig.Regex),
+				Modulus:     copyPtr(relabelConfig.Modulus),
+				TargetLabel: valueOf(relabelConfig.TargetLabel),
+				Replacement: copyPtr(relabelConfig.Replacement),
+				Action:      valueOf(relabelConfig.Action),
+			}
+			if relabelConfig.SourceLabels != nil {
+				c.SourceLabels = append(c.SourceLabels, *relabelConfig.SourceLabels...)
+			}
+			sc.MetricsRelabelConfigs = append(sc.MetricsRelabelConfigs, c)
+		}
+	}
+	return sc
+}
+
+func fromBasicAuth(basicAuth *argus.BasicAuth) *BasicAuth {
+	if basicAuth == nil {
+		return nil
+	}
+	return &BasicAuth{Username: valueOf(basicAuth.Username), Password: valueOf(basicAuth.Password)}
+}
+
+func fromOauth2(oauth2 *argus.OAuth2) *Oauth2 {
+	if oauth2 == nil {
+		return nil
+	}
+	c := &Oauth2{
+		ClientId:     valueOf(oauth2.ClientId),
+		ClientSecret: valueOf(oauth2.ClientSecret),
+		TokenUrl:     valueOf(oauth2.TokenUrl),
+		TlsConfig:    fromTlsConfig(oauth2.TlsConfig),
+	}
+	if oauth2.Scopes != nil {
+		c.Scopes = append(c.Scopes, *oauth2.Scopes...)
+	}
+	return c
+}
+
+func fromTlsConfig(tlsConfig *argus.TLSConfig) *TlsConfig {
+	if tlsConfig == nil {
+		return nil
+	}
+	return &TlsConfig{InsecureSkipVerify: copyPtr(tlsConfig.InsecureSkipVerify)}
+}
+
+// ParseDuration parses a duration in the Prometheus format, e.g. "1h30m" or "2d", as used by the scrape configs
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	matches := durationRegex.FindStringSubmatch(s)
+	if s == "" || matches == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	for i, unit := range durationUnits {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(matches[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
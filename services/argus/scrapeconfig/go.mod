@@ -0,0 +1,15 @@
+module github.com/stackitcloud/stackit-sdk-go/services/argus/scrapeconfig
+
+go 1.18
+
+require (
+	github.com/google/go-cmp v0.6.0
+	github.com/stackitcloud/stackit-sdk-go/core v0.12.0
+	github.com/stackitcloud/stackit-sdk-go/services/argus v0.11.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+)
@@ -0,0 +1,313 @@
+package scrapeconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+const fixtureConfig = `
+global:
+  scrape_interval: 2m
+  evaluation_interval: 1m
+rule_files:
+  - rules.yml
+scrape_configs:
+  - job_name: node
+    static_configs:
+      - targets: ["node-1:9100", "node-2:9100"]
+        labels:
+          env: prod
+    metric_relabel_configs:
+      - source_labels: [__name__]
+        regex: go_.*
+        action: drop
+  - job_name: api
+    scheme: https
+    metrics_path: /internal/metrics
+    scrape_interval: 5m
+    scrape_timeout: 30s
+    honor_labels: true
+    sample_limit: 1000
+    params:
+      module: [http_2xx]
+    oauth2:
+      client_id: id
+      client_secret: secret
+      token_url: https://auth.example.com/token
+      scopes: [metrics]
+    tls_config:
+      insecure_skip_verify: true
+    http_sd_configs:
+      - url: https://sd.example.com/targets
+        refresh_interval: 10m
+        basic_auth:
+          username: user
+          password: pass
+`
+
+func TestImport(t *testing.T) {
+	got, err := Import([]byte(fixtureConfig))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := []argus.CreateScrapeConfigPayload{
+		{
+			JobName:        utils.Ptr("node"),
+			Scheme:         utils.Ptr("http"),
+			ScrapeInterval: utils.Ptr("2m"),
+			ScrapeTimeout:  utils.Ptr("10s"),
+			MetricsPath:    utils.Ptr("/metrics"),
+			StaticConfigs: &[]argus.CreateScrapeConfigPayloadStaticConfigsInner{{
+				Targets: &[]string{"node-1:9100", "node-2:9100"},
+				Labels:  &map[string]interface{}{"env": "prod"},
+			}},
+			MetricsRelabelConfigs: &[]argus.CreateScrapeConfigPayloadMetricsRelabelConfigsInner{{
+				SourceLabels: &[]string{"__name__"},
+				Regex:        utils.Ptr("go_.*"),
+				Action:       utils.Ptr("drop"),
+			}},
+		},
+		{
+			JobName:        utils.Ptr("api"),
+			Scheme:         utils.Ptr("https"),
+			ScrapeInterval: utils.Ptr("5m"),
+			ScrapeTimeout:  utils.Ptr("30s"),
+			MetricsPath:    utils.Ptr("/internal/metrics"),
+			HonorLabels:    utils.Ptr(true),
+			SampleLimit:    utils.Ptr(float64(1000)),
+			Params:         &map[string]interface{}{"module": []string{"http_2xx"}},
+			Oauth2: &argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2{
+				ClientId:     utils.Ptr("id"),
+				ClientSecret: utils.Ptr("secret"),
+				TokenUrl:     utils.Ptr("https://auth.example.com/token"),
+				Scopes:       &[]string{"metrics"},
+			},
+			TlsConfig:     &argus.CreateScrapeConfigPayloadHttpSdConfigsInnerOauth2TlsConfig{InsecureSkipVerify: utils.Ptr(true)},
+			StaticConfigs: &[]argus.CreateScrapeConfigPayloadStaticConfigsInner{},
+			HttpSdConfigs: &[]argus.CreateScrapeConfigPayloadHttpSdConfigsInner{{
+				Url:             utils.Ptr("https://sd.example.com/targets"),
+				RefreshInterval: utils.Ptr("10m"),
+				BasicAuth: &argus.CreateScrapeConfigPayloadBasicAuth{
+					Username: utils.Ptr("user"),
+					Password: utils.Ptr("pass"),
+				},
+			}},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected payloads (-got +want): %s", diff)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		desc            string
+		config          string
+		wantUnsupported string
+	}{
+		{
+			desc: "unsupported_service_discovery",
+			config: `
+scrape_configs:
+  - job_name: k8s
+    kubernetes_sd_configs:
+      - role: pod
+`,
+			wantUnsupported: "scrape_configs[0].kubernetes_sd_configs",
+		},
+		{
+			desc: "unsupported_nested_field",
+			config: `
+scrape_configs:
+  - job_name: node
+    tls_config:
+      ca_file: /etc/ca.pem
+`,
+			wantUnsupported: "scrape_configs[0].tls_config.ca_file",
+		},
+		{
+			desc: "unsupported_relabel_configs",
+			config: `
+scrape_configs:
+  - job_name: node
+  - job_name: other
+    relabel_configs:
+      - action: labelmap
+`,
+			wantUnsupported: "scrape_configs[1].relabel_configs",
+		},
+		{
+			desc: "invalid_job_name",
+			config: `
+scrape_configs:
+  - job_name: node_exporter
+`,
+		},
+		{
+			desc: "duplicate_job_name",
+			config: `
+scrape_configs:
+  - job_name: node
+  - job_name: node
+`,
+		},
+		{
+			desc: "interval_too_low",
+			config: `
+scrape_configs:
+  - job_name: node
+    scrape_interval: 15s
+`,
+		},
+		{
+			desc: "timeout_not_lower_than_interval",
+			config: `
+scrape_configs:
+  - job_name: node
+    scrape_interval: 1m
+    scrape_timeout: 1m
+`,
+		},
+		{
+			desc: "invalid_duration",
+			config: `
+scrape_configs:
+  - job_name: node
+    scrape_interval: 1 minute
+`,
+		},
+		{
+			desc: "multiple_authentication_methods",
+			config: `
+scrape_configs:
+  - job_name: node
+    bearer_token: token
+    basic_auth:
+      username: user
+`,
+		},
+		{
+			desc: "replace_without_target_label",
+			config: `
+scrape_configs:
+  - job_name: node
+    metric_relabel_configs:
+      - source_labels: [instance]
+`,
+		},
+		{
+			desc:   "invalid_yaml",
+			config: "scrape_configs: [",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := Import([]byte(tt.config))
+			if err == nil {
+				t.Fatalf("Import returned no error")
+			}
+			var unsupportedErr *UnsupportedFieldError
+			isUnsupported := errors.As(err, &unsupportedErr)
+			if isUnsupported != (tt.wantUnsupported != "") {
+				t.Fatalf("Import error = %v, want unsupported field %q", err, tt.wantUnsupported)
+			}
+			if isUnsupported && unsupportedErr.Path != tt.wantUnsupported {
+				t.Fatalf("unsupported field = %q, want %q", unsupportedErr.Path, tt.wantUnsupported)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	jobs := []argus.Job{{
+		JobName:        utils.Ptr("node"),
+		Scheme:         utils.Ptr("https"),
+		ScrapeInterval: utils.Ptr("5m"),
+		ScrapeTimeout:  utils.Ptr("2m"),
+		MetricsPath:    utils.Ptr("/metrics"),
+		SampleLimit:    utils.Ptr(int64(500)),
+		BasicAuth:      &argus.BasicAuth{Username: utils.Ptr("user"), Password: utils.Ptr("pass")},
+		Params:         &map[string][]string{"module": {"http_2xx"}},
+		StaticConfigs: &[]argus.StaticConfigs{{
+			Targets: &[]string{"node-1:9100"},
+			Labels:  &map[string]string{"env": "prod"},
+		}},
+		MetricsRelabelConfigs: &[]argus.MetricsRelabelConfig{{
+			SourceLabels: &[]string{"__name__"},
+			Regex:        utils.Ptr("go_.*"),
+			Action:       utils.Ptr("drop"),
+		}},
+	}}
+	got, err := Export(jobs)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := `scrape_configs:
+  - job_name: node
+    params:
+      module:
+        - http_2xx
+    scrape_interval: 5m
+    scrape_timeout: 2m
+    metrics_path: /metrics
+    scheme: https
+    sample_limit: 500
+    basic_auth:
+      username: user
+      password: pass
+    static_configs:
+      - targets:
+          - node-1:9100
+        labels:
+          env: prod
+    metric_relabel_configs:
+      - source_labels:
+          - __name__
+        regex: go_.*
+        action: drop
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("unexpected YAML (-got +want): %s", diff)
+	}
+
+	// The exported configuration can be imported again
+	payloads, err := Import(got)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(payloads) != 1 || *payloads[0].JobName != "node" || *payloads[0].ScrapeTimeout != "2m" {
+		t.Fatalf("unexpected payloads after round trip: %v", payloads)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"30s", 30 * time.Second, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"1d", 24 * time.Hour, false},
+		{"1w2d", 9 * 24 * time.Hour, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"", 0, true},
+		{"1.5m", 0, true},
+		{"30m1h", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package sizing checks workloads against the quotas of the Argus plans, to pick a plan for a new instance
+// and to validate plan changes of existing instances.
+package sizing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus/scrapeconfig"
+)
+
+// DefaultScrapeInterval is the scrape interval assumed for workloads and jobs that don't set one
+const DefaultScrapeInterval = time.Minute
+
+// Quotas of argus.Plan checked by this package
+const (
+	QuotaTargetNumber       = "targetNumber"
+	QuotaSamplesPerScrape   = "samplesPerScrape"
+	QuotaTotalMetricSamples = "totalMetricSamples"
+	QuotaAlertRules         = "alertRules"
+	QuotaAlertReceivers     = "alertReceivers"
+	QuotaLogsStorage        = "logsStorage"
+	QuotaTracesStorage      = "tracesStorage"
+)
+
+// Workload is the intended usage of an instance
+type Workload struct {
+	// Targets is the number of scraped targets
+	Targets int64
+	// SamplesPerScrape is the highest number of samples scraped from a single target
+	SamplesPerScrape int64
+	// ScrapeInterval is the interval at which the targets are scraped, DefaultScrapeInterval if zero
+	ScrapeInterval time.Duration
+	AlertRules     int64
+	AlertReceivers int64
+	// LogsStorage and TracesStorage are the storage needed for logs and traces, in the unit of the plan quotas (GB)
+	LogsStorage   int64
+	TracesStorage int64
+}
+
+// SamplesPerMinute returns the number of samples ingested per minute, which is compared with the TotalMetricSamples quota
+func (w *Workload) SamplesPerMinute() int64 {
+	return samplesPerMinute(w.Targets, w.SamplesPerScrape, w.ScrapeInterval)
+}
+
+// Violation is a quota of a plan the workload exceeds
+type Violation struct {
+	Quota    string
+	Required int64
+	Limit    int64
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %d required, %d allowed", v.Quota, v.Required, v.Limit)
+}
+
+// PlanExceededError is returned when a workload exceeds the quotas of a plan
+type PlanExceededError struct {
+	PlanId     string
+	PlanName   string
+	Violations []Violation
+}
+
+func (e *PlanExceededError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		violations[i] = v.String()
+	}
+	return fmt.Sprintf("plan %s (%s) is too small: %s", e.PlanName, e.PlanId, strings.Join(violations, "; "))
+}
+
+// Check returns the quotas of the plan exceeded by the workload
+func Check(plan *argus.Plan, w Workload) []Violation {
+	return checkQuotas(plan, &w, w.SamplesPerMinute())
+}
+
+// checkQuotas returns the quotas of the plan exceeded by the workload ingesting the given samples per minute
+func checkQuotas(plan *argus.Plan, w *Workload, samplesPerMinute int64) []Violation {
+	violations := []Violation{}
+	check := func(quota string, required int64, limit *int64) {
+		if limit != nil && required > *limit {
+			violations = append(violations, Violation{Quota: quota, Required: required, Limit: *limit})
+		}
+	}
+	check(QuotaTargetNumber, w.Targets, plan.TargetNumber)
+	check(QuotaSamplesPerScrape, w.SamplesPerScrape, plan.SamplesPerScrape)
+	check(QuotaTotalMetricSamples, samplesPerMinute, plan.TotalMetricSamples)
+	check(QuotaAlertRules, w.AlertRules, plan.AlertRules)
+	check(QuotaAlertReceivers, w.AlertReceivers, plan.AlertReceivers)
+	check(QuotaLogsStorage, w.LogsStorage, plan.LogsStorage)
+	check(QuotaTracesStorage, w.TracesStorage, plan.TracesStorage)
+	return violations
+}
+
+// Recommend returns the cheapest of the public plans fitting the workload, by their amount.
+// Plans with the same amount are ordered by name.
+func Recommend(plans []argus.Plan, w Workload) (*argus.Plan, error) {
+	candidates := []*argus.Plan{}
+	for i := range plans {
+		plan := &plans[i]
+		if plan.IsPublic != nil && !*plan.IsPublic {
+			continue
+		}
+		if len(Check(plan, w)) == 0 {
+			candidates = append(candidates, plan)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no plan fits the workload")
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ai, aj := amount(candidates[i]), amount(candidates[j])
+		if ai != aj {
+			return ai < aj
+		}
+		return valueOf(candidates[i].Name) < valueOf(candidates[j].Name)
+	})
+	return candidates[0], nil
+}
+
+// apiClient is the part of the Argus API used by this package
+type apiClient interface {
+	ListPlansExecute(ctx context.Context, projectId string) (*argus.PlansResponse, error)
+	ListScrapeConfigsExecute(ctx context.Context, instanceId, projectId string) (*argus.ListScrapeConfigsResponse, error)
+	ListAlertConfigReceiversExecute(ctx context.Context, instanceId, projectId string) (*argus.AlertConfigReceiversResponse, error)
+}
+
+// RecommendPlan lists the plans available in the project and returns the cheapest one fitting the workload, see Recommend
+func RecommendPlan(ctx context.Context, client *argus.APIClient, projectId string, w Workload) (*argus.Plan, error) {
+	return recommendPlan(ctx, client, projectId, w)
+}
+
+func recommendPlan(ctx context.Context, a apiClient, projectId string, w Workload) (*argus.Plan, error) {
+	plans, err := listPlans(ctx, a, projectId)
+	if err != nil {
+		return nil, err
+	}
+	return Recommend(plans, w)
+}
+
+// ValidatePlanChange checks that the current usage of an instance fits the plan of the UpdateInstance payload before it is applied.
+// It returns a PlanExceededError listing the exceeded quotas otherwise.
+//
+// The usage is derived from the scrape configs and alert receivers of the instance: the targets of the static configs are counted,
+// the sample limits of the jobs must not exceed the samples per scrape of the plan, and the samples per minute allowed by the
+// sample limits must not exceed the total metric samples of the plan. Targets found by HTTP service discovery and jobs without
+// sample limit can't be taken into account. The scrape intervals are parsed in the Prometheus format, e.g. "1d", jobs without one
+// are assumed to use DefaultScrapeInterval.
+func ValidatePlanChange(ctx context.Context, client *argus.APIClient, instanceId, projectId string, payload argus.UpdateInstancePayload) error {
+	return validatePlanChange(ctx, client, instanceId, projectId, payload)
+}
+
+func validatePlanChange(ctx context.Context, a apiClient, instanceId, projectId string, payload argus.UpdateInstancePayload) error {
+	if payload.PlanId == nil || *payload.PlanId == "" {
+		return fmt.Errorf("payload is missing the plan id")
+	}
+	plans, err := listPlans(ctx, a, projectId)
+	if err != nil {
+		return err
+	}
+	var plan *argus.Plan
+	for i := range plans {
+		if valueOf(plans[i].PlanId) == *payload.PlanId || valueOf(plans[i].Id) == *payload.PlanId {
+			plan = &plans[i]
+			break
+		}
+	}
+	if plan == nil {
+		return fmt.Errorf("plan %s not found", *payload.PlanId)
+	}
+
+	jobsResp, err := a.ListScrapeConfigsExecute(ctx, instanceId, projectId)
+	if err != nil {
+		return fmt.Errorf("list scrape configs: %w", err)
+	}
+	receiversResp, err := a.ListAlertConfigReceiversExecute(ctx, instanceId, projectId)
+	if err != nil {
+		return fmt.Errorf("list alert receivers: %w", err)
+	}
+
+	w := Workload{}
+	var samples int64
+	if jobsResp.Data != nil {
+		for i := range *jobsResp.Data {
+			job := &(*jobsResp.Data)[i]
+			targets := int64(0)
+			if job.StaticConfigs != nil {
+				for _, c := range *job.StaticConfigs {
+					if c.Targets != nil {
+						targets += int64(len(*c.Targets))
+					}
+				}
+			}
+			w.Targets += targets
+			if job.SampleLimit == nil {
+				continue
+			}
+			if *job.SampleLimit > w.SamplesPerScrape {
+				w.SamplesPerScrape = *job.SampleLimit
+			}
+			interval := DefaultScrapeInterval
+			if valueOf(job.ScrapeInterval) != "" {
+				interval, err = scrapeconfig.ParseDuration(*job.ScrapeInterval)
+				if err != nil {
+					return fmt.Errorf("scrape config %s: scrape interval: %w", valueOf(job.JobName), err)
+				}
+			}
+			samples += samplesPerMinute(targets, *job.SampleLimit, interval)
+		}
+	}
+	if receiversResp.Data != nil {
+		w.AlertReceivers = int64(len(*receiversResp.Data))
+	}
+
+	// The jobs have their own scrape intervals, so the samples per minute are summed up per job
+	violations := checkQuotas(plan, &w, samples)
+	if len(violations) > 0 {
+		return &PlanExceededError{PlanId: *payload.PlanId, PlanName: valueOf(plan.Name), Violations: violations}
+	}
+	return nil
+}
+
+func listPlans(ctx context.Context, a apiClient, projectId string) ([]argus.Plan, error) {
+	resp, err := a.ListPlansExecute(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("list plans: %w", err)
+	}
+	if resp.Plans == nil {
+		return []argus.Plan{}, nil
+	}
+	return *resp.Plans, nil
+}
+
+// samplesPerMinute returns the samples scraped per minute from the targets, rounded up
+func samplesPerMinute(targets, samplesPerScrape int64, interval time.Duration) int64 {
+	if interval <= 0 {
+		interval = DefaultScrapeInterval
+	}
+	return int64(math.Ceil(float64(targets*samplesPerScrape) * float64(time.Minute) / float64(interval)))
+}
+
+// amount returns the price of the plan, plans without amount are free
+func amount(plan *argus.Plan) float64 {
+	if plan.Amount == nil {
+		return 0
+	}
+	return *plan.Amount
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,187 @@
+package sizing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+func fixturePlan(id, name string, amount float64, targets, samplesPerScrape, totalSamples int64) argus.Plan {
+	return argus.Plan{
+		Id:                 utils.Ptr(id),
+		PlanId:             utils.Ptr(id),
+		Name:               utils.Ptr(name),
+		Amount:             utils.Ptr(amount),
+		TargetNumber:       utils.Ptr(targets),
+		SamplesPerScrape:   utils.Ptr(samplesPerScrape),
+		TotalMetricSamples: utils.Ptr(totalSamples),
+		AlertRules:         utils.Ptr(int64(100)),
+		AlertReceivers:     utils.Ptr(int64(10)),
+		LogsStorage:        utils.Ptr(int64(20)),
+		TracesStorage:      utils.Ptr(int64(20)),
+	}
+}
+
+var fixturePlans = []argus.Plan{
+	fixturePlan("large", "Large", 300, 1000, 10000, 5000000),
+	fixturePlan("small", "Small", 50, 20, 2000, 20000),
+	fixturePlan("medium", "Medium", 100, 100, 5000, 200000),
+	fixturePlan("medium-b", "Medium-B", 100, 100, 5000, 200000),
+}
+
+func TestCheck(t *testing.T) {
+	plan := fixturePlan("small", "Small", 50, 20, 2000, 20000)
+	w := Workload{
+		Targets:          30,
+		SamplesPerScrape: 1000,
+		ScrapeInterval:   30 * time.Second,
+		AlertRules:       10,
+		LogsStorage:      50,
+	}
+	want := []Violation{
+		{Quota: QuotaTargetNumber, Required: 30, Limit: 20},
+		{Quota: QuotaTotalMetricSamples, Required: 60000, Limit: 20000},
+		{Quota: QuotaLogsStorage, Required: 50, Limit: 20},
+	}
+	if diff := cmp.Diff(Check(&plan, w), want); diff != "" {
+		t.Fatalf("unexpected violations (-got +want): %s", diff)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	private := fixturePlan("private", "Private", 0, 1000, 10000, 5000000)
+	private.IsPublic = utils.Ptr(false)
+	plans := append([]argus.Plan{private}, fixturePlans...)
+
+	tests := []struct {
+		desc    string
+		w       Workload
+		want    string
+		wantErr bool
+	}{
+		{"small", Workload{Targets: 10, SamplesPerScrape: 1000}, "small", false},
+		{"tie_by_name", Workload{Targets: 50, SamplesPerScrape: 1000}, "medium", false},
+		{"interval", Workload{Targets: 100, SamplesPerScrape: 5000, ScrapeInterval: 5 * time.Minute}, "medium", false},
+		{"large", Workload{Targets: 500, SamplesPerScrape: 1000}, "large", false},
+		{"too_large", Workload{Targets: 5000}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Recommend(plans, tt.w)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Recommend error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != nil && *got.Id != tt.want {
+				t.Fatalf("Recommend = %s, want %s", *got.Id, tt.want)
+			}
+		})
+	}
+}
+
+type apiClientMocked struct {
+	jobs      []argus.Job
+	receivers int
+}
+
+func (a *apiClientMocked) ListPlansExecute(_ context.Context, _ string) (*argus.PlansResponse, error) {
+	return &argus.PlansResponse{Plans: &fixturePlans}, nil
+}
+
+func (a *apiClientMocked) ListScrapeConfigsExecute(_ context.Context, _, _ string) (*argus.ListScrapeConfigsResponse, error) {
+	return &argus.ListScrapeConfigsResponse{Data: &a.jobs}, nil
+}
+
+func (a *apiClientMocked) ListAlertConfigReceiversExecute(_ context.Context, _, _ string) (*argus.AlertConfigReceiversResponse, error) {
+	receivers := make([]argus.Receivers, a.receivers)
+	return &argus.AlertConfigReceiversResponse{Data: &receivers}, nil
+}
+
+func fixtureJob(interval string, sampleLimit *int64, targets int) argus.Job {
+	t := make([]string, targets)
+	return argus.Job{
+		JobName:        utils.Ptr("job"),
+		ScrapeInterval: utils.Ptr(interval),
+		SampleLimit:    sampleLimit,
+		StaticConfigs:  &[]argus.StaticConfigs{{Targets: &t}},
+	}
+}
+
+func TestValidatePlanChange(t *testing.T) {
+	tests := []struct {
+		desc           string
+		jobs           []argus.Job
+		receivers      int
+		planId         string
+		wantViolations []Violation
+		wantErr        bool
+	}{
+		{
+			desc: "fits",
+			jobs: []argus.Job{
+				fixtureJob("1m", utils.Ptr(int64(1000)), 10),
+				fixtureJob("5m", nil, 5),
+			},
+			receivers: 2,
+			planId:    "small",
+		},
+		{
+			desc: "exceeds",
+			jobs: []argus.Job{
+				fixtureJob("1m", utils.Ptr(int64(1000)), 15),
+				fixtureJob("2m", utils.Ptr(int64(3000)), 10),
+			},
+			receivers: 11,
+			planId:    "small",
+			wantViolations: []Violation{
+				{Quota: QuotaTargetNumber, Required: 25, Limit: 20},
+				{Quota: QuotaSamplesPerScrape, Required: 3000, Limit: 2000},
+				{Quota: QuotaTotalMetricSamples, Required: 30000, Limit: 20000},
+				{Quota: QuotaAlertReceivers, Required: 11, Limit: 10},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "day_interval",
+			jobs: []argus.Job{
+				fixtureJob("1d", utils.Ptr(int64(2000)), 10),
+				fixtureJob("1w", utils.Ptr(int64(2000)), 10),
+			},
+			planId: "small",
+		},
+		{
+			desc: "invalid_interval",
+			jobs: []argus.Job{
+				fixtureJob("1x", utils.Ptr(int64(1000)), 1),
+			},
+			planId:  "small",
+			wantErr: true,
+		},
+		{
+			desc:    "unknown_plan",
+			planId:  "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{jobs: tt.jobs, receivers: tt.receivers}
+			err := validatePlanChange(context.Background(), a, "iid", "pid", argus.UpdateInstancePayload{PlanId: utils.Ptr(tt.planId)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validatePlanChange error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var exceededErr *PlanExceededError
+			if errors.As(err, &exceededErr) {
+				if diff := cmp.Diff(exceededErr.Violations, tt.wantViolations); diff != "" {
+					t.Fatalf("unexpected violations (-got +want): %s", diff)
+				}
+			} else if tt.wantViolations != nil {
+				t.Fatalf("validatePlanChange error = %v, want a PlanExceededError", err)
+			}
+		})
+	}
+}
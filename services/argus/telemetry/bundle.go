@@ -0,0 +1,111 @@
+// Package telemetry bundles the endpoints of an Argus instance with credentials to push and query telemetry data,
+// and renders them as configuration of Prometheus, the OpenTelemetry Collector, Promtail and Grafana.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+// Credentials are the basic authentication credentials of an Argus instance
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Endpoints are the URLs of an Argus instance used by telemetry agents and clients
+type Endpoints struct {
+	// PushMetricsUrl is the Prometheus remote write endpoint
+	PushMetricsUrl string
+	// MetricsUrl is the Prometheus query endpoint
+	MetricsUrl string
+	// OtlpTracesUrl is the OTLP/HTTP endpoint for traces
+	OtlpTracesUrl string
+	// ZipkinSpansUrl is the Zipkin endpoint for spans
+	ZipkinSpansUrl string
+	// JaegerTracesUrl is the Jaeger endpoint for traces
+	JaegerTracesUrl string
+	// LogsPushUrl is the Loki push endpoint
+	LogsPushUrl string
+	// LogsUrl is the Loki query endpoint
+	LogsUrl    string
+	GrafanaUrl string
+}
+
+// Bundle holds the endpoints of an Argus instance together with the credentials to access them
+type Bundle struct {
+	InstanceId   string
+	InstanceName string
+	Credentials  Credentials
+	Endpoints    Endpoints
+}
+
+// apiClient is the part of the Argus API used by this package
+type apiClient interface {
+	GetInstanceExecute(ctx context.Context, instanceId, projectId string) (*argus.GetInstanceResponse, error)
+	CreateCredentialsExecute(ctx context.Context, instanceId, projectId string) (*argus.CreateCredentialsResponse, error)
+}
+
+// NewBundle reads the endpoints of the instance and bundles them with credentials.
+// If creds is nil, new credentials are created for the instance; otherwise the given credentials are reused.
+// As the API doesn't return the password of existing credentials, it has to be stored by the caller to reuse them.
+func NewBundle(ctx context.Context, client *argus.APIClient, instanceId, projectId string, creds *Credentials) (*Bundle, error) {
+	return newBundle(ctx, client, instanceId, projectId, creds)
+}
+
+func newBundle(ctx context.Context, a apiClient, instanceId, projectId string, creds *Credentials) (*Bundle, error) {
+	instance, err := a.GetInstanceExecute(ctx, instanceId, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("get instance: %w", err)
+	}
+	if instance.Instance == nil {
+		return nil, fmt.Errorf("instance %s has no endpoints yet", instanceId)
+	}
+
+	if creds == nil {
+		resp, err := a.CreateCredentialsExecute(ctx, instanceId, projectId)
+		if err != nil {
+			return nil, fmt.Errorf("create credentials: %w", err)
+		}
+		if resp.Credentials == nil || resp.Credentials.Username == nil || resp.Credentials.Password == nil {
+			return nil, fmt.Errorf("create credentials: response is missing the credentials")
+		}
+		creds = &Credentials{Username: *resp.Credentials.Username, Password: *resp.Credentials.Password}
+	}
+
+	data := instance.Instance
+	return &Bundle{
+		InstanceId:   instanceId,
+		InstanceName: valueOf(instance.Name),
+		Credentials:  *creds,
+		Endpoints: Endpoints{
+			PushMetricsUrl:  valueOf(data.PushMetricsUrl),
+			MetricsUrl:      valueOf(data.MetricsUrl),
+			OtlpTracesUrl:   valueOf(data.OtlpTracesUrl),
+			ZipkinSpansUrl:  valueOf(data.ZipkinSpansUrl),
+			JaegerTracesUrl: valueOf(data.JaegerTracesUrl),
+			LogsPushUrl:     valueOf(data.LogsPushUrl),
+			LogsUrl:         valueOf(data.LogsUrl),
+			GrafanaUrl:      valueOf(data.GrafanaUrl),
+		},
+	}, nil
+}
+
+// MissingEndpointError is returned when a configuration is rendered for an endpoint the instance doesn't provide
+type MissingEndpointError struct {
+	Endpoint string
+}
+
+func (e *MissingEndpointError) Error() string {
+	return fmt.Sprintf("instance has no %s", e.Endpoint)
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,208 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the components in the rendered OpenTelemetry Collector configuration
+const (
+	OTelBasicAuthExtension = "basicauth/argus"
+	OTelTracesExporter     = "otlphttp/argus"
+	OTelMetricsExporter    = "prometheusremotewrite/argus"
+	OTelLogsExporter       = "loki/argus"
+)
+
+// Names of the rendered Grafana datasources
+const (
+	GrafanaMetricsDatasource = "Argus Metrics"
+	GrafanaLogsDatasource    = "Argus Logs"
+)
+
+const (
+	grafanaDatasourcesApiVersion  = 1
+	grafanaPrometheusDatasource   = "prometheus"
+	grafanaLokiDatasource         = "loki"
+	grafanaDatasourceAccessProxy  = "proxy"
+	grafanaBasicAuthPasswordField = "basicAuthPassword"
+)
+
+// BasicAuth is the basic authentication of a client, as configured in Prometheus, Promtail and the OpenTelemetry Collector
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// PrometheusConfig is the remote_write section of a Prometheus configuration file
+type PrometheusConfig struct {
+	RemoteWrite []RemoteWrite `yaml:"remote_write"`
+}
+
+// RemoteWrite is a Prometheus remote write target
+type RemoteWrite struct {
+	Url       string     `yaml:"url"`
+	BasicAuth *BasicAuth `yaml:"basic_auth,omitempty"`
+}
+
+// OTelCollectorConfig holds the extensions and exporters of an OpenTelemetry Collector configuration file.
+// The extension and exporters still have to be referenced in the service section, along with the pipelines using them.
+type OTelCollectorConfig struct {
+	Extensions map[string]OTelExtension `yaml:"extensions"`
+	Exporters  map[string]OTelExporter  `yaml:"exporters"`
+}
+
+// OTelExtension is the configuration of the basicauth extension of the OpenTelemetry Collector
+type OTelExtension struct {
+	ClientAuth BasicAuth `yaml:"client_auth"`
+}
+
+// OTelExporter is the configuration of an exporter of the OpenTelemetry Collector
+type OTelExporter struct {
+	Endpoint       string    `yaml:"endpoint,omitempty"`
+	TracesEndpoint string    `yaml:"traces_endpoint,omitempty"`
+	Auth           *OTelAuth `yaml:"auth,omitempty"`
+}
+
+// OTelAuth references the authenticator extension of an exporter
+type OTelAuth struct {
+	Authenticator string `yaml:"authenticator"`
+}
+
+// PromtailConfig is the clients section of a Promtail configuration file
+type PromtailConfig struct {
+	Clients []PromtailClient `yaml:"clients"`
+}
+
+// PromtailClient is a Loki instance Promtail pushes logs to
+type PromtailClient struct {
+	Url       string     `yaml:"url"`
+	BasicAuth *BasicAuth `yaml:"basic_auth,omitempty"`
+}
+
+// GrafanaDatasources is a Grafana datasource provisioning file
+type GrafanaDatasources struct {
+	ApiVersion  int                 `yaml:"apiVersion"`
+	Datasources []GrafanaDatasource `yaml:"datasources"`
+}
+
+// GrafanaDatasource is a datasource provisioned in Grafana
+type GrafanaDatasource struct {
+	Name           string            `yaml:"name"`
+	Type           string            `yaml:"type"`
+	Access         string            `yaml:"access"`
+	Url            string            `yaml:"url"`
+	BasicAuth      bool              `yaml:"basicAuth"`
+	BasicAuthUser  string            `yaml:"basicAuthUser,omitempty"`
+	SecureJsonData map[string]string `yaml:"secureJsonData,omitempty"`
+}
+
+// PrometheusRemoteWrite returns the remote_write configuration of Prometheus pushing metrics to the instance
+func (b *Bundle) PrometheusRemoteWrite() (*PrometheusConfig, error) {
+	if b.Endpoints.PushMetricsUrl == "" {
+		return nil, &MissingEndpointError{Endpoint: "PushMetricsUrl"}
+	}
+	return &PrometheusConfig{
+		RemoteWrite: []RemoteWrite{{Url: b.Endpoints.PushMetricsUrl, BasicAuth: b.basicAuth()}},
+	}, nil
+}
+
+// OTelCollector returns the configuration of the OpenTelemetry Collector exporting traces, metrics and logs to the instance.
+// Exporters are only added for the endpoints provided by the instance, see the OTel*Exporter constants for their names.
+func (b *Bundle) OTelCollector() (*OTelCollectorConfig, error) {
+	auth := &OTelAuth{Authenticator: OTelBasicAuthExtension}
+	exporters := map[string]OTelExporter{}
+	if b.Endpoints.OtlpTracesUrl != "" {
+		// The traces endpoint is used as is, while the /v1/traces path would be appended to the endpoint setting
+		exporters[OTelTracesExporter] = OTelExporter{TracesEndpoint: b.Endpoints.OtlpTracesUrl, Auth: auth}
+	}
+	if b.Endpoints.PushMetricsUrl != "" {
+		exporters[OTelMetricsExporter] = OTelExporter{Endpoint: b.Endpoints.PushMetricsUrl, Auth: auth}
+	}
+	if b.Endpoints.LogsPushUrl != "" {
+		exporters[OTelLogsExporter] = OTelExporter{Endpoint: b.Endpoints.LogsPushUrl, Auth: auth}
+	}
+	if len(exporters) == 0 {
+		return nil, &MissingEndpointError{Endpoint: "OtlpTracesUrl, PushMetricsUrl or LogsPushUrl"}
+	}
+	return &OTelCollectorConfig{
+		Extensions: map[string]OTelExtension{OTelBasicAuthExtension: {ClientAuth: *b.basicAuth()}},
+		Exporters:  exporters,
+	}, nil
+}
+
+// Promtail returns the clients configuration of Promtail pushing logs to the instance
+func (b *Bundle) Promtail() (*PromtailConfig, error) {
+	if b.Endpoints.LogsPushUrl == "" {
+		return nil, &MissingEndpointError{Endpoint: "LogsPushUrl"}
+	}
+	return &PromtailConfig{
+		Clients: []PromtailClient{{Url: b.Endpoints.LogsPushUrl, BasicAuth: b.basicAuth()}},
+	}, nil
+}
+
+// GrafanaDatasources returns the provisioning of Grafana datasources querying the metrics and logs of the instance.
+// Datasources are only added for the endpoints provided by the instance.
+func (b *Bundle) GrafanaDatasources() (*GrafanaDatasources, error) {
+	datasources := []GrafanaDatasource{}
+	if b.Endpoints.MetricsUrl != "" {
+		datasources = append(datasources, b.grafanaDatasource(GrafanaMetricsDatasource, grafanaPrometheusDatasource, b.Endpoints.MetricsUrl))
+	}
+	if b.Endpoints.LogsUrl != "" {
+		datasources = append(datasources, b.grafanaDatasource(GrafanaLogsDatasource, grafanaLokiDatasource, b.Endpoints.LogsUrl))
+	}
+	if len(datasources) == 0 {
+		return nil, &MissingEndpointError{Endpoint: "MetricsUrl or LogsUrl"}
+	}
+	return &GrafanaDatasources{ApiVersion: grafanaDatasourcesApiVersion, Datasources: datasources}, nil
+}
+
+func (b *Bundle) grafanaDatasource(name, datasourceType, url string) GrafanaDatasource {
+	return GrafanaDatasource{
+		Name:           name,
+		Type:           datasourceType,
+		Access:         grafanaDatasourceAccessProxy,
+		Url:            url,
+		BasicAuth:      true,
+		BasicAuthUser:  b.Credentials.Username,
+		SecureJsonData: map[string]string{grafanaBasicAuthPasswordField: b.Credentials.Password},
+	}
+}
+
+func (b *Bundle) basicAuth() *BasicAuth {
+	return &BasicAuth{Username: b.Credentials.Username, Password: b.Credentials.Password}
+}
+
+// Marshal renders the configuration as YAML
+func (c *PrometheusConfig) Marshal() ([]byte, error) {
+	return marshal(c)
+}
+
+// Marshal renders the configuration as YAML
+func (c *OTelCollectorConfig) Marshal() ([]byte, error) {
+	return marshal(c)
+}
+
+// Marshal renders the configuration as YAML
+func (c *PromtailConfig) Marshal() ([]byte, error) {
+	return marshal(c)
+}
+
+// Marshal renders the provisioning file as YAML
+func (c *GrafanaDatasources) Marshal() ([]byte, error) {
+	return marshal(c)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("encode YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,320 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/argus"
+)
+
+type apiClientMocked struct {
+	getFails          bool
+	createFails       bool
+	createCredentials int
+}
+
+func (a *apiClientMocked) GetInstanceExecute(_ context.Context, instanceId, _ string) (*argus.GetInstanceResponse, error) {
+	if a.getFails {
+		return nil, fmt.Errorf("get fails")
+	}
+	return &argus.GetInstanceResponse{
+		Id:   utils.Ptr(instanceId),
+		Name: utils.Ptr("instance"),
+		Instance: &argus.InstanceSensitiveData{
+			PushMetricsUrl: utils.Ptr("https://push.metrics"),
+			MetricsUrl:     utils.Ptr("https://metrics"),
+			OtlpTracesUrl:  utils.Ptr("https://otlp.traces"),
+			LogsPushUrl:    utils.Ptr("https://push.logs"),
+			LogsUrl:        utils.Ptr("https://logs"),
+			GrafanaUrl:     utils.Ptr("https://grafana"),
+		},
+	}, nil
+}
+
+func (a *apiClientMocked) CreateCredentialsExecute(_ context.Context, _, _ string) (*argus.CreateCredentialsResponse, error) {
+	a.createCredentials++
+	if a.createFails {
+		return nil, fmt.Errorf("create fails")
+	}
+	return &argus.CreateCredentialsResponse{
+		Credentials: &argus.Credentials{Username: utils.Ptr("new-user"), Password: utils.Ptr("new-password")},
+	}, nil
+}
+
+func TestNewBundle(t *testing.T) {
+	endpoints := Endpoints{
+		PushMetricsUrl: "https://push.metrics",
+		MetricsUrl:     "https://metrics",
+		OtlpTracesUrl:  "https://otlp.traces",
+		LogsPushUrl:    "https://push.logs",
+		LogsUrl:        "https://logs",
+		GrafanaUrl:     "https://grafana",
+	}
+	tests := []struct {
+		desc        string
+		creds       *Credentials
+		getFails    bool
+		createFails bool
+		want        *Bundle
+		wantCreated bool
+		wantErr     bool
+	}{
+		{
+			desc: "create_credentials",
+			want: &Bundle{
+				InstanceId:   "iid",
+				InstanceName: "instance",
+				Credentials:  Credentials{Username: "new-user", Password: "new-password"},
+				Endpoints:    endpoints,
+			},
+			wantCreated: true,
+		},
+		{
+			desc:  "reuse_credentials",
+			creds: &Credentials{Username: "user", Password: "password"},
+			want: &Bundle{
+				InstanceId:   "iid",
+				InstanceName: "instance",
+				Credentials:  Credentials{Username: "user", Password: "password"},
+				Endpoints:    endpoints,
+			},
+		},
+		{
+			desc:     "get_fails",
+			getFails: true,
+			wantErr:  true,
+		},
+		{
+			desc:        "create_fails",
+			createFails: true,
+			wantCreated: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{getFails: tt.getFails, createFails: tt.createFails}
+			got, err := newBundle(context.Background(), a, "iid", "pid", tt.creds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newBundle error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected bundle (-got +want): %s", diff)
+			}
+			if (a.createCredentials == 1) != tt.wantCreated {
+				t.Fatalf("credentials created %d times", a.createCredentials)
+			}
+		})
+	}
+}
+
+func fixtureBundle(mods ...func(*Bundle)) *Bundle {
+	b := &Bundle{
+		InstanceId:  "iid",
+		Credentials: Credentials{Username: "user", Password: "password"},
+		Endpoints: Endpoints{
+			PushMetricsUrl: "https://push.metrics",
+			MetricsUrl:     "https://metrics",
+			OtlpTracesUrl:  "https://otlp.traces",
+			LogsPushUrl:    "https://push.logs",
+			LogsUrl:        "https://logs",
+		},
+	}
+	for _, mod := range mods {
+		mod(b)
+	}
+	return b
+}
+
+func TestPrometheusRemoteWrite(t *testing.T) {
+	cfg, err := fixtureBundle().PrometheusRemoteWrite()
+	if err != nil {
+		t.Fatalf("PrometheusRemoteWrite: %v", err)
+	}
+	got, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `remote_write:
+  - url: https://push.metrics
+    basic_auth:
+      username: user
+      password: password
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("unexpected YAML (-got +want): %s", diff)
+	}
+
+	_, err = fixtureBundle(func(b *Bundle) { b.Endpoints.PushMetricsUrl = "" }).PrometheusRemoteWrite()
+	var missingErr *MissingEndpointError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("PrometheusRemoteWrite error = %v, want MissingEndpointError", err)
+	}
+}
+
+func TestOTelCollector(t *testing.T) {
+	tests := []struct {
+		desc    string
+		bundle  *Bundle
+		want    string
+		wantErr bool
+	}{
+		{
+			desc:   "all_endpoints",
+			bundle: fixtureBundle(),
+			want: `extensions:
+  basicauth/argus:
+    client_auth:
+      username: user
+      password: password
+exporters:
+  loki/argus:
+    endpoint: https://push.logs
+    auth:
+      authenticator: basicauth/argus
+  otlphttp/argus:
+    traces_endpoint: https://otlp.traces
+    auth:
+      authenticator: basicauth/argus
+  prometheusremotewrite/argus:
+    endpoint: https://push.metrics
+    auth:
+      authenticator: basicauth/argus
+`,
+		},
+		{
+			desc: "traces_only",
+			bundle: fixtureBundle(func(b *Bundle) {
+				b.Endpoints.PushMetricsUrl = ""
+				b.Endpoints.LogsPushUrl = ""
+			}),
+			want: `extensions:
+  basicauth/argus:
+    client_auth:
+      username: user
+      password: password
+exporters:
+  otlphttp/argus:
+    traces_endpoint: https://otlp.traces
+    auth:
+      authenticator: basicauth/argus
+`,
+		},
+		{
+			desc:    "no_endpoints",
+			bundle:  fixtureBundle(func(b *Bundle) { b.Endpoints = Endpoints{} }),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg, err := tt.bundle.OTelCollector()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OTelCollector error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := cfg.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Fatalf("unexpected YAML (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPromtail(t *testing.T) {
+	cfg, err := fixtureBundle().Promtail()
+	if err != nil {
+		t.Fatalf("Promtail: %v", err)
+	}
+	got, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `clients:
+  - url: https://push.logs
+    basic_auth:
+      username: user
+      password: password
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("unexpected YAML (-got +want): %s", diff)
+	}
+
+	if _, err := fixtureBundle(func(b *Bundle) { b.Endpoints.LogsPushUrl = "" }).Promtail(); err == nil {
+		t.Fatalf("Promtail without LogsPushUrl succeeded")
+	}
+}
+
+func TestGrafanaDatasources(t *testing.T) {
+	tests := []struct {
+		desc    string
+		bundle  *Bundle
+		want    *GrafanaDatasources
+		wantErr bool
+	}{
+		{
+			desc:   "metrics_and_logs",
+			bundle: fixtureBundle(),
+			want: &GrafanaDatasources{ApiVersion: 1, Datasources: []GrafanaDatasource{
+				{
+					Name:           GrafanaMetricsDatasource,
+					Type:           "prometheus",
+					Access:         "proxy",
+					Url:            "https://metrics",
+					BasicAuth:      true,
+					BasicAuthUser:  "user",
+					SecureJsonData: map[string]string{"basicAuthPassword": "password"},
+				},
+				{
+					Name:           GrafanaLogsDatasource,
+					Type:           "loki",
+					Access:         "proxy",
+					Url:            "https://logs",
+					BasicAuth:      true,
+					BasicAuthUser:  "user",
+					SecureJsonData: map[string]string{"basicAuthPassword": "password"},
+				},
+			}},
+		},
+		{
+			desc:   "logs_only",
+			bundle: fixtureBundle(func(b *Bundle) { b.Endpoints.MetricsUrl = "" }),
+			want: &GrafanaDatasources{ApiVersion: 1, Datasources: []GrafanaDatasource{
+				{
+					Name:           GrafanaLogsDatasource,
+					Type:           "loki",
+					Access:         "proxy",
+					Url:            "https://logs",
+					BasicAuth:      true,
+					BasicAuthUser:  "user",
+					SecureJsonData: map[string]string{"basicAuthPassword": "password"},
+				},
+			}},
+		},
+		{
+			desc:    "no_endpoints",
+			bundle:  fixtureBundle(func(b *Bundle) { b.Endpoints = Endpoints{} }),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.bundle.GrafanaDatasources()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GrafanaDatasources error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected datasources (-got +want): %s", diff)
+			}
+		})
+	}
+}
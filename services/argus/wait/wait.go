@@ -18,6 +18,12 @@ const (
 	DeleteFail    = "DELETE_FAILED"
 )
 
+// States of a scrape config reported by scrapeConfigState, they aren't returned by the API
+const (
+	scrapeConfigStateListed    = "LISTED"
+	scrapeConfigStateNotListed = "NOT_LISTED"
+)
+
 // APIClientInterface Interfaces needed for tests
 type APIClientInterface interface {
 	GetInstanceExecute(ctx context.Context, instanceId, projectId string) (*argus.GetInstanceResponse, error)
@@ -26,105 +32,99 @@ type APIClientInterface interface {
 
 // CreateInstanceWaitHandler will wait for instance creation
 func CreateInstanceWaitHandler(ctx context.Context, a APIClientInterface, instanceId, projectId string) *wait.AsyncActionHandler[argus.GetInstanceResponse] {
-	handler := wait.New(func() (waitFinished bool, response *argus.GetInstanceResponse, err error) {
-		s, err := a.GetInstanceExecute(ctx, instanceId, projectId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Id == nil || s.Status == nil {
-			return false, nil, fmt.Errorf("could not get instance id or status from response for project %s and instance %s", projectId, instanceId)
-		}
-		if *s.Id == instanceId && *s.Status == CreateSuccess {
-			return true, s, nil
-		}
-		if *s.Id == instanceId && *s.Status == CreateFail {
-			return true, s, fmt.Errorf("create failed for instance with id %s", instanceId)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getInstance(ctx, a, instanceId, projectId), instanceState(instanceId, projectId)).
+		Success(CreateSuccess).
+		Failure(CreateFail).
+		OnFailure(func(_ *argus.GetInstanceResponse, _ string) error {
+			return fmt.Errorf("create failed for instance with id %s", instanceId)
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
 
 // UpdateInstanceWaitHandler will wait for instance update
 func UpdateInstanceWaitHandler(ctx context.Context, a APIClientInterface, instanceId, projectId string) *wait.AsyncActionHandler[argus.GetInstanceResponse] {
-	handler := wait.New(func() (waitFinished bool, response *argus.GetInstanceResponse, err error) {
-		s, err := a.GetInstanceExecute(ctx, instanceId, projectId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Id == nil || s.Status == nil {
-			return false, nil, fmt.Errorf("could not get instance id or status from response for project %s and instance %s", projectId, instanceId)
-		}
-		// The argus instance API currently replies with create success in case the update was successful.
-		if *s.Id == instanceId && (*s.Status == UpdateSuccess || *s.Status == CreateSuccess) {
-			return true, s, nil
-		}
-		if *s.Id == instanceId && (*s.Status == UpdateFail || *s.Status == CreateFail) {
-			return true, s, fmt.Errorf("update failed for instance with id %s", instanceId)
-		}
-		return false, nil, nil
-	})
+	// The argus instance API currently replies with create success in case the update was successful.
+	handler := wait.ForState(getInstance(ctx, a, instanceId, projectId), instanceState(instanceId, projectId)).
+		Success(UpdateSuccess, CreateSuccess).
+		Failure(UpdateFail, CreateFail).
+		OnFailure(func(_ *argus.GetInstanceResponse, _ string) error {
+			return fmt.Errorf("update failed for instance with id %s", instanceId)
+		}).
+		Handler()
 	handler.SetTimeout(30 * time.Minute)
 	return handler
 }
 
 // DeleteInstanceWaitHandler will wait for instance deletion
 func DeleteInstanceWaitHandler(ctx context.Context, a APIClientInterface, instanceId, projectId string) *wait.AsyncActionHandler[argus.GetInstanceResponse] {
-	handler := wait.New(func() (waitFinished bool, response *argus.GetInstanceResponse, err error) {
-		s, err := a.GetInstanceExecute(ctx, instanceId, projectId)
-		if err != nil {
-			return false, nil, err
-		}
+	handler := wait.ForState(getInstance(ctx, a, instanceId, projectId), instanceState(instanceId, projectId)).
+		Success(DeleteSuccess).
+		Failure(DeleteFail).
+		OnFailure(func(_ *argus.GetInstanceResponse, _ string) error {
+			return fmt.Errorf("delete failed for instance with id %s", instanceId)
+		}).
+		Handler()
+	handler.SetTimeout(20 * time.Minute)
+	return handler
+}
+
+func getInstance(ctx context.Context, a APIClientInterface, instanceId, projectId string) wait.GetFunc[argus.GetInstanceResponse] {
+	return func() (*argus.GetInstanceResponse, error) {
+		return a.GetInstanceExecute(ctx, instanceId, projectId)
+	}
+}
+
+// instanceState returns the status of the instance, or an empty state while the response is for another instance
+func instanceState(instanceId, projectId string) wait.StateFunc[argus.GetInstanceResponse] {
+	return func(s *argus.GetInstanceResponse) (string, error) {
 		if s.Id == nil || s.Status == nil {
-			return false, nil, fmt.Errorf("could not get instance id or status from response for project %s and instance %s", projectId, instanceId)
-		}
-		if *s.Id == instanceId && *s.Status == DeleteSuccess {
-			return true, s, nil
+			return "", fmt.Errorf("could not get instance id or status from response for project %s and instance %s", projectId, instanceId)
 		}
-		if *s.Id == instanceId && *s.Status == DeleteFail {
-			return true, s, fmt.Errorf("delete failed for instance with id %s", instanceId)
+		if *s.Id != instanceId {
+			return "", nil
 		}
-		return false, nil, nil
-	})
-	handler.SetTimeout(20 * time.Minute)
-	return handler
+		return *s.Status, nil
+	}
 }
 
 // CreateScrapeConfigWaitHandler will wait for scrape config creation
 func CreateScrapeConfigWaitHandler(ctx context.Context, a APIClientInterface, instanceId, jobName, projectId string) *wait.AsyncActionHandler[argus.ListScrapeConfigsResponse] {
-	handler := wait.New(func() (waitFinished bool, response *argus.ListScrapeConfigsResponse, err error) {
-		s, err := a.ListScrapeConfigsExecute(ctx, instanceId, projectId)
-		if err != nil {
-			return false, nil, err
-		}
-		jobs := *s.Data
-		for i := range jobs {
-			if *jobs[i].JobName == jobName {
-				return true, s, nil
-			}
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(listScrapeConfigs(ctx, a, instanceId, projectId), scrapeConfigState(jobName)).
+		Success(scrapeConfigStateListed).
+		Handler()
 	handler.SetTimeout(5 * time.Minute)
 	return handler
 }
 
 // DeleteScrapeConfigWaitHandler will wait for scrape config deletion
 func DeleteScrapeConfigWaitHandler(ctx context.Context, a APIClientInterface, instanceId, jobName, projectId string) *wait.AsyncActionHandler[argus.ListScrapeConfigsResponse] {
-	handler := wait.New(func() (waitFinished bool, response *argus.ListScrapeConfigsResponse, err error) {
-		s, err := a.ListScrapeConfigsExecute(ctx, instanceId, projectId)
-		if err != nil {
-			return false, nil, err
+	handler := wait.ForState(listScrapeConfigs(ctx, a, instanceId, projectId), scrapeConfigState(jobName)).
+		Success(scrapeConfigStateNotListed).
+		Handler()
+	handler.SetTimeout(3 * time.Minute)
+	return handler
+}
+
+func listScrapeConfigs(ctx context.Context, a APIClientInterface, instanceId, projectId string) wait.GetFunc[argus.ListScrapeConfigsResponse] {
+	return func() (*argus.ListScrapeConfigsResponse, error) {
+		return a.ListScrapeConfigsExecute(ctx, instanceId, projectId)
+	}
+}
+
+// scrapeConfigState returns whether the scrape config of the job is listed
+func scrapeConfigState(jobName string) wait.StateFunc[argus.ListScrapeConfigsResponse] {
+	return func(s *argus.ListScrapeConfigsResponse) (string, error) {
+		if s.Data == nil {
+			return scrapeConfigStateNotListed, nil
 		}
 		jobs := *s.Data
 		for i := range jobs {
-			if *jobs[i].JobName == jobName {
-				return false, nil, nil
+			if jobs[i].JobName != nil && *jobs[i].JobName == jobName {
+				return scrapeConfigStateListed, nil
 			}
 		}
-		return true, s, nil
-	})
-	handler.SetTimeout(3 * time.Minute)
-	return handler
+		return scrapeConfigStateNotListed, nil
+	}
 }
@@ -0,0 +1,283 @@
+// Package acme implements a DNS-01 challenge provider for ACME clients such as lego, backed by the STACKIT DNS service.
+package acme
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+const (
+	// DefaultTTL is the TTL of the TXT record sets created for the challenges
+	DefaultTTL = int64(60)
+	// DefaultPropagationTimeout is how long the ACME client checks for the TXT records to be propagated
+	DefaultPropagationTimeout = 5 * time.Minute
+	// DefaultPollingInterval is the interval at which the ACME client checks for the TXT records to be propagated
+	DefaultPollingInterval = 10 * time.Second
+)
+
+// Actions of PartialUpdateRecordPayload
+const (
+	recordActionAdd    = "add"
+	recordActionDelete = "delete"
+)
+
+var _ challenge.ProviderTimeout = &DNSProvider{}
+
+// apiClient is the part of the DNS API used by DNSProvider.
+type apiClient interface {
+	wait.APIClientInterface
+	findZone(ctx context.Context, projectId, dnsName string) (*dns.Zone, error)
+	findRecordSet(ctx context.Context, projectId, zoneId, name string) (*dns.RecordSet, error)
+	createRecordSet(ctx context.Context, projectId, zoneId string, payload dns.CreateRecordSetPayload) (*dns.RecordSetResponse, error)
+	partialUpdateRecord(ctx context.Context, projectId, zoneId, rrSetId string, payload dns.PartialUpdateRecordPayload) error
+	deleteRecordSet(ctx context.Context, projectId, zoneId, rrSetId string) error
+}
+
+type apiClientAdapter struct {
+	*dns.APIClient
+}
+
+func (a apiClientAdapter) findZone(ctx context.Context, projectId, dnsName string) (*dns.Zone, error) {
+	resp, err := a.ListZones(ctx, projectId).DnsNameEq(dnsName).ActiveEq(true).Execute()
+	if err != nil {
+		return nil, err
+	}
+	if resp.Zones == nil || len(*resp.Zones) == 0 {
+		return nil, nil
+	}
+	return &(*resp.Zones)[0], nil
+}
+
+func (a apiClientAdapter) findRecordSet(ctx context.Context, projectId, zoneId, name string) (*dns.RecordSet, error) {
+	resp, err := a.ListRecordSets(ctx, projectId, zoneId).NameEq(name).TypeEq("TXT").StateNeq(wait.DeleteSuccess).Execute()
+	if err != nil {
+		return nil, err
+	}
+	if resp.RrSets == nil || len(*resp.RrSets) == 0 {
+		return nil, nil
+	}
+	return &(*resp.RrSets)[0], nil
+}
+
+func (a apiClientAdapter) createRecordSet(ctx context.Context, projectId, zoneId string, payload dns.CreateRecordSetPayload) (*dns.RecordSetResponse, error) {
+	return a.CreateRecordSet(ctx, projectId, zoneId).CreateRecordSetPayload(payload).Execute()
+}
+
+func (a apiClientAdapter) partialUpdateRecord(ctx context.Context, projectId, zoneId, rrSetId string, payload dns.PartialUpdateRecordPayload) error {
+	_, err := a.PartialUpdateRecord(ctx, projectId, zoneId, rrSetId).PartialUpdateRecordPayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) deleteRecordSet(ctx context.Context, projectId, zoneId, rrSetId string) error {
+	_, err := a.DeleteRecordSetExecute(ctx, projectId, zoneId, rrSetId)
+	return err
+}
+
+// DNSProvider solves DNS-01 challenges by creating the _acme-challenge TXT records in the zones of a STACKIT project.
+// It implements challenge.Provider and challenge.ProviderTimeout of lego.
+//
+// Several challenges for the same name (e.g. for "example.com" and "*.example.com") share a record set,
+// their values are added to and removed from it individually.
+type DNSProvider struct {
+	client             apiClient
+	projectId          string
+	ttl                int64
+	propagationTimeout time.Duration
+	pollingInterval    time.Duration
+	waitTimeout        time.Duration
+	// Serializes the changes to the record sets, since they are read before being modified
+	mu sync.Mutex
+}
+
+// NewDNSProvider initializes a DNSProvider for the zones of the given project
+func NewDNSProvider(client *dns.APIClient, projectId string) *DNSProvider {
+	return newDNSProvider(apiClientAdapter{client}, projectId)
+}
+
+func newDNSProvider(a apiClient, projectId string) *DNSProvider {
+	return &DNSProvider{
+		client:             a,
+		projectId:          projectId,
+		ttl:                DefaultTTL,
+		propagationTimeout: DefaultPropagationTimeout,
+		pollingInterval:    DefaultPollingInterval,
+	}
+}
+
+// SetTTL sets the TTL of the TXT record sets created for the challenges.
+func (d *DNSProvider) SetTTL(ttl int64) *DNSProvider {
+	d.ttl = ttl
+	return d
+}
+
+// SetPropagationTimeout sets how long and how often the ACME client checks for the TXT records to be propagated, see Timeout.
+func (d *DNSProvider) SetPropagationTimeout(timeout, interval time.Duration) *DNSProvider {
+	d.propagationTimeout = timeout
+	d.pollingInterval = interval
+	return d
+}
+
+// SetWaitTimeout sets how long to wait for a record set change to be applied by the DNS service.
+// If not set, the timeouts of the record set wait handlers are used.
+func (d *DNSProvider) SetWaitTimeout(timeout time.Duration) *DNSProvider {
+	d.waitTimeout = timeout
+	return d
+}
+
+// Timeout returns how long and how often the ACME client checks for the TXT records to be propagated.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.propagationTimeout, d.pollingInterval
+}
+
+// Present creates the TXT record for the challenge, or adds its value to the existing record set, and waits until it is applied.
+func (d *DNSProvider) Present(domain, _, keyAuth string) error {
+	ctx := context.Background()
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+	content := quote(value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	zone, err := d.authoritativeZone(ctx, fqdn)
+	if err != nil {
+		return err
+	}
+	rrSet, err := d.client.findRecordSet(ctx, d.projectId, *zone.Id, fqdn)
+	if err != nil {
+		return fmt.Errorf("get record set %s: %w", fqdn, err)
+	}
+
+	if rrSet == nil {
+		resp, err := d.client.createRecordSet(ctx, d.projectId, *zone.Id, dns.CreateRecordSetPayload{
+			Name:    utils.Ptr(fqdn),
+			Type:    utils.Ptr("TXT"),
+			Ttl:     utils.Ptr(d.ttl),
+			Records: &[]dns.RecordPayload{{Content: utils.Ptr(content)}},
+		})
+		if err != nil {
+			return fmt.Errorf("create record set %s: %w", fqdn, err)
+		}
+		if resp.Rrset == nil || resp.Rrset.Id == nil {
+			return fmt.Errorf("create record set %s: response is missing the record set id", fqdn)
+		}
+		handler := wait.CreateRecordSetWaitHandler(ctx, d.client, d.projectId, *zone.Id, *resp.Rrset.Id)
+		if d.waitTimeout != 0 {
+			handler.SetTimeout(d.waitTimeout)
+		}
+		if _, err := handler.WaitWithContext(ctx); err != nil {
+			return fmt.Errorf("wait for record set %s creation: %w", fqdn, err)
+		}
+		return nil
+	}
+
+	if _, ok := findRecord(rrSet, content); ok {
+		return nil
+	}
+	return d.updateRecords(ctx, *zone.Id, rrSet, recordActionAdd, content)
+}
+
+// CleanUp removes the value of the challenge from the TXT record set, deleting the record set if it was its last value.
+func (d *DNSProvider) CleanUp(domain, _, keyAuth string) error {
+	ctx := context.Background()
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+	content := quote(value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	zone, err := d.authoritativeZone(ctx, fqdn)
+	if err != nil {
+		return err
+	}
+	rrSet, err := d.client.findRecordSet(ctx, d.projectId, *zone.Id, fqdn)
+	if err != nil {
+		return fmt.Errorf("get record set %s: %w", fqdn, err)
+	}
+	if rrSet == nil {
+		return nil
+	}
+	stored, ok := findRecord(rrSet, content)
+	if !ok {
+		return nil
+	}
+
+	if len(*rrSet.Records) > 1 {
+		return d.updateRecords(ctx, *zone.Id, rrSet, recordActionDelete, stored)
+	}
+	if err := d.client.deleteRecordSet(ctx, d.projectId, *zone.Id, *rrSet.Id); err != nil {
+		return fmt.Errorf("delete record set %s: %w", fqdn, err)
+	}
+	handler := wait.DeleteRecordSetWaitHandler(ctx, d.client, d.projectId, *zone.Id, *rrSet.Id)
+	if d.waitTimeout != 0 {
+		handler.SetTimeout(d.waitTimeout)
+	}
+	if _, err := handler.WaitWithContext(ctx); err != nil {
+		return fmt.Errorf("wait for record set %s deletion: %w", fqdn, err)
+	}
+	return nil
+}
+
+// updateRecords adds or deletes a single record of the record set and waits until the change is applied
+func (d *DNSProvider) updateRecords(ctx context.Context, zoneId string, rrSet *dns.RecordSet, action, content string) error {
+	err := d.client.partialUpdateRecord(ctx, d.projectId, zoneId, *rrSet.Id, dns.PartialUpdateRecordPayload{
+		Action:  utils.Ptr(action),
+		Records: &[]dns.RecordPayload{{Content: utils.Ptr(content)}},
+	})
+	if err != nil {
+		return fmt.Errorf("%s record of record set %s: %w", action, *rrSet.Name, err)
+	}
+	handler := wait.PartialUpdateRecordSetWaitHandler(ctx, d.client, d.projectId, zoneId, *rrSet.Id)
+	if d.waitTimeout != 0 {
+		handler.SetTimeout(d.waitTimeout)
+	}
+	if _, err := handler.WaitWithContext(ctx); err != nil {
+		return fmt.Errorf("wait for record set %s update: %w", *rrSet.Name, err)
+	}
+	return nil
+}
+
+// authoritativeZone returns the zone of the project with the longest DNS name that fqdn belongs to
+func (d *DNSProvider) authoritativeZone(ctx context.Context, fqdn string) (*dns.Zone, error) {
+	labels := strings.Split(dns01.UnFqdn(fqdn), ".")
+	for i := range labels {
+		dnsName := strings.Join(labels[i:], ".")
+		zone, err := d.client.findZone(ctx, d.projectId, dnsName)
+		if err != nil {
+			return nil, fmt.Errorf("list zones with DNS name %s: %w", dnsName, err)
+		}
+		if zone != nil {
+			if zone.Id == nil {
+				return nil, fmt.Errorf("zone with DNS name %s is missing its id", dnsName)
+			}
+			return zone, nil
+		}
+	}
+	return nil, fmt.Errorf("no zone found for %s in project %s", fqdn, d.projectId)
+}
+
+// findRecord returns the content of the record of the record set with the given content, which may have been stored unquoted
+func findRecord(rrSet *dns.RecordSet, content string) (string, bool) {
+	if rrSet.Records == nil {
+		return "", false
+	}
+	for _, r := range *rrSet.Records {
+		if r.Content != nil && (*r.Content == content || *r.Content == strings.Trim(content, `"`)) {
+			return *r.Content, true
+		}
+	}
+	return "", false
+}
+
+// quote returns the value as a character string, which is how the content of TXT records is set
+func quote(value string) string {
+	return `"` + value + `"`
+}
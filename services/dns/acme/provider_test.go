@@ -0,0 +1,242 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// apiClientMocked is an in-memory DNS service with the zones "example.com" and "sub.example.com"
+type apiClientMocked struct {
+	mu     sync.Mutex
+	zones  map[string]string
+	rrSets map[string]*dns.RecordSet
+	calls  []string
+	nextId int
+}
+
+func newAPIClientMocked() *apiClientMocked {
+	return &apiClientMocked{
+		zones: map[string]string{
+			"example.com":     "zone-example",
+			"sub.example.com": "zone-sub",
+		},
+		rrSets: map[string]*dns.RecordSet{},
+	}
+}
+
+func (a *apiClientMocked) GetZoneExecute(_ context.Context, _, _ string) (*dns.ZoneResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) GetRecordSetExecute(_ context.Context, _, _, rrSetId string) (*dns.RecordSetResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, rrSet := range a.rrSets {
+		if *rrSet.Id == rrSetId {
+			return &dns.RecordSetResponse{Rrset: rrSet}, nil
+		}
+	}
+	return nil, fmt.Errorf("record set %s not found", rrSetId)
+}
+
+func (a *apiClientMocked) findZone(_ context.Context, _, dnsName string) (*dns.Zone, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, fmt.Sprintf("find zone %s", dnsName))
+	id, ok := a.zones[dnsName]
+	if !ok {
+		return nil, nil
+	}
+	return &dns.Zone{Id: utils.Ptr(id), DnsName: utils.Ptr(dnsName)}, nil
+}
+
+func (a *apiClientMocked) findRecordSet(_ context.Context, _, zoneId, name string) (*dns.RecordSet, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rrSet, ok := a.rrSets[zoneId+" "+name]
+	if !ok || *rrSet.State == wait.DeleteSuccess {
+		return nil, nil
+	}
+	records := append([]dns.Record{}, *rrSet.Records...)
+	found := *rrSet
+	found.Records = &records
+	return &found, nil
+}
+
+func (a *apiClientMocked) createRecordSet(_ context.Context, _, zoneId string, payload dns.CreateRecordSetPayload) (*dns.RecordSetResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, fmt.Sprintf("create %s in %s", *payload.Name, zoneId))
+	a.nextId++
+	records := []dns.Record{}
+	for _, r := range *payload.Records {
+		records = append(records, dns.Record{Content: r.Content})
+	}
+	rrSet := &dns.RecordSet{
+		Id:      utils.Ptr(fmt.Sprintf("rrset-%d", a.nextId)),
+		Name:    payload.Name,
+		Type:    payload.Type,
+		Ttl:     payload.Ttl,
+		Records: &records,
+		State:   utils.Ptr(wait.CreateSuccess),
+	}
+	a.rrSets[zoneId+" "+*payload.Name] = rrSet
+	return &dns.RecordSetResponse{Rrset: rrSet}, nil
+}
+
+func (a *apiClientMocked) partialUpdateRecord(_ context.Context, _, zoneId, rrSetId string, payload dns.PartialUpdateRecordPayload) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, fmt.Sprintf("%s record of %s", *payload.Action, rrSetId))
+	for _, rrSet := range a.rrSets {
+		if *rrSet.Id != rrSetId {
+			continue
+		}
+		records := []dns.Record{}
+		for _, r := range *rrSet.Records {
+			if *payload.Action == recordActionDelete && *r.Content == *(*payload.Records)[0].Content {
+				continue
+			}
+			records = append(records, r)
+		}
+		if *payload.Action == recordActionAdd {
+			records = append(records, dns.Record{Content: (*payload.Records)[0].Content})
+		}
+		rrSet.Records = &records
+		rrSet.State = utils.Ptr(wait.UpdateSuccess)
+		return nil
+	}
+	return fmt.Errorf("record set %s not found", rrSetId)
+}
+
+func (a *apiClientMocked) deleteRecordSet(_ context.Context, _, _, rrSetId string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, fmt.Sprintf("delete %s", rrSetId))
+	for _, rrSet := range a.rrSets {
+		if *rrSet.Id == rrSetId {
+			rrSet.State = utils.Ptr(wait.DeleteSuccess)
+			return nil
+		}
+	}
+	return fmt.Errorf("record set %s not found", rrSetId)
+}
+
+func (a *apiClientMocked) records(zoneId, name string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rrSet, ok := a.rrSets[zoneId+" "+name]
+	if !ok || *rrSet.State == wait.DeleteSuccess {
+		return nil
+	}
+	records := []string{}
+	for _, r := range *rrSet.Records {
+		records = append(records, *r.Content)
+	}
+	return records
+}
+
+func TestDNSProvider(t *testing.T) {
+	a := newAPIClientMocked()
+	p := newDNSProvider(a, "pid")
+	_, value1 := dns01.GetRecord("www.example.com", "key-auth-1")
+	_, value2 := dns01.GetRecord("www.example.com", "key-auth-2")
+	name := "_acme-challenge.www.example.com."
+
+	if err := p.Present("www.example.com", "token", "key-auth-1"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+	// Presenting the same challenge again is a no-op
+	if err := p.Present("www.example.com", "token", "key-auth-1"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+	// A second challenge for the same name, e.g. for a wildcard certificate, where lego passes the domain without "*."
+	if err := p.Present("www.example.com", "token", "key-auth-2"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+	if diff := cmp.Diff(a.records("zone-example", name), []string{quote(value1), quote(value2)}); diff != "" {
+		t.Fatalf("unexpected records after present (-got +want): %s", diff)
+	}
+
+	if err := p.CleanUp("www.example.com", "token", "key-auth-1"); err != nil {
+		t.Fatalf("CleanUp: %v", err)
+	}
+	if diff := cmp.Diff(a.records("zone-example", name), []string{quote(value2)}); diff != "" {
+		t.Fatalf("unexpected records after first clean up (-got +want): %s", diff)
+	}
+	if err := p.CleanUp("www.example.com", "token", "key-auth-2"); err != nil {
+		t.Fatalf("CleanUp: %v", err)
+	}
+	if got := a.records("zone-example", name); got != nil {
+		t.Fatalf("record set still exists with records %v", got)
+	}
+	// Cleaning up again is a no-op
+	if err := p.CleanUp("www.example.com", "token", "key-auth-1"); err != nil {
+		t.Fatalf("CleanUp: %v", err)
+	}
+
+	wantCalls := []string{
+		"find zone _acme-challenge.www.example.com",
+		"find zone www.example.com",
+		"find zone example.com",
+		"create _acme-challenge.www.example.com. in zone-example",
+	}
+	if diff := cmp.Diff(a.calls[:4], wantCalls); diff != "" {
+		t.Fatalf("unexpected calls (-got +want): %s", diff)
+	}
+}
+
+func TestDNSProviderLongestZone(t *testing.T) {
+	a := newAPIClientMocked()
+	p := newDNSProvider(a, "pid")
+
+	if err := p.Present("host.sub.example.com", "token", "key-auth"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+	if got := a.records("zone-sub", "_acme-challenge.host.sub.example.com."); len(got) != 1 {
+		t.Fatalf("record set not created in the zone sub.example.com, records: %v", got)
+	}
+}
+
+func TestDNSProviderNoZone(t *testing.T) {
+	a := newAPIClientMocked()
+	p := newDNSProvider(a, "pid")
+
+	if err := p.Present("www.example.org", "token", "key-auth"); err == nil {
+		t.Fatalf("Present returned no error for a name without zone")
+	}
+}
+
+func TestDNSProviderConcurrent(t *testing.T) {
+	a := newAPIClientMocked()
+	p := newDNSProvider(a, "pid").SetWaitTimeout(time.Second)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- p.Present("example.com", "token", fmt.Sprintf("key-auth-%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Present: %v", err)
+		}
+	}
+	if got := a.records("zone-example", "_acme-challenge.example.com."); len(got) != 5 {
+		t.Fatalf("record set has %d records, want 5", len(got))
+	}
+}
@@ -0,0 +1,139 @@
+// Package query provides typed filters and ordering for ListRecordSets and ListZones of the DNS API,
+// which are validated before being applied to the requests, and helpers to list all pages of results.
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultPageSize is the page size used to list all pages of results
+const DefaultPageSize = int32(100)
+
+// Order is the direction of the ordering
+type Order string
+
+const (
+	Asc  Order = "ASC"
+	Desc Order = "DESC"
+)
+
+func (o Order) validate() error {
+	switch o {
+	case "", Asc, Desc:
+		return nil
+	}
+	return fmt.Errorf("invalid order %q", o)
+}
+
+// State is the state of a zone or record set
+type State string
+
+const (
+	StateCreating        State = "CREATING"
+	StateCreateSucceeded State = "CREATE_SUCCEEDED"
+	StateCreateFailed    State = "CREATE_FAILED"
+	StateUpdating        State = "UPDATING"
+	StateUpdateSucceeded State = "UPDATE_SUCCEEDED"
+	StateUpdateFailed    State = "UPDATE_FAILED"
+	StateDeleting        State = "DELETING"
+	StateDeleteSucceeded State = "DELETE_SUCCEEDED"
+	StateDeleteFailed    State = "DELETE_FAILED"
+)
+
+func (s State) validate() error {
+	switch s {
+	case "", StateCreating, StateCreateSucceeded, StateCreateFailed,
+		StateUpdating, StateUpdateSucceeded, StateUpdateFailed,
+		StateDeleting, StateDeleteSucceeded, StateDeleteFailed:
+		return nil
+	}
+	return fmt.Errorf("invalid state %q", s)
+}
+
+// TimeFilter filters by a timestamp. Zero values are not applied.
+type TimeFilter struct {
+	// After matches timestamps strictly after the given time
+	After time.Time
+	// From matches timestamps at or after the given time
+	From time.Time
+	// Before matches timestamps strictly before the given time
+	Before time.Time
+	// Until matches timestamps at or before the given time
+	Until time.Time
+}
+
+// validate rejects time filters that can't match any timestamp
+func (f *TimeFilter) validate(name string) error {
+	if !f.After.IsZero() && !f.From.IsZero() {
+		return fmt.Errorf("%s: After and From can't be used together", name)
+	}
+	if !f.Before.IsZero() && !f.Until.IsZero() {
+		return fmt.Errorf("%s: Before and Until can't be used together", name)
+	}
+	lower, lowerInclusive := f.From, true
+	if !f.After.IsZero() {
+		lower, lowerInclusive = f.After, false
+	}
+	upper, upperInclusive := f.Until, true
+	if !f.Before.IsZero() {
+		upper, upperInclusive = f.Before, false
+	}
+	if lower.IsZero() || upper.IsZero() {
+		return nil
+	}
+	if lower.After(upper) || (lower.Equal(upper) && !(lowerInclusive && upperInclusive)) {
+		return fmt.Errorf("%s: the range from %s to %s is empty", name, lower.Format(time.RFC3339), upper.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// validateTimes validates the creation started, creation finished, update started and update finished filters
+func validateTimes(creationStarted, creationFinished, updateStarted, updateFinished TimeFilter) error {
+	if err := creationStarted.validate("creation started"); err != nil {
+		return err
+	}
+	if err := creationFinished.validate("creation finished"); err != nil {
+		return err
+	}
+	if err := updateStarted.validate("update started"); err != nil {
+		return err
+	}
+	return updateFinished.validate("update finished")
+}
+
+// applyString applies a string filter with the given request setter if it is set
+func applyString[R any](r R, value string, set func(R, string) R) R {
+	if value == "" {
+		return r
+	}
+	return set(r, value)
+}
+
+// applyTime applies the time filter with the given request setters, which take UTC timestamps
+func applyTime[R any](r R, f TimeFilter, gt, gte, lt, lte func(R, string) R) R {
+	if !f.After.IsZero() {
+		r = gt(r, timestamp(f.After))
+	}
+	if !f.From.IsZero() {
+		r = gte(r, timestamp(f.From))
+	}
+	if !f.Before.IsZero() {
+		r = lt(r, timestamp(f.Before))
+	}
+	if !f.Until.IsZero() {
+		r = lte(r, timestamp(f.Until))
+	}
+	return r
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func validatePageSize(pageSize int32) error {
+	if pageSize < 0 {
+		return fmt.Errorf("invalid page size %d", pageSize)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/config"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+// newTestClient returns a client for a server that returns the given number of pages of results and records the query of each request
+func newTestClient(t *testing.T, totalPages int, queries *[]url.Values) *dns.APIClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		*queries = append(*queries, query)
+		page, err := strconv.Atoi(query.Get("page"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		id := utils.Ptr(strconv.Itoa(page))
+		w.Header().Add("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"rrSets":     []dns.RecordSet{{Id: id}},
+			"zones":      []dns.Zone{{Id: id}},
+			"totalPages": totalPages,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := dns.NewAPIClient(config.WithEndpoint(server.URL), config.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("creating API client: %v", err)
+	}
+	return client
+}
+
+func TestRecordSetFilterValidate(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		desc    string
+		filter  RecordSetFilter
+		wantErr bool
+	}{
+		{
+			desc:   "empty",
+			filter: RecordSetFilter{},
+		},
+		{
+			desc: "ok",
+			filter: RecordSetFilter{
+				Type:            TypeTXT,
+				NotState:        StateDeleteSucceeded,
+				CreationStarted: TimeFilter{From: t0, Until: t0},
+				UpdateStarted:   TimeFilter{After: t0, Before: t0.Add(time.Hour)},
+				OrderBy:         RecordSetRecordCount,
+				Order:           Desc,
+			},
+		},
+		{
+			desc:    "invalid_type",
+			filter:  RecordSetFilter{Type: "txt"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_state",
+			filter:  RecordSetFilter{State: "ACTIVE"},
+			wantErr: true,
+		},
+		{
+			desc:    "state_eq_and_neq",
+			filter:  RecordSetFilter{State: StateCreateSucceeded, NotState: StateCreateSucceeded},
+			wantErr: true,
+		},
+		{
+			desc:    "after_and_from",
+			filter:  RecordSetFilter{UpdateFinished: TimeFilter{After: t0, From: t0}},
+			wantErr: true,
+		},
+		{
+			desc:    "empty_range",
+			filter:  RecordSetFilter{CreationFinished: TimeFilter{From: t0, Until: t0.Add(-time.Second)}},
+			wantErr: true,
+		},
+		{
+			desc:    "empty_exclusive_range",
+			filter:  RecordSetFilter{CreationStarted: TimeFilter{After: t0, Until: t0}},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_order_field",
+			filter:  RecordSetFilter{OrderBy: "dnsName"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_order",
+			filter:  RecordSetFilter{OrderBy: RecordSetName, Order: "up"},
+			wantErr: true,
+		},
+		{
+			desc:    "order_without_field",
+			filter:  RecordSetFilter{Order: Asc},
+			wantErr: true,
+		},
+		{
+			desc:    "negative_page_size",
+			filter:  RecordSetFilter{PageSize: -1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestZoneFilterValidate(t *testing.T) {
+	tests := []struct {
+		desc    string
+		filter  ZoneFilter
+		wantErr bool
+	}{
+		{
+			desc:   "ok",
+			filter: ZoneFilter{Type: ZoneSecondary, Name: "a", NotName: "b", OrderBy: ZoneDnsName},
+		},
+		{
+			desc:    "invalid_type",
+			filter:  ZoneFilter{Type: "PRIMARY"},
+			wantErr: true,
+		},
+		{
+			desc:    "name_eq_and_neq",
+			filter:  ZoneFilter{Name: "a", NotName: "a"},
+			wantErr: true,
+		},
+		{
+			desc:    "description_eq_and_neq",
+			filter:  ZoneFilter{Description: "a", NotDescription: "a"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_order_field",
+			filter:  ZoneFilter{OrderBy: "state"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListRecordSets(t *testing.T) {
+	queries := []url.Values{}
+	client := newTestClient(t, 3, &queries)
+	filter := RecordSetFilter{
+		NameLike: "www",
+		Type:     TypeA,
+		NotState: StateDeleteSucceeded,
+		Active:   utils.Ptr(true),
+		CreationStarted: TimeFilter{
+			From:   time.Date(2024, 5, 1, 2, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			Before: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		OrderBy: RecordSetName,
+	}
+
+	rrSets, err := ListRecordSets(context.Background(), client, "pid", "zid", filter)
+	if err != nil {
+		t.Fatalf("ListRecordSets: %v", err)
+	}
+	ids := []string{}
+	for _, rrSet := range rrSets {
+		ids = append(ids, *rrSet.Id)
+	}
+	if diff := cmp.Diff(ids, []string{"1", "2", "3"}); diff != "" {
+		t.Fatalf("unexpected record sets (-got +want): %s", diff)
+	}
+	if len(queries) != 3 {
+		t.Fatalf("got %d requests, want 3", len(queries))
+	}
+	want := url.Values{
+		"page":                 {"3"},
+		"pageSize":             {"100"},
+		"name[like]":           {"www"},
+		"type[eq]":             {"A"},
+		"state[neq]":           {"DELETE_SUCCEEDED"},
+		"active[eq]":           {"true"},
+		"creationStarted[gte]": {"2024-05-01T00:00:00Z"},
+		"creationStarted[lt]":  {"2024-06-01T00:00:00Z"},
+		"orderBy[name]":        {"ASC"},
+	}
+	if diff := cmp.Diff(queries[2], want); diff != "" {
+		t.Fatalf("unexpected query (-got +want): %s", diff)
+	}
+}
+
+func TestListRecordSetsInvalidFilter(t *testing.T) {
+	queries := []url.Values{}
+	client := newTestClient(t, 1, &queries)
+
+	_, err := ListRecordSets(context.Background(), client, "pid", "zid", RecordSetFilter{Order: Desc})
+	if err == nil {
+		t.Fatalf("ListRecordSets returned no error for an invalid filter")
+	}
+	if len(queries) != 0 {
+		t.Fatalf("got %d requests, want none", len(queries))
+	}
+}
+
+func TestListZones(t *testing.T) {
+	queries := []url.Values{}
+	client := newTestClient(t, 2, &queries)
+	filter := ZoneFilter{
+		DnsNameLike:    "example",
+		Type:           ZonePrimary,
+		NotName:        "test",
+		IsReverseZone:  utils.Ptr(false),
+		LabelKeys:      []string{"env"},
+		UpdateFinished: TimeFilter{After: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		OrderBy:        ZoneRecordCount,
+		Order:          Desc,
+		PageSize:       10,
+	}
+
+	zones, err := ListZones(context.Background(), client, "pid", filter)
+	if err != nil {
+		t.Fatalf("ListZones: %v", err)
+	}
+	if len(zones) != 2 {
+		t.Fatalf("got %d zones, want 2", len(zones))
+	}
+	want := url.Values{
+		"page":                 {"1"},
+		"pageSize":             {"10"},
+		"dnsName[like]":        {"example"},
+		"type[eq]":             {"primary"},
+		"name[neq]":            {"test"},
+		"isReverseZone[eq]":    {"false"},
+		"labelKey[eq]":         {"env"},
+		"updateFinished[gt]":   {"2024-05-01T00:00:00Z"},
+		"orderBy[recordCount]": {"DESC"},
+	}
+	if diff := cmp.Diff(queries[0], want); diff != "" {
+		t.Fatalf("unexpected query (-got +want): %s", diff)
+	}
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+// RecordType is the type of a record set
+type RecordType string
+
+const (
+	TypeA      RecordType = "A"
+	TypeAAAA   RecordType = "AAAA"
+	TypeSOA    RecordType = "SOA"
+	TypeCNAME  RecordType = "CNAME"
+	TypeNS     RecordType = "NS"
+	TypeMX     RecordType = "MX"
+	TypeTXT    RecordType = "TXT"
+	TypeSRV    RecordType = "SRV"
+	TypePTR    RecordType = "PTR"
+	TypeALIAS  RecordType = "ALIAS"
+	TypeDNAME  RecordType = "DNAME"
+	TypeCAA    RecordType = "CAA"
+	TypeDNSKEY RecordType = "DNSKEY"
+	TypeDS     RecordType = "DS"
+	TypeLOC    RecordType = "LOC"
+	TypeNAPTR  RecordType = "NAPTR"
+	TypeSSHFP  RecordType = "SSHFP"
+	TypeTLSA   RecordType = "TLSA"
+	TypeURI    RecordType = "URI"
+	TypeCERT   RecordType = "CERT"
+	TypeSVCB   RecordType = "SVCB"
+	TypeHTTPS  RecordType = "HTTPS"
+)
+
+func (t RecordType) validate() error {
+	switch t {
+	case "", TypeA, TypeAAAA, TypeSOA, TypeCNAME, TypeNS, TypeMX, TypeTXT, TypeSRV, TypePTR, TypeALIAS, TypeDNAME, TypeCAA,
+		TypeDNSKEY, TypeDS, TypeLOC, TypeNAPTR, TypeSSHFP, TypeTLSA, TypeURI, TypeCERT, TypeSVCB, TypeHTTPS:
+		return nil
+	}
+	return fmt.Errorf("invalid record type %q", t)
+}
+
+// RecordSetField is a field record sets can be ordered by
+type RecordSetField string
+
+const (
+	RecordSetName             RecordSetField = "name"
+	RecordSetCreationStarted  RecordSetField = "creationStarted"
+	RecordSetCreationFinished RecordSetField = "creationFinished"
+	RecordSetUpdateStarted    RecordSetField = "updateStarted"
+	RecordSetUpdateFinished   RecordSetField = "updateFinished"
+	RecordSetType             RecordSetField = "type"
+	RecordSetState            RecordSetField = "state"
+	RecordSetRecordCount      RecordSetField = "recordCount"
+)
+
+// RecordSetFilter holds the filters and ordering of ListRecordSets. Zero values are not applied.
+type RecordSetFilter struct {
+	Name     string
+	NameLike string
+	Type     RecordType
+	State    State
+	NotState State
+	Active   *bool
+
+	CreationStarted  TimeFilter
+	CreationFinished TimeFilter
+	UpdateStarted    TimeFilter
+	UpdateFinished   TimeFilter
+
+	OrderBy RecordSetField
+	// Order is the direction of OrderBy, defaults to Asc
+	Order Order
+
+	// PageSize is the page size used by ListRecordSets, defaults to DefaultPageSize
+	PageSize int32
+}
+
+// Validate returns an error if the filter has invalid values or contradictory filters
+func (f *RecordSetFilter) Validate() error {
+	if err := f.Type.validate(); err != nil {
+		return err
+	}
+	if err := f.State.validate(); err != nil {
+		return err
+	}
+	if err := f.NotState.validate(); err != nil {
+		return err
+	}
+	if f.State != "" && f.State == f.NotState {
+		return fmt.Errorf("state can't be both equal and not equal to %s", f.State)
+	}
+	if err := validateTimes(f.CreationStarted, f.CreationFinished, f.UpdateStarted, f.UpdateFinished); err != nil {
+		return err
+	}
+	switch f.OrderBy {
+	case "", RecordSetName, RecordSetCreationStarted, RecordSetCreationFinished, RecordSetUpdateStarted, RecordSetUpdateFinished,
+		RecordSetType, RecordSetState, RecordSetRecordCount:
+	default:
+		return fmt.Errorf("invalid record set order field %q", f.OrderBy)
+	}
+	if err := f.Order.validate(); err != nil {
+		return err
+	}
+	if f.Order != "" && f.OrderBy == "" {
+		return fmt.Errorf("order %s is set without a field to order by", f.Order)
+	}
+	return validatePageSize(f.PageSize)
+}
+
+// Apply validates the filter and sets it on the request
+func (f *RecordSetFilter) Apply(r dns.ApiListRecordSetsRequest) (dns.ApiListRecordSetsRequest, error) {
+	if err := f.Validate(); err != nil {
+		return r, err
+	}
+	type req = dns.ApiListRecordSetsRequest
+	r = applyString(r, f.Name, req.NameEq)
+	r = applyString(r, f.NameLike, req.NameLike)
+	r = applyString(r, string(f.Type), req.TypeEq)
+	r = applyString(r, string(f.State), req.StateEq)
+	r = applyString(r, string(f.NotState), req.StateNeq)
+	if f.Active != nil {
+		r = r.ActiveEq(*f.Active)
+	}
+	r = applyTime(r, f.CreationStarted, req.CreationStartedGt, req.CreationStartedGte, req.CreationStartedLt, req.CreationStartedLte)
+	r = applyTime(r, f.CreationFinished, req.CreationFinishedGt, req.CreationFinishedGte, req.CreationFinishedLt, req.CreationFinishedLte)
+	r = applyTime(r, f.UpdateStarted, req.UpdateStartedGt, req.UpdateStartedGte, req.UpdateStartedLt, req.UpdateStartedLte)
+	r = applyTime(r, f.UpdateFinished, req.UpdateFinishedGt, req.UpdateFinishedGte, req.UpdateFinishedLt, req.UpdateFinishedLte)
+
+	order := string(Asc)
+	if f.Order != "" {
+		order = string(f.Order)
+	}
+	switch f.OrderBy {
+	case RecordSetName:
+		r = r.OrderByName(order)
+	case RecordSetCreationStarted:
+		r = r.OrderByCreationStarted(order)
+	case RecordSetCreationFinished:
+		r = r.OrderByCreationFinished(order)
+	case RecordSetUpdateStarted:
+		r = r.OrderByUpdateStarted(order)
+	case RecordSetUpdateFinished:
+		r = r.OrderByUpdateFinished(order)
+	case RecordSetType:
+		r = r.OrderByType(order)
+	case RecordSetState:
+		r = r.OrderByState(order)
+	case RecordSetRecordCount:
+		r = r.OrderByRecordCount(order)
+	}
+	if f.PageSize != 0 {
+		r = r.PageSize(f.PageSize)
+	}
+	return r, nil
+}
+
+// ListRecordSets returns the record sets of the zone matching the filter, requesting all pages of results
+func ListRecordSets(ctx context.Context, client *dns.APIClient, projectId, zoneId string, filter RecordSetFilter) ([]dns.RecordSet, error) {
+	if filter.PageSize == 0 {
+		filter.PageSize = DefaultPageSize
+	}
+	req, err := filter.Apply(client.ListRecordSets(ctx, projectId, zoneId))
+	if err != nil {
+		return nil, err
+	}
+	rrSets := []dns.RecordSet{}
+	for page := int32(1); ; page++ {
+		resp, err := req.Page(page).Execute()
+		if err != nil {
+			return nil, fmt.Errorf("list record sets, page %d: %w", page, err)
+		}
+		if resp.RrSets != nil {
+			rrSets = append(rrSets, *resp.RrSets...)
+		}
+		if resp.TotalPages == nil || int64(page) >= *resp.TotalPages || resp.RrSets == nil || len(*resp.RrSets) == 0 {
+			return rrSets, nil
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+// ZoneType is the type of a zone
+type ZoneType string
+
+const (
+	ZonePrimary   ZoneType = "primary"
+	ZoneSecondary ZoneType = "secondary"
+)
+
+func (t ZoneType) validate() error {
+	switch t {
+	case "", ZonePrimary, ZoneSecondary:
+		return nil
+	}
+	return fmt.Errorf("invalid zone type %q", t)
+}
+
+// ZoneField is a field zones can be ordered by
+type ZoneField string
+
+const (
+	ZoneDnsName          ZoneField = "dnsName"
+	ZoneName             ZoneField = "name"
+	ZoneRecordCount      ZoneField = "recordCount"
+	ZoneTypeField        ZoneField = "type"
+	ZoneDescription      ZoneField = "description"
+	ZoneCreationStarted  ZoneField = "creationStarted"
+	ZoneCreationFinished ZoneField = "creationFinished"
+	ZoneUpdateStarted    ZoneField = "updateStarted"
+	ZoneUpdateFinished   ZoneField = "updateFinished"
+)
+
+// ZoneFilter holds the filters and ordering of ListZones. Zero values are not applied.
+type ZoneFilter struct {
+	DnsName               string
+	DnsNameLike           string
+	Type                  ZoneType
+	Name                  string
+	NotName               string
+	NameLike              string
+	Description           string
+	NotDescription        string
+	DescriptionLike       string
+	State                 State
+	NotState              State
+	PrimaryNameServer     string
+	PrimaryNameServerLike string
+	IsReverseZone         *bool
+	Active                *bool
+	// LabelKeys matches zones with a label with one of the keys
+	LabelKeys []string
+	// LabelValues matches zones with a label with one of the values
+	LabelValues []string
+
+	CreationStarted  TimeFilter
+	CreationFinished TimeFilter
+	UpdateStarted    TimeFilter
+	UpdateFinished   TimeFilter
+
+	OrderBy ZoneField
+	// Order is the direction of OrderBy, defaults to Asc
+	Order Order
+
+	// PageSize is the page size used by ListZones, defaults to DefaultPageSize
+	PageSize int32
+}
+
+// Validate returns an error if the filter has invalid values or contradictory filters
+func (f *ZoneFilter) Validate() error {
+	if err := f.Type.validate(); err != nil {
+		return err
+	}
+	if err := f.State.validate(); err != nil {
+		return err
+	}
+	if err := f.NotState.validate(); err != nil {
+		return err
+	}
+	if f.State != "" && f.State == f.NotState {
+		return fmt.Errorf("state can't be both equal and not equal to %s", f.State)
+	}
+	if f.Name != "" && f.Name == f.NotName {
+		return fmt.Errorf("name can't be both equal and not equal to %s", f.Name)
+	}
+	if f.Description != "" && f.Description == f.NotDescription {
+		return fmt.Errorf("description can't be both equal and not equal to %s", f.Description)
+	}
+	if err := validateTimes(f.CreationStarted, f.CreationFinished, f.UpdateStarted, f.UpdateFinished); err != nil {
+		return err
+	}
+	switch f.OrderBy {
+	case "", ZoneDnsName, ZoneName, ZoneRecordCount, ZoneTypeField, ZoneDescription,
+		ZoneCreationStarted, ZoneCreationFinished, ZoneUpdateStarted, ZoneUpdateFinished:
+	default:
+		return fmt.Errorf("invalid zone order field %q", f.OrderBy)
+	}
+	if err := f.Order.validate(); err != nil {
+		return err
+	}
+	if f.Order != "" && f.OrderBy == "" {
+		return fmt.Errorf("order %s is set without a field to order by", f.Order)
+	}
+	return validatePageSize(f.PageSize)
+}
+
+// Apply validates the filter and sets it on the request
+func (f *ZoneFilter) Apply(r dns.ApiListZonesRequest) (dns.ApiListZonesRequest, error) {
+	if err := f.Validate(); err != nil {
+		return r, err
+	}
+	type req = dns.ApiListZonesRequest
+	r = applyString(r, f.DnsName, req.DnsNameEq)
+	r = applyString(r, f.DnsNameLike, req.DnsNameLike)
+	r = applyString(r, string(f.Type), req.TypeEq)
+	r = applyString(r, f.Name, req.NameEq)
+	r = applyString(r, f.NotName, req.NameNeq)
+	r = applyString(r, f.NameLike, req.NameLike)
+	r = applyString(r, f.Description, req.DescriptionEq)
+	r = applyString(r, f.NotDescription, req.DescriptionNeq)
+	r = applyString(r, f.DescriptionLike, req.DescriptionLike)
+	r = applyString(r, string(f.State), req.StateEq)
+	r = applyString(r, string(f.NotState), req.StateNeq)
+	r = applyString(r, f.PrimaryNameServer, req.PrimaryNameServerEq)
+	r = applyString(r, f.PrimaryNameServerLike, req.PrimaryNameServerLike)
+	if len(f.LabelKeys) > 0 {
+		r = r.LabelKeyEq(f.LabelKeys)
+	}
+	if len(f.LabelValues) > 0 {
+		r = r.LabelValueEq(f.LabelValues)
+	}
+	if f.IsReverseZone != nil {
+		r = r.IsReverseZoneEq(*f.IsReverseZone)
+	}
+	if f.Active != nil {
+		r = r.ActiveEq(*f.Active)
+	}
+	r = applyTime(r, f.CreationStarted, req.CreationStartedGt, req.CreationStartedGte, req.CreationStartedLt, req.CreationStartedLte)
+	r = applyTime(r, f.CreationFinished, req.CreationFinishedGt, req.CreationFinishedGte, req.CreationFinishedLt, req.CreationFinishedLte)
+	r = applyTime(r, f.UpdateStarted, req.UpdateStartedGt, req.UpdateStartedGte, req.UpdateStartedLt, req.UpdateStartedLte)
+	r = applyTime(r, f.UpdateFinished, req.UpdateFinishedGt, req.UpdateFinishedGte, req.UpdateFinishedLt, req.UpdateFinishedLte)
+
+	order := string(Asc)
+	if f.Order != "" {
+		order = string(f.Order)
+	}
+	switch f.OrderBy {
+	case ZoneDnsName:
+		r = r.OrderByDnsName(order)
+	case ZoneName:
+		r = r.OrderByName(order)
+	case ZoneRecordCount:
+		r = r.OrderByRecordCount(order)
+	case ZoneTypeField:
+		r = r.OrderByType(order)
+	case ZoneDescription:
+		r = r.OrderByDescription(order)
+	case ZoneCreationStarted:
+		r = r.OrderByCreationStarted(order)
+	case ZoneCreationFinished:
+		r = r.OrderByCreationFinished(order)
+	case ZoneUpdateStarted:
+		r = r.OrderByUpdateStarted(order)
+	case ZoneUpdateFinished:
+		r = r.OrderByUpdateFinished(order)
+	}
+	if f.PageSize != 0 {
+		r = r.PageSize(f.PageSize)
+	}
+	return r, nil
+}
+
+// ListZones returns the zones of the project matching the filter, requesting all pages of results
+func ListZones(ctx context.Context, client *dns.APIClient, projectId string, filter ZoneFilter) ([]dns.Zone, error) {
+	if filter.PageSize == 0 {
+		filter.PageSize = DefaultPageSize
+	}
+	req, err := filter.Apply(client.ListZones(ctx, projectId))
+	if err != nil {
+		return nil, err
+	}
+	zones := []dns.Zone{}
+	for page := int32(1); ; page++ {
+		resp, err := req.Page(page).Execute()
+		if err != nil {
+			return nil, fmt.Errorf("list zones, page %d: %w", page, err)
+		}
+		if resp.Zones != nil {
+			zones = append(zones, *resp.Zones...)
+		}
+		if resp.TotalPages == nil || int64(page) >= *resp.TotalPages || resp.Zones == nil || len(*resp.Zones) == 0 {
+			return zones, nil
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// Actions of PartialUpdateRecordPayload
+const (
+	RecordActionAdd    = "add"
+	RecordActionDelete = "delete"
+)
+
+// RecordSet is the desired state of a record set
+type RecordSet struct {
+	// Name is the absolute name of the record set, a trailing dot is added if missing
+	Name string
+	Type string
+	// TTL is the time to live of the record set. If nil, the TTL of an existing record set is kept and new record sets get the zone TTL.
+	TTL     *int64
+	Records []string
+	Comment string
+}
+
+// ChangeType is the kind of change applied to a record set
+type ChangeType string
+
+const (
+	// ChangeCreate creates a new record set
+	ChangeCreate ChangeType = "create"
+	// ChangeUpdate updates the TTL, comment or records of a record set with PartialUpdateRecordSet
+	ChangeUpdate ChangeType = "update"
+	// ChangeAddRecords adds records to a record set with PartialUpdateRecord
+	ChangeAddRecords ChangeType = "add-records"
+	// ChangeDeleteRecords deletes records of a record set with PartialUpdateRecord
+	ChangeDeleteRecords ChangeType = "delete-records"
+	// ChangeDelete deletes a record set
+	ChangeDelete ChangeType = "delete"
+)
+
+// Change is a single API call needed to reconcile a record set
+type Change struct {
+	Type       ChangeType
+	Name       string
+	RecordType string
+	// RrSetId is the id of the existing record set, empty for ChangeCreate
+	RrSetId string
+	// TTL is set if it changes
+	TTL *int64
+	// Comment is set if it changes
+	Comment *string
+	// Records holds the records of the record set for ChangeCreate and ChangeUpdate,
+	// or the records added or deleted for ChangeAddRecords and ChangeDeleteRecords.
+	// It is nil for a ChangeUpdate that doesn't change the records.
+	Records []string
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Type, c.Name, c.RecordType)
+	details := []string{}
+	if c.TTL != nil {
+		details = append(details, fmt.Sprintf("ttl=%d", *c.TTL))
+	}
+	if c.Comment != nil {
+		details = append(details, fmt.Sprintf("comment=%q", *c.Comment))
+	}
+	if c.Records != nil {
+		details = append(details, fmt.Sprintf("records=[%s]", strings.Join(c.Records, ", ")))
+	}
+	if len(details) > 0 {
+		s = fmt.Sprintf("%s (%s)", s, strings.Join(details, " "))
+	}
+	return s
+}
+
+// Plan is the list of changes needed to reconcile the record sets of a zone
+type Plan struct {
+	Changes []Change
+}
+
+// IsEmpty reports whether the record sets are already reconciled
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns the changes of the plan, one per line
+func (p *Plan) String() string {
+	if p.IsEmpty() {
+		return "no changes"
+	}
+	lines := make([]string, len(p.Changes))
+	for i, c := range p.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Options configures how record sets are reconciled
+type Options struct {
+	// OwnerMarker marks the record sets managed by Sync.
+	// If set, only record sets whose comment contains the marker are updated or deleted, and the marker is added to the comment of
+	// the record sets that are created or updated. Otherwise all record sets of the zone are managed.
+	OwnerMarker string
+	// Types restricts the record sets managed by Sync to the given record types. If empty, record sets of all types are managed.
+	Types []string
+	// DryRun makes Sync compute the plan without applying it
+	DryRun bool
+}
+
+// owns reports whether the existing record set is managed by Sync
+func (o *Options) owns(rrSet *dns.RecordSet) bool {
+	// The SOA record set is managed by the DNS service
+	if rrSet.Type != nil && strings.EqualFold(*rrSet.Type, "SOA") {
+		return false
+	}
+	if !o.managesType(*rrSet.Type) {
+		return false
+	}
+	if o.OwnerMarker == "" {
+		return true
+	}
+	return rrSet.Comment != nil && strings.Contains(*rrSet.Comment, o.OwnerMarker)
+}
+
+// managesType reports whether record sets of the type are managed by Sync
+func (o *Options) managesType(rrType string) bool {
+	if len(o.Types) == 0 {
+		return true
+	}
+	for _, t := range o.Types {
+		if strings.EqualFold(t, rrType) {
+			return true
+		}
+	}
+	return false
+}
+
+// comment returns the comment of a desired record set, including the owner marker
+func (o *Options) comment(comment string) string {
+	if o.OwnerMarker == "" || strings.Contains(comment, o.OwnerMarker) {
+		return comment
+	}
+	if comment == "" {
+		return o.OwnerMarker
+	}
+	return comment + " " + o.OwnerMarker
+}
+
+// ComputePlan computes the changes needed to turn the current record sets of a zone, as returned by ListRecordSets, into the desired ones.
+// Record sets that are not owned (see Options.OwnerMarker) are never changed, trying to change one of them returns an error.
+// Owned record sets that are not desired are deleted.
+func ComputePlan(current []dns.RecordSet, desired []RecordSet, opts Options) (*Plan, error) {
+	existing := map[string]*dns.RecordSet{}
+	for i := range current {
+		rrSet := &current[i]
+		if rrSet.Name == nil || rrSet.Type == nil || rrSet.Id == nil {
+			return nil, fmt.Errorf("record set is missing name, type or id")
+		}
+		if rrSet.State != nil && *rrSet.State == wait.DeleteSuccess {
+			continue
+		}
+		existing[key(*rrSet.Name, *rrSet.Type)] = rrSet
+	}
+
+	plan := &Plan{}
+	seen := map[string]bool{}
+	for i := range desired {
+		d := &desired[i]
+		if d.Name == "" || d.Type == "" {
+			return nil, fmt.Errorf("desired record set %d is missing name or type", i)
+		}
+		if len(d.Records) == 0 {
+			return nil, fmt.Errorf("desired record set %s %s has no records", d.Name, d.Type)
+		}
+		if !opts.managesType(d.Type) {
+			return nil, fmt.Errorf("desired record set %s %s has a type that isn't managed", d.Name, d.Type)
+		}
+		k := key(d.Name, d.Type)
+		if seen[k] {
+			return nil, fmt.Errorf("desired record set %s %s is duplicated", d.Name, d.Type)
+		}
+		seen[k] = true
+
+		name := fqdn(d.Name)
+		rrType := strings.ToUpper(d.Type)
+		comment := opts.comment(d.Comment)
+		cur, ok := existing[k]
+		if !ok {
+			c := Change{
+				Type:       ChangeCreate,
+				Name:       name,
+				RecordType: rrType,
+				TTL:        d.TTL,
+				Records:    dedup(d.Records),
+			}
+			if comment != "" {
+				c.Comment = &comment
+			}
+			plan.Changes = append(plan.Changes, c)
+			continue
+		}
+		if !opts.owns(cur) {
+			return nil, fmt.Errorf("record set %s %s exists but isn't managed", name, rrType)
+		}
+		if c, ok := updateChange(cur, d, comment); ok {
+			plan.Changes = append(plan.Changes, c)
+		}
+	}
+
+	deletes := []Change{}
+	for k, cur := range existing {
+		if seen[k] || !opts.owns(cur) {
+			continue
+		}
+		deletes = append(deletes, Change{
+			Type:       ChangeDelete,
+			Name:       *cur.Name,
+			RecordType: *cur.Type,
+			RrSetId:    *cur.Id,
+		})
+	}
+	sort.Slice(deletes, func(i, j int) bool {
+		return key(deletes[i].Name, deletes[i].RecordType) < key(deletes[j].Name, deletes[j].RecordType)
+	})
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// updateChange returns the change needed to turn the existing record set into the desired one, if any.
+// Only added or only deleted records are changed with PartialUpdateRecord, any other change is done with a single PartialUpdateRecordSet.
+func updateChange(cur *dns.RecordSet, d *RecordSet, comment string) (Change, bool) {
+	c := Change{
+		Name:       *cur.Name,
+		RecordType: *cur.Type,
+		RrSetId:    *cur.Id,
+	}
+	if d.TTL != nil && (cur.Ttl == nil || *cur.Ttl != *d.TTL) {
+		c.TTL = d.TTL
+	}
+	if cur.Comment == nil || *cur.Comment != comment {
+		if comment != "" || cur.Comment != nil {
+			c.Comment = &comment
+		}
+	}
+
+	currentRecords := map[string]bool{}
+	if cur.Records != nil {
+		for _, r := range *cur.Records {
+			if r.Content != nil {
+				currentRecords[*r.Content] = true
+			}
+		}
+	}
+	desiredRecords := dedup(d.Records)
+	added := []string{}
+	for _, r := range desiredRecords {
+		if !currentRecords[r] {
+			added = append(added, r)
+		}
+		delete(currentRecords, r)
+	}
+	deleted := []string{}
+	for r := range currentRecords {
+		deleted = append(deleted, r)
+	}
+	sort.Strings(deleted)
+
+	switch {
+	case c.TTL != nil || c.Comment != nil:
+		c.Type = ChangeUpdate
+		if len(added) > 0 || len(deleted) > 0 {
+			c.Records = desiredRecords
+		}
+	case len(added) > 0 && len(deleted) > 0:
+		c.Type = ChangeUpdate
+		c.Records = desiredRecords
+	case len(added) > 0:
+		c.Type = ChangeAddRecords
+		c.Records = added
+	case len(deleted) > 0:
+		c.Type = ChangeDeleteRecords
+		c.Records = deleted
+	default:
+		return Change{}, false
+	}
+	return c, true
+}
+
+func key(name, rrType string) string {
+	return strings.ToLower(fqdn(name)) + " " + strings.ToUpper(rrType)
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func dedup(records []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, r := range records {
+		if !seen[r] {
+			seen[r] = true
+			result = append(result, r)
+		}
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+func fixtureRecordSet(id, name, rrType string, ttl int64, comment *string, records ...string) dns.RecordSet {
+	rs := []dns.Record{}
+	for _, r := range records {
+		rs = append(rs, dns.Record{Content: utils.Ptr(r)})
+	}
+	return dns.RecordSet{
+		Id:      utils.Ptr(id),
+		Name:    utils.Ptr(name),
+		Type:    utils.Ptr(rrType),
+		Ttl:     utils.Ptr(ttl),
+		Comment: comment,
+		Records: &rs,
+		State:   utils.Ptr("CREATE_SUCCEEDED"),
+	}
+}
+
+func TestComputePlan(t *testing.T) {
+	current := []dns.RecordSet{
+		fixtureRecordSet("soa", "example.com.", "SOA", 3600, nil, "ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300"),
+		fixtureRecordSet("www", "www.example.com.", "A", 300, utils.Ptr("managed"), "192.0.2.1", "192.0.2.2"),
+		fixtureRecordSet("api", "api.example.com.", "A", 300, utils.Ptr("managed"), "192.0.2.10"),
+		fixtureRecordSet("mail", "mail.example.com.", "A", 300, utils.Ptr("managed"), "192.0.2.20", "192.0.2.21"),
+		fixtureRecordSet("txt", "example.com.", "TXT", 300, utils.Ptr("managed"), `"v=spf1 -all"`),
+		fixtureRecordSet("old", "old.example.com.", "CNAME", 300, utils.Ptr("managed"), "www.example.com."),
+		fixtureRecordSet("manual", "manual.example.com.", "A", 300, nil, "192.0.2.30"),
+	}
+
+	tests := []struct {
+		desc     string
+		desired  []RecordSet
+		opts     Options
+		want     *Plan
+		wantErr  bool
+		wantNone bool
+	}{
+		{
+			desc: "owned_by_marker",
+			desired: []RecordSet{
+				{Name: "www.example.com", Type: "a", Records: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}},
+				{Name: "api.example.com.", Type: "A", TTL: utils.Ptr(int64(60)), Records: []string{"192.0.2.10"}},
+				{Name: "mail.example.com.", Type: "A", Records: []string{"192.0.2.20"}},
+				{Name: "example.com.", Type: "TXT", Records: []string{`"v=spf1 mx -all"`}},
+				{Name: "new.example.com.", Type: "AAAA", Records: []string{"2001:db8::1", "2001:db8::1"}, Comment: "new"},
+			},
+			opts: Options{OwnerMarker: "managed"},
+			want: &Plan{Changes: []Change{
+				{Type: ChangeAddRecords, Name: "www.example.com.", RecordType: "A", RrSetId: "www", Records: []string{"192.0.2.3"}},
+				{Type: ChangeUpdate, Name: "api.example.com.", RecordType: "A", RrSetId: "api", TTL: utils.Ptr(int64(60))},
+				{Type: ChangeDeleteRecords, Name: "mail.example.com.", RecordType: "A", RrSetId: "mail", Records: []string{"192.0.2.21"}},
+				{Type: ChangeUpdate, Name: "example.com.", RecordType: "TXT", RrSetId: "txt", Records: []string{`"v=spf1 mx -all"`}},
+				{Type: ChangeCreate, Name: "new.example.com.", RecordType: "AAAA", Comment: utils.Ptr("new managed"), Records: []string{"2001:db8::1"}},
+				{Type: ChangeDelete, Name: "old.example.com.", RecordType: "CNAME", RrSetId: "old"},
+			}},
+		},
+		{
+			desc: "everything_owned",
+			desired: []RecordSet{
+				{Name: "www.example.com.", Type: "A", Records: []string{"192.0.2.1", "192.0.2.2"}, Comment: "managed"},
+			},
+			want: &Plan{Changes: []Change{
+				{Type: ChangeDelete, Name: "api.example.com.", RecordType: "A", RrSetId: "api"},
+				{Type: ChangeDelete, Name: "example.com.", RecordType: "TXT", RrSetId: "txt"},
+				{Type: ChangeDelete, Name: "mail.example.com.", RecordType: "A", RrSetId: "mail"},
+				{Type: ChangeDelete, Name: "manual.example.com.", RecordType: "A", RrSetId: "manual"},
+				{Type: ChangeDelete, Name: "old.example.com.", RecordType: "CNAME", RrSetId: "old"},
+			}},
+		},
+		{
+			desc: "comment_change",
+			desired: []RecordSet{
+				{Name: "www.example.com.", Type: "A", Records: []string{"192.0.2.1", "192.0.2.2"}, Comment: "web"},
+				{Name: "api.example.com.", Type: "A", Records: []string{"192.0.2.10"}},
+				{Name: "mail.example.com.", Type: "A", Records: []string{"192.0.2.20", "192.0.2.21"}},
+				{Name: "example.com.", Type: "TXT", Records: []string{`"v=spf1 -all"`}},
+				{Name: "old.example.com.", Type: "CNAME", Records: []string{"www.example.com."}},
+			},
+			opts: Options{OwnerMarker: "managed"},
+			want: &Plan{Changes: []Change{
+				{Type: ChangeUpdate, Name: "www.example.com.", RecordType: "A", RrSetId: "www", Comment: utils.Ptr("web managed")},
+			}},
+		},
+		{
+			desc: "only_types",
+			desired: []RecordSet{
+				{Name: "example.com.", Type: "TXT", Records: []string{`"v=spf1 -all"`}, Comment: "managed"},
+			},
+			opts: Options{Types: []string{"txt", "CNAME"}},
+			want: &Plan{Changes: []Change{
+				{Type: ChangeDelete, Name: "old.example.com.", RecordType: "CNAME", RrSetId: "old"},
+			}},
+		},
+		{
+			desc: "type_not_managed",
+			desired: []RecordSet{
+				{Name: "www.example.com.", Type: "A", Records: []string{"192.0.2.1"}},
+			},
+			opts:    Options{Types: []string{"TXT"}},
+			wantErr: true,
+		},
+		{
+			desc: "not_owned",
+			desired: []RecordSet{
+				{Name: "manual.example.com.", Type: "A", Records: []string{"192.0.2.31"}},
+			},
+			opts:    Options{OwnerMarker: "managed"},
+			wantErr: true,
+		},
+		{
+			desc: "duplicated",
+			desired: []RecordSet{
+				{Name: "new.example.com.", Type: "A", Records: []string{"192.0.2.1"}},
+				{Name: "NEW.example.com", Type: "a", Records: []string{"192.0.2.2"}},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "no_records",
+			desired: []RecordSet{
+				{Name: "new.example.com.", Type: "A"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := ComputePlan(current, tt.desired, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComputePlan error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected plan (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanString(t *testing.T) {
+	plan := &Plan{Changes: []Change{
+		{Type: ChangeCreate, Name: "new.example.com.", RecordType: "A", TTL: utils.Ptr(int64(60)), Records: []string{"192.0.2.1", "192.0.2.2"}},
+		{Type: ChangeDelete, Name: "old.example.com.", RecordType: "CNAME", RrSetId: "old"},
+	}}
+	want := "create new.example.com. A (ttl=60 records=[192.0.2.1, 192.0.2.2])\ndelete old.example.com. CNAME"
+	if got := plan.String(); got != want {
+		t.Fatalf("String = %q, want %q", got, want)
+	}
+	if got := (&Plan{}).String(); got != "no changes" {
+		t.Fatalf("String of empty plan = %q", got)
+	}
+}
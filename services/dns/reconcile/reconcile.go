@@ -0,0 +1,162 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	corewait "github.com/stackitcloud/stackit-sdk-go/core/wait"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// Page size used to list the record sets of a zone
+const listPageSize = 1000
+
+// apiClient is the part of the DNS API used by Sync.
+type apiClient interface {
+	wait.APIClientInterface
+	listRecordSets(ctx context.Context, projectId, zoneId string, page int32) (*dns.ListRecordSetsResponse, error)
+	createRecordSet(ctx context.Context, projectId, zoneId string, payload dns.CreateRecordSetPayload) (*dns.RecordSetResponse, error)
+	partialUpdateRecordSet(ctx context.Context, projectId, zoneId, rrSetId string, payload dns.PartialUpdateRecordSetPayload) error
+	partialUpdateRecord(ctx context.Context, projectId, zoneId, rrSetId string, payload dns.PartialUpdateRecordPayload) error
+	deleteRecordSet(ctx context.Context, projectId, zoneId, rrSetId string) error
+}
+
+type apiClientAdapter struct {
+	*dns.APIClient
+}
+
+func (a apiClientAdapter) listRecordSets(ctx context.Context, projectId, zoneId string, page int32) (*dns.ListRecordSetsResponse, error) {
+	return a.ListRecordSets(ctx, projectId, zoneId).Page(page).PageSize(listPageSize).Execute()
+}
+
+func (a apiClientAdapter) createRecordSet(ctx context.Context, projectId, zoneId string, payload dns.CreateRecordSetPayload) (*dns.RecordSetResponse, error) {
+	return a.CreateRecordSet(ctx, projectId, zoneId).CreateRecordSetPayload(payload).Execute()
+}
+
+func (a apiClientAdapter) partialUpdateRecordSet(ctx context.Context, projectId, zoneId, rrSetId string, payload dns.PartialUpdateRecordSetPayload) error {
+	_, err := a.PartialUpdateRecordSet(ctx, projectId, zoneId, rrSetId).PartialUpdateRecordSetPayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) partialUpdateRecord(ctx context.Context, projectId, zoneId, rrSetId string, payload dns.PartialUpdateRecordPayload) error {
+	_, err := a.PartialUpdateRecord(ctx, projectId, zoneId, rrSetId).PartialUpdateRecordPayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) deleteRecordSet(ctx context.Context, projectId, zoneId, rrSetId string) error {
+	_, err := a.DeleteRecordSetExecute(ctx, projectId, zoneId, rrSetId)
+	return err
+}
+
+// Sync reconciles the record sets of a zone with the desired ones.
+// It lists the current record sets, computes the plan with ComputePlan, applies it and waits until all changed record sets converged.
+// The plan is returned even if applying it fails, with DryRun it is only computed.
+func Sync(ctx context.Context, client *dns.APIClient, projectId, zoneId string, desired []RecordSet, opts Options) (*Plan, error) {
+	return sync(ctx, apiClientAdapter{client}, projectId, zoneId, desired, opts)
+}
+
+func sync(ctx context.Context, a apiClient, projectId, zoneId string, desired []RecordSet, opts Options) (*Plan, error) {
+	current, err := listAllRecordSets(ctx, a, projectId, zoneId)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := ComputePlan(current, desired, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || plan.IsEmpty() {
+		return plan, nil
+	}
+
+	group := corewait.NewGroup()
+	for _, c := range plan.Changes {
+		job, err := apply(ctx, a, projectId, zoneId, c)
+		if err != nil {
+			return plan, fmt.Errorf("%s: %w", c, err)
+		}
+		group.Add(job)
+	}
+	if _, err := group.WaitAll(ctx); err != nil {
+		return plan, fmt.Errorf("wait for record sets: %w", err)
+	}
+	return plan, nil
+}
+
+func listAllRecordSets(ctx context.Context, a apiClient, projectId, zoneId string) ([]dns.RecordSet, error) {
+	rrSets := []dns.RecordSet{}
+	for page := int32(1); ; page++ {
+		resp, err := a.listRecordSets(ctx, projectId, zoneId, page)
+		if err != nil {
+			return nil, fmt.Errorf("list record sets: %w", err)
+		}
+		if resp.RrSets != nil {
+			rrSets = append(rrSets, *resp.RrSets...)
+		}
+		if resp.TotalPages == nil || int64(page) >= *resp.TotalPages {
+			return rrSets, nil
+		}
+	}
+}
+
+// apply calls the API for the change and returns a job that waits for the record set to converge
+func apply(ctx context.Context, a apiClient, projectId, zoneId string, c Change) (corewait.Job, error) {
+	name := fmt.Sprintf("%s %s", c.Name, c.RecordType)
+	switch c.Type {
+	case ChangeCreate:
+		resp, err := a.createRecordSet(ctx, projectId, zoneId, dns.CreateRecordSetPayload{
+			Name:    utils.Ptr(c.Name),
+			Type:    utils.Ptr(c.RecordType),
+			Ttl:     c.TTL,
+			Comment: c.Comment,
+			Records: recordPayloads(c.Records),
+		})
+		if err != nil {
+			return corewait.Job{}, err
+		}
+		if resp.Rrset == nil || resp.Rrset.Id == nil {
+			return corewait.Job{}, fmt.Errorf("response is missing the record set id")
+		}
+		return corewait.NewJob(name, wait.CreateRecordSetWaitHandler(ctx, a, projectId, zoneId, *resp.Rrset.Id)), nil
+	case ChangeUpdate:
+		payload := dns.PartialUpdateRecordSetPayload{
+			Ttl:     c.TTL,
+			Comment: c.Comment,
+		}
+		if c.Records != nil {
+			payload.Records = recordPayloads(c.Records)
+		}
+		if err := a.partialUpdateRecordSet(ctx, projectId, zoneId, c.RrSetId, payload); err != nil {
+			return corewait.Job{}, err
+		}
+	case ChangeAddRecords, ChangeDeleteRecords:
+		action := RecordActionAdd
+		if c.Type == ChangeDeleteRecords {
+			action = RecordActionDelete
+		}
+		err := a.partialUpdateRecord(ctx, projectId, zoneId, c.RrSetId, dns.PartialUpdateRecordPayload{
+			Action:  utils.Ptr(action),
+			Records: recordPayloads(c.Records),
+		})
+		if err != nil {
+			return corewait.Job{}, err
+		}
+	case ChangeDelete:
+		if err := a.deleteRecordSet(ctx, projectId, zoneId, c.RrSetId); err != nil {
+			return corewait.Job{}, err
+		}
+		return corewait.NewJob(name, wait.DeleteRecordSetWaitHandler(ctx, a, projectId, zoneId, c.RrSetId)), nil
+	default:
+		return corewait.Job{}, fmt.Errorf("unknown change type %s", c.Type)
+	}
+	return corewait.NewJob(name, wait.PartialUpdateRecordSetWaitHandler(ctx, a, projectId, zoneId, c.RrSetId)), nil
+}
+
+func recordPayloads(records []string) *[]dns.RecordPayload {
+	payloads := make([]dns.RecordPayload, len(records))
+	for i := range records {
+		payloads[i] = dns.RecordPayload{Content: utils.Ptr(records[i])}
+	}
+	return &payloads
+}
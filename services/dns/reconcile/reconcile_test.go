@@ -0,0 +1,162 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+type apiClientMocked struct {
+	pages      [][]dns.RecordSet
+	applyFails bool
+	waitFails  bool
+	calls      []string
+	// state of the record sets changed by the mocked calls
+	states map[string]string
+}
+
+func (a *apiClientMocked) setState(rrSetId, success, fail string) {
+	if a.waitFails {
+		a.states[rrSetId] = fail
+		return
+	}
+	a.states[rrSetId] = success
+}
+
+func (a *apiClientMocked) GetZoneExecute(_ context.Context, _, _ string) (*dns.ZoneResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) GetRecordSetExecute(_ context.Context, _, _, rrSetId string) (*dns.RecordSetResponse, error) {
+	return &dns.RecordSetResponse{
+		Rrset: &dns.RecordSet{
+			Id:    utils.Ptr(rrSetId),
+			State: utils.Ptr(a.states[rrSetId]),
+		},
+	}, nil
+}
+
+func (a *apiClientMocked) listRecordSets(_ context.Context, _, _ string, page int32) (*dns.ListRecordSetsResponse, error) {
+	a.calls = append(a.calls, fmt.Sprintf("list %d", page))
+	return &dns.ListRecordSetsResponse{
+		RrSets:     &a.pages[page-1],
+		TotalPages: utils.Ptr(int64(len(a.pages))),
+	}, nil
+}
+
+func (a *apiClientMocked) createRecordSet(_ context.Context, _, _ string, payload dns.CreateRecordSetPayload) (*dns.RecordSetResponse, error) {
+	a.calls = append(a.calls, fmt.Sprintf("create %s %s %d records", *payload.Name, *payload.Type, len(*payload.Records)))
+	if a.applyFails {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	a.setState("new", wait.CreateSuccess, wait.CreateFail)
+	return &dns.RecordSetResponse{Rrset: &dns.RecordSet{Id: utils.Ptr("new")}}, nil
+}
+
+func (a *apiClientMocked) partialUpdateRecordSet(_ context.Context, _, _, rrSetId string, _ dns.PartialUpdateRecordSetPayload) error {
+	a.calls = append(a.calls, fmt.Sprintf("update %s", rrSetId))
+	a.setState(rrSetId, wait.UpdateSuccess, wait.UpdateFail)
+	return nil
+}
+
+func (a *apiClientMocked) partialUpdateRecord(_ context.Context, _, _, rrSetId string, payload dns.PartialUpdateRecordPayload) error {
+	a.calls = append(a.calls, fmt.Sprintf("%s records %s", *payload.Action, rrSetId))
+	a.setState(rrSetId, wait.UpdateSuccess, wait.UpdateFail)
+	return nil
+}
+
+func (a *apiClientMocked) deleteRecordSet(_ context.Context, _, _, rrSetId string) error {
+	a.calls = append(a.calls, fmt.Sprintf("delete %s", rrSetId))
+	a.setState(rrSetId, wait.DeleteSuccess, wait.DeleteFail)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	pages := [][]dns.RecordSet{
+		{
+			fixtureRecordSet("www", "www.example.com.", "A", 300, utils.Ptr("managed"), "192.0.2.1"),
+		},
+		{
+			fixtureRecordSet("old", "old.example.com.", "A", 300, utils.Ptr("managed"), "192.0.2.2"),
+			fixtureRecordSet("manual", "manual.example.com.", "A", 300, nil, "192.0.2.3"),
+		},
+	}
+	desired := []RecordSet{
+		{Name: "www.example.com.", Type: "A", Records: []string{"192.0.2.1", "192.0.2.4"}},
+		{Name: "new.example.com.", Type: "TXT", Records: []string{`"hello"`}},
+	}
+
+	tests := []struct {
+		desc       string
+		dryRun     bool
+		applyFails bool
+		waitFails  bool
+		wantCalls  []string
+		wantErr    bool
+	}{
+		{
+			desc: "ok",
+			wantCalls: []string{
+				"list 1",
+				"list 2",
+				"add records www",
+				"create new.example.com. TXT 1 records",
+				"delete old",
+			},
+		},
+		{
+			desc:      "dry_run",
+			dryRun:    true,
+			wantCalls: []string{"list 1", "list 2"},
+		},
+		{
+			desc:       "apply_fails",
+			applyFails: true,
+			wantCalls: []string{
+				"list 1",
+				"list 2",
+				"add records www",
+				"create new.example.com. TXT 1 records",
+			},
+			wantErr: true,
+		},
+		{
+			desc:      "wait_fails",
+			waitFails: true,
+			wantCalls: []string{
+				"list 1",
+				"list 2",
+				"add records www",
+				"create new.example.com. TXT 1 records",
+				"delete old",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				pages:      pages,
+				applyFails: tt.applyFails,
+				waitFails:  tt.waitFails,
+				states:     map[string]string{},
+			}
+			plan, err := sync(context.Background(), a, "pid", "zid", desired, Options{OwnerMarker: "managed", DryRun: tt.dryRun})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sync error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if plan == nil || len(plan.Changes) != 3 {
+				t.Fatalf("sync returned plan %v, want 3 changes", plan)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package reverse
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	corewait "github.com/stackitcloud/stackit-sdk-go/core/wait"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/reconcile"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// apiClient is the part of the DNS API used by EnsureZones.
+type apiClient interface {
+	wait.APIClientInterface
+	findZone(ctx context.Context, projectId, dnsName string) (*dns.Zone, error)
+	createZone(ctx context.Context, projectId string, payload dns.CreateZonePayload) (*dns.ZoneResponse, error)
+}
+
+type apiClientAdapter struct {
+	*dns.APIClient
+}
+
+func (a apiClientAdapter) findZone(ctx context.Context, projectId, dnsName string) (*dns.Zone, error) {
+	resp, err := a.ListZones(ctx, projectId).DnsNameEq(dnsName).ActiveEq(true).Execute()
+	if err != nil {
+		return nil, err
+	}
+	if resp.Zones == nil || len(*resp.Zones) == 0 {
+		return nil, nil
+	}
+	return &(*resp.Zones)[0], nil
+}
+
+func (a apiClientAdapter) createZone(ctx context.Context, projectId string, payload dns.CreateZonePayload) (*dns.ZoneResponse, error) {
+	return a.CreateZone(ctx, projectId).CreateZonePayload(payload).Execute()
+}
+
+// EnsureZones creates the reverse zones of the CIDR (see Zones) that don't exist in the project yet, and returns all of them with
+// their ids. New zones are created from the template payload, with their DNS name, as name if the template has none, and as
+// reverse zones. It waits until the created zones are active.
+func EnsureZones(ctx context.Context, client *dns.APIClient, projectId, cidr string, template dns.CreateZonePayload) ([]Zone, error) {
+	return ensureZones(ctx, apiClientAdapter{client}, projectId, cidr, template)
+}
+
+func ensureZones(ctx context.Context, a apiClient, projectId, cidr string, template dns.CreateZonePayload) ([]Zone, error) {
+	zones, err := Zones(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	group := corewait.NewGroup()
+	for i := range zones {
+		zone := &zones[i]
+		dnsName := strings.TrimSuffix(zone.DnsName, ".")
+		existing, err := a.findZone(ctx, projectId, dnsName)
+		if err != nil {
+			return nil, fmt.Errorf("list zones with DNS name %s: %w", dnsName, err)
+		}
+		if existing != nil {
+			if existing.Id == nil {
+				return nil, fmt.Errorf("zone with DNS name %s is missing its id", dnsName)
+			}
+			zone.Id = *existing.Id
+			continue
+		}
+
+		payload := template
+		payload.DnsName = &dnsName
+		if payload.Name == nil {
+			payload.Name = &dnsName
+		}
+		isReverseZone := true
+		payload.IsReverseZone = &isReverseZone
+		resp, err := a.createZone(ctx, projectId, payload)
+		if err != nil {
+			return nil, fmt.Errorf("create zone %s: %w", dnsName, err)
+		}
+		if resp.Zone == nil || resp.Zone.Id == nil {
+			return nil, fmt.Errorf("create zone %s: the response is missing the zone id", dnsName)
+		}
+		zone.Id = *resp.Zone.Id
+		group.Add(corewait.NewJob(dnsName, wait.CreateZoneWaitHandler(ctx, a, projectId, zone.Id)))
+	}
+	if _, err := group.WaitAll(ctx); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// PTRRecordSets returns the PTR record sets of each zone, by zone DNS name, pointing the addresses to their hosts.
+// Every address must be in one of the zones.
+func PTRRecordSets(zones []Zone, hosts map[netip.Addr]string) (map[string][]reconcile.RecordSet, error) {
+	rrSets := map[string][]reconcile.RecordSet{}
+	for i := range zones {
+		rrSets[zones[i].DnsName] = []reconcile.RecordSet{}
+	}
+	for addr, host := range hosts {
+		zone := zoneOf(zones, addr)
+		if zone == nil {
+			return nil, fmt.Errorf("address %s isn't in any of the zones", addr)
+		}
+		name, err := zone.PTRName(addr)
+		if err != nil {
+			return nil, err
+		}
+		if host == "" {
+			return nil, fmt.Errorf("address %s has an empty host name", addr)
+		}
+		if !strings.HasSuffix(host, ".") {
+			host += "."
+		}
+		rrSets[zone.DnsName] = append(rrSets[zone.DnsName], reconcile.RecordSet{
+			Name:    name,
+			Type:    "PTR",
+			Records: []string{host},
+		})
+	}
+	for _, r := range rrSets {
+		sort.Slice(r, func(i, j int) bool { return r[i].Name < r[j].Name })
+	}
+	return rrSets, nil
+}
+
+// DelegationRecordSets returns the record sets delegating the classless zone from the zone of the enclosing /24 prefix, as
+// described in RFC 2317, and the DNS name of that parent zone: a CNAME record set for every address of the prefix pointing
+// to its PTR name in the classless zone, and an NS record set for the classless zone with the given name servers.
+// The record sets must be created in the parent zone, whoever manages it, for the PTR records of the classless zone to be resolved.
+func (z *Zone) DelegationRecordSets(nameServers []string) (parentDnsName string, rrSets []reconcile.RecordSet, err error) {
+	if !z.Classless {
+		return "", nil, fmt.Errorf("zone %s isn't classless", z.DnsName)
+	}
+	if len(nameServers) == 0 {
+		return "", nil, fmt.Errorf("at least one name server is required")
+	}
+	nsRecords := make([]string, 0, len(nameServers))
+	for _, ns := range nameServers {
+		if ns == "" {
+			return "", nil, fmt.Errorf("name server is empty")
+		}
+		if !strings.HasSuffix(ns, ".") {
+			ns += "."
+		}
+		nsRecords = append(nsRecords, ns)
+	}
+
+	parentDnsName = reverseName(z.Prefix.Addr(), 3)
+	rrSets = []reconcile.RecordSet{}
+	for addr := z.Prefix.Addr(); z.Prefix.Contains(addr); addr = addr.Next() {
+		target, err := z.PTRName(addr)
+		if err != nil {
+			return "", nil, err
+		}
+		octets := addr.As4()
+		rrSets = append(rrSets, reconcile.RecordSet{
+			Name:    fmt.Sprintf("%d.%s", octets[3], parentDnsName),
+			Type:    "CNAME",
+			Records: []string{target},
+		})
+	}
+	rrSets = append(rrSets, reconcile.RecordSet{
+		Name:    z.DnsName,
+		Type:    "NS",
+		Records: nsRecords,
+	})
+	return parentDnsName, rrSets, nil
+}
+
+// SyncPTRRecords reconciles the PTR record sets of the zones, which must have been returned by EnsureZones, with the hosts of the
+// addresses, and returns the applied plans by zone DNS name. Only PTR record sets are managed, see reconcile.Sync for the options.
+func SyncPTRRecords(ctx context.Context, client *dns.APIClient, projectId string, zones []Zone, hosts map[netip.Addr]string, opts reconcile.Options) (map[string]*reconcile.Plan, error) {
+	rrSets, err := PTRRecordSets(zones, hosts)
+	if err != nil {
+		return nil, err
+	}
+	opts.Types = []string{"PTR"}
+	plans := map[string]*reconcile.Plan{}
+	for i := range zones {
+		zone := &zones[i]
+		if zone.Id == "" {
+			return plans, fmt.Errorf("zone %s has no id", zone.DnsName)
+		}
+		plan, err := reconcile.Sync(ctx, client, projectId, zone.Id, rrSets[zone.DnsName], opts)
+		if plan != nil {
+			plans[zone.DnsName] = plan
+		}
+		if err != nil {
+			return plans, fmt.Errorf("sync PTR records of zone %s: %w", zone.DnsName, err)
+		}
+	}
+	return plans, nil
+}
+
+// zoneOf returns the zone with the longest prefix containing the address
+func zoneOf(zones []Zone, addr netip.Addr) *Zone {
+	addr = addr.Unmap()
+	var found *Zone
+	for i := range zones {
+		if zones[i].Prefix.Contains(addr) && (found == nil || zones[i].Prefix.Bits() > found.Prefix.Bits()) {
+			found = &zones[i]
+		}
+	}
+	return found
+}
@@ -0,0 +1,223 @@
+package reverse
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/reconcile"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+type apiClientMocked struct {
+	existing   map[string]string
+	createFail bool
+	calls      []string
+	payloads   []dns.CreateZonePayload
+}
+
+func (a *apiClientMocked) GetZoneExecute(_ context.Context, _, zoneId string) (*dns.ZoneResponse, error) {
+	return &dns.ZoneResponse{Zone: &dns.Zone{
+		Id:    utils.Ptr(zoneId),
+		State: utils.Ptr(wait.CreateSuccess),
+	}}, nil
+}
+
+func (a *apiClientMocked) GetRecordSetExecute(_ context.Context, _, _, _ string) (*dns.RecordSetResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) findZone(_ context.Context, _, dnsName string) (*dns.Zone, error) {
+	id, ok := a.existing[dnsName]
+	if !ok {
+		return nil, nil
+	}
+	return &dns.Zone{Id: utils.Ptr(id), DnsName: utils.Ptr(dnsName)}, nil
+}
+
+func (a *apiClientMocked) createZone(_ context.Context, _ string, payload dns.CreateZonePayload) (*dns.ZoneResponse, error) {
+	a.calls = append(a.calls, fmt.Sprintf("create %s", *payload.DnsName))
+	a.payloads = append(a.payloads, payload)
+	if a.createFail {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	return &dns.ZoneResponse{Zone: &dns.Zone{Id: utils.Ptr("new-" + *payload.DnsName)}}, nil
+}
+
+func TestEnsureZones(t *testing.T) {
+	a := &apiClientMocked{
+		existing: map[string]string{"5.0.10.in-addr.arpa": "existing"},
+	}
+	template := dns.CreateZonePayload{ContactEmail: utils.Ptr("hostmaster@example.com")}
+
+	zones, err := ensureZones(context.Background(), a, "pid", "10.0.4.0/23", template)
+	if err != nil {
+		t.Fatalf("ensureZones: %v", err)
+	}
+	want := []Zone{
+		{DnsName: "4.0.10.in-addr.arpa.", Prefix: netip.MustParsePrefix("10.0.4.0/24"), Id: "new-4.0.10.in-addr.arpa"},
+		{DnsName: "5.0.10.in-addr.arpa.", Prefix: netip.MustParsePrefix("10.0.5.0/24"), Id: "existing"},
+	}
+	if diff := cmp.Diff(zones, want, cmp.Comparer(func(a, b netip.Prefix) bool { return a == b })); diff != "" {
+		t.Fatalf("unexpected zones (-got +want): %s", diff)
+	}
+	wantPayloads := []dns.CreateZonePayload{{
+		ContactEmail:  utils.Ptr("hostmaster@example.com"),
+		DnsName:       utils.Ptr("4.0.10.in-addr.arpa"),
+		Name:          utils.Ptr("4.0.10.in-addr.arpa"),
+		IsReverseZone: utils.Ptr(true),
+	}}
+	if diff := cmp.Diff(a.payloads, wantPayloads); diff != "" {
+		t.Fatalf("unexpected payloads (-got +want): %s", diff)
+	}
+	if template.DnsName != nil || template.IsReverseZone != nil {
+		t.Fatalf("template was modified: %v", template)
+	}
+}
+
+func TestEnsureZonesCreateFails(t *testing.T) {
+	a := &apiClientMocked{createFail: true}
+	if _, err := ensureZones(context.Background(), a, "pid", "192.0.2.0/24", dns.CreateZonePayload{}); err == nil {
+		t.Fatalf("ensureZones returned no error")
+	}
+}
+
+func TestPTRRecordSets(t *testing.T) {
+	zones := []Zone{
+		{DnsName: "2.0.192.in-addr.arpa.", Prefix: netip.MustParsePrefix("192.0.2.0/24")},
+		{DnsName: "64-26.2.0.192.in-addr.arpa.", Prefix: netip.MustParsePrefix("192.0.2.64/26"), Classless: true},
+		{DnsName: "8.b.d.0.1.0.0.2.ip6.arpa.", Prefix: netip.MustParsePrefix("2001:db8::/32")},
+	}
+	tests := []struct {
+		desc    string
+		hosts   map[netip.Addr]string
+		want    map[string][]reconcile.RecordSet
+		wantErr bool
+	}{
+		{
+			desc: "ok",
+			hosts: map[netip.Addr]string{
+				netip.MustParseAddr("192.0.2.10"):  "www.example.com",
+				netip.MustParseAddr("192.0.2.1"):   "gw.example.com.",
+				netip.MustParseAddr("192.0.2.70"):  "mail.example.com",
+				netip.MustParseAddr("2001:db8::1"): "www.example.com",
+			},
+			want: map[string][]reconcile.RecordSet{
+				"2.0.192.in-addr.arpa.": {
+					{Name: "1.2.0.192.in-addr.arpa.", Type: "PTR", Records: []string{"gw.example.com."}},
+					{Name: "10.2.0.192.in-addr.arpa.", Type: "PTR", Records: []string{"www.example.com."}},
+				},
+				"64-26.2.0.192.in-addr.arpa.": {
+					{Name: "70.64-26.2.0.192.in-addr.arpa.", Type: "PTR", Records: []string{"mail.example.com."}},
+				},
+				"8.b.d.0.1.0.0.2.ip6.arpa.": {
+					{Name: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", Type: "PTR", Records: []string{"www.example.com."}},
+				},
+			},
+		},
+		{
+			desc: "no_hosts",
+			want: map[string][]reconcile.RecordSet{
+				"2.0.192.in-addr.arpa.":       {},
+				"64-26.2.0.192.in-addr.arpa.": {},
+				"8.b.d.0.1.0.0.2.ip6.arpa.":   {},
+			},
+		},
+		{
+			desc: "address_outside_zones",
+			hosts: map[netip.Addr]string{
+				netip.MustParseAddr("198.51.100.1"): "www.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			desc: "empty_host",
+			hosts: map[netip.Addr]string{
+				netip.MustParseAddr("192.0.2.1"): "",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := PTRRecordSets(zones, tt.hosts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PTRRecordSets error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want, cmpopts.EquateEmpty()); diff != "" {
+				t.Fatalf("unexpected record sets (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDelegationRecordSets(t *testing.T) {
+	tests := []struct {
+		desc        string
+		cidr        string
+		nameServers []string
+		wantParent  string
+		want        []reconcile.RecordSet
+		wantErr     bool
+	}{
+		{
+			desc:        "ok",
+			cidr:        "192.0.2.64/30",
+			nameServers: []string{"ns1.example.com", "ns2.example.com."},
+			wantParent:  "2.0.192.in-addr.arpa.",
+			want: []reconcile.RecordSet{
+				{Name: "64.2.0.192.in-addr.arpa.", Type: "CNAME", Records: []string{"64.64-30.2.0.192.in-addr.arpa."}},
+				{Name: "65.2.0.192.in-addr.arpa.", Type: "CNAME", Records: []string{"65.64-30.2.0.192.in-addr.arpa."}},
+				{Name: "66.2.0.192.in-addr.arpa.", Type: "CNAME", Records: []string{"66.64-30.2.0.192.in-addr.arpa."}},
+				{Name: "67.2.0.192.in-addr.arpa.", Type: "CNAME", Records: []string{"67.64-30.2.0.192.in-addr.arpa."}},
+				{Name: "64-30.2.0.192.in-addr.arpa.", Type: "NS", Records: []string{"ns1.example.com.", "ns2.example.com."}},
+			},
+		},
+		{
+			desc:        "last_addresses",
+			cidr:        "192.0.2.254/31",
+			nameServers: []string{"ns1.example.com."},
+			wantParent:  "2.0.192.in-addr.arpa.",
+			want: []reconcile.RecordSet{
+				{Name: "254.2.0.192.in-addr.arpa.", Type: "CNAME", Records: []string{"254.254-31.2.0.192.in-addr.arpa."}},
+				{Name: "255.2.0.192.in-addr.arpa.", Type: "CNAME", Records: []string{"255.254-31.2.0.192.in-addr.arpa."}},
+				{Name: "254-31.2.0.192.in-addr.arpa.", Type: "NS", Records: []string{"ns1.example.com."}},
+			},
+		},
+		{
+			desc:        "not_classless",
+			cidr:        "192.0.2.0/24",
+			nameServers: []string{"ns1.example.com."},
+			wantErr:     true,
+		},
+		{
+			desc:    "no_name_servers",
+			cidr:    "192.0.2.64/26",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			zones, err := Zones(tt.cidr)
+			if err != nil {
+				t.Fatalf("Zones: %v", err)
+			}
+			gotParent, got, err := zones[0].DelegationRecordSets(tt.nameServers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DelegationRecordSets error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotParent != tt.wantParent {
+				t.Fatalf("DelegationRecordSets parent = %q, want %q", gotParent, tt.wantParent)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected record sets (-got +want): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Package reverse derives reverse DNS zones from IPv4 and IPv6 prefixes, creates them and keeps their PTR records in sync.
+package reverse
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+const (
+	ipv4ReverseDomain = "in-addr.arpa."
+	ipv6ReverseDomain = "ip6.arpa."
+)
+
+// Zone is a reverse zone holding the PTR records of a range of addresses
+type Zone struct {
+	// DnsName is the name of the zone, with a trailing dot
+	DnsName string
+	// Prefix is the range of addresses whose PTR records are in the zone
+	Prefix netip.Prefix
+	// Classless is set for zones of IPv4 prefixes longer than /24, named as described in RFC 2317.
+	// The PTR records of these zones are only resolved if the zone of the enclosing /24 prefix delegates them with CNAME records.
+	Classless bool
+	// Id is the id of the zone in the DNS service, set by EnsureZones
+	Id string
+}
+
+// Zones returns the reverse zones covering the addresses of the CIDR.
+//
+// Prefixes are extended to the next octet (IPv4) or nibble (IPv6) boundary, e.g. 10.0.0.0/22 is covered by the zones of the
+// four /24 prefixes it contains. IPv4 prefixes longer than /24 get a classless zone named "<first address>-<prefix length>"
+// below the zone of the enclosing /24 prefix, e.g. "64-26.2.0.192.in-addr.arpa." for 192.0.2.64/26, as described in RFC 2317
+// (with "-" instead of "/", which isn't allowed in zone names).
+func Zones(cidr string) ([]Zone, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("parse CIDR %s: %w", cidr, err)
+	}
+	prefix = prefix.Masked()
+	if prefix.Bits() == 0 {
+		return nil, fmt.Errorf("CIDR %s is too large", cidr)
+	}
+
+	if prefix.Addr().Is4() && prefix.Bits() > 24 {
+		octets := prefix.Addr().As4()
+		return []Zone{{
+			DnsName:   fmt.Sprintf("%d-%d.%s", octets[3], prefix.Bits(), reverseName(prefix.Addr(), 3)),
+			Prefix:    prefix,
+			Classless: true,
+		}}, nil
+	}
+
+	bits := labelBits(prefix.Addr())
+	zoneBits := (prefix.Bits() + bits - 1) / bits * bits
+	count := 1 << (zoneBits - prefix.Bits())
+
+	zones := make([]Zone, 0, count)
+	addr := prefix.Addr()
+	for i := 0; i < count; i++ {
+		zonePrefix := netip.PrefixFrom(addr, zoneBits)
+		zones = append(zones, Zone{
+			DnsName: reverseName(addr, zoneBits/bits),
+			Prefix:  zonePrefix,
+		})
+		addr = nextPrefixAddr(zonePrefix)
+	}
+	return zones, nil
+}
+
+// PTRName returns the name of the PTR record of the address in the zone
+func (z *Zone) PTRName(addr netip.Addr) (string, error) {
+	addr = addr.Unmap()
+	if !z.Prefix.Contains(addr) {
+		return "", fmt.Errorf("address %s isn't in the prefix %s of zone %s", addr, z.Prefix, z.DnsName)
+	}
+	if z.Classless {
+		octets := addr.As4()
+		return fmt.Sprintf("%d.%s", octets[3], z.DnsName), nil
+	}
+	return reverseName(addr, addr.BitLen()/labelBits(addr)), nil
+}
+
+// reverseName returns the reverse name of the first labels octets (IPv4) or nibbles (IPv6) of the address
+func reverseName(addr netip.Addr, labels int) string {
+	parts := []string{}
+	if addr.Is4() {
+		octets := addr.As4()
+		for i := labels - 1; i >= 0; i-- {
+			parts = append(parts, strconv.Itoa(int(octets[i])))
+		}
+		return strings.Join(append(parts, ipv4ReverseDomain), ".")
+	}
+	bytes := addr.As16()
+	for i := labels - 1; i >= 0; i-- {
+		nibble := bytes[i/2] >> 4
+		if i%2 == 1 {
+			nibble = bytes[i/2] & 0x0f
+		}
+		parts = append(parts, strconv.FormatUint(uint64(nibble), 16))
+	}
+	return strings.Join(append(parts, ipv6ReverseDomain), ".")
+}
+
+// nextPrefixAddr returns the first address after the prefix
+func nextPrefixAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	// Add 1 at the last bit of the prefix, carrying over to the previous bytes
+	bit := prefix.Bits() - 1
+	for i := bit / 8; i >= 0; i-- {
+		increment := byte(1)
+		if i == bit/8 {
+			increment = 1 << (7 - bit%8)
+		}
+		bytes[i] += increment
+		if bytes[i] >= increment {
+			break
+		}
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// labelBits returns the number of bits of the address represented by each label of the reverse names
+func labelBits(addr netip.Addr) int {
+	if addr.Is4() {
+		return 8
+	}
+	return 4
+}
@@ -0,0 +1,78 @@
+package reverse
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestZones(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cidr    string
+		want    []string
+		wantErr bool
+	}{
+		{"ipv4_24", "192.0.2.0/24", []string{"2.0.192.in-addr.arpa."}, false},
+		{"ipv4_16", "10.1.0.0/16", []string{"1.10.in-addr.arpa."}, false},
+		{"ipv4_8", "10.0.0.0/8", []string{"10.in-addr.arpa."}, false},
+		{"ipv4_not_masked", "192.0.2.17/24", []string{"2.0.192.in-addr.arpa."}, false},
+		{"ipv4_22", "10.0.4.0/22", []string{"4.0.10.in-addr.arpa.", "5.0.10.in-addr.arpa.", "6.0.10.in-addr.arpa.", "7.0.10.in-addr.arpa."}, false},
+		{"ipv4_23_carry", "10.0.254.0/23", []string{"254.0.10.in-addr.arpa.", "255.0.10.in-addr.arpa."}, false},
+		{"ipv4_15_carry", "10.254.0.0/15", []string{"254.10.in-addr.arpa.", "255.10.in-addr.arpa."}, false},
+		{"ipv4_classless", "192.0.2.64/26", []string{"64-26.2.0.192.in-addr.arpa."}, false},
+		{"ipv4_host", "192.0.2.5/32", []string{"5-32.2.0.192.in-addr.arpa."}, false},
+		{"ipv6_32", "2001:db8::/32", []string{"8.b.d.0.1.0.0.2.ip6.arpa."}, false},
+		{"ipv6_48", "2001:db8:abcd::/48", []string{"d.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa."}, false},
+		{"ipv6_47", "2001:db8:abce::/47", []string{"e.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa.", "f.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa."}, false},
+		{"invalid", "192.0.2.0", nil, true},
+		{"too_large", "0.0.0.0/0", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			zones, err := Zones(tt.cidr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Zones error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, z := range zones {
+				got = append(got, z.DnsName)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected zones (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPTRName(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cidr    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{"ipv4", "192.0.2.0/24", "192.0.2.10", "10.2.0.192.in-addr.arpa.", false},
+		{"ipv4_mapped", "192.0.2.0/24", "::ffff:192.0.2.10", "10.2.0.192.in-addr.arpa.", false},
+		{"ipv4_classless", "192.0.2.64/26", "192.0.2.70", "70.64-26.2.0.192.in-addr.arpa.", false},
+		{"ipv4_outside", "192.0.2.64/26", "192.0.2.10", "", true},
+		{"ipv6", "2001:db8::/32", "2001:db8::567:89ab", "b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			zones, err := Zones(tt.cidr)
+			if err != nil {
+				t.Fatalf("Zones: %v", err)
+			}
+			got, err := zones[0].PTRName(netip.MustParseAddr(tt.addr))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PTRName error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("PTRName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
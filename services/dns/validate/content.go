@@ -0,0 +1,282 @@
+package validate
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxCharacterString is the maximum length in bytes of a character string of a TXT record
+const maxCharacterString = 255
+
+// contentValidators validate and normalize the content of a single record of each type.
+// zone is the absolute name of the zone of the record, or empty if it's unknown.
+var contentValidators = map[string]func(zone, content string) (string, error){
+	"A":     aContent,
+	"AAAA":  aaaaContent,
+	"CNAME": domainNameContent,
+	"NS":    domainNameContent,
+	"PTR":   domainNameContent,
+	"MX":    mxContent,
+	"TXT":   txtContent,
+	"SRV":   srvContent,
+	"CAA":   caaContent,
+	"SOA":   soaContent,
+}
+
+// Content validates the content of a single record of the given type and returns it normalized:
+//   - IP addresses in their canonical form
+//   - domain names lowercased and with a trailing dot. Names without any dot, like "www", are relative to the zone of the record
+//     and rejected, see CreateRecordSetPayload and PartialUpdateRecordSetPayload to resolve them. Other names without trailing
+//     dot are taken as absolute
+//   - TXT content quoted and split in character strings of at most 255 bytes
+//   - CAA tags lowercased and values quoted
+//
+// The content of other record types is only trimmed.
+func Content(rrType, content string) (string, error) {
+	return recordContent("", rrType, content)
+}
+
+// recordContent validates and normalizes the content of a record of the zone, see Content.
+// Relative names are resolved against the zone, or rejected if zone is empty.
+func recordContent(zone, rrType, content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", fmt.Errorf("empty content")
+	}
+	validator, ok := contentValidators[strings.ToUpper(rrType)]
+	if !ok {
+		return content, nil
+	}
+	return validator(zone, content)
+}
+
+func aContent(_, content string) (string, error) {
+	addr, err := netip.ParseAddr(content)
+	if err != nil || !addr.Is4() {
+		return "", fmt.Errorf("%q is not an IPv4 address", content)
+	}
+	return addr.String(), nil
+}
+
+func aaaaContent(_, content string) (string, error) {
+	addr, err := netip.ParseAddr(content)
+	if err != nil || !addr.Is6() || addr.Zone() != "" {
+		return "", fmt.Errorf("%q is not an IPv6 address", content)
+	}
+	return addr.String(), nil
+}
+
+func domainNameContent(zone, content string) (string, error) {
+	if len(strings.Fields(content)) != 1 {
+		return "", fmt.Errorf("%q must be a single domain name", content)
+	}
+	return hostName(zone, content)
+}
+
+// mxContent validates "<preference> <exchange>"
+func mxContent(zone, content string) (string, error) {
+	fields := strings.Fields(content)
+	if len(fields) != 2 {
+		return "", fmt.Errorf("MX content %q must be \"<preference> <exchange>\"", content)
+	}
+	preference, err := uint16Field("preference", fields[0])
+	if err != nil {
+		return "", err
+	}
+	exchange, err := targetName(zone, fields[1])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %s", preference, exchange), nil
+}
+
+// srvContent validates "<priority> <weight> <port> <target>"
+func srvContent(zone, content string) (string, error) {
+	fields := strings.Fields(content)
+	if len(fields) != 4 {
+		return "", fmt.Errorf("SRV content %q must be \"<priority> <weight> <port> <target>\"", content)
+	}
+	values := make([]uint16, 3)
+	for i, name := range []string{"priority", "weight", "port"} {
+		v, err := uint16Field(name, fields[i])
+		if err != nil {
+			return "", err
+		}
+		values[i] = v
+	}
+	target, err := targetName(zone, fields[3])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %d %d %s", values[0], values[1], values[2], target), nil
+}
+
+// caaContent validates "<flags> <tag> <value>"
+func caaContent(_, content string) (string, error) {
+	flagsField, rest := cutField(content)
+	tagField, value := cutField(rest)
+	if value == "" {
+		return "", fmt.Errorf("CAA content %q must be \"<flags> <tag> <value>\"", content)
+	}
+	flags, err := strconv.ParseUint(flagsField, 10, 8)
+	if err != nil {
+		return "", fmt.Errorf("CAA flags %q must be a number between 0 and 255", flagsField)
+	}
+	tag := strings.ToLower(tagField)
+	if !isAlphanumeric(tag) {
+		return "", fmt.Errorf("CAA tag %q must be alphanumeric", tagField)
+	}
+	if !strings.HasPrefix(value, `"`) {
+		value = quote(value)
+	} else if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+		return "", fmt.Errorf("CAA value %s isn't quoted correctly", value)
+	}
+	return fmt.Sprintf("%d %s %s", flags, tag, value), nil
+}
+
+// soaContent validates "<mname> <rname> <serial> <refresh> <retry> <expire> <minimum>"
+func soaContent(zone, content string) (string, error) {
+	fields := strings.Fields(content)
+	if len(fields) != 7 {
+		return "", fmt.Errorf("SOA content %q must be \"<mname> <rname> <serial> <refresh> <retry> <expire> <minimum>\"", content)
+	}
+	mname, err := hostName(zone, fields[0])
+	if err != nil {
+		return "", err
+	}
+	rname, err := hostName(zone, fields[1])
+	if err != nil {
+		return "", err
+	}
+	result := []string{mname, rname}
+	for i, name := range []string{"serial", "refresh", "retry", "expire", "minimum"} {
+		v, err := strconv.ParseUint(fields[i+2], 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("SOA %s %q must be a 32 bit unsigned number", name, fields[i+2])
+		}
+		result = append(result, strconv.FormatUint(v, 10))
+	}
+	return strings.Join(result, " "), nil
+}
+
+// txtContent quotes unquoted content and splits the character strings longer than 255 bytes, without splitting UTF-8 characters
+func txtContent(_, content string) (string, error) {
+	var strs []string
+	if !strings.HasPrefix(content, `"`) {
+		strs = []string{content}
+	} else {
+		var err error
+		strs, err = characterStrings(content)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	chunks := []string{}
+	for _, s := range strs {
+		for len(s) > maxCharacterString {
+			n := maxCharacterString
+			for n > 0 && !utf8.RuneStart(s[n]) {
+				n--
+			}
+			if n == 0 {
+				// Not valid UTF-8, split at the byte limit
+				n = maxCharacterString
+			}
+			chunks = append(chunks, quote(s[:n]))
+			s = s[n:]
+		}
+		chunks = append(chunks, quote(s))
+	}
+	return strings.Join(chunks, " "), nil
+}
+
+// characterStrings returns the unescaped values of a list of quoted character strings
+func characterStrings(content string) ([]string, error) {
+	strs := []string{}
+	var current strings.Builder
+	inQuotes := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inQuotes && c == '\\':
+			if i+1 == len(content) {
+				return nil, fmt.Errorf("TXT content %s ends with an escape", content)
+			}
+			i++
+			current.WriteByte(content[i])
+		case c == '"':
+			if inQuotes {
+				strs = append(strs, current.String())
+				current.Reset()
+			}
+			inQuotes = !inQuotes
+		case inQuotes:
+			current.WriteByte(c)
+		case c != ' ' && c != '\t':
+			return nil, fmt.Errorf("TXT content %s has text outside of quotes", content)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("TXT content %s has an unterminated quote", content)
+	}
+	return strs, nil
+}
+
+// quote returns the value as a quoted character string, escaping quotes and backslashes
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// targetName validates the target of MX and SRV records, where "." means that there is no service
+func targetName(zone, name string) (string, error) {
+	if name == "." {
+		return name, nil
+	}
+	return hostName(zone, name)
+}
+
+// hostName validates a domain name in the content of a record of the zone.
+// Names without any dot are relative to the zone, they are resolved against it or rejected if zone is empty.
+func hostName(zone, name string) (string, error) {
+	if !strings.Contains(name, ".") {
+		if zone == "" {
+			return "", fmt.Errorf("name %s is relative to the zone of the record, give it as absolute name with a trailing dot", name)
+		}
+		name = name + "." + zone
+	}
+	return domainName(name, false)
+}
+
+// cutField returns the first whitespace separated field of s and the rest of s after the whitespace following it
+func cutField(s string) (field, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+}
+
+func uint16Field(name, value string) (uint16, error) {
+	v, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q must be a number between 0 and 65535", name, value)
+	}
+	return uint16(v), nil
+}
+
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
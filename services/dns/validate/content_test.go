@@ -0,0 +1,76 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContent(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	// 254 bytes followed by 2 byte characters, the first of which would be split at 255 bytes
+	longUTF8 := strings.Repeat("a", 254) + strings.Repeat("é", 10)
+	tests := []struct {
+		desc    string
+		rrType  string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"a", "A", " 192.0.2.1 ", "192.0.2.1", false},
+		{"a_lowercase_type", "a", "192.0.2.1", "192.0.2.1", false},
+		{"a_ipv6", "A", "2001:db8::1", "", true},
+		{"a_invalid", "A", "192.0.2", "", true},
+		{"aaaa", "AAAA", "2001:DB8:0:0:0:0:0:1", "2001:db8::1", false},
+		{"aaaa_ipv4", "AAAA", "192.0.2.1", "", true},
+		{"aaaa_zone", "AAAA", "fe80::1%eth0", "", true},
+		{"cname", "CNAME", "WWW.Example.com", "www.example.com.", false},
+		{"cname_two_names", "CNAME", "a.example.com. b.example.com.", "", true},
+		{"cname_relative", "CNAME", "www", "", true},
+		{"cname_relative_with_dot", "CNAME", "www.", "www.", false},
+		{"cname_empty_label", "CNAME", "www..example.com.", "", true},
+		{"ns", "NS", "ns1.example.com.", "ns1.example.com.", false},
+		{"ptr_underscore", "PTR", "_service.example.com", "_service.example.com.", false},
+		{"ns_invalid_character", "NS", "ns1!.example.com.", "", true},
+		{"ns_hyphen", "NS", "-ns1.example.com.", "", true},
+		{"mx", "MX", "10  Mail.example.com", "10 mail.example.com.", false},
+		{"mx_null", "MX", "0 .", "0 .", false},
+		{"mx_relative", "MX", "10 mail", "", true},
+		{"mx_no_priority", "MX", "mail.example.com.", "", true},
+		{"mx_invalid_priority", "MX", "70000 mail.example.com.", "", true},
+		{"srv", "SRV", "10 5 5060 sip.example.com", "10 5 5060 sip.example.com.", false},
+		{"srv_relative", "SRV", "10 5 5060 sip", "", true},
+		{"srv_missing_port", "SRV", "10 5 sip.example.com.", "", true},
+		{"caa", "CAA", "0 ISSUE letsencrypt.org", `0 issue "letsencrypt.org"`, false},
+		{"caa_quoted", "CAA", `128 iodef "mailto:security@example.com"`, `128 iodef "mailto:security@example.com"`, false},
+		{"caa_whitespace", "CAA", "0  issue\tletsencrypt.org", `0 issue "letsencrypt.org"`, false},
+		{"caa_value_with_spaces", "CAA", `0 iodef "mailto:security@example.com"  `, `0 iodef "mailto:security@example.com"`, false},
+		{"caa_missing_value", "CAA", "0  issue ", "", true},
+		{"caa_invalid_flags", "CAA", `256 issue "letsencrypt.org"`, "", true},
+		{"caa_invalid_tag", "CAA", `0 is-sue "letsencrypt.org"`, "", true},
+		{"caa_unterminated", "CAA", `0 issue "letsencrypt.org`, "", true},
+		{"txt_unquoted", "TXT", `v=spf1 "mx" -all`, `"v=spf1 \"mx\" -all"`, false},
+		{"txt_quoted", "TXT", `"v=spf1" "-all"`, `"v=spf1" "-all"`, false},
+		{"txt_escaped", "TXT", `"a \"b\" \\ c"`, `"a \"b\" \\ c"`, false},
+		{"txt_long", "TXT", long, `"` + long[:255] + `" "` + long[255:] + `"`, false},
+		{"txt_long_quoted", "TXT", `"` + long + `"`, `"` + long[:255] + `" "` + long[255:] + `"`, false},
+		{"txt_long_utf8", "TXT", longUTF8, `"` + longUTF8[:254] + `" "` + longUTF8[254:] + `"`, false},
+		{"txt_unterminated", "TXT", `"v=spf1`, "", true},
+		{"txt_text_outside_quotes", "TXT", `"v=spf1" -all`, "", true},
+		{"soa", "SOA", "NS1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300", "ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300", false},
+		{"soa_missing_field", "SOA", "ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600", "", true},
+		{"soa_invalid_serial", "SOA", "ns1.example.com. hostmaster.example.com. -1 7200 3600 1209600 300", "", true},
+		{"other_type", "TLSA", " 3 1 1 abcdef ", "3 1 1 abcdef", false},
+		{"empty", "A", " ", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Content(tt.rrType, tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Content error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
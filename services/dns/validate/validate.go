@@ -0,0 +1,197 @@
+// Package validate checks and normalizes record set payloads of the DNS API before they are sent,
+// so that mistakes like an IPv6 address in an A record or a CNAME at the zone apex are caught client-side.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+const (
+	maxLabelLength = 63
+	maxNameLength  = 253
+)
+
+// CreateRecordSetPayload validates the payload for a record set of the zone with the given DNS name and normalizes it in place.
+// The name is made absolute and the type uppercased, the content of the records is normalized as described in Content.
+//
+// Names without trailing dot are taken as absolute if they are the zone name or one of its subdomains, and relative to the zone otherwise.
+// Names in the content of the records, like the target of a CNAME, are relative to the zone if they don't have any dot.
+func CreateRecordSetPayload(zoneDnsName string, payload *dns.CreateRecordSetPayload) error {
+	if payload == nil {
+		return fmt.Errorf("payload is nil")
+	}
+	if payload.Name == nil || payload.Type == nil {
+		return fmt.Errorf("name and type are required")
+	}
+	if payload.Records == nil || len(*payload.Records) == 0 {
+		return fmt.Errorf("at least one record is required")
+	}
+	rrType := strings.ToUpper(strings.TrimSpace(*payload.Type))
+	if rrType == "" {
+		return fmt.Errorf("type is required")
+	}
+	name, err := recordSetName(zoneDnsName, *payload.Name)
+	if err != nil {
+		return err
+	}
+	records, err := recordSet(zoneDnsName, name, rrType, *payload.Records)
+	if err != nil {
+		return err
+	}
+	if err := ttl(payload.Ttl); err != nil {
+		return err
+	}
+	payload.Name = &name
+	payload.Type = &rrType
+	payload.Records = &records
+	return nil
+}
+
+// PartialUpdateRecordSetPayload validates the payload for the record set of the zone with the given DNS name, name and type,
+// and normalizes it in place as CreateRecordSetPayload does.
+func PartialUpdateRecordSetPayload(zoneDnsName, name, rrType string, payload *dns.PartialUpdateRecordSetPayload) error {
+	if payload == nil {
+		return fmt.Errorf("payload is nil")
+	}
+	rrType = strings.ToUpper(strings.TrimSpace(rrType))
+	if payload.Name != nil {
+		name = *payload.Name
+	}
+	name, err := recordSetName(zoneDnsName, name)
+	if err != nil {
+		return err
+	}
+	var records []dns.RecordPayload
+	if payload.Records != nil {
+		if len(*payload.Records) == 0 {
+			return fmt.Errorf("at least one record is required")
+		}
+		records, err = recordSet(zoneDnsName, name, rrType, *payload.Records)
+		if err != nil {
+			return err
+		}
+	}
+	if err := ttl(payload.Ttl); err != nil {
+		return err
+	}
+	if payload.Name != nil {
+		payload.Name = &name
+	}
+	if payload.Records != nil {
+		payload.Records = &records
+	}
+	return nil
+}
+
+// recordSet validates the records of a record set and returns them with normalized content
+func recordSet(zoneDnsName, name, rrType string, records []dns.RecordPayload) ([]dns.RecordPayload, error) {
+	isApex := name == zoneName(zoneDnsName)
+	switch rrType {
+	case "CNAME":
+		if isApex {
+			return nil, fmt.Errorf("CNAME record set %s can't be at the zone apex", name)
+		}
+		if len(records) > 1 {
+			return nil, fmt.Errorf("CNAME record set %s must have a single record", name)
+		}
+	case "SOA":
+		if !isApex {
+			return nil, fmt.Errorf("SOA record set %s must be at the zone apex", name)
+		}
+		if len(records) > 1 {
+			return nil, fmt.Errorf("SOA record set %s must have a single record", name)
+		}
+	}
+
+	normalized := make([]dns.RecordPayload, len(records))
+	seen := map[string]bool{}
+	for i := range records {
+		if records[i].Content == nil {
+			return nil, fmt.Errorf("record %d of %s %s has no content", i, name, rrType)
+		}
+		content, err := recordContent(zoneName(zoneDnsName), rrType, *records[i].Content)
+		if err != nil {
+			return nil, fmt.Errorf("record %d of %s %s: %w", i, name, rrType, err)
+		}
+		if seen[content] {
+			return nil, fmt.Errorf("record %d of %s %s is duplicated: %s", i, name, rrType, content)
+		}
+		seen[content] = true
+		normalized[i] = dns.RecordPayload{Content: &content}
+	}
+	return normalized, nil
+}
+
+// recordSetName returns the absolute name of a record set, which must be within the zone
+func recordSetName(zoneDnsName, name string) (string, error) {
+	zone := zoneName(zoneDnsName)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if !strings.HasSuffix(name, ".") && fqdn(name) != zone && !strings.HasSuffix(fqdn(name), "."+zone) {
+		name = name + "." + zone
+	}
+	name, err := domainName(name, true)
+	if err != nil {
+		return "", err
+	}
+	if name != zone && !strings.HasSuffix(name, "."+zone) {
+		return "", fmt.Errorf("name %s isn't within the zone %s", name, zone)
+	}
+	return name, nil
+}
+
+// domainName validates a domain name and returns it lowercased and with a trailing dot.
+// Underscores are allowed in labels for names like "_acme-challenge", wildcards only as first label if allowWildcard is set.
+func domainName(name string, allowWildcard bool) (string, error) {
+	name = fqdn(strings.ToLower(name))
+	if name == "." {
+		return "", fmt.Errorf("the root isn't a valid name")
+	}
+	if len(name)-1 > maxNameLength {
+		return "", fmt.Errorf("name %s is longer than %d characters", name, maxNameLength)
+	}
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i, label := range labels {
+		if label == "*" && i == 0 && allowWildcard {
+			continue
+		}
+		if label == "" {
+			return "", fmt.Errorf("name %s has an empty label", name)
+		}
+		if len(label) > maxLabelLength {
+			return "", fmt.Errorf("label %s of name %s is longer than %d characters", label, name, maxLabelLength)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return "", fmt.Errorf("label %s of name %s starts or ends with a hyphen", label, name)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return "", fmt.Errorf("label %s of name %s has invalid character %q", label, name, c)
+			}
+		}
+	}
+	return name, nil
+}
+
+func ttl(ttl *int64) error {
+	if ttl != nil && (*ttl < 0 || *ttl > 1<<31-1) {
+		return fmt.Errorf("TTL %d must be between 0 and %d", *ttl, 1<<31-1)
+	}
+	return nil
+}
+
+func zoneName(zoneDnsName string) string {
+	return fqdn(strings.ToLower(strings.TrimSpace(zoneDnsName)))
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
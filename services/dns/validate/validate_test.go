@@ -0,0 +1,253 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+func fixtureRecords(contents ...string) *[]dns.RecordPayload {
+	records := []dns.RecordPayload{}
+	for _, c := range contents {
+		records = append(records, dns.RecordPayload{Content: utils.Ptr(c)})
+	}
+	return &records
+}
+
+func TestCreateRecordSetPayload(t *testing.T) {
+	tests := []struct {
+		desc    string
+		payload dns.CreateRecordSetPayload
+		want    dns.CreateRecordSetPayload
+		wantErr bool
+	}{
+		{
+			desc: "relative_name",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("WWW"),
+				Type:    utils.Ptr("aaaa"),
+				Ttl:     utils.Ptr(int64(60)),
+				Records: fixtureRecords("2001:DB8::1"),
+			},
+			want: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www.example.com."),
+				Type:    utils.Ptr("AAAA"),
+				Ttl:     utils.Ptr(int64(60)),
+				Records: fixtureRecords("2001:db8::1"),
+			},
+		},
+		{
+			desc: "absolute_name_without_dot",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("_acme-challenge.example.com"),
+				Type:    utils.Ptr("TXT"),
+				Records: fixtureRecords("token"),
+			},
+			want: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("_acme-challenge.example.com."),
+				Type:    utils.Ptr("TXT"),
+				Records: fixtureRecords(`"token"`),
+			},
+		},
+		{
+			desc: "wildcard",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("*.example.com."),
+				Type:    utils.Ptr("CNAME"),
+				Records: fixtureRecords("www.example.com"),
+			},
+			want: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("*.example.com."),
+				Type:    utils.Ptr("CNAME"),
+				Records: fixtureRecords("www.example.com."),
+			},
+		},
+		{
+			desc: "relative_target",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www"),
+				Type:    utils.Ptr("CNAME"),
+				Records: fixtureRecords("Web"),
+			},
+			want: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www.example.com."),
+				Type:    utils.Ptr("CNAME"),
+				Records: fixtureRecords("web.example.com."),
+			},
+		},
+		{
+			desc: "cname_at_apex",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("example.com."),
+				Type:    utils.Ptr("CNAME"),
+				Records: fixtureRecords("www.example.org."),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "cname_multiple_records",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www.example.com."),
+				Type:    utils.Ptr("CNAME"),
+				Records: fixtureRecords("a.example.org.", "b.example.org."),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "soa_not_at_apex",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www.example.com."),
+				Type:    utils.Ptr("SOA"),
+				Records: fixtureRecords("ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "outside_zone",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www.example.org."),
+				Type:    utils.Ptr("A"),
+				Records: fixtureRecords("192.0.2.1"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "duplicated_records",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www"),
+				Type:    utils.Ptr("AAAA"),
+				Records: fixtureRecords("2001:db8::1", "2001:DB8:0::1"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid_content",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www"),
+				Type:    utils.Ptr("A"),
+				Records: fixtureRecords("192.0.2.1", "2001:db8::1"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid_ttl",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www"),
+				Type:    utils.Ptr("A"),
+				Ttl:     utils.Ptr(int64(-1)),
+				Records: fixtureRecords("192.0.2.1"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "no_records",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www"),
+				Type:    utils.Ptr("A"),
+				Records: fixtureRecords(),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "no_type",
+			payload: dns.CreateRecordSetPayload{
+				Name:    utils.Ptr("www"),
+				Records: fixtureRecords("192.0.2.1"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			payload := tt.payload
+			err := CreateRecordSetPayload("Example.com", &payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateRecordSetPayload error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if diff := cmp.Diff(payload, tt.payload); diff != "" {
+					t.Fatalf("payload changed on error (-got +want): %s", diff)
+				}
+				return
+			}
+			if diff := cmp.Diff(payload, tt.want); diff != "" {
+				t.Fatalf("unexpected payload (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPartialUpdateRecordSetPayload(t *testing.T) {
+	tests := []struct {
+		desc    string
+		rrType  string
+		payload dns.PartialUpdateRecordSetPayload
+		want    dns.PartialUpdateRecordSetPayload
+		wantErr bool
+	}{
+		{
+			desc:   "records",
+			rrType: "mx",
+			payload: dns.PartialUpdateRecordSetPayload{
+				Records: fixtureRecords("10 Mail.example.com"),
+			},
+			want: dns.PartialUpdateRecordSetPayload{
+				Records: fixtureRecords("10 mail.example.com."),
+			},
+		},
+		{
+			desc:   "rename",
+			rrType: "MX",
+			payload: dns.PartialUpdateRecordSetPayload{
+				Name: utils.Ptr("Mail2"),
+				Ttl:  utils.Ptr(int64(300)),
+			},
+			want: dns.PartialUpdateRecordSetPayload{
+				Name: utils.Ptr("mail2.example.com."),
+				Ttl:  utils.Ptr(int64(300)),
+			},
+		},
+		{
+			desc:   "rename_cname_to_apex",
+			rrType: "CNAME",
+			payload: dns.PartialUpdateRecordSetPayload{
+				Name:    utils.Ptr("example.com."),
+				Records: fixtureRecords("www.example.org."),
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "mx_without_priority",
+			rrType: "MX",
+			payload: dns.PartialUpdateRecordSetPayload{
+				Records: fixtureRecords("mail.example.com."),
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "no_records",
+			rrType: "MX",
+			payload: dns.PartialUpdateRecordSetPayload{
+				Records: fixtureRecords(),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			payload := tt.payload
+			err := PartialUpdateRecordSetPayload("example.com.", "mail.example.com.", tt.rrType, &payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PartialUpdateRecordSetPayload error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(payload, tt.want); diff != "" {
+				t.Fatalf("unexpected payload (-got +want): %s", diff)
+			}
+		})
+	}
+}
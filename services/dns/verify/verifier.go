@@ -0,0 +1,314 @@
+// Package verify checks that record sets are served by the authoritative name servers of their zone,
+// by querying them directly over DNS.
+package verify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	miekgdns "github.com/miekg/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+const (
+	// DefaultTimeout is how long WaitForRecordSet waits for the record set to be served by all name servers
+	DefaultTimeout = 5 * time.Minute
+	// DefaultInterval is the interval at which WaitForRecordSet queries the name servers
+	DefaultInterval = 5 * time.Second
+	// DefaultQueryTimeout is the timeout of each DNS query
+	DefaultQueryTimeout = 5 * time.Second
+)
+
+// Verifier queries the authoritative name servers of a zone, the primary name server and the primaries of secondary zones,
+// until they serve a record set as expected.
+type Verifier struct {
+	resolverAddress string
+	port            int
+	timeout         time.Duration
+	interval        time.Duration
+	client          *miekgdns.Client
+}
+
+// NewVerifier initializes a Verifier that resolves the host names of the name servers with the system resolver
+// and queries them on port 53
+func NewVerifier() *Verifier {
+	return &Verifier{
+		port:     53,
+		timeout:  DefaultTimeout,
+		interval: DefaultInterval,
+		client:   &miekgdns.Client{Timeout: DefaultQueryTimeout},
+	}
+}
+
+// SetResolverAddress sets the address ("host:port") of the recursive resolver used to resolve the host names of the name servers,
+// instead of the system resolver
+func (v *Verifier) SetResolverAddress(address string) *Verifier {
+	v.resolverAddress = address
+	return v
+}
+
+// SetPort sets the port on which the name servers are queried
+func (v *Verifier) SetPort(port int) *Verifier {
+	v.port = port
+	return v
+}
+
+// SetTimeout sets how long WaitForRecordSet waits for the record set to be served by all name servers
+func (v *Verifier) SetTimeout(timeout time.Duration) *Verifier {
+	v.timeout = timeout
+	return v
+}
+
+// SetInterval sets the interval at which WaitForRecordSet queries the name servers
+func (v *Verifier) SetInterval(interval time.Duration) *Verifier {
+	v.interval = interval
+	return v
+}
+
+// SetQueryTimeout sets the timeout of each DNS query
+func (v *Verifier) SetQueryTimeout(timeout time.Duration) *Verifier {
+	v.client.Timeout = timeout
+	return v
+}
+
+// NotServedError is returned when the name servers don't serve the record set as expected before the timeout
+type NotServedError struct {
+	Name string
+	Type string
+	// Mismatches holds why the record set isn't served as expected, by name server address
+	Mismatches map[string]string
+}
+
+func (e *NotServedError) Error() string {
+	servers := make([]string, 0, len(e.Mismatches))
+	for server := range e.Mismatches {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+	details := make([]string, len(servers))
+	for i, server := range servers {
+		details[i] = fmt.Sprintf("%s: %s", server, e.Mismatches[server])
+	}
+	return fmt.Sprintf("record set %s %s isn't served as expected: %s", e.Name, e.Type, strings.Join(details, "; "))
+}
+
+// WaitForRecordSet waits until all authoritative name servers of the zone serve the records of the record set, or no records if
+// the record set is deleted, and a SOA record with at least the serial number of the zone.
+// The zone and the record set should be the ones returned by the API once the change is done, e.g. by the wait handlers.
+func (v *Verifier) WaitForRecordSet(ctx context.Context, zone *dns.Zone, rrSet *dns.RecordSet) error {
+	if zone == nil || zone.DnsName == nil {
+		return fmt.Errorf("zone is missing its DNS name")
+	}
+	if rrSet == nil || rrSet.Name == nil || rrSet.Type == nil {
+		return fmt.Errorf("record set is missing its name or type")
+	}
+	rrType, ok := miekgdns.StringToType[strings.ToUpper(*rrSet.Type)]
+	if !ok {
+		return fmt.Errorf("unknown record type %s", *rrSet.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	servers, err := v.nameServers(ctx, zone)
+	if err != nil {
+		return err
+	}
+	expected := expectedRecords(rrType, rrSet)
+	var minSerial *uint32
+	if zone.SerialNumber != nil {
+		serial := uint32(*zone.SerialNumber)
+		minSerial = &serial
+	}
+
+	pending := map[string]string{}
+	for _, server := range servers {
+		pending[server] = "not queried"
+	}
+	for {
+		for server := range pending {
+			mismatch := v.check(ctx, server, miekgdns.Fqdn(*zone.DnsName), miekgdns.Fqdn(*rrSet.Name), rrType, expected, minSerial)
+			if mismatch == "" {
+				delete(pending, server)
+			} else {
+				pending[server] = mismatch
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &NotServedError{Name: *rrSet.Name, Type: *rrSet.Type, Mismatches: pending}
+		case <-time.After(v.interval):
+		}
+	}
+}
+
+// check queries the server for the record set and the SOA record of the zone, and returns why they aren't served as expected, if so
+func (v *Verifier) check(ctx context.Context, server, zoneName, name string, rrType uint16, expected []string, minSerial *uint32) string {
+	if minSerial != nil {
+		answer, err := v.query(ctx, server, zoneName, miekgdns.TypeSOA)
+		if err != nil {
+			return err.Error()
+		}
+		var soa *miekgdns.SOA
+		for _, rr := range answer {
+			if s, ok := rr.(*miekgdns.SOA); ok {
+				soa = s
+			}
+		}
+		if soa == nil {
+			return "no SOA record"
+		}
+		if serialLess(soa.Serial, *minSerial) {
+			return fmt.Sprintf("SOA serial number %d is older than %d", soa.Serial, *minSerial)
+		}
+	}
+
+	answer, err := v.query(ctx, server, name, rrType)
+	if err != nil {
+		return err.Error()
+	}
+	got := []string{}
+	for _, rr := range answer {
+		if rr.Header().Rrtype == rrType && strings.EqualFold(rr.Header().Name, name) {
+			got = append(got, normalize(rrType, strings.TrimPrefix(rr.String(), rr.Header().String())))
+		}
+	}
+	sort.Strings(got)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		return fmt.Sprintf("serves records [%s], want [%s]", strings.Join(got, ", "), strings.Join(expected, ", "))
+	}
+	return ""
+}
+
+// query sends a non-recursive query to the server and returns the answer, which must be authoritative
+func (v *Verifier) query(ctx context.Context, server, name string, rrType uint16) ([]miekgdns.RR, error) {
+	msg := new(miekgdns.Msg)
+	msg.SetQuestion(name, rrType)
+	msg.RecursionDesired = false
+
+	resp, _, err := v.client.ExchangeContext(ctx, msg, server)
+	if err == nil && resp.Truncated {
+		tcpClient := &miekgdns.Client{Net: "tcp", Timeout: v.client.Timeout}
+		resp, _, err = tcpClient.ExchangeContext(ctx, msg, server)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query %s %s: %w", name, miekgdns.TypeToString[rrType], err)
+	}
+	if resp.Rcode != miekgdns.RcodeSuccess && resp.Rcode != miekgdns.RcodeNameError {
+		return nil, fmt.Errorf("query %s %s: response code %s", name, miekgdns.TypeToString[rrType], miekgdns.RcodeToString[resp.Rcode])
+	}
+	if !resp.Authoritative {
+		return nil, fmt.Errorf("query %s %s: the answer isn't authoritative", name, miekgdns.TypeToString[rrType])
+	}
+	return resp.Answer, nil
+}
+
+// nameServers returns the addresses ("host:port") of the authoritative name servers of the zone
+func (v *Verifier) nameServers(ctx context.Context, zone *dns.Zone) ([]string, error) {
+	hosts := []string{}
+	if zone.PrimaryNameServer != nil && *zone.PrimaryNameServer != "" {
+		hosts = append(hosts, *zone.PrimaryNameServer)
+	}
+	if zone.Primaries != nil {
+		hosts = append(hosts, *zone.Primaries...)
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("zone %s has no primary name server", *zone.DnsName)
+	}
+
+	seen := map[string]bool{}
+	servers := []string{}
+	for _, host := range hosts {
+		ips, err := v.resolve(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("resolve name server %s: %w", host, err)
+		}
+		for _, ip := range ips {
+			server := net.JoinHostPort(ip, strconv.Itoa(v.port))
+			if !seen[server] {
+				seen[server] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+	return servers, nil
+}
+
+// resolve returns the IP addresses of the host, which may be an IP address itself
+func (v *Verifier) resolve(ctx context.Context, host string) ([]string, error) {
+	host = strings.TrimSuffix(host, ".")
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	if v.resolverAddress == "" {
+		return net.DefaultResolver.LookupHost(ctx, host)
+	}
+
+	ips := []string{}
+	for _, rrType := range []uint16{miekgdns.TypeA, miekgdns.TypeAAAA} {
+		msg := new(miekgdns.Msg)
+		msg.SetQuestion(miekgdns.Fqdn(host), rrType)
+		resp, _, err := v.client.ExchangeContext(ctx, msg, v.resolverAddress)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range resp.Answer {
+			switch r := rr.(type) {
+			case *miekgdns.A:
+				ips = append(ips, r.A.String())
+			case *miekgdns.AAAA:
+				ips = append(ips, r.AAAA.String())
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found")
+	}
+	return ips, nil
+}
+
+// expectedRecords returns the normalized contents of the records the name servers should serve for the record set
+func expectedRecords(rrType uint16, rrSet *dns.RecordSet) []string {
+	expected := []string{}
+	if rrSet.State != nil && *rrSet.State == wait.DeleteSuccess {
+		return expected
+	}
+	if rrSet.Records != nil {
+		for _, r := range *rrSet.Records {
+			if r.Content != nil {
+				expected = append(expected, normalize(rrType, *r.Content))
+			}
+		}
+	}
+	sort.Strings(expected)
+	return expected
+}
+
+// normalize makes record contents comparable regardless of whitespace, the notation of IP addresses and the case of domain names
+func normalize(rrType uint16, content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	switch rrType {
+	case miekgdns.TypeA, miekgdns.TypeAAAA:
+		if ip := net.ParseIP(content); ip != nil {
+			return ip.String()
+		}
+	case miekgdns.TypeCNAME, miekgdns.TypeNS, miekgdns.TypePTR, miekgdns.TypeDNAME, miekgdns.TypeMX, miekgdns.TypeSRV, miekgdns.TypeSOA:
+		return strings.ToLower(content)
+	}
+	return content
+}
+
+// serialLess reports whether serial a is older than b, following the serial number arithmetic of RFC 1982
+func serialLess(a, b uint32) bool {
+	return a != b && int32(b-a) > 0
+}
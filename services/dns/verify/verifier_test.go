@@ -0,0 +1,204 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	miekgdns "github.com/miekg/dns"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// stubServer is an authoritative name server for "example.com." that also resolves "ns1.example.com." to 127.0.0.1
+type stubServer struct {
+	mu sync.Mutex
+	// records served, in presentation format
+	records []string
+	serial  uint32
+	// number of queries after which the records and serial are replaced by the updated ones
+	updateAfter    int
+	updatedRecords []string
+	updatedSerial  uint32
+	queries        int
+}
+
+func (s *stubServer) ServeDNS(w miekgdns.ResponseWriter, req *miekgdns.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries++
+	if s.updateAfter > 0 && s.queries > s.updateAfter {
+		s.records = s.updatedRecords
+		s.serial = s.updatedSerial
+	}
+
+	resp := new(miekgdns.Msg)
+	resp.SetReply(req)
+	resp.Authoritative = true
+	q := req.Question[0]
+	records := append([]string{
+		"ns1.example.com. 3600 IN A 127.0.0.1",
+		fmt.Sprintf("example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. %d 7200 3600 1209600 300", s.serial),
+	}, s.records...)
+	for _, r := range records {
+		rr, err := miekgdns.NewRR(r)
+		if err != nil {
+			panic(err)
+		}
+		if rr.Header().Rrtype == q.Qtype && rr.Header().Name == q.Name {
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+	_ = w.WriteMsg(resp)
+}
+
+// startStubServer starts the stub server on a random local port and returns the port
+func startStubServer(t *testing.T, s *stubServer) int {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	started := make(chan struct{})
+	server := &miekgdns.Server{PacketConn: pc, Handler: s, NotifyStartedFunc: func() { close(started) }}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+	return pc.LocalAddr().(*net.UDPAddr).Port
+}
+
+func fixtureRecordSet(state string, records ...string) *dns.RecordSet {
+	rs := []dns.Record{}
+	for _, r := range records {
+		rs = append(rs, dns.Record{Content: utils.Ptr(r)})
+	}
+	return &dns.RecordSet{
+		Name:    utils.Ptr("www.example.com."),
+		Type:    utils.Ptr("A"),
+		Records: &rs,
+		State:   utils.Ptr(state),
+	}
+}
+
+func TestWaitForRecordSet(t *testing.T) {
+	tests := []struct {
+		desc              string
+		server            *stubServer
+		primaryNameServer string
+		rrSet             *dns.RecordSet
+		wantErr           bool
+	}{
+		{
+			desc: "served",
+			server: &stubServer{
+				records: []string{"www.example.com. 60 IN A 192.0.2.2", "www.example.com. 60 IN A 192.0.2.1"},
+				serial:  2,
+			},
+			primaryNameServer: "127.0.0.1",
+			rrSet:             fixtureRecordSet(wait.CreateSuccess, "192.0.2.1", "192.0.2.2"),
+		},
+		{
+			desc: "served_after_update",
+			server: &stubServer{
+				records:        []string{},
+				serial:         1,
+				updateAfter:    4,
+				updatedRecords: []string{"www.example.com. 60 IN A 192.0.2.1", "www.example.com. 60 IN A 192.0.2.2"},
+				updatedSerial:  3,
+			},
+			primaryNameServer: "127.0.0.1",
+			rrSet:             fixtureRecordSet(wait.CreateSuccess, "192.0.2.1", "192.0.2.2"),
+		},
+		{
+			desc: "name_server_host_name",
+			server: &stubServer{
+				records: []string{"www.example.com. 60 IN A 192.0.2.1", "www.example.com. 60 IN A 192.0.2.2"},
+				serial:  2,
+			},
+			primaryNameServer: "ns1.example.com.",
+			rrSet:             fixtureRecordSet(wait.CreateSuccess, "192.0.2.1", "192.0.2.2"),
+		},
+		{
+			desc: "deleted",
+			server: &stubServer{
+				records: []string{},
+				serial:  2,
+			},
+			primaryNameServer: "127.0.0.1",
+			rrSet:             fixtureRecordSet(wait.DeleteSuccess, "192.0.2.1"),
+		},
+		{
+			desc: "old_serial",
+			server: &stubServer{
+				records: []string{"www.example.com. 60 IN A 192.0.2.1", "www.example.com. 60 IN A 192.0.2.2"},
+				serial:  1,
+			},
+			primaryNameServer: "127.0.0.1",
+			rrSet:             fixtureRecordSet(wait.CreateSuccess, "192.0.2.1", "192.0.2.2"),
+			wantErr:           true,
+		},
+		{
+			desc: "missing_record",
+			server: &stubServer{
+				records: []string{"www.example.com. 60 IN A 192.0.2.1"},
+				serial:  2,
+			},
+			primaryNameServer: "127.0.0.1",
+			rrSet:             fixtureRecordSet(wait.CreateSuccess, "192.0.2.1", "192.0.2.2"),
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			port := startStubServer(t, tt.server)
+			zone := &dns.Zone{
+				DnsName:           utils.Ptr("example.com"),
+				PrimaryNameServer: utils.Ptr(tt.primaryNameServer),
+				SerialNumber:      utils.Ptr(int64(2)),
+			}
+			v := NewVerifier().
+				SetPort(port).
+				SetResolverAddress(net.JoinHostPort("127.0.0.1", strconv.Itoa(port))).
+				SetInterval(10 * time.Millisecond).
+				SetTimeout(time.Second).
+				SetQueryTimeout(time.Second)
+
+			err := v.WaitForRecordSet(context.Background(), zone, tt.rrSet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForRecordSet error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var notServedErr *NotServedError
+			if tt.wantErr && !errors.As(err, &notServedErr) {
+				t.Fatalf("WaitForRecordSet error = %v, want a NotServedError", err)
+			}
+		})
+	}
+}
+
+func TestSerialLess(t *testing.T) {
+	tests := []struct {
+		a, b uint32
+		want bool
+	}{
+		{1, 2, true},
+		{2, 1, false},
+		{2, 2, false},
+		{4294967295, 1, true},
+		{1, 4294967295, false},
+	}
+	for _, tt := range tests {
+		if got := serialLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("serialLess(%d, %d) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
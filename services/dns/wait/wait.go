@@ -3,8 +3,10 @@ package wait
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
 	"github.com/stackitcloud/stackit-sdk-go/core/wait"
 	"github.com/stackitcloud/stackit-sdk-go/services/dns"
 )
@@ -26,44 +28,26 @@ type APIClientInterface interface {
 
 // CreateZoneWaitHandler will wait for zone creation
 func CreateZoneWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[dns.ZoneResponse] {
-	handler := wait.New(func() (waitFinished bool, response *dns.ZoneResponse, err error) {
-		s, err := a.GetZoneExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Zone.Id == nil || s.Zone.State == nil {
-			return false, nil, fmt.Errorf("create failed for instance with id %s. The response is not valid: the id or the state are missing", instanceId)
-		}
-		if *s.Zone.Id == instanceId && *s.Zone.State == CreateSuccess {
-			return true, s, nil
-		}
-		if *s.Zone.Id == instanceId && *s.Zone.State == CreateFail {
-			return true, s, fmt.Errorf("create failed for zone with id %s", instanceId)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getZone(ctx, a, projectId, instanceId), zoneState("create", instanceId)).
+		Success(CreateSuccess).
+		Failure(CreateFail).
+		OnFailure(func(_ *dns.ZoneResponse, _ string) error {
+			return fmt.Errorf("create failed for zone with id %s", instanceId)
+		}).
+		Handler()
 	handler.SetTimeout(10 * time.Minute)
 	return handler
 }
 
 // PartialUpdateZoneWaitHandler will wait for zone update
 func PartialUpdateZoneWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[dns.ZoneResponse] {
-	handler := wait.New(func() (waitFinished bool, response *dns.ZoneResponse, err error) {
-		s, err := a.GetZoneExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Zone.Id == nil || s.Zone.State == nil {
-			return false, nil, fmt.Errorf("update failed for instance with id %s. The response is not valid: the id or the state are missing", instanceId)
-		}
-		if *s.Zone.Id == instanceId && *s.Zone.State == UpdateSuccess {
-			return true, s, nil
-		}
-		if *s.Zone.Id == instanceId && *s.Zone.State == UpdateFail {
-			return true, s, fmt.Errorf("update failed for zone with id %s", instanceId)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getZone(ctx, a, projectId, instanceId), zoneState("update", instanceId)).
+		Success(UpdateSuccess).
+		Failure(UpdateFail).
+		OnFailure(func(_ *dns.ZoneResponse, _ string) error {
+			return fmt.Errorf("update failed for zone with id %s", instanceId)
+		}).
+		Handler()
 	handler.SetTimeout(10 * time.Minute)
 	return handler
 }
@@ -71,66 +55,79 @@ func PartialUpdateZoneWaitHandler(ctx context.Context, a APIClientInterface, pro
 // DeleteZoneWaitHandler will wait for zone deletion
 // returned interface is nil or *ZoneResponseZone
 func DeleteZoneWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[dns.ZoneResponse] {
-	handler := wait.New(func() (waitFinished bool, response *dns.ZoneResponse, err error) {
-		s, err := a.GetZoneExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Zone.Id == nil || s.Zone.State == nil {
-			return false, nil, fmt.Errorf("delete failed for instance with id %s. The response is not valid: the id or the state are missing", instanceId)
-		}
-		if *s.Zone.Id == instanceId && *s.Zone.State == DeleteSuccess {
-			return true, s, nil
-		}
-		if *s.Zone.Id == instanceId && *s.Zone.State == DeleteFail {
-			return true, s, fmt.Errorf("delete failed for zone with id %s", instanceId)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getZone(ctx, a, projectId, instanceId), zoneState("delete", instanceId)).
+		Success(DeleteSuccess).
+		Failure(DeleteFail).
+		OnFailure(func(_ *dns.ZoneResponse, _ string) error {
+			return fmt.Errorf("delete failed for zone with id %s", instanceId)
+		}).
+		Handler()
+	handler.SetTimeout(10 * time.Minute)
+	return handler
+}
+
+// CloneZoneWaitHandler will wait for the zone created by CloneZone to be active
+func CloneZoneWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[dns.ZoneResponse] {
+	handler := wait.ForState(getZone(ctx, a, projectId, instanceId), activeZoneState("clone", instanceId)).
+		Success(CreateSuccess).
+		Failure(CreateFail).
+		OnFailure(func(_ *dns.ZoneResponse, _ string) error {
+			return fmt.Errorf("clone failed for zone with id %s", instanceId)
+		}).
+		Handler()
+	handler.SetTimeout(10 * time.Minute)
+	return handler
+}
+
+// MoveZoneWaitHandler will wait for the zone moved by MoveZone to be active in the target project.
+// The zone may not be found in the target project right after the move, which is waited out.
+func MoveZoneWaitHandler(ctx context.Context, a APIClientInterface, targetProjectId, instanceId string) *wait.AsyncActionHandler[dns.ZoneResponse] {
+	handler := wait.ForState(getMovedZone(ctx, a, targetProjectId, instanceId), activeZoneState("move", instanceId)).
+		Success(CreateSuccess, UpdateSuccess).
+		Failure(CreateFail, UpdateFail).
+		OnFailure(func(_ *dns.ZoneResponse, _ string) error {
+			return fmt.Errorf("move failed for zone with id %s", instanceId)
+		}).
+		Handler()
+	handler.SetTimeout(10 * time.Minute)
+	return handler
+}
+
+// RestoreZoneWaitHandler will wait for the zone restored by RestoreZone to be active
+func RestoreZoneWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[dns.ZoneResponse] {
+	handler := wait.ForState(getZone(ctx, a, projectId, instanceId), activeZoneState("restore", instanceId)).
+		Success(CreateSuccess, UpdateSuccess).
+		Failure(CreateFail, UpdateFail).
+		OnFailure(func(_ *dns.ZoneResponse, _ string) error {
+			return fmt.Errorf("restore failed for zone with id %s", instanceId)
+		}).
+		Handler()
 	handler.SetTimeout(10 * time.Minute)
 	return handler
 }
 
 // CreateRecordWaitHandler will wait for recordset creation
 func CreateRecordSetWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId, rrSetId string) *wait.AsyncActionHandler[dns.RecordSetResponse] {
-	handler := wait.New(func() (waitFinished bool, response *dns.RecordSetResponse, err error) {
-		s, err := a.GetRecordSetExecute(ctx, projectId, instanceId, rrSetId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Rrset.Id == nil || s.Rrset.State == nil {
-			return false, nil, fmt.Errorf("create failed for record set with id %s. The response is not valid: the id or the state are missing", instanceId)
-		}
-		if *s.Rrset.Id == rrSetId && *s.Rrset.State == CreateSuccess {
-			return true, s, nil
-		}
-		if *s.Rrset.Id == rrSetId && *s.Rrset.State == CreateFail {
-			return true, s, fmt.Errorf("create failed for record with id %s", rrSetId)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getRecordSet(ctx, a, projectId, instanceId, rrSetId), recordSetState("create", rrSetId)).
+		Success(CreateSuccess).
+		Failure(CreateFail).
+		OnFailure(func(_ *dns.RecordSetResponse, _ string) error {
+			return fmt.Errorf("create failed for record with id %s", rrSetId)
+		}).
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
 
 // UpdateRecordWaitHandler will wait for recordset update
 func PartialUpdateRecordSetWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId, rrSetId string) *wait.AsyncActionHandler[dns.RecordSetResponse] {
-	handler := wait.New(func() (waitFinished bool, response *dns.RecordSetResponse, err error) {
-		s, err := a.GetRecordSetExecute(ctx, projectId, instanceId, rrSetId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.Rrset.Id == nil || s.Rrset.State == nil {
-			return false, nil, fmt.Errorf("update failed for record set with id %s. The response is not valid: the id or the state are missing", rrSetId)
-		}
-		if *s.Rrset.Id == rrSetId && *s.Rrset.State == UpdateSuccess {
-			return true, s, nil
-		}
-		if *s.Rrset.Id == rrSetId && *s.Rrset.State == UpdateFail {
-			return true, s, fmt.Errorf("update failed for record with id %s", rrSetId)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getRecordSet(ctx, a, projectId, instanceId, rrSetId), recordSetState("update", rrSetId)).
+		Success(UpdateSuccess).
+		Failure(UpdateFail).
+		OnFailure(func(_ *dns.RecordSetResponse, _ string) error {
+			return fmt.Errorf("update failed for record with id %s", rrSetId)
+		}).
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
@@ -138,22 +135,85 @@ func PartialUpdateRecordSetWaitHandler(ctx context.Context, a APIClientInterface
 // DeleteRecordWaitHandler will wait for deletion
 // returned interface is nil or *RecordSetResponse
 func DeleteRecordSetWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId, rrSetId string) *wait.AsyncActionHandler[dns.RecordSetResponse] {
-	handler := wait.New(func() (waitFinished bool, response *dns.RecordSetResponse, err error) {
-		s, err := a.GetRecordSetExecute(ctx, projectId, instanceId, rrSetId)
-		if err != nil {
-			return false, nil, err
+	handler := wait.ForState(getRecordSet(ctx, a, projectId, instanceId, rrSetId), recordSetState("delete", rrSetId)).
+		Success(DeleteSuccess).
+		Failure(DeleteFail).
+		OnFailure(func(_ *dns.RecordSetResponse, _ string) error {
+			return fmt.Errorf("delete failed for record with id %s", rrSetId)
+		}).
+		Handler()
+	handler.SetTimeout(2 * time.Minute)
+	return handler
+}
+
+func getZone(ctx context.Context, a APIClientInterface, projectId, zoneId string) wait.GetFunc[dns.ZoneResponse] {
+	return func() (*dns.ZoneResponse, error) {
+		return a.GetZoneExecute(ctx, projectId, zoneId)
+	}
+}
+
+// zoneState returns the state of the zone, or an empty state while the response is for another zone
+func zoneState(operation, zoneId string) wait.StateFunc[dns.ZoneResponse] {
+	return func(s *dns.ZoneResponse) (string, error) {
+		if s.Zone == nil || s.Zone.Id == nil || s.Zone.State == nil {
+			return "", fmt.Errorf("%s failed for instance with id %s. The response is not valid: the id or the state are missing", operation, zoneId)
 		}
-		if s.Rrset.Id == nil || s.Rrset.State == nil {
-			return false, nil, fmt.Errorf("delete failed for record set with id %s. The response is not valid: the id or the state are missing", rrSetId)
+		if *s.Zone.Id != zoneId {
+			return "", nil
 		}
-		if *s.Rrset.Id == rrSetId && *s.Rrset.State == DeleteSuccess {
-			return true, s, nil
+		return *s.Zone.State, nil
+	}
+}
+
+// getMovedZone gets the zone in the project it is moved to, returning no zone while it isn't found there.
+// Other errors, e.g. missing permissions in the target project, are returned right away.
+func getMovedZone(ctx context.Context, a APIClientInterface, targetProjectId, zoneId string) wait.GetFunc[dns.ZoneResponse] {
+	return func() (*dns.ZoneResponse, error) {
+		resp, err := a.GetZoneExecute(ctx, targetProjectId, zoneId)
+		if err != nil {
+			oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
+			if ok && oapiErr.StatusCode == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// activeZoneState returns the state of the zone, or an empty state while there is no zone, the response is for another zone or the zone
+// is inactive
+func activeZoneState(operation, zoneId string) wait.StateFunc[dns.ZoneResponse] {
+	return func(s *dns.ZoneResponse) (string, error) {
+		if s == nil {
+			return "", nil
+		}
+		state, err := zoneState(operation, zoneId)(s)
+		if err != nil || state == "" {
+			return state, err
+		}
+		if s.Zone.Active != nil && !*s.Zone.Active && state != CreateFail && state != UpdateFail {
+			return "", nil
+		}
+		return state, nil
+	}
+}
+
+func getRecordSet(ctx context.Context, a APIClientInterface, projectId, zoneId, rrSetId string) wait.GetFunc[dns.RecordSetResponse] {
+	return func() (*dns.RecordSetResponse, error) {
+		return a.GetRecordSetExecute(ctx, projectId, zoneId, rrSetId)
+	}
+}
+
+// recordSetState returns the state of the record set, or an empty state while the response is for another record set
+func recordSetState(operation, rrSetId string) wait.StateFunc[dns.RecordSetResponse] {
+	return func(s *dns.RecordSetResponse) (string, error) {
+		if s.Rrset == nil || s.Rrset.Id == nil || s.Rrset.State == nil {
+			return "", fmt.Errorf("%s failed for record set with id %s. The response is not valid: the id or the state are missing", operation, rrSetId)
 		}
-		if *s.Rrset.Id == rrSetId && *s.Rrset.State == DeleteFail {
-			return true, s, fmt.Errorf("delete failed for record with id %s", rrSetId)
+		if *s.Rrset.Id != rrSetId {
+			return "", nil
 		}
-		return false, nil, nil
-	})
-	handler.SetTimeout(2 * time.Minute)
-	return handler
+		return *s.Rrset.State, nil
+	}
 }
@@ -2,6 +2,7 @@ package wait
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -14,6 +15,12 @@ import (
 type apiClientMocked struct {
 	getFails      bool
 	resourceState string
+	// number of calls to GetZoneExecute that fail with not found, before returning the zone
+	notFoundCalls int
+	// whether the zone is returned as inactive
+	inactive bool
+	// whether GetZoneExecute fails with forbidden
+	forbidden bool
 }
 
 func (a *apiClientMocked) GetZoneExecute(_ context.Context, _, _ string) (*dns.ZoneResponse, error) {
@@ -22,12 +29,27 @@ func (a *apiClientMocked) GetZoneExecute(_ context.Context, _, _ string) (*dns.Z
 			StatusCode: 500,
 		}
 	}
+	if a.forbidden {
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: 403,
+		}
+	}
+	if a.notFoundCalls > 0 {
+		a.notFoundCalls--
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: 404,
+		}
+	}
 
+	zone := &dns.Zone{
+		State: &a.resourceState,
+		Id:    utils.Ptr("zid"),
+	}
+	if a.inactive {
+		zone.Active = utils.Ptr(false)
+	}
 	return &dns.ZoneResponse{
-		Zone: &dns.Zone{
-			State: &a.resourceState,
-			Id:    utils.Ptr("zid"),
-		},
+		Zone: zone,
 	}, nil
 }
 
@@ -460,3 +482,226 @@ func TestDeleteRecordSetWaitHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestCloneZoneWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc          string
+		getFails      bool
+		resourceState string
+		inactive      bool
+		wantErr       bool
+		wantResp      bool
+	}{
+		{
+			desc:          "clone_succeeded",
+			getFails:      false,
+			resourceState: CreateSuccess,
+			wantErr:       false,
+			wantResp:      true,
+		},
+		{
+			desc:          "clone_failed",
+			getFails:      false,
+			resourceState: CreateFail,
+			wantErr:       true,
+			wantResp:      true,
+		},
+		{
+			desc:          "inactive",
+			getFails:      false,
+			resourceState: CreateSuccess,
+			inactive:      true,
+			wantErr:       true,
+			wantResp:      false,
+		},
+		{
+			desc:          "get_fails",
+			getFails:      true,
+			resourceState: "",
+			wantErr:       true,
+			wantResp:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientMocked{
+				getFails:      tt.getFails,
+				resourceState: tt.resourceState,
+				inactive:      tt.inactive,
+			}
+
+			var wantRes *dns.ZoneResponse
+			if tt.wantResp {
+				wantRes = &dns.ZoneResponse{
+					Zone: &dns.Zone{
+						State: &tt.resourceState,
+						Id:    utils.Ptr("zid"),
+					},
+				}
+			}
+
+			handler := CloneZoneWaitHandler(context.Background(), apiClient, "pid", "zid")
+
+			gotRes, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(gotRes, wantRes) {
+				t.Fatalf("handler gotRes = %v, want %v", gotRes, wantRes)
+			}
+		})
+	}
+}
+
+func TestMoveZoneWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc          string
+		getFails      bool
+		notFoundCalls int
+		forbidden     bool
+		resourceState string
+		wantErr       bool
+		wantResp      bool
+	}{
+		{
+			desc:          "move_succeeded",
+			getFails:      false,
+			resourceState: UpdateSuccess,
+			wantErr:       false,
+			wantResp:      true,
+		},
+		{
+			desc:          "not_found_at_first",
+			getFails:      false,
+			notFoundCalls: 2,
+			resourceState: CreateSuccess,
+			wantErr:       false,
+			wantResp:      true,
+		},
+		{
+			desc:          "move_failed",
+			getFails:      false,
+			resourceState: UpdateFail,
+			wantErr:       true,
+			wantResp:      true,
+		},
+		{
+			desc:          "get_fails",
+			getFails:      true,
+			resourceState: "",
+			wantErr:       true,
+			wantResp:      false,
+		},
+		{
+			desc:          "timeout",
+			getFails:      false,
+			notFoundCalls: 1000,
+			resourceState: UpdateSuccess,
+			wantErr:       true,
+			wantResp:      false,
+		},
+		{
+			desc:          "forbidden",
+			forbidden:     true,
+			resourceState: UpdateSuccess,
+			wantErr:       true,
+			wantResp:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientMocked{
+				getFails:      tt.getFails,
+				resourceState: tt.resourceState,
+				notFoundCalls: tt.notFoundCalls,
+				forbidden:     tt.forbidden,
+			}
+
+			var wantRes *dns.ZoneResponse
+			if tt.wantResp {
+				wantRes = &dns.ZoneResponse{
+					Zone: &dns.Zone{
+						State: &tt.resourceState,
+						Id:    utils.Ptr("zid"),
+					},
+				}
+			}
+
+			handler := MoveZoneWaitHandler(context.Background(), apiClient, "target-pid", "zid")
+
+			gotRes, err := handler.SetThrottle(time.Millisecond).SetTimeout(100 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.forbidden && errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("handler timed out instead of returning the forbidden error: %v", err)
+			}
+			if !cmp.Equal(gotRes, wantRes) {
+				t.Fatalf("handler gotRes = %v, want %v", gotRes, wantRes)
+			}
+		})
+	}
+}
+
+func TestRestoreZoneWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc          string
+		getFails      bool
+		resourceState string
+		wantErr       bool
+		wantResp      bool
+	}{
+		{
+			desc:          "restore_succeeded",
+			getFails:      false,
+			resourceState: UpdateSuccess,
+			wantErr:       false,
+			wantResp:      true,
+		},
+		{
+			desc:          "restore_failed",
+			getFails:      false,
+			resourceState: UpdateFail,
+			wantErr:       true,
+			wantResp:      true,
+		},
+		{
+			desc:          "timeout",
+			getFails:      false,
+			resourceState: DeleteSuccess,
+			wantErr:       true,
+			wantResp:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientMocked{
+				getFails:      tt.getFails,
+				resourceState: tt.resourceState,
+			}
+
+			var wantRes *dns.ZoneResponse
+			if tt.wantResp {
+				wantRes = &dns.ZoneResponse{
+					Zone: &dns.Zone{
+						State: &tt.resourceState,
+						Id:    utils.Ptr("zid"),
+					},
+				}
+			}
+
+			handler := RestoreZoneWaitHandler(context.Background(), apiClient, "pid", "zid")
+
+			gotRes, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(gotRes, wantRes) {
+				t.Fatalf("handler gotRes = %v, want %v", gotRes, wantRes)
+			}
+		})
+	}
+}
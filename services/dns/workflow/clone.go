@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// CloneZone clones the zone with its record sets to a new zone with the DNS name of the payload and returns the new zone.
+//
+// It waits until the new zone is active and verifies that its record sets match the ones of the source zone, returning a
+// *VerificationError otherwise. The DNS name of the source zone is expected to be replaced with the new one in the names of the
+// record sets, and also in their contents if the payload sets AdjustRecords.
+func CloneZone(ctx context.Context, client *dns.APIClient, projectId, zoneId string, payload dns.CloneZonePayload) (*dns.Zone, error) {
+	return cloneZone(ctx, apiClientAdapter{client}, projectId, zoneId, payload)
+}
+
+func cloneZone(ctx context.Context, a apiClient, projectId, zoneId string, payload dns.CloneZonePayload) (*dns.Zone, error) {
+	if payload.DnsName == nil || *payload.DnsName == "" {
+		return nil, fmt.Errorf("the DNS name of the new zone is required")
+	}
+	zone, err := getZone(ctx, a, projectId, zoneId)
+	if err != nil {
+		return nil, err
+	}
+	source, err := listRecordSets(ctx, a, projectId, zoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.cloneZone(ctx, projectId, zoneId, payload)
+	if err != nil {
+		return nil, fmt.Errorf("clone zone %s: %w", zoneId, err)
+	}
+	if resp.Zone == nil || resp.Zone.Id == nil {
+		return nil, fmt.Errorf("clone zone %s: the response is missing the id of the new zone", zoneId)
+	}
+	cloneId := *resp.Zone.Id
+
+	waitResp, err := wait.CloneZoneWaitHandler(ctx, a, projectId, cloneId).WaitWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for clone %s of zone %s: %w", cloneId, zoneId, err)
+	}
+	result, err := listRecordSets(ctx, a, projectId, cloneId)
+	if err != nil {
+		return waitResp.Zone, err
+	}
+	renameName := renameZone(*zone.DnsName, *payload.DnsName)
+	renameContent := unchanged
+	if payload.AdjustRecords != nil && *payload.AdjustRecords {
+		renameContent = renameName
+	}
+	return waitResp.Zone, verify(cloneId, source, result, renameName, renameContent)
+}
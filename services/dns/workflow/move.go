@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// MoveZone moves the zone with its record sets from its project to the target project and returns the moved zone.
+//
+// It creates and validates a move code, moves the zone, waits until it is active in the target project and verifies that its
+// record sets match the ones before the move, returning a *VerificationError otherwise. If the zone couldn't be moved,
+// the move code is deleted.
+func MoveZone(ctx context.Context, client *dns.APIClient, projectId, zoneId, targetProjectId string) (*dns.Zone, error) {
+	return moveZone(ctx, apiClientAdapter{client}, projectId, zoneId, targetProjectId)
+}
+
+func moveZone(ctx context.Context, a apiClient, projectId, zoneId, targetProjectId string) (*dns.Zone, error) {
+	zone, err := getZone(ctx, a, projectId, zoneId)
+	if err != nil {
+		return nil, err
+	}
+	source, err := listRecordSets(ctx, a, projectId, zoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	moveCode, err := a.createMoveCode(ctx, projectId, zoneId)
+	if err != nil {
+		return nil, fmt.Errorf("create move code for zone %s: %w", zoneId, err)
+	}
+	if moveCode.Code == nil {
+		return nil, cleanUpMoveCode(ctx, a, projectId, zoneId, fmt.Errorf("create move code for zone %s: the response is missing the code", zoneId))
+	}
+	if err := a.validateMoveCode(ctx, projectId, zoneId, *moveCode.Code); err != nil {
+		return nil, cleanUpMoveCode(ctx, a, projectId, zoneId, fmt.Errorf("validate move code for zone %s: %w", zoneId, err))
+	}
+	err = a.moveZone(ctx, targetProjectId, dns.MoveZonePayload{
+		Code:        moveCode.Code,
+		ZoneDnsName: zone.DnsName,
+	})
+	if err != nil {
+		return nil, cleanUpMoveCode(ctx, a, projectId, zoneId, fmt.Errorf("move zone %s to project %s: %w", zoneId, targetProjectId, err))
+	}
+
+	resp, err := wait.MoveZoneWaitHandler(ctx, a, targetProjectId, zoneId).WaitWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for zone %s to be moved: %w", zoneId, err)
+	}
+	result, err := listRecordSets(ctx, a, targetProjectId, zoneId)
+	if err != nil {
+		return resp.Zone, err
+	}
+	return resp.Zone, verify(zoneId, source, result, unchanged, unchanged)
+}
+
+// cleanUpMoveCode deletes the move code of the zone after the move failed with err, and returns the error to report
+func cleanUpMoveCode(ctx context.Context, a apiClient, projectId, zoneId string, err error) error {
+	if deleteErr := a.deleteMoveCode(ctx, projectId, zoneId); deleteErr != nil {
+		return fmt.Errorf("%w (deleting the move code also failed: %v)", err, deleteErr)
+	}
+	return err
+}
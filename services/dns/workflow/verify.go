@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+// VerificationError is returned when the record sets of the resulting zone don't match the ones of the source zone
+type VerificationError struct {
+	ZoneId string
+	// Differences describes each record set that is missing, unexpected or different, sorted by name and type
+	Differences []string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("record sets of zone %s don't match the source zone: %s", e.ZoneId, strings.Join(e.Differences, "; "))
+}
+
+// recordSetSummary is the part of a record set that must match between the source and the resulting zone
+type recordSetSummary struct {
+	ttl     int64
+	records []string
+}
+
+func (s recordSetSummary) String() string {
+	return fmt.Sprintf("ttl=%d records=[%s]", s.ttl, strings.Join(s.records, ", "))
+}
+
+// summarize returns the record sets by name and type, with their names mapped by renameName and their contents by renameContent.
+// SOA record sets are left out, since they are managed by the DNS service and differ between zones.
+func summarize(rrSets []dns.RecordSet, renameName, renameContent func(string) string) map[string]recordSetSummary {
+	summaries := map[string]recordSetSummary{}
+	for i := range rrSets {
+		rrSet := &rrSets[i]
+		if rrSet.Name == nil || rrSet.Type == nil || strings.EqualFold(*rrSet.Type, "SOA") {
+			continue
+		}
+		s := recordSetSummary{records: []string{}}
+		if rrSet.Ttl != nil {
+			s.ttl = *rrSet.Ttl
+		}
+		if rrSet.Records != nil {
+			for _, r := range *rrSet.Records {
+				if r.Content != nil {
+					s.records = append(s.records, renameContent(*r.Content))
+				}
+			}
+		}
+		sort.Strings(s.records)
+		summaries[strings.ToLower(renameName(*rrSet.Name))+" "+strings.ToUpper(*rrSet.Type)] = s
+	}
+	return summaries
+}
+
+// verify compares the record sets of the resulting zone with the ones of the source zone, after mapping the source names with
+// renameName and the source contents with renameContent
+func verify(zoneId string, source, result []dns.RecordSet, renameName, renameContent func(string) string) error {
+	want := summarize(source, renameName, renameContent)
+	got := summarize(result, unchanged, unchanged)
+
+	differences := []string{}
+	for k, w := range want {
+		g, ok := got[k]
+		switch {
+		case !ok:
+			differences = append(differences, fmt.Sprintf("%s is missing", k))
+		case g.String() != w.String():
+			differences = append(differences, fmt.Sprintf("%s has %s, want %s", k, g, w))
+		}
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			differences = append(differences, fmt.Sprintf("%s is unexpected", k))
+		}
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	sort.Strings(differences)
+	return &VerificationError{ZoneId: zoneId, Differences: differences}
+}
+
+func unchanged(s string) string {
+	return s
+}
+
+// renameZone returns a function replacing the DNS name of a zone with another one in record set names,
+// and in the names that are fields of record set contents
+func renameZone(from, to string) func(string) string {
+	from = strings.ToLower(fqdn(from))
+	to = strings.ToLower(fqdn(to))
+	return func(s string) string {
+		fields := strings.Split(s, " ")
+		for i, field := range fields {
+			lower := strings.ToLower(field)
+			if lower == from {
+				fields[i] = to
+			} else if strings.HasSuffix(lower, "."+from) {
+				fields[i] = field[:len(field)-len(from)] + to
+			}
+		}
+		return strings.Join(fields, " ")
+	}
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
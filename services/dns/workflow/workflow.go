@@ -0,0 +1,85 @@
+// Package workflow implements multi-step zone operations of the DNS API, which wait for the resulting zone to be active
+// and verify that its record sets match the source zone.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+// listPageSize is the page size used to list the record sets of a zone
+const listPageSize = int32(1000)
+
+// apiClient is the part of the DNS API used by the workflows.
+type apiClient interface {
+	wait.APIClientInterface
+	listRecordSets(ctx context.Context, projectId, zoneId string, page int32) (*dns.ListRecordSetsResponse, error)
+	createMoveCode(ctx context.Context, projectId, zoneId string) (*dns.MoveCodeResponse, error)
+	validateMoveCode(ctx context.Context, projectId, zoneId, code string) error
+	deleteMoveCode(ctx context.Context, projectId, zoneId string) error
+	moveZone(ctx context.Context, targetProjectId string, payload dns.MoveZonePayload) error
+	cloneZone(ctx context.Context, projectId, zoneId string, payload dns.CloneZonePayload) (*dns.ZoneResponse, error)
+}
+
+type apiClientAdapter struct {
+	*dns.APIClient
+}
+
+func (a apiClientAdapter) listRecordSets(ctx context.Context, projectId, zoneId string, page int32) (*dns.ListRecordSetsResponse, error) {
+	return a.ListRecordSets(ctx, projectId, zoneId).Page(page).PageSize(listPageSize).StateNeq(wait.DeleteSuccess).Execute()
+}
+
+func (a apiClientAdapter) createMoveCode(ctx context.Context, projectId, zoneId string) (*dns.MoveCodeResponse, error) {
+	return a.CreateMoveCodeExecute(ctx, projectId, zoneId)
+}
+
+func (a apiClientAdapter) validateMoveCode(ctx context.Context, projectId, zoneId, code string) error {
+	_, err := a.ValidateMoveCode(ctx, projectId, zoneId).ValidateMoveCodePayload(dns.ValidateMoveCodePayload{Code: &code}).Execute()
+	return err
+}
+
+func (a apiClientAdapter) deleteMoveCode(ctx context.Context, projectId, zoneId string) error {
+	_, err := a.DeleteMoveCodeExecute(ctx, projectId, zoneId)
+	return err
+}
+
+func (a apiClientAdapter) moveZone(ctx context.Context, targetProjectId string, payload dns.MoveZonePayload) error {
+	_, err := a.MoveZone(ctx, targetProjectId).MoveZonePayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) cloneZone(ctx context.Context, projectId, zoneId string, payload dns.CloneZonePayload) (*dns.ZoneResponse, error) {
+	return a.CloneZone(ctx, projectId, zoneId).CloneZonePayload(payload).Execute()
+}
+
+// getZone returns the zone, which must have an id and a DNS name
+func getZone(ctx context.Context, a apiClient, projectId, zoneId string) (*dns.Zone, error) {
+	resp, err := a.GetZoneExecute(ctx, projectId, zoneId)
+	if err != nil {
+		return nil, fmt.Errorf("get zone %s: %w", zoneId, err)
+	}
+	if resp.Zone == nil || resp.Zone.Id == nil || resp.Zone.DnsName == nil {
+		return nil, fmt.Errorf("get zone %s: the response is missing the id or the DNS name", zoneId)
+	}
+	return resp.Zone, nil
+}
+
+// listRecordSets returns all record sets of the zone
+func listRecordSets(ctx context.Context, a apiClient, projectId, zoneId string) ([]dns.RecordSet, error) {
+	rrSets := []dns.RecordSet{}
+	for page := int32(1); ; page++ {
+		resp, err := a.listRecordSets(ctx, projectId, zoneId, page)
+		if err != nil {
+			return nil, fmt.Errorf("list record sets of zone %s: %w", zoneId, err)
+		}
+		if resp.RrSets != nil {
+			rrSets = append(rrSets, *resp.RrSets...)
+		}
+		if resp.TotalPages == nil || int64(page) >= *resp.TotalPages || resp.RrSets == nil || len(*resp.RrSets) == 0 {
+			return rrSets, nil
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns/wait"
+)
+
+func fixtureRecordSet(name, rrType string, records ...string) dns.RecordSet {
+	rs := []dns.Record{}
+	for _, r := range records {
+		rs = append(rs, dns.Record{Content: utils.Ptr(r)})
+	}
+	return dns.RecordSet{
+		Id:      utils.Ptr(name + " " + rrType),
+		Name:    utils.Ptr(name),
+		Type:    utils.Ptr(rrType),
+		Ttl:     utils.Ptr(int64(3600)),
+		Records: &rs,
+		State:   utils.Ptr(wait.CreateSuccess),
+	}
+}
+
+// apiClientMocked is a DNS service with the zone "zid" with DNS name "example.com" in the project "pid"
+type apiClientMocked struct {
+	// project of each zone
+	projects map[string]string
+	rrSets   map[string][]dns.RecordSet
+
+	validateFails bool
+	moveFails     bool
+	// record sets returned for the zone after it is moved or cloned, instead of the source ones
+	resultRrSets []dns.RecordSet
+
+	calls []string
+}
+
+func newAPIClientMocked() *apiClientMocked {
+	return &apiClientMocked{
+		projects: map[string]string{"zid": "pid"},
+		rrSets: map[string][]dns.RecordSet{
+			"zid": {
+				fixtureRecordSet("example.com.", "SOA", "ns1.stackit.cloud. hostmaster.stackit.cloud. 1 3600 600 1209600 60"),
+				fixtureRecordSet("example.com.", "MX", "10 mail.example.com."),
+				fixtureRecordSet("www.example.com.", "CNAME", "example.com."),
+				fixtureRecordSet("example.com.", "TXT", `"v=spf1 -all"`),
+			},
+		},
+	}
+}
+
+func (a *apiClientMocked) GetZoneExecute(_ context.Context, projectId, zoneId string) (*dns.ZoneResponse, error) {
+	if a.projects[zoneId] != projectId {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: 404}
+	}
+	dnsName := "example.com"
+	if zoneId == "clone" {
+		dnsName = "example.org"
+	}
+	return &dns.ZoneResponse{Zone: &dns.Zone{
+		Id:      utils.Ptr(zoneId),
+		DnsName: utils.Ptr(dnsName),
+		State:   utils.Ptr(wait.CreateSuccess),
+		Active:  utils.Ptr(true),
+	}}, nil
+}
+
+func (a *apiClientMocked) GetRecordSetExecute(_ context.Context, _, _, _ string) (*dns.RecordSetResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) listRecordSets(_ context.Context, projectId, zoneId string, page int32) (*dns.ListRecordSetsResponse, error) {
+	a.calls = append(a.calls, fmt.Sprintf("list %s %s", projectId, zoneId))
+	if a.projects[zoneId] != projectId {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: 404}
+	}
+	rrSets := a.rrSets[zoneId]
+	return &dns.ListRecordSetsResponse{
+		RrSets:     &rrSets,
+		TotalPages: utils.Ptr(int64(page)),
+	}, nil
+}
+
+func (a *apiClientMocked) createMoveCode(_ context.Context, _, zoneId string) (*dns.MoveCodeResponse, error) {
+	a.calls = append(a.calls, fmt.Sprintf("create move code %s", zoneId))
+	return &dns.MoveCodeResponse{Code: utils.Ptr("code")}, nil
+}
+
+func (a *apiClientMocked) validateMoveCode(_ context.Context, _, zoneId, code string) error {
+	a.calls = append(a.calls, fmt.Sprintf("validate move code %s %s", zoneId, code))
+	if a.validateFails {
+		return &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	return nil
+}
+
+func (a *apiClientMocked) deleteMoveCode(_ context.Context, _, zoneId string) error {
+	a.calls = append(a.calls, fmt.Sprintf("delete move code %s", zoneId))
+	return nil
+}
+
+func (a *apiClientMocked) moveZone(_ context.Context, targetProjectId string, payload dns.MoveZonePayload) error {
+	a.calls = append(a.calls, fmt.Sprintf("move %s to %s with %s", *payload.ZoneDnsName, targetProjectId, *payload.Code))
+	if a.moveFails {
+		return &oapierror.GenericOpenAPIError{StatusCode: 400}
+	}
+	a.projects["zid"] = targetProjectId
+	if a.resultRrSets != nil {
+		a.rrSets["zid"] = a.resultRrSets
+	}
+	return nil
+}
+
+func (a *apiClientMocked) cloneZone(_ context.Context, projectId, zoneId string, payload dns.CloneZonePayload) (*dns.ZoneResponse, error) {
+	a.calls = append(a.calls, fmt.Sprintf("clone %s to %s", zoneId, *payload.DnsName))
+	a.projects["clone"] = projectId
+	a.rrSets["clone"] = a.resultRrSets
+	return &dns.ZoneResponse{Zone: &dns.Zone{Id: utils.Ptr("clone")}}, nil
+}
+
+func TestMoveZone(t *testing.T) {
+	tests := []struct {
+		desc          string
+		validateFails bool
+		moveFails     bool
+		resultRrSets  []dns.RecordSet
+		wantCalls     []string
+		wantErr       bool
+		wantVerifyErr bool
+	}{
+		{
+			desc: "ok",
+			wantCalls: []string{
+				"list pid zid",
+				"create move code zid",
+				"validate move code zid code",
+				"move example.com to target with code",
+				"list target zid",
+			},
+		},
+		{
+			desc:          "validate_fails",
+			validateFails: true,
+			wantCalls: []string{
+				"list pid zid",
+				"create move code zid",
+				"validate move code zid code",
+				"delete move code zid",
+			},
+			wantErr: true,
+		},
+		{
+			desc:      "move_fails",
+			moveFails: true,
+			wantCalls: []string{
+				"list pid zid",
+				"create move code zid",
+				"validate move code zid code",
+				"move example.com to target with code",
+				"delete move code zid",
+			},
+			wantErr: true,
+		},
+		{
+			desc: "verification_fails",
+			resultRrSets: []dns.RecordSet{
+				fixtureRecordSet("example.com.", "MX", "20 mail.example.com."),
+				fixtureRecordSet("www.example.com.", "CNAME", "example.com."),
+			},
+			wantCalls: []string{
+				"list pid zid",
+				"create move code zid",
+				"validate move code zid code",
+				"move example.com to target with code",
+				"list target zid",
+			},
+			wantErr:       true,
+			wantVerifyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := newAPIClientMocked()
+			a.validateFails = tt.validateFails
+			a.moveFails = tt.moveFails
+			a.resultRrSets = tt.resultRrSets
+
+			zone, err := moveZone(context.Background(), a, "pid", "zid", "target")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("moveZone error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var verifyErr *VerificationError
+			if errors.As(err, &verifyErr) != tt.wantVerifyErr {
+				t.Fatalf("moveZone error = %v, want verification error %v", err, tt.wantVerifyErr)
+			}
+			if tt.wantVerifyErr {
+				want := []string{
+					"example.com. MX has ttl=3600 records=[20 mail.example.com.], want ttl=3600 records=[10 mail.example.com.]",
+					"example.com. TXT is missing",
+				}
+				if diff := cmp.Diff(verifyErr.Differences, want); diff != "" {
+					t.Fatalf("unexpected differences (-got +want): %s", diff)
+				}
+			}
+			if !tt.wantErr && (zone == nil || *zone.Id != "zid") {
+				t.Fatalf("moveZone returned zone %v", zone)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCloneZone(t *testing.T) {
+	tests := []struct {
+		desc          string
+		adjustRecords bool
+		resultRrSets  []dns.RecordSet
+		wantErr       bool
+	}{
+		{
+			desc: "ok",
+			resultRrSets: []dns.RecordSet{
+				fixtureRecordSet("example.org.", "SOA", "ns1.stackit.cloud. hostmaster.stackit.cloud. 2 3600 600 1209600 60"),
+				fixtureRecordSet("example.org.", "MX", "10 mail.example.com."),
+				fixtureRecordSet("www.example.org.", "CNAME", "example.com."),
+				fixtureRecordSet("example.org.", "TXT", `"v=spf1 -all"`),
+			},
+		},
+		{
+			desc:          "adjust_records",
+			adjustRecords: true,
+			resultRrSets: []dns.RecordSet{
+				fixtureRecordSet("example.org.", "MX", "10 mail.example.org."),
+				fixtureRecordSet("www.example.org.", "CNAME", "example.org."),
+				fixtureRecordSet("example.org.", "TXT", `"v=spf1 -all"`),
+			},
+		},
+		{
+			desc:          "records_not_adjusted",
+			adjustRecords: true,
+			resultRrSets: []dns.RecordSet{
+				fixtureRecordSet("example.org.", "MX", "10 mail.example.com."),
+				fixtureRecordSet("www.example.org.", "CNAME", "example.com."),
+				fixtureRecordSet("example.org.", "TXT", `"v=spf1 -all"`),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "unexpected_record_set",
+			resultRrSets: []dns.RecordSet{
+				fixtureRecordSet("example.org.", "MX", "10 mail.example.com."),
+				fixtureRecordSet("www.example.org.", "CNAME", "example.com."),
+				fixtureRecordSet("example.org.", "TXT", `"v=spf1 -all"`),
+				fixtureRecordSet("new.example.org.", "A", "192.0.2.1"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := newAPIClientMocked()
+			a.resultRrSets = tt.resultRrSets
+
+			zone, err := cloneZone(context.Background(), a, "pid", "zid", dns.CloneZonePayload{
+				DnsName:       utils.Ptr("example.org"),
+				AdjustRecords: utils.Ptr(tt.adjustRecords),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cloneZone error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if zone == nil || *zone.Id != "clone" {
+				t.Fatalf("cloneZone returned zone %v", zone)
+			}
+			wantCalls := []string{"list pid zid", "clone zid to example.org", "list pid clone"}
+			if diff := cmp.Diff(a.calls, wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRenameZone(t *testing.T) {
+	rename := renameZone("example.com", "example.org.")
+	for in, want := range map[string]string{
+		"example.com.":             "example.org.",
+		"WWW.Example.com.":         "WWW.example.org.",
+		"10 mail.example.com.":     "10 mail.example.org.",
+		"notexample.com.":          "notexample.com.",
+		`"v=spf1 include:example"`: `"v=spf1 include:example"`,
+	} {
+		if got := rename(in); got != want {
+			t.Errorf("rename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package zonefile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// token is a field of an entry of a zone file
+type token struct {
+	value string
+	// quoted is set for character strings, e.g. the content of TXT records. The escape sequences of value are kept as they are.
+	quoted bool
+}
+
+func (t token) String() string {
+	if t.quoted {
+		return `"` + t.value + `"`
+	}
+	return t.value
+}
+
+// entry is a logical line of a zone file, i.e. a directive or a resource record.
+// Parentheses let an entry span several physical lines.
+type entry struct {
+	// line is the line number where the entry starts
+	line int
+	// blankOwner is set if the entry starts with whitespace, i.e. it has the owner of the previous resource record
+	blankOwner bool
+	tokens     []token
+	// comment is the trailing comment of an entry on a single line. The comments of entries spanning several lines annotate
+	// single fields, e.g. the SOA serial, and are left out.
+	comment string
+}
+
+// lex splits a zone file into its entries, leaving out empty lines and lines with only comments.
+func lex(input string) ([]entry, error) {
+	entries := []entry{}
+	line := 1
+	current := entry{line: line}
+	comment := ""
+	// grouped is set once the current entry uses parentheses
+	grouped := false
+	parens := 0
+	atLineStart := true
+
+	flush := func() {
+		if len(current.tokens) > 0 {
+			if !grouped {
+				current.comment = comment
+			}
+			entries = append(entries, current)
+		}
+		comment = ""
+		grouped = false
+		current = entry{line: line}
+		atLineStart = true
+	}
+
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+			if parens == 0 {
+				flush()
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			if atLineStart && parens == 0 && len(current.tokens) == 0 {
+				current.blankOwner = true
+			}
+			i++
+		case c == ';':
+			end := strings.IndexByte(input[i:], '\n')
+			if end == -1 {
+				end = len(input) - i
+			}
+			comment = strings.TrimSpace(input[i+1 : i+end])
+			i += end
+		case c == '(':
+			parens++
+			grouped = true
+			i++
+		case c == ')':
+			if parens == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced parenthesis", line)
+			}
+			parens--
+			i++
+		case c == '"':
+			start := line
+			var b strings.Builder
+			i++
+			for ; i < len(input) && input[i] != '"'; i++ {
+				if input[i] == '\n' {
+					line++
+				}
+				if input[i] == '\\' && i+1 < len(input) {
+					b.WriteByte(input[i])
+					i++
+				}
+				b.WriteByte(input[i])
+			}
+			if i >= len(input) {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", start)
+			}
+			i++
+			current.tokens = append(current.tokens, token{value: b.String(), quoted: true})
+		default:
+			var b strings.Builder
+			for ; i < len(input) && !strings.ContainsRune(" \t\r\n;()\"", rune(input[i])); i++ {
+				if input[i] == '\\' && i+1 < len(input) {
+					b.WriteByte(input[i])
+					i++
+				}
+				b.WriteByte(input[i])
+			}
+			current.tokens = append(current.tokens, token{value: b.String()})
+		}
+		atLineStart = false
+	}
+	if parens != 0 {
+		return nil, fmt.Errorf("line %d: unbalanced parenthesis", current.line)
+	}
+	flush()
+	return entries, nil
+}
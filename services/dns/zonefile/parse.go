@@ -0,0 +1,312 @@
+package zonefile
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+// SupportedTypes are the record types supported by the DNS service
+var SupportedTypes = []string{
+	"A", "AAAA", "SOA", "CNAME", "NS", "MX", "TXT", "SRV", "PTR", "ALIAS", "DNAME", "CAA",
+	"DNSKEY", "DS", "LOC", "NAPTR", "SSHFP", "TLSA", "URI", "CERT", "SVCB", "HTTPS",
+}
+
+// Positions of the fields holding domain names in the content of each record type.
+// Relative names in these fields are made absolute using the origin.
+var domainNameFields = map[string][]int{
+	"SOA":   {0, 1},
+	"CNAME": {0},
+	"NS":    {0},
+	"MX":    {1},
+	"SRV":   {3},
+	"PTR":   {0},
+	"ALIAS": {0},
+	"DNAME": {0},
+	"NAPTR": {5},
+	"SVCB":  {1},
+	"HTTPS": {1},
+}
+
+var classes = []string{"IN", "CH", "HS", "CS"}
+
+// UnsupportedRecord is a resource record of a zone file whose type isn't supported by the DNS service
+type UnsupportedRecord struct {
+	Line int
+	Name string
+	Type string
+}
+
+// UnsupportedRecordsError is returned by Parse if the zone file has records with types that aren't supported by the DNS service.
+// These records are left out of the parsed zone.
+type UnsupportedRecordsError struct {
+	Records []UnsupportedRecord
+}
+
+func (e *UnsupportedRecordsError) Error() string {
+	records := []string{}
+	for _, r := range e.Records {
+		records = append(records, fmt.Sprintf("%s %s (line %d)", r.Name, r.Type, r.Line))
+	}
+	return fmt.Sprintf("zone file has records of unsupported types: %s", strings.Join(records, ", "))
+}
+
+type parser struct {
+	origin     string
+	defaultTTL *int64
+	lastTTL    *int64
+	lastOwner  string
+	rrSets     []dns.RecordDataExchange
+	// index of the record set of each name and type in rrSets
+	rrSetIndex  map[string]int
+	unsupported []UnsupportedRecord
+}
+
+// Parse parses a zone file in master file format (RFC 1035 section 5) into record sets that can be imported with ImportRecordSets.
+// Records with the same name and type are merged into a single record set. The comment of the record set is the first trailing
+// comment of its records written on a single line, the comments of records spanning several lines annotate their fields.
+//
+// origin is used to complete relative names until the zone file sets another one with $ORIGIN, it may be empty if the zone file sets it.
+// The names of the parsed record sets are absolute, with a trailing dot. Character strings, e.g. the content of TXT records, are kept quoted.
+//
+// If the zone file has records of types that are not supported by the DNS service, the parsed zone without these records
+// is returned together with an *UnsupportedRecordsError.
+func Parse(r io.Reader, origin string) (*dns.ZoneDataExchange, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read zone file: %w", err)
+	}
+	entries, err := lex(string(input))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		rrSetIndex: map[string]int{},
+	}
+	if origin != "" {
+		p.origin = fqdn(origin)
+	}
+	for i := range entries {
+		if err := p.parseEntry(&entries[i]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", entries[i].line, err)
+		}
+	}
+
+	zone := &dns.ZoneDataExchange{RrSets: &p.rrSets}
+	if len(p.unsupported) > 0 {
+		return zone, &UnsupportedRecordsError{Records: p.unsupported}
+	}
+	return zone, nil
+}
+
+func (p *parser) parseEntry(e *entry) error {
+	if !e.blankOwner && strings.HasPrefix(e.tokens[0].value, "$") {
+		return p.parseDirective(e)
+	}
+
+	tokens := e.tokens
+	owner := p.lastOwner
+	if !e.blankOwner {
+		var err error
+		owner, err = p.absoluteName(tokens[0].value)
+		if err != nil {
+			return err
+		}
+		tokens = tokens[1:]
+	}
+	if owner == "" {
+		return fmt.Errorf("record has no owner")
+	}
+	p.lastOwner = owner
+
+	var ttl *int64
+	class := ""
+	for len(tokens) > 0 && !tokens[0].quoted {
+		if v, err := parseTTL(tokens[0].value); err == nil && ttl == nil {
+			ttl = &v
+		} else if isClass(tokens[0].value) && class == "" {
+			class = strings.ToUpper(tokens[0].value)
+		} else {
+			break
+		}
+		tokens = tokens[1:]
+	}
+	if class != "" && class != "IN" {
+		return fmt.Errorf("class %s isn't supported", class)
+	}
+	if len(tokens) == 0 || tokens[0].quoted {
+		return fmt.Errorf("record type missing")
+	}
+	rrType := strings.ToUpper(tokens[0].value)
+	rdata := tokens[1:]
+
+	if ttl != nil {
+		p.lastTTL = ttl
+	} else if p.defaultTTL != nil {
+		ttl = p.defaultTTL
+	} else if p.lastTTL != nil {
+		ttl = p.lastTTL
+	} else {
+		return fmt.Errorf("record has no TTL and no default TTL is set")
+	}
+
+	if !utils.Contains(SupportedTypes, rrType) {
+		p.unsupported = append(p.unsupported, UnsupportedRecord{Line: e.line, Name: owner, Type: rrType})
+		return nil
+	}
+	if len(rdata) == 0 {
+		return fmt.Errorf("%s record has no content", rrType)
+	}
+	content, err := p.content(rrType, rdata)
+	if err != nil {
+		return err
+	}
+	p.addRecord(owner, rrType, *ttl, content, e.comment)
+	return nil
+}
+
+func (p *parser) parseDirective(e *entry) error {
+	directive := strings.ToUpper(e.tokens[0].value)
+	args := e.tokens[1:]
+	switch directive {
+	case "$ORIGIN":
+		if len(args) != 1 {
+			return fmt.Errorf("$ORIGIN expects one argument")
+		}
+		origin, err := p.absoluteName(args[0].value)
+		if err != nil {
+			return err
+		}
+		p.origin = origin
+	case "$TTL":
+		if len(args) != 1 {
+			return fmt.Errorf("$TTL expects one argument")
+		}
+		ttl, err := parseTTL(args[0].value)
+		if err != nil {
+			return err
+		}
+		p.defaultTTL = &ttl
+	default:
+		return fmt.Errorf("directive %s isn't supported", directive)
+	}
+	return nil
+}
+
+// content returns the content of a record in presentation format, with the domain names made absolute
+func (p *parser) content(rrType string, rdata []token) (string, error) {
+	fields := make([]string, len(rdata))
+	for i, t := range rdata {
+		fields[i] = t.String()
+	}
+	for _, i := range domainNameFields[rrType] {
+		if i >= len(rdata) {
+			return "", fmt.Errorf("%s record has %d fields, expected at least %d", rrType, len(rdata), i+1)
+		}
+		name, err := p.absoluteName(rdata[i].value)
+		if err != nil {
+			return "", err
+		}
+		fields[i] = name
+	}
+	return strings.Join(fields, " "), nil
+}
+
+func (p *parser) addRecord(name, rrType string, ttl int64, content, comment string) {
+	key := strings.ToLower(name) + " " + rrType
+	i, ok := p.rrSetIndex[key]
+	if !ok {
+		rrSet := dns.RecordDataExchange{
+			Name:    &name,
+			Type:    &rrType,
+			Ttl:     &ttl,
+			Content: &[]string{},
+		}
+		if comment != "" {
+			rrSet.Comment = &comment
+		}
+		p.rrSets = append(p.rrSets, rrSet)
+		i = len(p.rrSets) - 1
+		p.rrSetIndex[key] = i
+	}
+	rrSet := &p.rrSets[i]
+	*rrSet.Content = append(*rrSet.Content, content)
+	// All records of a record set share the same TTL, the lowest one is used
+	if ttl < *rrSet.Ttl {
+		rrSet.Ttl = &ttl
+	}
+	if rrSet.Comment == nil && comment != "" {
+		rrSet.Comment = &comment
+	}
+}
+
+func (p *parser) absoluteName(name string) (string, error) {
+	if name == "@" {
+		if p.origin == "" {
+			return "", fmt.Errorf("@ used without origin")
+		}
+		return p.origin, nil
+	}
+	if strings.HasSuffix(name, ".") && !strings.HasSuffix(name, `\.`) {
+		return name, nil
+	}
+	if p.origin == "" {
+		return "", fmt.Errorf("relative name %s used without origin", name)
+	}
+	if p.origin == "." {
+		return name + ".", nil
+	}
+	return name + "." + p.origin, nil
+}
+
+// parseTTL parses a TTL given in seconds or with units, e.g. "3600" or "1h30m"
+func parseTTL(s string) (int64, error) {
+	if v, err := strconv.ParseUint(s, 10, 31); err == nil {
+		return int64(v), nil
+	}
+	units := map[byte]int64{'s': 1, 'm': 60, 'h': 60 * 60, 'd': 24 * 60 * 60, 'w': 7 * 24 * 60 * 60}
+	var ttl, n int64
+	digits := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			n = n*10 + int64(c-'0')
+			digits = true
+		case digits && units[c|0x20] != 0:
+			ttl += n * units[c|0x20]
+			n = 0
+			digits = false
+		default:
+			return 0, fmt.Errorf("invalid TTL %s", s)
+		}
+		if n > 1<<31-1 || ttl > 1<<31-1 {
+			return 0, fmt.Errorf("TTL %s out of range", s)
+		}
+	}
+	if digits || s == "" {
+		return 0, fmt.Errorf("invalid TTL %s", s)
+	}
+	return ttl, nil
+}
+
+func isClass(s string) bool {
+	for _, c := range classes {
+		if strings.EqualFold(s, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
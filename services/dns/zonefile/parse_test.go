@@ -0,0 +1,212 @@
+package zonefile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+const fixtureZoneFile = `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	ns1 hostmaster (
+		2024060701 ; serial
+		7200       ; refresh
+		3600       ; retry
+		1209600    ; expire
+		300 )      ; minimum
+	IN	NS	ns1
+	IN	NS	ns2.example.net.
+ns1	300	IN	A	192.0.2.1
+www	A	192.0.2.10 ; web servers
+	A	192.0.2.11
+mail	IN	1d	MX	10 mx1
+@	TXT	"v=spf1 mx -all"
+txt	TXT	"first string" "second \"quoted\" string"
+_sip._tcp	SRV	10 60 5060 sip
+$ORIGIN sub
+host	AAAA	2001:db8::1
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse(strings.NewReader(fixtureZoneFile), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := &dns.ZoneDataExchange{
+		RrSets: &[]dns.RecordDataExchange{
+			{
+				Name:    utils.Ptr("example.com."),
+				Type:    utils.Ptr("SOA"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{"ns1.example.com. hostmaster.example.com. 2024060701 7200 3600 1209600 300"},
+			},
+			{
+				Name:    utils.Ptr("example.com."),
+				Type:    utils.Ptr("NS"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{"ns1.example.com.", "ns2.example.net."},
+			},
+			{
+				Name:    utils.Ptr("ns1.example.com."),
+				Type:    utils.Ptr("A"),
+				Ttl:     utils.Ptr(int64(300)),
+				Content: &[]string{"192.0.2.1"},
+			},
+			{
+				Name:    utils.Ptr("www.example.com."),
+				Type:    utils.Ptr("A"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{"192.0.2.10", "192.0.2.11"},
+				Comment: utils.Ptr("web servers"),
+			},
+			{
+				Name:    utils.Ptr("mail.example.com."),
+				Type:    utils.Ptr("MX"),
+				Ttl:     utils.Ptr(int64(86400)),
+				Content: &[]string{"10 mx1.example.com."},
+			},
+			{
+				Name:    utils.Ptr("example.com."),
+				Type:    utils.Ptr("TXT"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{`"v=spf1 mx -all"`},
+			},
+			{
+				Name:    utils.Ptr("txt.example.com."),
+				Type:    utils.Ptr("TXT"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{`"first string" "second \"quoted\" string"`},
+			},
+			{
+				Name:    utils.Ptr("_sip._tcp.example.com."),
+				Type:    utils.Ptr("SRV"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{"10 60 5060 sip.example.com."},
+			},
+			{
+				Name:    utils.Ptr("host.sub.example.com."),
+				Type:    utils.Ptr("AAAA"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{"2001:db8::1"},
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected zone (-got +want): %s", diff)
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	zoneFile := `$TTL 300
+www	A	192.0.2.10
+www	HINFO	"PC" "Linux"
+@	TYPE65534	\# 0
+`
+	got, err := Parse(strings.NewReader(zoneFile), "example.com")
+	unsupportedErr := &UnsupportedRecordsError{}
+	if !errors.As(err, &unsupportedErr) {
+		t.Fatalf("Parse error = %v, want UnsupportedRecordsError", err)
+	}
+	wantUnsupported := []UnsupportedRecord{
+		{Line: 3, Name: "www.example.com.", Type: "HINFO"},
+		{Line: 4, Name: "example.com.", Type: "TYPE65534"},
+	}
+	if diff := cmp.Diff(unsupportedErr.Records, wantUnsupported); diff != "" {
+		t.Fatalf("unexpected unsupported records (-got +want): %s", diff)
+	}
+	if got == nil || got.RrSets == nil || len(*got.RrSets) != 1 {
+		t.Fatalf("Parse returned %+v, want the supported record set", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		desc     string
+		zoneFile string
+		origin   string
+	}{
+		{
+			desc:     "no_origin",
+			zoneFile: "$TTL 300\nwww A 192.0.2.1\n",
+		},
+		{
+			desc:     "no_ttl",
+			zoneFile: "www A 192.0.2.1\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "no_owner",
+			zoneFile: "$TTL 300\n  A 192.0.2.1\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "unbalanced_parentheses",
+			zoneFile: "$TTL 300\n@ SOA ns1 hostmaster ( 1 2 3 4 5\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "unterminated_string",
+			zoneFile: "$TTL 300\n@ TXT \"foo\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "include",
+			zoneFile: "$INCLUDE other.zone\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "other_class",
+			zoneFile: "$TTL 300\nwww CH A 192.0.2.1\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "invalid_ttl",
+			zoneFile: "$TTL 1x\n",
+			origin:   "example.com",
+		},
+		{
+			desc:     "missing_content",
+			zoneFile: "$TTL 300\nwww MX 10\n",
+			origin:   "example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.zoneFile), tt.origin)
+			if err == nil {
+				t.Fatalf("Parse returned no error")
+			}
+		})
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "3600", want: 3600},
+		{value: "1h30m", want: 5400},
+		{value: "1W2D", want: 777600},
+		{value: "", wantErr: true},
+		{value: "h", wantErr: true},
+		{value: "1h30", wantErr: true},
+		{value: "A", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTTL(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTTL error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseTTL = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
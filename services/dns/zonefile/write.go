@@ -0,0 +1,152 @@
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+// Maximum length of a character string in bytes, longer strings are split
+const maxCharacterStringLength = 255
+
+// Write writes the record sets as a zone file in master file format (RFC 1035 section 5), e.g. the output of ExportRecordSets.
+// Names are written relative to origin, which is set with $ORIGIN. Names of the record sets without a trailing dot
+// are considered to be relative to origin, unless they end with it.
+// The SOA record set is written first, the others are written in the given order.
+func Write(w io.Writer, origin string, zone *dns.ZoneDataExchange) error {
+	if origin == "" {
+		return fmt.Errorf("origin must be set")
+	}
+	origin = fqdn(origin)
+
+	rrSets := []dns.RecordDataExchange{}
+	if zone != nil && zone.RrSets != nil {
+		rrSets = *zone.RrSets
+	}
+	ordered := make([]dns.RecordDataExchange, 0, len(rrSets))
+	for i := range rrSets {
+		if rrSets[i].Type != nil && strings.EqualFold(*rrSets[i].Type, "SOA") {
+			ordered = append([]dns.RecordDataExchange{rrSets[i]}, ordered...)
+		} else {
+			ordered = append(ordered, rrSets[i])
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$ORIGIN %s\n", origin)
+	for i := range ordered {
+		if err := writeRecordSet(bw, origin, &ordered[i]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteRecordSets writes record sets returned by ListRecordSets as a zone file, see Write.
+// Record sets that are not active are left out.
+func WriteRecordSets(w io.Writer, origin string, rrSets []dns.RecordSet) error {
+	return Write(w, origin, FromRecordSets(rrSets))
+}
+
+// FromRecordSets converts record sets returned by ListRecordSets to the format used by ImportRecordSets and ExportRecordSets.
+// Record sets that are not active are left out.
+func FromRecordSets(rrSets []dns.RecordSet) *dns.ZoneDataExchange {
+	exchange := []dns.RecordDataExchange{}
+	for i := range rrSets {
+		rrSet := rrSets[i]
+		if rrSet.Active != nil && !*rrSet.Active {
+			continue
+		}
+		content := []string{}
+		if rrSet.Records != nil {
+			for _, r := range *rrSet.Records {
+				if r.Content != nil {
+					content = append(content, *r.Content)
+				}
+			}
+		}
+		exchange = append(exchange, dns.RecordDataExchange{
+			Comment: rrSet.Comment,
+			Content: &content,
+			Name:    rrSet.Name,
+			Ttl:     rrSet.Ttl,
+			Type:    rrSet.Type,
+		})
+	}
+	return &dns.ZoneDataExchange{RrSets: &exchange}
+}
+
+func writeRecordSet(w io.Writer, origin string, rrSet *dns.RecordDataExchange) error {
+	if rrSet.Name == nil || rrSet.Type == nil || rrSet.Ttl == nil {
+		return fmt.Errorf("record set name, type and TTL must be set")
+	}
+	if rrSet.Content == nil || len(*rrSet.Content) == 0 {
+		return fmt.Errorf("record set %s %s has no content", *rrSet.Name, *rrSet.Type)
+	}
+	name := relativeName(*rrSet.Name, origin)
+	rrType := strings.ToUpper(*rrSet.Type)
+	for i, content := range *rrSet.Content {
+		if rrType == "TXT" {
+			content = quoteCharacterStrings(content)
+		}
+		line := fmt.Sprintf("%s\t%d\tIN\t%s\t%s", name, *rrSet.Ttl, rrType, content)
+		if i == 0 && rrSet.Comment != nil && *rrSet.Comment != "" {
+			line = fmt.Sprintf("%s ; %s", line, strings.ReplaceAll(*rrSet.Comment, "\n", " "))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relativeName returns name relative to origin, or "@" for origin itself
+func relativeName(name, origin string) string {
+	if !strings.HasSuffix(name, ".") {
+		if !strings.HasSuffix(fqdn(name), "."+origin) && fqdn(name) != origin {
+			return name
+		}
+		name = fqdn(name)
+	}
+	if strings.EqualFold(name, origin) {
+		return "@"
+	}
+	if len(name) > len(origin) && strings.EqualFold(name[len(name)-len(origin)-1:], "."+origin) {
+		return name[:len(name)-len(origin)-1]
+	}
+	return name
+}
+
+// quoteCharacterStrings quotes content that isn't quoted yet, splitting it into strings of at most 255 bytes
+// without splitting UTF-8 characters. The strings are escaped once split, so escape sequences are never cut in half.
+func quoteCharacterStrings(content string) string {
+	if strings.HasPrefix(content, `"`) {
+		return content
+	}
+	parts := []string{}
+	for len(content) > maxCharacterStringLength {
+		n := maxCharacterStringLength
+		for n > 0 && !utf8.RuneStart(content[n]) {
+			n--
+		}
+		if n == 0 {
+			// Not valid UTF-8, split at the byte limit
+			n = maxCharacterStringLength
+		}
+		parts = append(parts, quoteCharacterString(content[:n]))
+		content = content[n:]
+	}
+	parts = append(parts, quoteCharacterString(content))
+	return strings.Join(parts, " ")
+}
+
+// quoteCharacterString returns the value as a quoted character string, escaping quotes and backslashes
+func quoteCharacterString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
@@ -0,0 +1,216 @@
+package zonefile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/dns"
+)
+
+func TestWrite(t *testing.T) {
+	zone := &dns.ZoneDataExchange{
+		RrSets: &[]dns.RecordDataExchange{
+			{
+				Name:    utils.Ptr("www.example.com."),
+				Type:    utils.Ptr("A"),
+				Ttl:     utils.Ptr(int64(300)),
+				Content: &[]string{"192.0.2.10", "192.0.2.11"},
+				Comment: utils.Ptr("web servers"),
+			},
+			{
+				Name:    utils.Ptr("example.com"),
+				Type:    utils.Ptr("SOA"),
+				Ttl:     utils.Ptr(int64(3600)),
+				Content: &[]string{"ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300"},
+			},
+			{
+				Name:    utils.Ptr("txt"),
+				Type:    utils.Ptr("txt"),
+				Ttl:     utils.Ptr(int64(300)),
+				Content: &[]string{`say "hi"`, `"already quoted"`},
+			},
+			{
+				Name:    utils.Ptr("other.example.net."),
+				Type:    utils.Ptr("CNAME"),
+				Ttl:     utils.Ptr(int64(300)),
+				Content: &[]string{"www.example.com."},
+			},
+		},
+	}
+
+	var b strings.Builder
+	if err := Write(&b, "example.com", zone); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := `$ORIGIN example.com.
+@	3600	IN	SOA	ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300
+www	300	IN	A	192.0.2.10 ; web servers
+www	300	IN	A	192.0.2.11
+txt	300	IN	TXT	"say \"hi\""
+txt	300	IN	TXT	"already quoted"
+other.example.net.	300	IN	CNAME	www.example.com.
+`
+	if diff := cmp.Diff(b.String(), want); diff != "" {
+		t.Fatalf("unexpected zone file (-got +want): %s", diff)
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	zone, err := Parse(strings.NewReader(fixtureZoneFile), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var b strings.Builder
+	if err := Write(&b, "example.com.", zone); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Parse(strings.NewReader(b.String()), "")
+	if err != nil {
+		t.Fatalf("Parse written zone file: %v", err)
+	}
+	if diff := cmp.Diff(got, zone); diff != "" {
+		t.Fatalf("round trip changed the zone (-got +want): %s", diff)
+	}
+}
+
+func TestWriteRecordSets(t *testing.T) {
+	rrSets := []dns.RecordSet{
+		{
+			Active: utils.Ptr(true),
+			Name:   utils.Ptr("www.example.com."),
+			Type:   utils.Ptr("A"),
+			Ttl:    utils.Ptr(int64(300)),
+			Records: &[]dns.Record{
+				{Content: utils.Ptr("192.0.2.10")},
+			},
+		},
+		{
+			Active: utils.Ptr(false),
+			Name:   utils.Ptr("old.example.com."),
+			Type:   utils.Ptr("A"),
+			Ttl:    utils.Ptr(int64(300)),
+			Records: &[]dns.Record{
+				{Content: utils.Ptr("192.0.2.20")},
+			},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteRecordSets(&b, "example.com.", rrSets); err != nil {
+		t.Fatalf("WriteRecordSets: %v", err)
+	}
+	want := "$ORIGIN example.com.\nwww\t300\tIN\tA\t192.0.2.10\n"
+	if diff := cmp.Diff(b.String(), want); diff != "" {
+		t.Fatalf("unexpected zone file (-got +want): %s", diff)
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	tests := []struct {
+		desc   string
+		origin string
+		rrSet  dns.RecordDataExchange
+	}{
+		{
+			desc:   "no_origin",
+			origin: "",
+			rrSet: dns.RecordDataExchange{
+				Name: utils.Ptr("www"), Type: utils.Ptr("A"), Ttl: utils.Ptr(int64(300)), Content: &[]string{"192.0.2.1"},
+			},
+		},
+		{
+			desc:   "no_ttl",
+			origin: "example.com",
+			rrSet: dns.RecordDataExchange{
+				Name: utils.Ptr("www"), Type: utils.Ptr("A"), Content: &[]string{"192.0.2.1"},
+			},
+		},
+		{
+			desc:   "no_content",
+			origin: "example.com",
+			rrSet: dns.RecordDataExchange{
+				Name: utils.Ptr("www"), Type: utils.Ptr("A"), Ttl: utils.Ptr(int64(300)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var b strings.Builder
+			err := Write(&b, tt.origin, &dns.ZoneDataExchange{RrSets: &[]dns.RecordDataExchange{tt.rrSet}})
+			if err == nil {
+				t.Fatalf("Write returned no error")
+			}
+		})
+	}
+}
+
+func TestWriteLongTXT(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+	}{
+		{"backslashes_across_boundary", "a" + strings.Repeat(`\`, 300)},
+		{"backslash_at_boundary", strings.Repeat("a", 254) + `\` + strings.Repeat("b", 10)},
+		{"quotes_across_boundary", strings.Repeat("a", 250) + strings.Repeat(`"`, 20)},
+		{"multi_byte_across_boundary", strings.Repeat("a", 254) + "äöü" + strings.Repeat(`\`, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			zone := &dns.ZoneDataExchange{RrSets: &[]dns.RecordDataExchange{{
+				Name:    utils.Ptr("txt"),
+				Type:    utils.Ptr("TXT"),
+				Ttl:     utils.Ptr(int64(300)),
+				Content: &[]string{tt.content},
+			}}}
+			var b strings.Builder
+			if err := Write(&b, "example.com.", zone); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			parsed, err := Parse(strings.NewReader(b.String()), "")
+			if err != nil {
+				t.Fatalf("Parse written zone file: %v", err)
+			}
+			content := (*(*parsed.RrSets)[0].Content)[0]
+			strs := unquoteCharacterStrings(t, content)
+			for _, s := range strs {
+				if len(s) > maxCharacterStringLength {
+					t.Fatalf("character string of %d bytes in %s", len(s), content)
+				}
+			}
+			if got := strings.Join(strs, ""); got != tt.content {
+				t.Fatalf("round trip changed the content to %q", got)
+			}
+		})
+	}
+}
+
+// unquoteCharacterStrings returns the unescaped values of the quoted character strings of content
+func unquoteCharacterStrings(t *testing.T, content string) []string {
+	t.Helper()
+	strs := []string{}
+	var current strings.Builder
+	inQuotes := false
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case inQuotes && c == '\\':
+			i++
+			current.WriteByte(content[i])
+		case c == '"':
+			if inQuotes {
+				strs = append(strs, current.String())
+				current.Reset()
+			}
+			inQuotes = !inQuotes
+		case inQuotes:
+			current.WriteByte(c)
+		case c != ' ':
+			t.Fatalf("text outside of quotes in %s", content)
+		}
+	}
+	if inQuotes {
+		t.Fatalf("unterminated quote in %s", content)
+	}
+	return strs
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
 	"github.com/stackitcloud/stackit-sdk-go/core/wait"
 	"github.com/stackitcloud/stackit-sdk-go/services/iaas"
 )
@@ -24,39 +23,21 @@ type APIClientInterface interface {
 
 // CreateNetworkAreaWaitHandler will wait for network area creation
 func CreateNetworkAreaWaitHandler(ctx context.Context, a APIClientInterface, organizationId, areaId string) *wait.AsyncActionHandler[iaas.NetworkArea] {
-	handler := wait.New(func() (waitFinished bool, response *iaas.NetworkArea, err error) {
-		area, err := a.GetNetworkAreaExecute(ctx, organizationId, areaId)
-		if err != nil {
-			return false, area, err
-		}
-		if area.AreaId == nil || area.State == nil {
-			return false, area, fmt.Errorf("create failed for network area with id %s, the response is not valid: the id or the state are missing", areaId)
-		}
-		if *area.AreaId == areaId && *area.State == CreateSuccess {
-			return true, area, nil
-		}
-		return false, area, nil
-	})
+	handler := wait.ForState(getNetworkArea(ctx, a, organizationId, areaId), networkAreaState("create", areaId)).
+		Success(CreateSuccess).
+		KeepLastResponse().
+		Handler()
 	handler.SetTimeout(10 * time.Minute)
 	return handler
 }
 
 // UpdateNetworkAreaWaitHandler will wait for network area update
 func UpdateNetworkAreaWaitHandler(ctx context.Context, a APIClientInterface, organizationId, areaId string) *wait.AsyncActionHandler[iaas.NetworkArea] {
-	handler := wait.New(func() (waitFinished bool, response *iaas.NetworkArea, err error) {
-		area, err := a.GetNetworkAreaExecute(ctx, organizationId, areaId)
-		if err != nil {
-			return false, area, err
-		}
-		if area.AreaId == nil || area.State == nil {
-			return false, nil, fmt.Errorf("update failed for network area with id %s, the response is not valid: the id or the state are missing", areaId)
-		}
-		// The state returns to "CREATED" after a successful update is completed
-		if *area.AreaId == areaId && *area.State == CreateSuccess {
-			return true, area, nil
-		}
-		return false, area, nil
-	})
+	// The state returns to "CREATED" after a successful update is completed
+	handler := wait.ForState(getNetworkArea(ctx, a, organizationId, areaId), networkAreaState("update", areaId)).
+		Success(CreateSuccess).
+		KeepLastResponse().
+		Handler()
 	handler.SetSleepBeforeWait(2 * time.Second)
 	handler.SetTimeout(10 * time.Minute)
 	return handler
@@ -64,47 +45,33 @@ func UpdateNetworkAreaWaitHandler(ctx context.Context, a APIClientInterface, org
 
 // DeleteNetworkAreaWaitHandler will wait for network area deletion
 func DeleteNetworkAreaWaitHandler(ctx context.Context, a APIClientInterface, organizationId, areaId string) *wait.AsyncActionHandler[iaas.NetworkArea] {
-	handler := wait.New(func() (waitFinished bool, response *iaas.NetworkArea, err error) {
-		area, err := a.GetNetworkAreaExecute(ctx, organizationId, areaId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, area, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError: %w", err)
-		}
-		if oapiErr.StatusCode != http.StatusNotFound {
-			return false, area, err
-		}
-		return true, nil, nil
-	})
+	handler := wait.ForState(getNetworkArea(ctx, a, organizationId, areaId), nil).
+		GoneIsSuccess(http.StatusNotFound).
+		KeepLastResponse().
+		Handler()
 	handler.SetTimeout(10 * time.Minute)
 	return handler
 }
 
 // CreateNetworkWaitHandler will wait for network creation
 func CreateNetworkWaitHandler(ctx context.Context, a APIClientInterface, projectId, requestId string) *wait.AsyncActionHandler[iaas.Network] {
-	handler := wait.New(func() (waitFinished bool, response *iaas.Network, err error) {
+	var networkId string
+	handler := wait.ForState(func() (*iaas.Network, error) {
 		request, err := a.GetProjectRequestExecute(ctx, projectId, requestId)
 		if err != nil {
-			return false, nil, err
+			return nil, err
 		}
 		if request == nil || request.Resources == nil || len(*request.Resources) == 0 || (*request.Resources)[0].Id == nil {
-			return false, nil, fmt.Errorf("no resources found for request with id %s", requestId)
-		}
-		networkId := *(*request.Resources)[0].Id
-		network, err := a.GetNetworkExecute(ctx, projectId, networkId)
-		if err != nil {
-			return false, network, err
-		}
-		if network.NetworkId == nil || network.State == nil {
-			return false, network, fmt.Errorf("create failed for network with id %s, the response is not valid: the id or the state are missing", networkId)
-		}
-		if *network.NetworkId == networkId && *network.State == CreateSuccess {
-			return true, network, nil
-		}
-		return false, network, nil
-	})
+			return nil, fmt.Errorf("no resources found for request with id %s", requestId)
+		}
+		networkId = *(*request.Resources)[0].Id
+		return a.GetNetworkExecute(ctx, projectId, networkId)
+	}, func(network *iaas.Network) (string, error) {
+		return networkState("create", networkId)(network)
+	}).
+		Success(CreateSuccess).
+		KeepLastResponse().
+		Handler()
 	handler.SetSleepBeforeWait(2 * time.Second)
 	handler.SetTimeout(10 * time.Minute)
 	return handler
@@ -112,20 +79,11 @@ func CreateNetworkWaitHandler(ctx context.Context, a APIClientInterface, project
 
 // UpdateNetworkWaitHandler will wait for network update
 func UpdateNetworkWaitHandler(ctx context.Context, a APIClientInterface, projectId, networkId string) *wait.AsyncActionHandler[iaas.Network] {
-	handler := wait.New(func() (waitFinished bool, response *iaas.Network, err error) {
-		network, err := a.GetNetworkExecute(ctx, projectId, networkId)
-		if err != nil {
-			return false, network, err
-		}
-		if network.NetworkId == nil || network.State == nil {
-			return false, network, fmt.Errorf("update failed for network area with id %s, the response is not valid: the id or the state are missing", networkId)
-		}
-		// The state returns to "CREATED" after a successful update is completed
-		if *network.NetworkId == networkId && *network.State == CreateSuccess {
-			return true, network, nil
-		}
-		return false, network, nil
-	})
+	// The state returns to "CREATED" after a successful update is completed
+	handler := wait.ForState(getNetwork(ctx, a, projectId, networkId), networkState("update", networkId)).
+		Success(CreateSuccess).
+		KeepLastResponse().
+		Handler()
 	handler.SetSleepBeforeWait(2 * time.Second)
 	handler.SetTimeout(10 * time.Minute)
 	return handler
@@ -133,20 +91,48 @@ func UpdateNetworkWaitHandler(ctx context.Context, a APIClientInterface, project
 
 // DeleteNetworkWaitHandler will wait for network deletion
 func DeleteNetworkWaitHandler(ctx context.Context, a APIClientInterface, projectId, networkId string) *wait.AsyncActionHandler[iaas.Network] {
-	handler := wait.New(func() (waitFinished bool, response *iaas.Network, err error) {
-		network, err := a.GetNetworkExecute(ctx, projectId, networkId)
-		if err == nil {
-			return false, nil, nil
+	handler := wait.ForState(getNetwork(ctx, a, projectId, networkId), nil).
+		GoneIsSuccess(http.StatusNotFound).
+		KeepLastResponse().
+		Handler()
+	handler.SetTimeout(10 * time.Minute)
+	return handler
+}
+
+func getNetworkArea(ctx context.Context, a APIClientInterface, organizationId, areaId string) wait.GetFunc[iaas.NetworkArea] {
+	return func() (*iaas.NetworkArea, error) {
+		return a.GetNetworkAreaExecute(ctx, organizationId, areaId)
+	}
+}
+
+func getNetwork(ctx context.Context, a APIClientInterface, projectId, networkId string) wait.GetFunc[iaas.Network] {
+	return func() (*iaas.Network, error) {
+		return a.GetNetworkExecute(ctx, projectId, networkId)
+	}
+}
+
+// networkAreaState returns the state of the network area, or an empty state while the response is for another network area
+func networkAreaState(operation, areaId string) wait.StateFunc[iaas.NetworkArea] {
+	return func(area *iaas.NetworkArea) (string, error) {
+		if area.AreaId == nil || area.State == nil {
+			return "", fmt.Errorf("%s failed for network area with id %s, the response is not valid: the id or the state are missing", operation, areaId)
 		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, network, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError: %w", err)
+		if *area.AreaId != areaId {
+			return "", nil
 		}
-		if oapiErr.StatusCode != http.StatusNotFound {
-			return false, network, err
+		return *area.State, nil
+	}
+}
+
+// networkState returns the state of the network, or an empty state while the response is for another network
+func networkState(operation, networkId string) wait.StateFunc[iaas.Network] {
+	return func(network *iaas.Network) (string, error) {
+		if network.NetworkId == nil || network.State == nil {
+			return "", fmt.Errorf("%s failed for network with id %s, the response is not valid: the id or the state are missing", operation, networkId)
 		}
-		return true, nil, nil
-	})
-	handler.SetTimeout(10 * time.Minute)
-	return handler
+		if *network.NetworkId != networkId {
+			return "", nil
+		}
+		return *network.State, nil
+	}
 }
@@ -0,0 +1,200 @@
+// Package observability wires the observability options of load balancers: it provisions and rotates the credentials the load
+// balancers push their logs and metrics with, attaches them to the load balancer options, and deletes the credentials no load
+// balancer references anymore.
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer/payload"
+)
+
+// Target is where load balancers push their logs and metrics to, typically an Argus instance.
+// With an Argus telemetry bundle, the URLs are Endpoints.LogsPushUrl and Endpoints.PushMetricsUrl, and the username and
+// password are the ones of its Credentials.
+type Target struct {
+	// DisplayName is the name of the credentials in the Load Balancer API, which identifies them when they are rotated
+	DisplayName string
+	Username    string
+	Password    string
+	// LogsPushUrl is the URL logs are pushed to, logs aren't pushed if empty
+	LogsPushUrl string
+	// MetricsPushUrl is the URL metrics are pushed to, metrics aren't pushed if empty
+	MetricsPushUrl string
+}
+
+// apiClient is the part of the Load Balancer API used by this package.
+type apiClient interface {
+	ListCredentialsExecute(ctx context.Context, projectId string) (*loadbalancer.ListCredentialsResponse, error)
+	DeleteCredentialsExecute(ctx context.Context, projectId, credentialsRef string) (map[string]interface{}, error)
+	ListLoadBalancersExecute(ctx context.Context, projectId string) (*loadbalancer.ListLoadBalancersResponse, error)
+	createCredentials(ctx context.Context, projectId string, payload loadbalancer.CreateCredentialsPayload) (*loadbalancer.CreateCredentialsResponse, error)
+	updateCredentials(ctx context.Context, projectId, credentialsRef string, payload loadbalancer.UpdateCredentialsPayload) error
+	updateLoadBalancer(ctx context.Context, projectId, name string, mutate func(p *loadbalancer.UpdateLoadBalancerPayload) error) (previousVersion string, err error)
+}
+
+type apiClientAdapter struct {
+	*loadbalancer.APIClient
+}
+
+func (a apiClientAdapter) createCredentials(ctx context.Context, projectId string, payload loadbalancer.CreateCredentialsPayload) (*loadbalancer.CreateCredentialsResponse, error) {
+	return a.CreateCredentials(ctx, projectId).CreateCredentialsPayload(payload).Execute()
+}
+
+func (a apiClientAdapter) updateCredentials(ctx context.Context, projectId, credentialsRef string, payload loadbalancer.UpdateCredentialsPayload) error {
+	_, err := a.UpdateCredentials(ctx, projectId, credentialsRef).UpdateCredentialsPayload(payload).Execute()
+	return err
+}
+
+func (a apiClientAdapter) updateLoadBalancer(ctx context.Context, projectId, name string, mutate func(p *loadbalancer.UpdateLoadBalancerPayload) error) (string, error) {
+	return payload.Update(ctx, a.APIClient, projectId, name, mutate)
+}
+
+// EnsureCredentials returns the reference of the credentials with the display name of the target, created with its username and
+// password if there are none. Existing credentials are updated to the username and password of the target, so rotated passwords
+// take effect on all load balancers using them.
+func EnsureCredentials(ctx context.Context, client *loadbalancer.APIClient, projectId string, target Target) (credentialsRef string, err error) {
+	return ensureCredentials(ctx, apiClientAdapter{client}, projectId, target)
+}
+
+func ensureCredentials(ctx context.Context, a apiClient, projectId string, target Target) (string, error) {
+	if target.DisplayName == "" || target.Username == "" || target.Password == "" {
+		return "", fmt.Errorf("display name, username and password of the target are required")
+	}
+	resp, err := a.ListCredentialsExecute(ctx, projectId)
+	if err != nil {
+		return "", fmt.Errorf("list credentials: %w", err)
+	}
+	for _, creds := range valueOf(resp.Credentials) {
+		if valueOf(creds.DisplayName) != target.DisplayName {
+			continue
+		}
+		credentialsRef := valueOf(creds.CredentialsRef)
+		err := a.updateCredentials(ctx, projectId, credentialsRef, loadbalancer.UpdateCredentialsPayload{
+			DisplayName: &target.DisplayName,
+			Username:    &target.Username,
+			Password:    &target.Password,
+		})
+		if err != nil {
+			return "", fmt.Errorf("update credentials %s: %w", credentialsRef, err)
+		}
+		return credentialsRef, nil
+	}
+
+	created, err := a.createCredentials(ctx, projectId, loadbalancer.CreateCredentialsPayload{
+		DisplayName: &target.DisplayName,
+		Username:    &target.Username,
+		Password:    &target.Password,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create credentials: %w", err)
+	}
+	if created == nil || created.Credential == nil || valueOf(created.Credential.CredentialsRef) == "" {
+		return "", fmt.Errorf("create credentials: no credentials reference returned")
+	}
+	return *created.Credential.CredentialsRef, nil
+}
+
+// Attach returns the options with the logs and metrics pushed to the URLs of the target with the credentials.
+// options may be nil, e.g. for a payload without options, and isn't modified.
+func Attach(options *loadbalancer.LoadBalancerOptions, credentialsRef string, target Target) *loadbalancer.LoadBalancerOptions {
+	attached := &loadbalancer.LoadBalancerOptions{}
+	if options != nil {
+		*attached = *options
+	}
+	observability := &loadbalancer.LoadbalancerOptionObservability{}
+	if target.LogsPushUrl != "" {
+		observability.Logs = &loadbalancer.LoadbalancerOptionLogs{CredentialsRef: &credentialsRef, PushUrl: &target.LogsPushUrl}
+	}
+	if target.MetricsPushUrl != "" {
+		observability.Metrics = &loadbalancer.LoadbalancerOptionMetrics{CredentialsRef: &credentialsRef, PushUrl: &target.MetricsPushUrl}
+	}
+	attached.Observability = observability
+	return attached
+}
+
+// Wire ensures the credentials of the target and updates the load balancer to push its logs and metrics to the target with them.
+// It returns the version the update was calculated for, to be passed to wait.UpdateLoadBalancerWaitHandler.
+// The credentials the load balancer used before are left in place, see CollectGarbage.
+func Wire(ctx context.Context, client *loadbalancer.APIClient, projectId, loadBalancerName string, target Target) (previousVersion string, err error) {
+	return wire(ctx, apiClientAdapter{client}, projectId, loadBalancerName, target)
+}
+
+func wire(ctx context.Context, a apiClient, projectId, loadBalancerName string, target Target) (string, error) {
+	credentialsRef, err := ensureCredentials(ctx, a, projectId, target)
+	if err != nil {
+		return "", err
+	}
+	previousVersion, err := a.updateLoadBalancer(ctx, projectId, loadBalancerName, func(p *loadbalancer.UpdateLoadBalancerPayload) error {
+		p.Options = Attach(p.Options, credentialsRef, target)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("attach credentials %s to load balancer %s: %w", credentialsRef, loadBalancerName, err)
+	}
+	return previousVersion, nil
+}
+
+// CollectGarbage deletes the credentials of the project that no load balancer references, except the ones to keep, and returns
+// their references. Credentials created but not attached yet by concurrent callers must be kept, or they will be deleted.
+func CollectGarbage(ctx context.Context, client *loadbalancer.APIClient, projectId string, keep ...string) (deleted []string, err error) {
+	return collectGarbage(ctx, apiClientAdapter{client}, projectId, keep...)
+}
+
+func collectGarbage(ctx context.Context, a apiClient, projectId string, keep ...string) ([]string, error) {
+	referenced := map[string]bool{}
+	for _, ref := range keep {
+		referenced[ref] = true
+	}
+	lbs, err := a.ListLoadBalancersExecute(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("list load balancers: %w", err)
+	}
+	for _, lb := range valueOf(lbs.LoadBalancers) {
+		if lb.Options == nil || lb.Options.Observability == nil {
+			continue
+		}
+		if logs := lb.Options.Observability.Logs; logs != nil {
+			referenced[valueOf(logs.CredentialsRef)] = true
+		}
+		if metrics := lb.Options.Observability.Metrics; metrics != nil {
+			referenced[valueOf(metrics.CredentialsRef)] = true
+		}
+	}
+
+	creds, err := a.ListCredentialsExecute(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("list credentials: %w", err)
+	}
+	deleted := []string{}
+	for _, c := range valueOf(creds.Credentials) {
+		credentialsRef := valueOf(c.CredentialsRef)
+		if credentialsRef == "" || referenced[credentialsRef] {
+			continue
+		}
+		_, err := a.DeleteCredentialsExecute(ctx, projectId, credentialsRef)
+		if err != nil && !isNotFound(err) {
+			return deleted, fmt.Errorf("delete credentials %s: %w", credentialsRef, err)
+		}
+		deleted = append(deleted, credentialsRef)
+	}
+	return deleted, nil
+}
+
+func isNotFound(err error) bool {
+	var oapiErr *oapierror.GenericOpenAPIError
+	return errors.As(err, &oapiErr) && oapiErr.StatusCode == http.StatusNotFound
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,287 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+type apiClientMocked struct {
+	credentials   []loadbalancer.CredentialsResponse
+	passwords     map[string]string
+	loadBalancers []loadbalancer.LoadBalancer
+	createFails   bool
+	updateFails   bool
+	deleteFails   bool
+	calls         []string
+}
+
+func (a *apiClientMocked) ListCredentialsExecute(_ context.Context, _ string) (*loadbalancer.ListCredentialsResponse, error) {
+	creds := append([]loadbalancer.CredentialsResponse{}, a.credentials...)
+	return &loadbalancer.ListCredentialsResponse{Credentials: &creds}, nil
+}
+
+func (a *apiClientMocked) DeleteCredentialsExecute(_ context.Context, _, credentialsRef string) (map[string]interface{}, error) {
+	a.calls = append(a.calls, "delete credentials "+credentialsRef)
+	if a.deleteFails {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusInternalServerError}
+	}
+	if credentialsRef == "gone" {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusNotFound}
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (a *apiClientMocked) ListLoadBalancersExecute(_ context.Context, _ string) (*loadbalancer.ListLoadBalancersResponse, error) {
+	lbs := append([]loadbalancer.LoadBalancer{}, a.loadBalancers...)
+	return &loadbalancer.ListLoadBalancersResponse{LoadBalancers: &lbs}, nil
+}
+
+func (a *apiClientMocked) createCredentials(_ context.Context, _ string, payload loadbalancer.CreateCredentialsPayload) (*loadbalancer.CreateCredentialsResponse, error) {
+	a.calls = append(a.calls, "create credentials "+*payload.DisplayName)
+	if a.createFails {
+		return nil, fmt.Errorf("create fails")
+	}
+	creds := loadbalancer.CredentialsResponse{
+		CredentialsRef: utils.Ptr(fmt.Sprintf("credentials-%d", len(a.credentials))),
+		DisplayName:    payload.DisplayName,
+		Username:       payload.Username,
+	}
+	a.credentials = append(a.credentials, creds)
+	a.passwords[*creds.CredentialsRef] = *payload.Password
+	return &loadbalancer.CreateCredentialsResponse{Credential: &creds}, nil
+}
+
+func (a *apiClientMocked) updateCredentials(_ context.Context, _, credentialsRef string, payload loadbalancer.UpdateCredentialsPayload) error {
+	a.calls = append(a.calls, "update credentials "+credentialsRef)
+	if a.updateFails {
+		return fmt.Errorf("update fails")
+	}
+	a.passwords[credentialsRef] = *payload.Password
+	return nil
+}
+
+func (a *apiClientMocked) updateLoadBalancer(_ context.Context, _, name string, mutate func(p *loadbalancer.UpdateLoadBalancerPayload) error) (string, error) {
+	a.calls = append(a.calls, "update load balancer "+name)
+	for i, lb := range a.loadBalancers {
+		if *lb.Name != name {
+			continue
+		}
+		p := loadbalancer.UpdateLoadBalancerPayload(lb)
+		if err := mutate(&p); err != nil {
+			return "", err
+		}
+		a.loadBalancers[i] = loadbalancer.LoadBalancer(p)
+		return *lb.Version, nil
+	}
+	return "", &oapierror.GenericOpenAPIError{StatusCode: http.StatusNotFound}
+}
+
+func fixtureTarget() Target {
+	return Target{
+		DisplayName:    "argus",
+		Username:       "user",
+		Password:       "secret",
+		LogsPushUrl:    "https://logs.example.com/push",
+		MetricsPushUrl: "https://metrics.example.com/push",
+	}
+}
+
+func TestEnsureCredentials(t *testing.T) {
+	tests := []struct {
+		desc        string
+		credentials []loadbalancer.CredentialsResponse
+		target      Target
+		createFails bool
+		updateFails bool
+		want        string
+		wantCalls   []string
+		wantErr     bool
+	}{
+		{
+			desc:      "create",
+			target:    fixtureTarget(),
+			want:      "credentials-0",
+			wantCalls: []string{"create credentials argus"},
+		},
+		{
+			desc: "rotate",
+			credentials: []loadbalancer.CredentialsResponse{
+				{CredentialsRef: utils.Ptr("other"), DisplayName: utils.Ptr("other")},
+				{CredentialsRef: utils.Ptr("existing"), DisplayName: utils.Ptr("argus")},
+			},
+			target:    fixtureTarget(),
+			want:      "existing",
+			wantCalls: []string{"update credentials existing"},
+		},
+		{
+			desc:        "create_fails",
+			target:      fixtureTarget(),
+			createFails: true,
+			wantCalls:   []string{"create credentials argus"},
+			wantErr:     true,
+		},
+		{
+			desc:        "update_fails",
+			credentials: []loadbalancer.CredentialsResponse{{CredentialsRef: utils.Ptr("existing"), DisplayName: utils.Ptr("argus")}},
+			target:      fixtureTarget(),
+			updateFails: true,
+			wantCalls:   []string{"update credentials existing"},
+			wantErr:     true,
+		},
+		{
+			desc:    "no_password",
+			target:  Target{DisplayName: "argus", Username: "user"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				credentials: tt.credentials,
+				passwords:   map[string]string{},
+				createFails: tt.createFails,
+				updateFails: tt.updateFails,
+			}
+			got, err := ensureCredentials(context.Background(), a, "pid", tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureCredentials error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ensureCredentials = %q, want %q", got, tt.want)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+			if !tt.wantErr && a.passwords[got] != tt.target.Password {
+				t.Fatalf("credentials %s have password %q, want %q", got, a.passwords[got], tt.target.Password)
+			}
+		})
+	}
+}
+
+func TestAttach(t *testing.T) {
+	options := &loadbalancer.LoadBalancerOptions{EphemeralAddress: utils.Ptr(true)}
+	target := fixtureTarget()
+	target.LogsPushUrl = ""
+
+	got := Attach(options, "ref", target)
+
+	want := &loadbalancer.LoadBalancerOptions{
+		EphemeralAddress: utils.Ptr(true),
+		Observability: &loadbalancer.LoadbalancerOptionObservability{
+			Metrics: &loadbalancer.LoadbalancerOptionMetrics{CredentialsRef: utils.Ptr("ref"), PushUrl: utils.Ptr("https://metrics.example.com/push")},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected options (-got +want): %s", diff)
+	}
+	if options.Observability != nil {
+		t.Fatalf("Attach modified the options")
+	}
+	if got := Attach(nil, "ref", target); got.Observability == nil {
+		t.Fatalf("Attach(nil) = %v, want observability options", got)
+	}
+}
+
+func TestWire(t *testing.T) {
+	a := &apiClientMocked{
+		passwords: map[string]string{},
+		loadBalancers: []loadbalancer.LoadBalancer{
+			{Name: utils.Ptr("lb"), Version: utils.Ptr("v1"), Options: &loadbalancer.LoadBalancerOptions{PrivateNetworkOnly: utils.Ptr(true)}},
+		},
+	}
+
+	gotVersion, err := wire(context.Background(), a, "pid", "lb", fixtureTarget())
+	if err != nil {
+		t.Fatalf("wire: %v", err)
+	}
+	if gotVersion != "v1" {
+		t.Fatalf("wire version = %q, want %q", gotVersion, "v1")
+	}
+	want := Attach(&loadbalancer.LoadBalancerOptions{PrivateNetworkOnly: utils.Ptr(true)}, "credentials-0", fixtureTarget())
+	if diff := cmp.Diff(a.loadBalancers[0].Options, want); diff != "" {
+		t.Fatalf("unexpected options (-got +want): %s", diff)
+	}
+	if diff := cmp.Diff(a.calls, []string{"create credentials argus", "update load balancer lb"}); diff != "" {
+		t.Fatalf("unexpected calls (-got +want): %s", diff)
+	}
+
+	if _, err := wire(context.Background(), a, "pid", "missing", fixtureTarget()); err == nil {
+		t.Fatalf("wire to a missing load balancer succeeded")
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	loadBalancers := []loadbalancer.LoadBalancer{
+		{
+			Name: utils.Ptr("lb-1"),
+			Options: &loadbalancer.LoadBalancerOptions{
+				Observability: &loadbalancer.LoadbalancerOptionObservability{
+					Logs: &loadbalancer.LoadbalancerOptionLogs{CredentialsRef: utils.Ptr("logs")},
+				},
+			},
+		},
+		{
+			Name: utils.Ptr("lb-2"),
+			Options: &loadbalancer.LoadBalancerOptions{
+				Observability: &loadbalancer.LoadbalancerOptionObservability{
+					Metrics: &loadbalancer.LoadbalancerOptionMetrics{CredentialsRef: utils.Ptr("metrics")},
+				},
+			},
+		},
+		{Name: utils.Ptr("lb-3")},
+	}
+	credentials := []loadbalancer.CredentialsResponse{
+		{CredentialsRef: utils.Ptr("logs")},
+		{CredentialsRef: utils.Ptr("unused")},
+		{CredentialsRef: utils.Ptr("metrics")},
+		{CredentialsRef: utils.Ptr("new")},
+		{CredentialsRef: utils.Ptr("gone")},
+	}
+	tests := []struct {
+		desc        string
+		keep        []string
+		deleteFails bool
+		want        []string
+		wantErr     bool
+	}{
+		{
+			desc: "unreferenced",
+			want: []string{"unused", "new", "gone"},
+		},
+		{
+			desc: "keep",
+			keep: []string{"new"},
+			want: []string{"unused", "gone"},
+		},
+		{
+			desc:        "delete_fails",
+			deleteFails: true,
+			want:        []string{},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				credentials:   credentials,
+				loadBalancers: loadBalancers,
+				deleteFails:   tt.deleteFails,
+			}
+			got, err := collectGarbage(context.Background(), a, "pid", tt.keep...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("collectGarbage error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected deleted credentials (-got +want): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package payload
+
+import (
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+// Builder builds the payload to create a load balancer.
+// Target pools and listeners are kept in the order they are added.
+type Builder struct {
+	payload loadbalancer.CreateLoadBalancerPayload
+}
+
+// NewBuilder returns a Builder for a load balancer with the name
+func NewBuilder(name string) *Builder {
+	return &Builder{
+		payload: loadbalancer.CreateLoadBalancerPayload{
+			Name:        &name,
+			Listeners:   &[]loadbalancer.Listener{},
+			Networks:    &[]loadbalancer.Network{},
+			TargetPools: &[]loadbalancer.TargetPool{},
+			Options:     &loadbalancer.LoadBalancerOptions{},
+		},
+	}
+}
+
+// Network adds a network with the role, one of the Role* constants
+func (b *Builder) Network(networkId, role string) *Builder {
+	*b.payload.Networks = append(*b.payload.Networks, loadbalancer.Network{NetworkId: &networkId, Role: &role})
+	return b
+}
+
+// ExternalAddress sets the existing public IP the load balancer is reachable at
+func (b *Builder) ExternalAddress(ip string) *Builder {
+	b.payload.ExternalAddress = &ip
+	return b
+}
+
+// EphemeralAddress makes the load balancer reachable at a public IP allocated for it and released with it
+func (b *Builder) EphemeralAddress() *Builder {
+	ephemeral := true
+	b.payload.Options.EphemeralAddress = &ephemeral
+	return b
+}
+
+// PrivateNetworkOnly makes the load balancer reachable only from its network
+func (b *Builder) PrivateNetworkOnly() *Builder {
+	privateNetworkOnly := true
+	b.payload.Options.PrivateNetworkOnly = &privateNetworkOnly
+	return b
+}
+
+// AllowedSourceRanges restricts the access to the load balancer to the CIDRs
+func (b *Builder) AllowedSourceRanges(cidrs ...string) *Builder {
+	b.payload.Options.AccessControl = &loadbalancer.LoadbalancerOptionAccessControl{AllowedSourceRanges: &cidrs}
+	return b
+}
+
+// Logs sends the logs of the load balancer to the push URL, with the credentials referenced
+func (b *Builder) Logs(credentialsRef, pushUrl string) *Builder {
+	b.observability().Logs = &loadbalancer.LoadbalancerOptionLogs{CredentialsRef: &credentialsRef, PushUrl: &pushUrl}
+	return b
+}
+
+// Metrics sends the metrics of the load balancer to the push URL, with the credentials referenced
+func (b *Builder) Metrics(credentialsRef, pushUrl string) *Builder {
+	b.observability().Metrics = &loadbalancer.LoadbalancerOptionMetrics{CredentialsRef: &credentialsRef, PushUrl: &pushUrl}
+	return b
+}
+
+func (b *Builder) observability() *loadbalancer.LoadbalancerOptionObservability {
+	if b.payload.Options.Observability == nil {
+		b.payload.Options.Observability = &loadbalancer.LoadbalancerOptionObservability{}
+	}
+	return b.payload.Options.Observability
+}
+
+// TargetPoolOption configures a target pool added with Builder.TargetPool
+type TargetPoolOption func(*loadbalancer.TargetPool)
+
+// HealthCheck configures the active health check of a target pool
+type HealthCheck struct {
+	Interval           time.Duration
+	IntervalJitter     time.Duration
+	Timeout            time.Duration
+	HealthyThreshold   int64
+	UnhealthyThreshold int64
+}
+
+// WithActiveHealthCheck sets the active health check of the target pool. Zero fields are left to the defaults of the API.
+func WithActiveHealthCheck(hc HealthCheck) TargetPoolOption {
+	return func(pool *loadbalancer.TargetPool) {
+		check := &loadbalancer.ActiveHealthCheck{}
+		if hc.Interval > 0 {
+			check.Interval = durationPtr(hc.Interval)
+		}
+		if hc.IntervalJitter > 0 {
+			check.IntervalJitter = durationPtr(hc.IntervalJitter)
+		}
+		if hc.Timeout > 0 {
+			check.Timeout = durationPtr(hc.Timeout)
+		}
+		if hc.HealthyThreshold != 0 {
+			check.HealthyThreshold = &hc.HealthyThreshold
+		}
+		if hc.UnhealthyThreshold != 0 {
+			check.UnhealthyThreshold = &hc.UnhealthyThreshold
+		}
+		pool.ActiveHealthCheck = check
+	}
+}
+
+// WithSessionPersistence redirects all connections from a source IP to the same target
+func WithSessionPersistence() TargetPoolOption {
+	return func(pool *loadbalancer.TargetPool) {
+		useSourceIpAddress := true
+		pool.SessionPersistence = &loadbalancer.SessionPersistence{UseSourceIpAddress: &useSourceIpAddress}
+	}
+}
+
+// Target returns a target with the display name and IP
+func Target(displayName, ip string) loadbalancer.Target {
+	return loadbalancer.Target{DisplayName: &displayName, Ip: &ip}
+}
+
+// TargetPool adds a target pool forwarding to the port of the targets
+func (b *Builder) TargetPool(name string, targetPort int64, targets []loadbalancer.Target, opts ...TargetPoolOption) *Builder {
+	targets = append([]loadbalancer.Target{}, targets...)
+	pool := loadbalancer.TargetPool{Name: &name, TargetPort: &targetPort, Targets: &targets}
+	for _, opt := range opts {
+		opt(&pool)
+	}
+	*b.payload.TargetPools = append(*b.payload.TargetPools, pool)
+	return b
+}
+
+// ListenerOption configures a listener added with Builder.Listener
+type ListenerOption func(*loadbalancer.Listener)
+
+// WithDisplayName sets the display name of the listener
+func WithDisplayName(displayName string) ListenerOption {
+	return func(listener *loadbalancer.Listener) {
+		listener.DisplayName = &displayName
+	}
+}
+
+// WithServerNames sets the domain names a TLS passthrough listener accepts connections for
+func WithServerNames(names ...string) ListenerOption {
+	return func(listener *loadbalancer.Listener) {
+		snis := make([]loadbalancer.ServerNameIndicator, len(names))
+		for i := range names {
+			snis[i] = loadbalancer.ServerNameIndicator{Name: &names[i]}
+		}
+		listener.ServerNameIndicators = &snis
+	}
+}
+
+// WithIdleTimeout sets the time after which an idle connection is closed, as TCP or UDP option depending on the protocol of the listener.
+// It must be applied after the protocol is set, which Builder.Listener does.
+func WithIdleTimeout(d time.Duration) ListenerOption {
+	return func(listener *loadbalancer.Listener) {
+		if valueOf(listener.Protocol) == ProtocolUDP {
+			listener.Udp = &loadbalancer.OptionsUDP{IdleTimeout: durationPtr(d)}
+			return
+		}
+		listener.Tcp = &loadbalancer.OptionsTCP{IdleTimeout: durationPtr(d)}
+	}
+}
+
+// Listener adds a listener for the protocol, one of the Protocol* constants, forwarding from the port to the target pool
+func (b *Builder) Listener(protocol string, port int64, targetPool string, opts ...ListenerOption) *Builder {
+	listener := loadbalancer.Listener{Protocol: &protocol, Port: &port, TargetPool: &targetPool}
+	for _, opt := range opts {
+		opt(&listener)
+	}
+	*b.payload.Listeners = append(*b.payload.Listeners, listener)
+	return b
+}
+
+// Build returns the payload, and an error of type *ValidationError if it isn't valid
+func (b *Builder) Build() (*loadbalancer.CreateLoadBalancerPayload, error) {
+	p := b.payload
+	listeners := append([]loadbalancer.Listener{}, *p.Listeners...)
+	networks := append([]loadbalancer.Network{}, *p.Networks...)
+	targetPools := append([]loadbalancer.TargetPool{}, *p.TargetPools...)
+	p.Listeners, p.Networks, p.TargetPools = &listeners, &networks, &targetPools
+	options := *p.Options
+	if options.Observability != nil {
+		observability := *options.Observability
+		options.Observability = &observability
+	}
+	p.Options = &options
+	if options == (loadbalancer.LoadBalancerOptions{}) {
+		p.Options = nil
+	}
+	if err := Validate(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func durationPtr(d time.Duration) *string {
+	s := FormatDuration(d)
+	return &s
+}
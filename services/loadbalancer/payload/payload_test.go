@@ -0,0 +1,575 @@
+package payload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+func fixtureBuilder() *Builder {
+	return NewBuilder("my-lb").
+		Network("nid", RoleListenersAndTargets).
+		ExternalAddress("192.0.2.1").
+		TargetPool("web", 8080, []loadbalancer.Target{Target("web-1", "10.0.0.1")}).
+		Listener(ProtocolTCP, 80, "web")
+}
+
+func fixturePayload(mods ...func(*loadbalancer.CreateLoadBalancerPayload)) *loadbalancer.CreateLoadBalancerPayload {
+	p := &loadbalancer.CreateLoadBalancerPayload{
+		Name:            utils.Ptr("my-lb"),
+		ExternalAddress: utils.Ptr("192.0.2.1"),
+		Networks:        &[]loadbalancer.Network{{NetworkId: utils.Ptr("nid"), Role: utils.Ptr(RoleListenersAndTargets)}},
+		TargetPools: &[]loadbalancer.TargetPool{
+			{
+				Name:       utils.Ptr("web"),
+				TargetPort: utils.Ptr(int64(8080)),
+				Targets:    &[]loadbalancer.Target{Target("web-1", "10.0.0.1")},
+			},
+		},
+		Listeners: &[]loadbalancer.Listener{
+			{Protocol: utils.Ptr(ProtocolTCP), Port: utils.Ptr(int64(80)), TargetPool: utils.Ptr("web")},
+		},
+	}
+	for _, mod := range mods {
+		mod(p)
+	}
+	return p
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		desc    string
+		builder *Builder
+		want    *loadbalancer.CreateLoadBalancerPayload
+		wantErr bool
+	}{
+		{
+			desc:    "minimal",
+			builder: fixtureBuilder(),
+			want:    fixturePayload(),
+		},
+		{
+			desc: "options",
+			builder: NewBuilder("my-lb").
+				Network("nid", RoleListenersAndTargets).
+				EphemeralAddress().
+				AllowedSourceRanges("203.0.113.0/24").
+				Logs("credentials-logs", "https://logs.example.com/push").
+				Metrics("credentials-metrics", "https://metrics.example.com/push").
+				TargetPool("web", 8080, []loadbalancer.Target{Target("web-1", "10.0.0.1")},
+					WithActiveHealthCheck(HealthCheck{Interval: 10 * time.Second, Timeout: 1500 * time.Millisecond, HealthyThreshold: 2}),
+					WithSessionPersistence()).
+				TargetPool("dns", 53, []loadbalancer.Target{Target("dns-1", "10.0.0.2")}).
+				Listener(ProtocolTLSPassthrough, 443, "web", WithDisplayName("https"), WithServerNames("example.com"), WithIdleTimeout(time.Minute)).
+				Listener(ProtocolUDP, 53, "dns", WithIdleTimeout(30*time.Second)),
+			want: &loadbalancer.CreateLoadBalancerPayload{
+				Name:     utils.Ptr("my-lb"),
+				Networks: &[]loadbalancer.Network{{NetworkId: utils.Ptr("nid"), Role: utils.Ptr(RoleListenersAndTargets)}},
+				Options: &loadbalancer.LoadBalancerOptions{
+					EphemeralAddress: utils.Ptr(true),
+					AccessControl:    &loadbalancer.LoadbalancerOptionAccessControl{AllowedSourceRanges: &[]string{"203.0.113.0/24"}},
+					Observability: &loadbalancer.LoadbalancerOptionObservability{
+						Logs:    &loadbalancer.LoadbalancerOptionLogs{CredentialsRef: utils.Ptr("credentials-logs"), PushUrl: utils.Ptr("https://logs.example.com/push")},
+						Metrics: &loadbalancer.LoadbalancerOptionMetrics{CredentialsRef: utils.Ptr("credentials-metrics"), PushUrl: utils.Ptr("https://metrics.example.com/push")},
+					},
+				},
+				TargetPools: &[]loadbalancer.TargetPool{
+					{
+						Name:       utils.Ptr("web"),
+						TargetPort: utils.Ptr(int64(8080)),
+						Targets:    &[]loadbalancer.Target{Target("web-1", "10.0.0.1")},
+						ActiveHealthCheck: &loadbalancer.ActiveHealthCheck{
+							Interval:         utils.Ptr("10s"),
+							Timeout:          utils.Ptr("1.5s"),
+							HealthyThreshold: utils.Ptr(int64(2)),
+						},
+						SessionPersistence: &loadbalancer.SessionPersistence{UseSourceIpAddress: utils.Ptr(true)},
+					},
+					{
+						Name:       utils.Ptr("dns"),
+						TargetPort: utils.Ptr(int64(53)),
+						Targets:    &[]loadbalancer.Target{Target("dns-1", "10.0.0.2")},
+					},
+				},
+				Listeners: &[]loadbalancer.Listener{
+					{
+						DisplayName:          utils.Ptr("https"),
+						Protocol:             utils.Ptr(ProtocolTLSPassthrough),
+						Port:                 utils.Ptr(int64(443)),
+						TargetPool:           utils.Ptr("web"),
+						ServerNameIndicators: &[]loadbalancer.ServerNameIndicator{{Name: utils.Ptr("example.com")}},
+						Tcp:                  &loadbalancer.OptionsTCP{IdleTimeout: utils.Ptr("60s")},
+					},
+					{
+						Protocol:   utils.Ptr(ProtocolUDP),
+						Port:       utils.Ptr(int64(53)),
+						TargetPool: utils.Ptr("dns"),
+						Udp:        &loadbalancer.OptionsUDP{IdleTimeout: utils.Ptr("30s")},
+					},
+				},
+			},
+		},
+		{
+			desc:    "invalid",
+			builder: fixtureBuilder().Listener(ProtocolTCP, 443, "missing"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected payload (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildIsolated(t *testing.T) {
+	b := fixtureBuilder()
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	b.Listener(ProtocolTCP, 443, "web").AllowedSourceRanges("203.0.113.0/24")
+	if diff := cmp.Diff(first, fixturePayload()); diff != "" {
+		t.Fatalf("payload changed by the builder (-got +want): %s", diff)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		desc         string
+		payload      *loadbalancer.CreateLoadBalancerPayload
+		wantProblems []string
+	}{
+		{
+			desc:    "valid",
+			payload: fixturePayload(),
+		},
+		{
+			desc: "valid_sni_on_shared_port",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Listeners = &[]loadbalancer.Listener{
+					{Protocol: utils.Ptr(ProtocolTLSPassthrough), Port: utils.Ptr(int64(443)), TargetPool: utils.Ptr("web")},
+					{
+						Protocol:             utils.Ptr(ProtocolTLSPassthrough),
+						Port:                 utils.Ptr(int64(443)),
+						TargetPool:           utils.Ptr("web"),
+						ServerNameIndicators: &[]loadbalancer.ServerNameIndicator{{Name: utils.Ptr("example.com")}},
+					},
+					{Protocol: utils.Ptr(ProtocolUDP), Port: utils.Ptr(int64(443)), TargetPool: utils.Ptr("web")},
+				}
+			}),
+		},
+		{
+			desc: "valid_private_network_only",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.ExternalAddress = nil
+				p.Options = &loadbalancer.LoadBalancerOptions{PrivateNetworkOnly: utils.Ptr(true)}
+			}),
+		},
+		{
+			desc: "name_and_version",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Name = utils.Ptr("My_LB")
+				p.Version = utils.Ptr("1")
+			}),
+			wantProblems: []string{
+				`name: must be a lowercase DNS label of at most 63 characters, got "My_LB"`,
+				"version: must be empty on creation",
+			},
+		},
+		{
+			desc: "missing_target_pool",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				(*p.Listeners)[0].TargetPool = utils.Ptr("api")
+			}),
+			wantProblems: []string{`listeners[0].targetPool: target pool "api" doesn't exist`},
+		},
+		{
+			desc: "duplicate_ports",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Listeners = &[]loadbalancer.Listener{
+					{Protocol: utils.Ptr(ProtocolUDP), Port: utils.Ptr(int64(53)), TargetPool: utils.Ptr("web")},
+					{Protocol: utils.Ptr(ProtocolUDP), Port: utils.Ptr(int64(53)), TargetPool: utils.Ptr("web")},
+					{Protocol: utils.Ptr(ProtocolTCP), Port: utils.Ptr(int64(80)), TargetPool: utils.Ptr("web")},
+					{Protocol: utils.Ptr(ProtocolTCPProxy), Port: utils.Ptr(int64(80)), TargetPool: utils.Ptr("web")},
+				}
+			}),
+			wantProblems: []string{
+				"listeners[1].port: UDP port 53 is already used by listeners[0]",
+				"listeners[2].port: TCP port 80 is used by several listeners, which is only allowed if all of them are PROTOCOL_TLS_PASSTHROUGH listeners",
+				"listeners[3].port: TCP port 80 is used by several listeners, which is only allowed if all of them are PROTOCOL_TLS_PASSTHROUGH listeners",
+			},
+		},
+		{
+			desc: "sni",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				sni := &[]loadbalancer.ServerNameIndicator{{Name: utils.Ptr("example.com")}}
+				p.Listeners = &[]loadbalancer.Listener{
+					{Protocol: utils.Ptr(ProtocolTCP), Port: utils.Ptr(int64(80)), TargetPool: utils.Ptr("web"), ServerNameIndicators: sni},
+					{Protocol: utils.Ptr(ProtocolTLSPassthrough), Port: utils.Ptr(int64(443)), TargetPool: utils.Ptr("web"), ServerNameIndicators: sni},
+					{Protocol: utils.Ptr(ProtocolTLSPassthrough), Port: utils.Ptr(int64(443)), TargetPool: utils.Ptr("web"), ServerNameIndicators: sni},
+					{Protocol: utils.Ptr(ProtocolTLSPassthrough), Port: utils.Ptr(int64(8443)), TargetPool: utils.Ptr("web")},
+					{Protocol: utils.Ptr(ProtocolTLSPassthrough), Port: utils.Ptr(int64(8443)), TargetPool: utils.Ptr("web")},
+				}
+			}),
+			wantProblems: []string{
+				"listeners[0].serverNameIndicators: are only allowed for PROTOCOL_TLS_PASSTHROUGH listeners",
+				`listeners[2].serverNameIndicators: domain "example.com" on port 443 is already used by listeners[1]`,
+				"listeners[4]: port 8443 already has a default listener without server name indicators, listeners[3]",
+			},
+		},
+		{
+			desc: "listener_options",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Listeners = &[]loadbalancer.Listener{
+					{Protocol: utils.Ptr("PROTOCOL_HTTP"), Port: utils.Ptr(int64(0)), TargetPool: utils.Ptr("web")},
+					{
+						Protocol:   utils.Ptr(ProtocolUDP),
+						Port:       utils.Ptr(int64(53)),
+						TargetPool: utils.Ptr("web"),
+						Tcp:        &loadbalancer.OptionsTCP{IdleTimeout: utils.Ptr("10m")},
+						Udp:        &loadbalancer.OptionsUDP{IdleTimeout: utils.Ptr("300s")},
+					},
+				}
+			}),
+			wantProblems: []string{
+				"listeners[0].port: must be between 1 and 65535, got 0",
+				`listeners[0].protocol: must be one of PROTOCOL_TCP, PROTOCOL_TCP_PROXY, PROTOCOL_TLS_PASSTHROUGH, PROTOCOL_UDP, got "PROTOCOL_HTTP"`,
+				"listeners[1].tcp: is not allowed for PROTOCOL_UDP listeners",
+				`listeners[1].tcp.idleTimeout: must be a duration in seconds such as "10s" or "1.5s", got "10m"`,
+				"listeners[1].udp.idleTimeout: must be at most 120s, got 300s",
+			},
+		},
+		{
+			desc: "target_pools",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				pool := (*p.TargetPools)[0]
+				pool.Targets = &[]loadbalancer.Target{
+					{Ip: utils.Ptr("10.0.0.1")},
+					Target("web-2", "10.0.0.1"),
+					Target("web-3", "10.0.0.300"),
+				}
+				pool.ActiveHealthCheck = &loadbalancer.ActiveHealthCheck{
+					Interval:           utils.Ptr("ten seconds"),
+					UnhealthyThreshold: utils.Ptr(int64(0)),
+				}
+				p.TargetPools = &[]loadbalancer.TargetPool{pool, (*p.TargetPools)[0]}
+			}),
+			wantProblems: []string{
+				"targetPools[0].targets[0].displayName: is required",
+				"targetPools[0].targets[1].ip: IP 10.0.0.1 is used by more than one target",
+				`targetPools[0].targets[2].ip: must be an IP address, got "10.0.0.300"`,
+				`targetPools[0].activeHealthCheck.interval: must be a duration in seconds such as "10s" or "1.5s", got "ten seconds"`,
+				"targetPools[0].activeHealthCheck.unhealthyThreshold: must be positive, got 0",
+				`targetPools[1].name: target pool "web" is defined more than once`,
+			},
+		},
+		{
+			desc: "networks",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Networks = &[]loadbalancer.Network{{Role: utils.Ptr("ROLE_ALL")}, {NetworkId: utils.Ptr("nid")}}
+			}),
+			wantProblems: []string{
+				"networks: at most 1 network is allowed, got 2",
+				"networks[0].networkId: is required",
+				`networks[0].role: must be one of ROLE_LISTENERS_AND_TARGETS, ROLE_LISTENERS, ROLE_TARGETS, got "ROLE_ALL"`,
+				`networks[1].role: must be one of ROLE_LISTENERS_AND_TARGETS, ROLE_LISTENERS, ROLE_TARGETS, got ""`,
+			},
+		},
+		{
+			desc: "empty",
+			payload: &loadbalancer.CreateLoadBalancerPayload{
+				Name:    utils.Ptr("my-lb"),
+				Options: &loadbalancer.LoadBalancerOptions{EphemeralAddress: utils.Ptr(true)},
+			},
+			wantProblems: []string{
+				"targetPools: at least one target pool is required",
+				"listeners: at least one listener is required",
+				"networks: at least one network is required",
+			},
+		},
+		{
+			desc: "private_network_only_with_address",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Options = &loadbalancer.LoadBalancerOptions{PrivateNetworkOnly: utils.Ptr(true), EphemeralAddress: utils.Ptr(true)}
+			}),
+			wantProblems: []string{
+				"externalAddress: is not allowed if options.privateNetworkOnly is set",
+				"options.ephemeralAddress: is not allowed if options.privateNetworkOnly is set",
+			},
+		},
+		{
+			desc: "external_and_ephemeral_address",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Options = &loadbalancer.LoadBalancerOptions{EphemeralAddress: utils.Ptr(true)}
+			}),
+			wantProblems: []string{"externalAddress: is not allowed if options.ephemeralAddress is set"},
+		},
+		{
+			desc: "no_address",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.ExternalAddress = nil
+			}),
+			wantProblems: []string{"externalAddress: is required unless options.ephemeralAddress or options.privateNetworkOnly is set"},
+		},
+		{
+			desc: "options",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.ExternalAddress = utils.Ptr("my-ip")
+				p.Options = &loadbalancer.LoadBalancerOptions{
+					AccessControl: &loadbalancer.LoadbalancerOptionAccessControl{AllowedSourceRanges: &[]string{"203.0.113.0/24", "203.0.113.1"}},
+					Observability: &loadbalancer.LoadbalancerOptionObservability{
+						Logs:    &loadbalancer.LoadbalancerOptionLogs{PushUrl: utils.Ptr("https://logs.example.com/push")},
+						Metrics: &loadbalancer.LoadbalancerOptionMetrics{CredentialsRef: utils.Ptr("ref"), PushUrl: utils.Ptr("metrics.example.com")},
+					},
+				}
+			}),
+			wantProblems: []string{
+				`externalAddress: must be an IP address, got "my-ip"`,
+				`options.accessControl.allowedSourceRanges[1]: must be a CIDR, got "203.0.113.1"`,
+				"options.observability.logs.credentialsRef: is required",
+				`options.observability.metrics.pushUrl: must be an HTTPS URL, got "metrics.example.com"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := Validate(tt.payload)
+			var gotProblems []string
+			if err != nil {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Validate returned %T, want *ValidationError", err)
+				}
+				gotProblems = validationErr.Problems
+			}
+			if diff := cmp.Diff(gotProblems, tt.wantProblems); diff != "" {
+				t.Fatalf("unexpected problems (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+type apiClientMocked struct {
+	maxLoadBalancers int64
+	loadBalancers    []string
+	getQuotaFails    bool
+}
+
+func (a *apiClientMocked) GetQuotaExecute(_ context.Context, projectId string) (*loadbalancer.GetQuotaResponse, error) {
+	if a.getQuotaFails {
+		return nil, fmt.Errorf("get quota fails")
+	}
+	return &loadbalancer.GetQuotaResponse{ProjectId: utils.Ptr(projectId), MaxLoadBalancers: utils.Ptr(a.maxLoadBalancers)}, nil
+}
+
+func (a *apiClientMocked) ListLoadBalancersExecute(_ context.Context, _ string) (*loadbalancer.ListLoadBalancersResponse, error) {
+	lbs := []loadbalancer.LoadBalancer{}
+	for _, name := range a.loadBalancers {
+		lbs = append(lbs, loadbalancer.LoadBalancer{Name: utils.Ptr(name)})
+	}
+	return &loadbalancer.ListLoadBalancersResponse{LoadBalancers: &lbs}, nil
+}
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		desc             string
+		payload          *loadbalancer.CreateLoadBalancerPayload
+		maxLoadBalancers int64
+		loadBalancers    []string
+		getQuotaFails    bool
+		wantErr          bool
+		wantQuotaErr     bool
+	}{
+		{
+			desc:             "ok",
+			payload:          fixturePayload(),
+			maxLoadBalancers: 2,
+			loadBalancers:    []string{"other-lb"},
+		},
+		{
+			desc:             "unlimited",
+			payload:          fixturePayload(),
+			maxLoadBalancers: -1,
+			loadBalancers:    []string{"other-lb", "another-lb"},
+		},
+		{
+			desc:             "quota_exceeded",
+			payload:          fixturePayload(),
+			maxLoadBalancers: 2,
+			loadBalancers:    []string{"other-lb", "another-lb"},
+			wantErr:          true,
+			wantQuotaErr:     true,
+		},
+		{
+			desc:             "exists",
+			payload:          fixturePayload(),
+			maxLoadBalancers: 2,
+			loadBalancers:    []string{"my-lb"},
+			wantErr:          true,
+		},
+		{
+			desc: "invalid",
+			payload: fixturePayload(func(p *loadbalancer.CreateLoadBalancerPayload) {
+				p.Listeners = nil
+			}),
+			maxLoadBalancers: 2,
+			wantErr:          true,
+		},
+		{
+			desc:          "get_quota_fails",
+			payload:       fixturePayload(),
+			getQuotaFails: true,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				maxLoadBalancers: tt.maxLoadBalancers,
+				loadBalancers:    tt.loadBalancers,
+				getQuotaFails:    tt.getQuotaFails,
+			}
+			err := preflight(context.Background(), a, "pid", tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("preflight error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var quotaErr *QuotaExceededError
+			if errors.As(err, &quotaErr) != tt.wantQuotaErr {
+				t.Fatalf("preflight error = %v, want QuotaExceededError %t", err, tt.wantQuotaErr)
+			}
+		})
+	}
+}
+
+type updateAPIClientMocked struct {
+	lb *loadbalancer.LoadBalancer
+	// concurrentUpdates is the number of updates that fail because the load balancer is updated concurrently
+	concurrentUpdates int
+	updateFails       bool
+	payloads          []loadbalancer.UpdateLoadBalancerPayload
+}
+
+func (a *updateAPIClientMocked) GetLoadBalancerExecute(_ context.Context, _, _ string) (*loadbalancer.LoadBalancer, error) {
+	lb := *a.lb
+	listeners := append([]loadbalancer.Listener{}, *a.lb.Listeners...)
+	lb.Listeners = &listeners
+	return &lb, nil
+}
+
+func (a *updateAPIClientMocked) updateLoadBalancer(_ context.Context, _, _ string, payload loadbalancer.UpdateLoadBalancerPayload) (*loadbalancer.LoadBalancer, error) {
+	a.payloads = append(a.payloads, payload)
+	if a.updateFails {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusBadRequest}
+	}
+	if a.concurrentUpdates > 0 {
+		a.concurrentUpdates--
+		a.lb.Version = utils.Ptr(*a.lb.Version + "'")
+	}
+	if *payload.Version != *a.lb.Version {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusConflict}
+	}
+	lb := loadbalancer.LoadBalancer(payload)
+	lb.Version = utils.Ptr(*a.lb.Version + "+")
+	a.lb = &lb
+	return &lb, nil
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		desc              string
+		concurrentUpdates int
+		updateFails       bool
+		mutateFails       bool
+		wantVersion       string
+		wantAttempts      int
+		wantErr           bool
+	}{
+		{
+			desc:         "updated",
+			wantVersion:  "v1",
+			wantAttempts: 1,
+		},
+		{
+			desc:              "retried",
+			concurrentUpdates: 2,
+			wantVersion:       "v1''",
+			wantAttempts:      3,
+		},
+		{
+			desc:              "too_many_attempts",
+			concurrentUpdates: MaxUpdateAttempts,
+			wantAttempts:      MaxUpdateAttempts,
+			wantErr:           true,
+		},
+		{
+			desc:         "update_fails",
+			updateFails:  true,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			desc:        "mutate_fails",
+			mutateFails: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &updateAPIClientMocked{
+				lb: &loadbalancer.LoadBalancer{
+					Name:           utils.Ptr("my-lb"),
+					Version:        utils.Ptr("v1"),
+					Status:         utils.Ptr("STATUS_READY"),
+					PrivateAddress: utils.Ptr("10.0.0.10"),
+					Listeners:      fixturePayload().Listeners,
+				},
+				concurrentUpdates: tt.concurrentUpdates,
+				updateFails:       tt.updateFails,
+			}
+			mutate := func(p *loadbalancer.UpdateLoadBalancerPayload) error {
+				if tt.mutateFails {
+					return fmt.Errorf("mutate fails")
+				}
+				*p.Listeners = append(*p.Listeners, loadbalancer.Listener{Protocol: utils.Ptr(ProtocolTCP), Port: utils.Ptr(int64(443)), TargetPool: utils.Ptr("web")})
+				return nil
+			}
+
+			gotVersion, err := update(context.Background(), a, "pid", "my-lb", mutate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("update error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotVersion != tt.wantVersion {
+				t.Fatalf("update version = %q, want %q", gotVersion, tt.wantVersion)
+			}
+			if len(a.payloads) != tt.wantAttempts {
+				t.Fatalf("%d updates, want %d", len(a.payloads), tt.wantAttempts)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := loadbalancer.UpdateLoadBalancerPayload{
+				Name:      utils.Ptr("my-lb"),
+				Version:   utils.Ptr(tt.wantVersion),
+				Listeners: utils.Ptr(append(*fixturePayload().Listeners, loadbalancer.Listener{Protocol: utils.Ptr(ProtocolTCP), Port: utils.Ptr(int64(443)), TargetPool: utils.Ptr("web")})),
+			}
+			if diff := cmp.Diff(a.payloads[len(a.payloads)-1], want); diff != "" {
+				t.Fatalf("unexpected payload (-got +want): %s", diff)
+			}
+		})
+	}
+}
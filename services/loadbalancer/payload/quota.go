@@ -0,0 +1,69 @@
+package payload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+// QuotaExceededError is returned if the project can't have another load balancer
+type QuotaExceededError struct {
+	MaxLoadBalancers int64
+	LoadBalancers    int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("project has %d load balancers, reaching its quota of %d", e.LoadBalancers, e.MaxLoadBalancers)
+}
+
+// apiClient is the part of the Load Balancer API used by this package
+type apiClient interface {
+	GetQuotaExecute(ctx context.Context, projectId string) (*loadbalancer.GetQuotaResponse, error)
+	ListLoadBalancersExecute(ctx context.Context, projectId string) (*loadbalancer.ListLoadBalancersResponse, error)
+}
+
+// CheckQuota returns a QuotaExceededError if the project already has as many load balancers as its quota allows
+func CheckQuota(ctx context.Context, client *loadbalancer.APIClient, projectId string) error {
+	_, err := listIfQuotaAllows(ctx, client, projectId)
+	return err
+}
+
+// Preflight validates the payload and checks that the project can have another load balancer with its name
+func Preflight(ctx context.Context, client *loadbalancer.APIClient, projectId string, p *loadbalancer.CreateLoadBalancerPayload) error {
+	return preflight(ctx, client, projectId, p)
+}
+
+func preflight(ctx context.Context, a apiClient, projectId string, p *loadbalancer.CreateLoadBalancerPayload) error {
+	if err := Validate(p); err != nil {
+		return err
+	}
+	lbs, err := listIfQuotaAllows(ctx, a, projectId)
+	if err != nil {
+		return err
+	}
+	for _, lb := range lbs {
+		if valueOf(lb.Name) == valueOf(p.Name) {
+			return fmt.Errorf("load balancer %s already exists", valueOf(p.Name))
+		}
+	}
+	return nil
+}
+
+// listIfQuotaAllows returns the load balancers of the project, or a QuotaExceededError if there are as many as its quota allows
+func listIfQuotaAllows(ctx context.Context, a apiClient, projectId string) ([]loadbalancer.LoadBalancer, error) {
+	quota, err := a.GetQuotaExecute(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("get quota: %w", err)
+	}
+	resp, err := a.ListLoadBalancersExecute(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("list load balancers: %w", err)
+	}
+	lbs := valueOf(resp.LoadBalancers)
+	// A negative quota means the number of load balancers is unlimited
+	if max := valueOf(quota.MaxLoadBalancers); max >= 0 && int64(len(lbs)) >= max {
+		return nil, &QuotaExceededError{MaxLoadBalancers: max, LoadBalancers: int64(len(lbs))}
+	}
+	return lbs, nil
+}
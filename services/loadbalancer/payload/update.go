@@ -0,0 +1,58 @@
+package payload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer/wait"
+)
+
+// MaxUpdateAttempts is the number of times Update tries an update whose version became stale in the meantime
+const MaxUpdateAttempts = 5
+
+// updateAPIClient is the part of the Load Balancer API used by Update.
+type updateAPIClient interface {
+	GetLoadBalancerExecute(ctx context.Context, projectId, name string) (*loadbalancer.LoadBalancer, error)
+	updateLoadBalancer(ctx context.Context, projectId, name string, payload loadbalancer.UpdateLoadBalancerPayload) (*loadbalancer.LoadBalancer, error)
+}
+
+type apiClientAdapter struct {
+	*loadbalancer.APIClient
+}
+
+func (a apiClientAdapter) updateLoadBalancer(ctx context.Context, projectId, name string, payload loadbalancer.UpdateLoadBalancerPayload) (*loadbalancer.LoadBalancer, error) {
+	return a.UpdateLoadBalancer(ctx, projectId, name).UpdateLoadBalancerPayload(payload).Execute()
+}
+
+// Update gets the load balancer, applies mutate to the update payload derived from it and updates it.
+// If the load balancer changed in the meantime, the update is calculated again from its new version, up to MaxUpdateAttempts times.
+// It returns the version the update was calculated for, to be passed to wait.UpdateLoadBalancerWaitHandler.
+// mutate may be called several times and must only change the payload it is given.
+func Update(ctx context.Context, client *loadbalancer.APIClient, projectId, name string, mutate func(p *loadbalancer.UpdateLoadBalancerPayload) error) (previousVersion string, err error) {
+	return update(ctx, apiClientAdapter{client}, projectId, name, mutate)
+}
+
+func update(ctx context.Context, a updateAPIClient, projectId, name string, mutate func(p *loadbalancer.UpdateLoadBalancerPayload) error) (previousVersion string, err error) {
+	for attempt := 1; ; attempt++ {
+		lb, err := a.GetLoadBalancerExecute(ctx, projectId, name)
+		if err != nil {
+			return "", fmt.Errorf("get load balancer: %w", err)
+		}
+		p := loadbalancer.UpdateLoadBalancerPayload(*lb)
+		// Read-only fields, reported by the API
+		p.Errors, p.PrivateAddress, p.Status = nil, nil, nil
+		if err := mutate(&p); err != nil {
+			return "", err
+		}
+		p.Version = lb.Version
+
+		_, err = a.updateLoadBalancer(ctx, projectId, name, p)
+		if err == nil {
+			return valueOf(lb.Version), nil
+		}
+		if !wait.IsStaleVersionError(err) || attempt >= MaxUpdateAttempts {
+			return "", fmt.Errorf("update load balancer: %w", err)
+		}
+	}
+}
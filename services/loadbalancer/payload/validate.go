@@ -0,0 +1,357 @@
+// Package payload builds and validates the payloads to create load balancers, catching inconsistent listeners, target pools,
+// networks and options before they are sent to the API, checks the quota of the project before a creation, and updates load
+// balancers safely against concurrent changes.
+package payload
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+// Listener protocols
+const (
+	ProtocolTCP            = "PROTOCOL_TCP"
+	ProtocolTCPProxy       = "PROTOCOL_TCP_PROXY"
+	ProtocolTLSPassthrough = "PROTOCOL_TLS_PASSTHROUGH"
+	ProtocolUDP            = "PROTOCOL_UDP"
+)
+
+// Network roles
+const (
+	RoleListenersAndTargets = "ROLE_LISTENERS_AND_TARGETS"
+	RoleListeners           = "ROLE_LISTENERS"
+	RoleTargets             = "ROLE_TARGETS"
+)
+
+// Limits of the API
+const (
+	MaxListeners      = 20
+	MaxTargetPools    = 20
+	MaxNetworks       = 1
+	MaxTCPIdleTimeout = time.Hour
+	MaxUDPIdleTimeout = 2 * time.Minute
+)
+
+var (
+	nameRegex     = regexp.MustCompile(`^[0-9a-z](?:(?:[0-9a-z]|-){0,61}[0-9a-z])?$`)
+	durationRegex = regexp.MustCompile(`^\d+(?:\.\d{1,9})?s$`)
+)
+
+// ValidationError lists the problems found in a payload
+type ValidationError struct {
+	// Problems are prefixed with the path of the field they concern, e.g. "listeners[0].port"
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid load balancer payload: %s", strings.Join(e.Problems, "; "))
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(field, format string, args ...interface{}) {
+	v.problems = append(v.problems, field+": "+fmt.Sprintf(format, args...))
+}
+
+// Validate checks the payload to create a load balancer for missing fields, broken references between listeners and target
+// pools, conflicting listener ports, malformed durations, addresses and URLs, and inconsistent address options.
+// It returns a ValidationError listing all problems found.
+func Validate(p *loadbalancer.CreateLoadBalancerPayload) error {
+	v := &validator{}
+	if p.Name == nil || !nameRegex.MatchString(*p.Name) {
+		v.addf("name", "must be a lowercase DNS label of at most 63 characters, got %q", valueOf(p.Name))
+	}
+	if valueOf(p.Version) != "" {
+		v.addf("version", "must be empty on creation")
+	}
+	pools := v.validateTargetPools(valueOf(p.TargetPools))
+	v.validateListeners(valueOf(p.Listeners), pools)
+	v.validateNetworks(valueOf(p.Networks))
+	v.validateOptions(p)
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validateTargetPools checks the target pools and returns their names
+func (v *validator) validateTargetPools(pools []loadbalancer.TargetPool) map[string]bool {
+	names := map[string]bool{}
+	if len(pools) == 0 {
+		v.addf("targetPools", "at least one target pool is required")
+	}
+	if len(pools) > MaxTargetPools {
+		v.addf("targetPools", "at most %d target pools are allowed, got %d", MaxTargetPools, len(pools))
+	}
+	for i, pool := range pools {
+		field := fmt.Sprintf("targetPools[%d]", i)
+		name := valueOf(pool.Name)
+		switch {
+		case !nameRegex.MatchString(name):
+			v.addf(field+".name", "must be a lowercase DNS label of at most 63 characters, got %q", name)
+		case names[name]:
+			v.addf(field+".name", "target pool %q is defined more than once", name)
+		}
+		names[name] = true
+		v.validatePort(field+".targetPort", pool.TargetPort)
+
+		ips := map[string]bool{}
+		for j, target := range valueOf(pool.Targets) {
+			targetField := fmt.Sprintf("%s.targets[%d]", field, j)
+			if valueOf(target.DisplayName) == "" {
+				v.addf(targetField+".displayName", "is required")
+			}
+			ip := valueOf(target.Ip)
+			switch {
+			case net.ParseIP(ip) == nil:
+				v.addf(targetField+".ip", "must be an IP address, got %q", ip)
+			case ips[ip]:
+				v.addf(targetField+".ip", "IP %s is used by more than one target", ip)
+			}
+			ips[ip] = true
+		}
+
+		if hc := pool.ActiveHealthCheck; hc != nil {
+			hcField := field + ".activeHealthCheck"
+			v.validateDuration(hcField+".interval", hc.Interval, 0)
+			v.validateDuration(hcField+".intervalJitter", hc.IntervalJitter, 0)
+			v.validateDuration(hcField+".timeout", hc.Timeout, 0)
+			if hc.HealthyThreshold != nil && *hc.HealthyThreshold < 1 {
+				v.addf(hcField+".healthyThreshold", "must be positive, got %d", *hc.HealthyThreshold)
+			}
+			if hc.UnhealthyThreshold != nil && *hc.UnhealthyThreshold < 1 {
+				v.addf(hcField+".unhealthyThreshold", "must be positive, got %d", *hc.UnhealthyThreshold)
+			}
+		}
+	}
+	return names
+}
+
+func (v *validator) validateListeners(listeners []loadbalancer.Listener, pools map[string]bool) {
+	if len(listeners) == 0 {
+		v.addf("listeners", "at least one listener is required")
+	}
+	if len(listeners) > MaxListeners {
+		v.addf("listeners", "at most %d listeners are allowed, got %d", MaxListeners, len(listeners))
+	}
+
+	// Listeners by port, separately for UDP and TCP-derived protocols
+	udpPorts := map[int64]int{}
+	tcpPorts := map[int64][]int{}
+	names := map[string]bool{}
+	for i, listener := range listeners {
+		field := fmt.Sprintf("listeners[%d]", i)
+		if name := valueOf(listener.DisplayName); name != "" {
+			if names[name] {
+				v.addf(field+".displayName", "listener %q is defined more than once", name)
+			}
+			names[name] = true
+		}
+		targetPool := valueOf(listener.TargetPool)
+		if !pools[targetPool] {
+			v.addf(field+".targetPool", "target pool %q doesn't exist", targetPool)
+		}
+		v.validatePort(field+".port", listener.Port)
+		port := valueOf(listener.Port)
+
+		protocol := valueOf(listener.Protocol)
+		switch protocol {
+		case ProtocolUDP:
+			if j, ok := udpPorts[port]; ok {
+				v.addf(field+".port", "UDP port %d is already used by listeners[%d]", port, j)
+			}
+			udpPorts[port] = i
+		case ProtocolTCP, ProtocolTCPProxy, ProtocolTLSPassthrough:
+			tcpPorts[port] = append(tcpPorts[port], i)
+		default:
+			v.addf(field+".protocol", "must be one of %s, got %q", strings.Join([]string{ProtocolTCP, ProtocolTCPProxy, ProtocolTLSPassthrough, ProtocolUDP}, ", "), protocol)
+		}
+
+		if len(valueOf(listener.ServerNameIndicators)) > 0 && protocol != ProtocolTLSPassthrough {
+			v.addf(field+".serverNameIndicators", "are only allowed for %s listeners", ProtocolTLSPassthrough)
+		}
+		for j, sni := range valueOf(listener.ServerNameIndicators) {
+			if valueOf(sni.Name) == "" {
+				v.addf(fmt.Sprintf("%s.serverNameIndicators[%d].name", field, j), "is required")
+			}
+		}
+		if listener.Tcp != nil {
+			if protocol == ProtocolUDP {
+				v.addf(field+".tcp", "is not allowed for %s listeners", ProtocolUDP)
+			}
+			v.validateDuration(field+".tcp.idleTimeout", listener.Tcp.IdleTimeout, MaxTCPIdleTimeout)
+		}
+		if listener.Udp != nil {
+			if protocol != ProtocolUDP {
+				v.addf(field+".udp", "is only allowed for %s listeners", ProtocolUDP)
+			}
+			v.validateDuration(field+".udp.idleTimeout", listener.Udp.IdleTimeout, MaxUDPIdleTimeout)
+		}
+	}
+
+	ports := make([]int64, 0, len(tcpPorts))
+	for port := range tcpPorts {
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	for _, port := range ports {
+		if len(tcpPorts[port]) > 1 {
+			v.validateSharedTCPPort(listeners, port, tcpPorts[port])
+		}
+	}
+}
+
+// validateSharedTCPPort checks listeners with TCP-derived protocols sharing a port, which must all be TLS passthrough listeners
+// with distinct domain names, with at most one default listener without domain name
+func (v *validator) validateSharedTCPPort(listeners []loadbalancer.Listener, port int64, indexes []int) {
+	domains := map[string]int{}
+	defaultListener := -1
+	for _, i := range indexes {
+		field := fmt.Sprintf("listeners[%d]", i)
+		listener := listeners[i]
+		if valueOf(listener.Protocol) != ProtocolTLSPassthrough {
+			v.addf(field+".port", "TCP port %d is used by several listeners, which is only allowed if all of them are %s listeners", port, ProtocolTLSPassthrough)
+			continue
+		}
+		snis := valueOf(listener.ServerNameIndicators)
+		if len(snis) == 0 {
+			if defaultListener >= 0 {
+				v.addf(field, "port %d already has a default listener without server name indicators, listeners[%d]", port, defaultListener)
+			}
+			defaultListener = i
+		}
+		for _, sni := range snis {
+			name := strings.ToLower(valueOf(sni.Name))
+			if j, ok := domains[name]; ok && j != i {
+				v.addf(field+".serverNameIndicators", "domain %q on port %d is already used by listeners[%d]", name, port, j)
+			}
+			domains[name] = i
+		}
+	}
+}
+
+func (v *validator) validateNetworks(networks []loadbalancer.Network) {
+	if len(networks) == 0 {
+		v.addf("networks", "at least one network is required")
+	}
+	if len(networks) > MaxNetworks {
+		v.addf("networks", "at most %d network is allowed, got %d", MaxNetworks, len(networks))
+	}
+	for i, network := range networks {
+		field := fmt.Sprintf("networks[%d]", i)
+		if valueOf(network.NetworkId) == "" {
+			v.addf(field+".networkId", "is required")
+		}
+		switch role := valueOf(network.Role); role {
+		case RoleListenersAndTargets, RoleListeners, RoleTargets:
+		default:
+			v.addf(field+".role", "must be one of %s, got %q", strings.Join([]string{RoleListenersAndTargets, RoleListeners, RoleTargets}, ", "), role)
+		}
+	}
+}
+
+func (v *validator) validateOptions(p *loadbalancer.CreateLoadBalancerPayload) {
+	options := p.Options
+	if options == nil {
+		options = &loadbalancer.LoadBalancerOptions{}
+	}
+	externalAddress := valueOf(p.ExternalAddress)
+	ephemeral := valueOf(options.EphemeralAddress)
+	if externalAddress != "" && net.ParseIP(externalAddress) == nil {
+		v.addf("externalAddress", "must be an IP address, got %q", externalAddress)
+	}
+	if valueOf(options.PrivateNetworkOnly) {
+		if externalAddress != "" {
+			v.addf("externalAddress", "is not allowed if options.privateNetworkOnly is set")
+		}
+		if ephemeral {
+			v.addf("options.ephemeralAddress", "is not allowed if options.privateNetworkOnly is set")
+		}
+	} else {
+		switch {
+		case externalAddress != "" && ephemeral:
+			v.addf("externalAddress", "is not allowed if options.ephemeralAddress is set")
+		case externalAddress == "" && !ephemeral:
+			v.addf("externalAddress", "is required unless options.ephemeralAddress or options.privateNetworkOnly is set")
+		}
+	}
+
+	if options.AccessControl != nil {
+		for i, cidr := range valueOf(options.AccessControl.AllowedSourceRanges) {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				v.addf(fmt.Sprintf("options.accessControl.allowedSourceRanges[%d]", i), "must be a CIDR, got %q", cidr)
+			}
+		}
+	}
+
+	if o := options.Observability; o != nil {
+		if o.Logs != nil {
+			v.validateObservability("options.observability.logs", o.Logs.CredentialsRef, o.Logs.PushUrl)
+		}
+		if o.Metrics != nil {
+			v.validateObservability("options.observability.metrics", o.Metrics.CredentialsRef, o.Metrics.PushUrl)
+		}
+	}
+}
+
+func (v *validator) validateObservability(field string, credentialsRef, pushUrl *string) {
+	if valueOf(credentialsRef) == "" {
+		v.addf(field+".credentialsRef", "is required")
+	}
+	u, err := url.Parse(valueOf(pushUrl))
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		v.addf(field+".pushUrl", "must be an HTTPS URL, got %q", valueOf(pushUrl))
+	}
+}
+
+func (v *validator) validatePort(field string, port *int64) {
+	if port == nil || *port < 1 || *port > 65535 {
+		v.addf(field, "must be between 1 and 65535, got %d", valueOf(port))
+	}
+}
+
+// validateDuration checks that the duration, if set, is in seconds as expected by the API, e.g. "1.5s", and doesn't exceed max unless it is zero
+func (v *validator) validateDuration(field string, duration *string, max time.Duration) {
+	if duration == nil {
+		return
+	}
+	d, err := ParseDuration(*duration)
+	if err != nil {
+		v.addf(field, "%v", err)
+		return
+	}
+	if max > 0 && d > max {
+		v.addf(field, "must be at most %s, got %s", FormatDuration(max), *duration)
+	}
+}
+
+// FormatDuration formats the duration in seconds as expected by the API, e.g. "1.5s"
+func FormatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// ParseDuration parses a duration in seconds as returned by the API, e.g. "1.5s"
+func ParseDuration(s string) (time.Duration, error) {
+	if !durationRegex.MatchString(s) {
+		return 0, fmt.Errorf("must be a duration in seconds such as \"10s\" or \"1.5s\", got %q", s)
+	}
+	return time.ParseDuration(s)
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,70 @@
+package targetpool
+
+import (
+	"context"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+// Source provides the targets a target pool should have
+type Source interface {
+	Targets(ctx context.Context) ([]loadbalancer.Target, error)
+}
+
+// StaticSource is a fixed list of targets
+type StaticSource []loadbalancer.Target
+
+// Targets returns a copy of the targets
+func (s StaticSource) Targets(_ context.Context) ([]loadbalancer.Target, error) {
+	return append([]loadbalancer.Target{}, s...), nil
+}
+
+// SourceFunc adapts a function returning the targets to a Source
+type SourceFunc func(ctx context.Context) ([]loadbalancer.Target, error)
+
+// Targets calls f
+func (f SourceFunc) Targets(ctx context.Context) ([]loadbalancer.Target, error) {
+	return f(ctx)
+}
+
+// EndpointAddress is an address of an endpoint, as in the Endpoints resource of Kubernetes
+type EndpointAddress struct {
+	IP       string
+	Hostname string
+	// TargetRefName is the name of the object backing the endpoint, e.g. a pod or a node
+	TargetRefName string
+}
+
+// EndpointSubset is a group of addresses of an endpoint, as in the Endpoints resource of Kubernetes.
+// Only the ready addresses become targets.
+type EndpointSubset struct {
+	Addresses         []EndpointAddress
+	NotReadyAddresses []EndpointAddress
+}
+
+// EndpointsSource adapts a function returning Kubernetes-style endpoint subsets to a Source.
+// The targets are the ready addresses, named after their target reference, their host name or their IP, in this order.
+type EndpointsSource func(ctx context.Context) ([]EndpointSubset, error)
+
+// Targets returns the ready addresses of the endpoint subsets as targets
+func (f EndpointsSource) Targets(ctx context.Context) ([]loadbalancer.Target, error) {
+	subsets, err := f(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := []loadbalancer.Target{}
+	for _, subset := range subsets {
+		for _, address := range subset.Addresses {
+			ip := address.IP
+			displayName := address.TargetRefName
+			if displayName == "" {
+				displayName = address.Hostname
+			}
+			if displayName == "" {
+				displayName = ip
+			}
+			targets = append(targets, loadbalancer.Target{Ip: &ip, DisplayName: &displayName})
+		}
+	}
+	return targets, nil
+}
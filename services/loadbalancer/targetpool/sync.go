@@ -0,0 +1,282 @@
+// Package targetpool keeps the targets of a load balancer target pool aligned with a dynamic source of targets,
+// such as a static list, a callback or Kubernetes-style endpoints.
+package targetpool
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	corewait "github.com/stackitcloud/stackit-sdk-go/core/wait"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer/wait"
+)
+
+// DefaultVerifyTimeout is the time the result of an update is verified for, if Options.VerifyTimeout is zero
+const DefaultVerifyTimeout = 5 * time.Minute
+
+// Options configures a Syncer
+type Options struct {
+	// Debounce is the time a changed set of targets has to be provided unchanged by the source before the target pool is updated.
+	// Changes are applied right away if zero.
+	Debounce time.Duration
+	// AllowEmpty allows removing all targets of the target pool, which is refused otherwise to guard against faulty sources
+	AllowEmpty bool
+	// VerifyTimeout is the time to wait for the update to be visible in the load balancer, DefaultVerifyTimeout if zero
+	VerifyTimeout time.Duration
+}
+
+// Result is the outcome of a synchronization
+type Result struct {
+	// Updated is true if the target pool was updated
+	Updated bool
+	// Pending is true if the targets changed, but the update is delayed by the debounce
+	Pending bool
+	Added   []loadbalancer.Target
+	Removed []loadbalancer.Target
+}
+
+// Syncer updates the targets of a target pool to the ones provided by a Source.
+// The other settings of the target pool and the other target pools of the load balancer are left unchanged.
+type Syncer struct {
+	client           apiClient
+	projectId        string
+	loadBalancerName string
+	targetPoolName   string
+	source           Source
+	opts             Options
+	now              func() time.Time
+	verifyThrottle   time.Duration
+
+	// pending is the set of targets waiting for the debounce, and since when it is provided
+	pending      []loadbalancer.Target
+	pendingSince time.Time
+}
+
+// apiClient is the part of the Load Balancer API used by this package.
+type apiClient interface {
+	wait.APIClientInterface
+	updateTargetPool(ctx context.Context, projectId, loadBalancerName, targetPoolName string, payload loadbalancer.UpdateTargetPoolPayload) error
+}
+
+type apiClientAdapter struct {
+	*loadbalancer.APIClient
+}
+
+func (a apiClientAdapter) updateTargetPool(ctx context.Context, projectId, loadBalancerName, targetPoolName string, payload loadbalancer.UpdateTargetPoolPayload) error {
+	_, err := a.UpdateTargetPool(ctx, projectId, loadBalancerName, targetPoolName).UpdateTargetPoolPayload(payload).Execute()
+	return err
+}
+
+// NewSyncer returns a Syncer for the target pool of the load balancer
+func NewSyncer(client *loadbalancer.APIClient, projectId, loadBalancerName, targetPoolName string, source Source, opts Options) *Syncer {
+	return newSyncer(apiClientAdapter{client}, projectId, loadBalancerName, targetPoolName, source, opts)
+}
+
+func newSyncer(a apiClient, projectId, loadBalancerName, targetPoolName string, source Source, opts Options) *Syncer {
+	if opts.VerifyTimeout == 0 {
+		opts.VerifyTimeout = DefaultVerifyTimeout
+	}
+	return &Syncer{
+		client:           a,
+		projectId:        projectId,
+		loadBalancerName: loadBalancerName,
+		targetPoolName:   targetPoolName,
+		source:           source,
+		opts:             opts,
+		now:              time.Now,
+		verifyThrottle:   5 * time.Second,
+	}
+}
+
+// Sync gets the targets from the source and updates the target pool if they differ from its current targets.
+// After an update, it waits until the load balancer has the new targets in the target pool and its other target pools unchanged.
+// A Syncer must not be used concurrently.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	sourceTargets, err := s.source.Targets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get targets from source: %w", err)
+	}
+	desired, err := normalize(sourceTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	lb, err := s.client.GetLoadBalancerExecute(ctx, s.projectId, s.loadBalancerName)
+	if err != nil {
+		return nil, fmt.Errorf("get load balancer: %w", err)
+	}
+	pool, others, err := splitTargetPools(lb, s.targetPoolName)
+	if err != nil {
+		return nil, err
+	}
+	current, err := normalize(valueOf(pool.Targets))
+	if err != nil {
+		return nil, fmt.Errorf("current targets: %w", err)
+	}
+
+	result := &Result{}
+	result.Added, result.Removed = diff(current, desired)
+	if len(result.Added) == 0 && len(result.Removed) == 0 {
+		s.pending = nil
+		return result, nil
+	}
+	if len(desired) == 0 && !s.opts.AllowEmpty {
+		return nil, fmt.Errorf("source has no targets, refusing to remove all targets of target pool %s", s.targetPoolName)
+	}
+
+	if s.opts.Debounce > 0 {
+		now := s.now()
+		if s.pending == nil || !reflect.DeepEqual(s.pending, desired) {
+			s.pending = desired
+			s.pendingSince = now
+		}
+		if now.Sub(s.pendingSince) < s.opts.Debounce {
+			result.Pending = true
+			return result, nil
+		}
+	}
+
+	payload := loadbalancer.UpdateTargetPoolPayload{
+		Name:               pool.Name,
+		TargetPort:         pool.TargetPort,
+		ActiveHealthCheck:  pool.ActiveHealthCheck,
+		SessionPersistence: pool.SessionPersistence,
+		Targets:            &desired,
+	}
+	if err := s.client.updateTargetPool(ctx, s.projectId, s.loadBalancerName, s.targetPoolName, payload); err != nil {
+		return nil, fmt.Errorf("update target pool %s: %w", s.targetPoolName, err)
+	}
+	s.pending = nil
+	result.Updated = true
+
+	if _, err := s.verifyHandler(ctx, desired, others).WaitWithContext(ctx); err != nil {
+		return result, fmt.Errorf("verify target pool %s: %w", s.targetPoolName, err)
+	}
+	return result, nil
+}
+
+// Run synchronizes the target pool at the interval until the context is canceled, reporting each outcome to the callback.
+// Errors don't stop the synchronization, they are retried at the next interval.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration, report func(*Result, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := s.Sync(ctx)
+		if report != nil {
+			report(result, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// verifyHandler waits until the target pool has the targets and the other target pools are unchanged
+func (s *Syncer) verifyHandler(ctx context.Context, targets []loadbalancer.Target, others []loadbalancer.TargetPool) *corewait.AsyncActionHandler[loadbalancer.LoadBalancer] {
+	handler := corewait.New(func() (waitFinished bool, response *loadbalancer.LoadBalancer, err error) {
+		lb, err := s.client.GetLoadBalancerExecute(ctx, s.projectId, s.loadBalancerName)
+		if err != nil {
+			return false, nil, err
+		}
+		pool, gotOthers, err := splitTargetPools(lb, s.targetPoolName)
+		if err != nil {
+			return true, lb, err
+		}
+		if !reflect.DeepEqual(gotOthers, others) {
+			return true, lb, fmt.Errorf("other target pools of load balancer %s changed", s.loadBalancerName)
+		}
+		current, err := normalize(valueOf(pool.Targets))
+		if err != nil {
+			return true, lb, err
+		}
+		return reflect.DeepEqual(current, targets), lb, nil
+	})
+	handler.SetTimeout(s.opts.VerifyTimeout)
+	handler.SetThrottle(s.verifyThrottle)
+	return handler
+}
+
+// splitTargetPools returns the target pool with the name and the other target pools of the load balancer
+func splitTargetPools(lb *loadbalancer.LoadBalancer, name string) (pool *loadbalancer.TargetPool, others []loadbalancer.TargetPool, err error) {
+	others = []loadbalancer.TargetPool{}
+	for _, p := range valueOf(lb.TargetPools) {
+		if valueOf(p.Name) == name {
+			p := p
+			pool = &p
+			continue
+		}
+		others = append(others, p)
+	}
+	if pool == nil {
+		return nil, nil, fmt.Errorf("load balancer %s has no target pool %s", valueOf(lb.Name), name)
+	}
+	return pool, others, nil
+}
+
+// normalize returns the targets sorted by IP, with the IP as display name if they have none.
+// It returns an error if a target has no IP or if IPs are duplicated with different display names.
+func normalize(targets []loadbalancer.Target) ([]loadbalancer.Target, error) {
+	byIp := map[string]string{}
+	for _, target := range targets {
+		ip := strings.TrimSpace(valueOf(target.Ip))
+		if ip == "" {
+			return nil, fmt.Errorf("target %q has no IP", valueOf(target.DisplayName))
+		}
+		displayName := valueOf(target.DisplayName)
+		if displayName == "" {
+			displayName = ip
+		}
+		if existing, ok := byIp[ip]; ok && existing != displayName {
+			return nil, fmt.Errorf("IP %s is used by targets %q and %q", ip, existing, displayName)
+		}
+		byIp[ip] = displayName
+	}
+	ips := make([]string, 0, len(byIp))
+	for ip := range byIp {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	normalized := make([]loadbalancer.Target, len(ips))
+	for i, ip := range ips {
+		ip, displayName := ip, byIp[ip]
+		normalized[i] = loadbalancer.Target{Ip: &ip, DisplayName: &displayName}
+	}
+	return normalized, nil
+}
+
+// diff returns the targets to add to and to remove from the current ones to get the desired ones.
+// A target with a changed display name is removed and added again.
+func diff(current, desired []loadbalancer.Target) (added, removed []loadbalancer.Target) {
+	key := func(t loadbalancer.Target) string { return *t.Ip + "/" + *t.DisplayName }
+	currentKeys := map[string]bool{}
+	for _, t := range current {
+		currentKeys[key(t)] = true
+	}
+	desiredKeys := map[string]bool{}
+	for _, t := range desired {
+		desiredKeys[key(t)] = true
+		if !currentKeys[key(t)] {
+			added = append(added, t)
+		}
+	}
+	for _, t := range current {
+		if !desiredKeys[key(t)] {
+			removed = append(removed, t)
+		}
+	}
+	return added, removed
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
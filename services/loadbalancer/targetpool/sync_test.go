@@ -0,0 +1,269 @@
+package targetpool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
+)
+
+type apiClientMocked struct {
+	lb          *loadbalancer.LoadBalancer
+	updateFails bool
+	// ignoreUpdates makes updates succeed without changing the load balancer
+	ignoreUpdates bool
+	// changeOthers makes updates also change the other target pools
+	changeOthers bool
+	payloads     []loadbalancer.UpdateTargetPoolPayload
+}
+
+func (a *apiClientMocked) GetLoadBalancerExecute(_ context.Context, _, _ string) (*loadbalancer.LoadBalancer, error) {
+	pools := append([]loadbalancer.TargetPool{}, *a.lb.TargetPools...)
+	lb := *a.lb
+	lb.TargetPools = &pools
+	return &lb, nil
+}
+
+func (a *apiClientMocked) GetServiceStatusExecute(_ context.Context, _ string) (*loadbalancer.GetServiceStatusResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) updateTargetPool(_ context.Context, _, _, targetPoolName string, payload loadbalancer.UpdateTargetPoolPayload) error {
+	a.payloads = append(a.payloads, payload)
+	if a.updateFails {
+		return fmt.Errorf("update fails")
+	}
+	if a.ignoreUpdates {
+		return nil
+	}
+	pools := []loadbalancer.TargetPool{}
+	for _, pool := range *a.lb.TargetPools {
+		switch {
+		case *pool.Name == targetPoolName:
+			pool = loadbalancer.TargetPool(payload)
+		case a.changeOthers:
+			pool.TargetPort = utils.Ptr(int64(9999))
+		}
+		pools = append(pools, pool)
+	}
+	a.lb.TargetPools = &pools
+	return nil
+}
+
+func fixtureTarget(ip, name string) loadbalancer.Target {
+	return loadbalancer.Target{Ip: utils.Ptr(ip), DisplayName: utils.Ptr(name)}
+}
+
+func fixtureLoadBalancer() *loadbalancer.LoadBalancer {
+	return &loadbalancer.LoadBalancer{
+		Name: utils.Ptr("lb"),
+		TargetPools: &[]loadbalancer.TargetPool{
+			{
+				Name:               utils.Ptr("web"),
+				TargetPort:         utils.Ptr(int64(80)),
+				ActiveHealthCheck:  &loadbalancer.ActiveHealthCheck{Interval: utils.Ptr("10s")},
+				SessionPersistence: &loadbalancer.SessionPersistence{UseSourceIpAddress: utils.Ptr(true)},
+				Targets: &[]loadbalancer.Target{
+					fixtureTarget("10.0.0.2", "web-2"),
+					fixtureTarget("10.0.0.1", "web-1"),
+				},
+			},
+			{
+				Name:       utils.Ptr("api"),
+				TargetPort: utils.Ptr(int64(8080)),
+				Targets:    &[]loadbalancer.Target{fixtureTarget("10.0.1.1", "api-1")},
+			},
+		},
+	}
+}
+
+func TestSync(t *testing.T) {
+	tests := []struct {
+		desc          string
+		source        Source
+		opts          Options
+		updateFails   bool
+		ignoreUpdates bool
+		changeOthers  bool
+		want          *Result
+		wantTargets   []loadbalancer.Target
+		wantErr       bool
+	}{
+		{
+			desc:   "unchanged",
+			source: StaticSource{fixtureTarget("10.0.0.1", "web-1"), fixtureTarget("10.0.0.2", "web-2")},
+			want:   &Result{},
+		},
+		{
+			desc: "changed",
+			source: SourceFunc(func(_ context.Context) ([]loadbalancer.Target, error) {
+				return []loadbalancer.Target{fixtureTarget("10.0.0.3", "web-3"), fixtureTarget("10.0.0.1", "web-1")}, nil
+			}),
+			want: &Result{
+				Updated: true,
+				Added:   []loadbalancer.Target{fixtureTarget("10.0.0.3", "web-3")},
+				Removed: []loadbalancer.Target{fixtureTarget("10.0.0.2", "web-2")},
+			},
+			wantTargets: []loadbalancer.Target{fixtureTarget("10.0.0.1", "web-1"), fixtureTarget("10.0.0.3", "web-3")},
+		},
+		{
+			desc: "endpoints",
+			source: EndpointsSource(func(_ context.Context) ([]EndpointSubset, error) {
+				return []EndpointSubset{{
+					Addresses: []EndpointAddress{
+						{IP: "10.0.0.1", TargetRefName: "web-1"},
+						{IP: "10.0.0.4", Hostname: "web-4"},
+						{IP: "10.0.0.5"},
+					},
+					NotReadyAddresses: []EndpointAddress{{IP: "10.0.0.2", TargetRefName: "web-2"}},
+				}}, nil
+			}),
+			want: &Result{
+				Updated: true,
+				Added:   []loadbalancer.Target{fixtureTarget("10.0.0.4", "web-4"), fixtureTarget("10.0.0.5", "10.0.0.5")},
+				Removed: []loadbalancer.Target{fixtureTarget("10.0.0.2", "web-2")},
+			},
+			wantTargets: []loadbalancer.Target{
+				fixtureTarget("10.0.0.1", "web-1"),
+				fixtureTarget("10.0.0.4", "web-4"),
+				fixtureTarget("10.0.0.5", "10.0.0.5"),
+			},
+		},
+		{
+			desc:    "empty",
+			source:  StaticSource{},
+			wantErr: true,
+		},
+		{
+			desc:   "empty_allowed",
+			source: StaticSource{},
+			opts:   Options{AllowEmpty: true},
+			want: &Result{
+				Updated: true,
+				Removed: []loadbalancer.Target{fixtureTarget("10.0.0.1", "web-1"), fixtureTarget("10.0.0.2", "web-2")},
+			},
+			wantTargets: []loadbalancer.Target{},
+		},
+		{
+			desc:    "duplicate_ip",
+			source:  StaticSource{fixtureTarget("10.0.0.1", "web-1"), fixtureTarget("10.0.0.1", "other")},
+			wantErr: true,
+		},
+		{
+			desc: "source_fails",
+			source: SourceFunc(func(_ context.Context) ([]loadbalancer.Target, error) {
+				return nil, fmt.Errorf("source fails")
+			}),
+			wantErr: true,
+		},
+		{
+			desc:        "update_fails",
+			source:      StaticSource{fixtureTarget("10.0.0.1", "web-1")},
+			updateFails: true,
+			wantErr:     true,
+		},
+		{
+			desc:          "not_applied",
+			source:        StaticSource{fixtureTarget("10.0.0.1", "web-1")},
+			ignoreUpdates: true,
+			want: &Result{
+				Updated: true,
+				Removed: []loadbalancer.Target{fixtureTarget("10.0.0.2", "web-2")},
+			},
+			wantErr: true,
+		},
+		{
+			desc:         "other_pools_changed",
+			source:       StaticSource{fixtureTarget("10.0.0.1", "web-1")},
+			changeOthers: true,
+			want: &Result{
+				Updated: true,
+				Removed: []loadbalancer.Target{fixtureTarget("10.0.0.2", "web-2")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				lb:            fixtureLoadBalancer(),
+				updateFails:   tt.updateFails,
+				ignoreUpdates: tt.ignoreUpdates,
+				changeOthers:  tt.changeOthers,
+			}
+			tt.opts.VerifyTimeout = 10 * time.Millisecond
+			s := newSyncer(a, "pid", "lb", "web", tt.source, tt.opts)
+			s.verifyThrottle = time.Millisecond
+
+			got, err := s.Sync(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sync error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected result (-got +want): %s", diff)
+			}
+			if tt.wantTargets == nil {
+				return
+			}
+			if len(a.payloads) != 1 {
+				t.Fatalf("%d updates, want 1", len(a.payloads))
+			}
+			payload := a.payloads[0]
+			if diff := cmp.Diff(*payload.Targets, tt.wantTargets); diff != "" {
+				t.Fatalf("unexpected targets (-got +want): %s", diff)
+			}
+			pool := (*fixtureLoadBalancer().TargetPools)[0]
+			if !cmp.Equal(payload.ActiveHealthCheck, pool.ActiveHealthCheck) ||
+				!cmp.Equal(payload.SessionPersistence, pool.SessionPersistence) ||
+				*payload.TargetPort != 80 || *payload.Name != "web" {
+				t.Fatalf("settings of the target pool not preserved: %+v", payload)
+			}
+		})
+	}
+}
+
+func TestSyncDebounce(t *testing.T) {
+	targets := []loadbalancer.Target{fixtureTarget("10.0.0.1", "web-1"), fixtureTarget("10.0.0.2", "web-2")}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := &apiClientMocked{lb: fixtureLoadBalancer()}
+	source := SourceFunc(func(_ context.Context) ([]loadbalancer.Target, error) {
+		return append([]loadbalancer.Target{}, targets...), nil
+	})
+	s := newSyncer(a, "pid", "lb", "web", source, Options{Debounce: time.Minute, VerifyTimeout: 10 * time.Millisecond})
+	s.now = func() time.Time { return now }
+	s.verifyThrottle = time.Millisecond
+
+	steps := []struct {
+		desc        string
+		targets     []loadbalancer.Target
+		advance     time.Duration
+		wantPending bool
+		wantUpdated bool
+	}{
+		{desc: "unchanged", targets: targets},
+		{desc: "changed", targets: targets[:1], wantPending: true},
+		{desc: "flapping_back", targets: targets, advance: 30 * time.Second},
+		{desc: "changed_again", targets: targets[:1], advance: 30 * time.Second, wantPending: true},
+		{desc: "still_debounced", targets: targets[:1], advance: 59 * time.Second, wantPending: true},
+		{desc: "stable", targets: targets[:1], advance: time.Second, wantUpdated: true},
+		{desc: "applied", targets: targets[:1], advance: time.Second},
+	}
+	for _, step := range steps {
+		targets = step.targets
+		now = now.Add(step.advance)
+		got, err := s.Sync(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Sync: %v", step.desc, err)
+		}
+		if got.Pending != step.wantPending || got.Updated != step.wantUpdated {
+			t.Fatalf("%s: Sync = %+v, want pending %t and updated %t", step.desc, got, step.wantPending, step.wantUpdated)
+		}
+	}
+	if len(a.payloads) != 1 {
+		t.Fatalf("%d updates, want 1", len(a.payloads))
+	}
+}
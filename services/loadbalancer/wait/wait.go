@@ -2,6 +2,7 @@ package wait
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -37,6 +38,71 @@ type APIClientInterface interface {
 	GetServiceStatusExecute(ctx context.Context, projectId string) (*loadbalancer.GetServiceStatusResponse, error)
 }
 
+// LoadBalancerError is an error reported by a load balancer, e.g. a floating IP that can't be configured
+type LoadBalancerError struct {
+	// Type is the part of the load balancer that encountered the error, e.g. TYPE_FIP_NOT_CONFIGURED
+	Type        string
+	Description string
+}
+
+// LoadBalancerErrors is returned by the wait handlers if the load balancer reports errors
+type LoadBalancerErrors struct {
+	// Operation is the operation waited for, e.g. "create"
+	Operation    string
+	InstanceName string
+	Status       string
+	Errors       []LoadBalancerError
+}
+
+func (e *LoadBalancerErrors) Error() string {
+	errs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = fmt.Sprintf("%s: %s", err.Type, err.Description)
+	}
+	return fmt.Sprintf("%s failed for instance with name %s, got status %s and errors: %s", e.Operation, e.InstanceName, e.Status, strings.Join(errs, ";"))
+}
+
+// HasType returns true if one of the errors has the type
+func (e *LoadBalancerErrors) HasType(errorType string) bool {
+	for _, err := range e.Errors {
+		if err.Type == errorType {
+			return true
+		}
+	}
+	return false
+}
+
+// loadBalancerErrors returns the errors reported by the load balancer, nil if there are none
+func loadBalancerErrors(s *loadbalancer.LoadBalancer, operation, instanceName string) error {
+	if s.Errors == nil || len(*s.Errors) == 0 {
+		return nil
+	}
+	e := &LoadBalancerErrors{Operation: operation, InstanceName: instanceName}
+	if s.Status != nil {
+		e.Status = *s.Status
+	}
+	for _, err := range *s.Errors {
+		lbErr := LoadBalancerError{}
+		if err.Type != nil {
+			lbErr.Type = *err.Type
+		}
+		if err.Description != nil {
+			lbErr.Description = *err.Description
+		}
+		e.Errors = append(e.Errors, lbErr)
+	}
+	return e
+}
+
+// IsStaleVersionError returns true if an update failed because the version it was calculated for isn't the current version of the load balancer anymore
+func IsStaleVersionError(err error) bool {
+	var oapiErr *oapierror.GenericOpenAPIError
+	if !errors.As(err, &oapiErr) {
+		return false
+	}
+	return oapiErr.StatusCode == http.StatusConflict || oapiErr.StatusCode == http.StatusPreconditionFailed
+}
+
 // CreateLoadBalancerWaitHandler will wait for load balancer creation
 func CreateLoadBalancerWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceName string) *wait.AsyncActionHandler[loadbalancer.LoadBalancer] {
 	handler := wait.New(func() (waitFinished bool, response *loadbalancer.LoadBalancer, err error) {
@@ -48,12 +114,8 @@ func CreateLoadBalancerWaitHandler(ctx context.Context, a APIClientInterface, pr
 			return false, nil, nil
 		}
 
-		var errors []string
-		if s.Errors != nil && len(*s.Errors) > 0 {
-			for _, err := range *s.Errors {
-				errors = append(errors, fmt.Sprintf("%s: %s", *err.Type, *err.Description))
-			}
-			return true, s, fmt.Errorf("create failed for instance with name %s, got status %s and errors: %s", instanceName, *s.Status, strings.Join(errors, ";"))
+		if err := loadBalancerErrors(s, "create", instanceName); err != nil {
+			return true, s, err
 		}
 
 		switch *s.Status {
@@ -75,53 +137,79 @@ func CreateLoadBalancerWaitHandler(ctx context.Context, a APIClientInterface, pr
 	return handler
 }
 
-// DeleteLoadBalancerWaitHandler will wait for load balancer deletion
-func DeleteLoadBalancerWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetLoadBalancerExecute(ctx, projectId, instanceId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError")
-		}
-		if oapiErr.StatusCode != http.StatusNotFound {
-			return false, nil, err
-		}
-		return true, nil, nil
-	})
-	handler.SetTimeout(15 * time.Minute)
-	return handler
+// UpdateLoadBalancerWaitHandler will wait for a load balancer update to be applied.
+// previousVersion is the version the update was calculated for, i.e. the version of its payload. The update is applied once the load balancer has another version and is ready.
+func UpdateLoadBalancerWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceName, previousVersion string) *wait.AsyncActionHandler[loadbalancer.LoadBalancer] {
+	return updateWaitHandler(ctx, a, projectId, instanceName, previousVersion, "update")
 }
 
-// EnableServiceWaitHandler will wait for functionality to be enabled
-func EnableServiceWaitHandler(ctx context.Context, a APIClientInterface, projectId string) *wait.AsyncActionHandler[loadbalancer.GetServiceStatusResponse] {
-	handler := wait.New(func() (waitFinished bool, response *loadbalancer.GetServiceStatusResponse, err error) {
-		s, err := a.GetServiceStatusExecute(ctx, projectId)
+// UpdateTargetPoolWaitHandler will wait for a target pool update to be applied.
+// previousVersion is the version of the load balancer before the update. The update is applied once the load balancer has another version and is ready.
+func UpdateTargetPoolWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceName, previousVersion string) *wait.AsyncActionHandler[loadbalancer.LoadBalancer] {
+	return updateWaitHandler(ctx, a, projectId, instanceName, previousVersion, "target pool update")
+}
+
+func updateWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceName, previousVersion, operation string) *wait.AsyncActionHandler[loadbalancer.LoadBalancer] {
+	handler := wait.New(func() (waitFinished bool, response *loadbalancer.LoadBalancer, err error) {
+		s, err := a.GetLoadBalancerExecute(ctx, projectId, instanceName)
 		if err != nil {
 			return false, nil, err
 		}
-		if s == nil || s.Status == nil {
+		// The errors and status of the previous version are stale until the load balancer has a new version
+		if s == nil || s.Version == nil || *s.Version == previousVersion || s.Status == nil {
 			return false, nil, nil
 		}
+		if err := loadBalancerErrors(s, operation, instanceName); err != nil {
+			return true, s, err
+		}
+
 		switch *s.Status {
-		case FunctionalityStatusReady:
+		case InstanceStatusReady:
 			return true, s, nil
-		case FunctionalityStatusUnspecified:
-			return false, nil, nil
-		case FunctionalityStatusDisabled:
+		case InstanceStatusUnspecified, InstanceStatusPending:
 			return false, nil, nil
-		case FunctionalityStatusUpdating:
-			return false, nil, nil
-		case FunctionalityStatusDeleting:
-			return true, s, fmt.Errorf("enabling load balancing failed for project %s, got status %s", projectId, FunctionalityStatusDeleting)
-		case FunctionalityStatusFailed:
-			return true, s, fmt.Errorf("enabling load balancing failed for project %s, got status %s", projectId, FunctionalityStatusFailed)
+		case InstanceStatusTerminating, InstanceStatusError:
+			return true, s, fmt.Errorf("%s failed for instance with name %s, got status %s", operation, instanceName, *s.Status)
 		default:
-			return true, s, fmt.Errorf("load balancing for project %s has unexpected status %s", projectId, *s.Status)
+			return true, s, fmt.Errorf("instance with name %s has unexpected status %s", instanceName, *s.Status)
 		}
 	})
+	handler.SetTimeout(45 * time.Minute)
+	return handler
+}
+
+// DeleteLoadBalancerWaitHandler will wait for load balancer deletion
+func DeleteLoadBalancerWaitHandler(ctx context.Context, a APIClientInterface, projectId, instanceId string) *wait.AsyncActionHandler[struct{}] {
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetLoadBalancerExecute(ctx, projectId, instanceId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess().
+		Handler()
+	handler.SetTimeout(15 * time.Minute)
+	return handler
+}
+
+// EnableServiceWaitHandler will wait for functionality to be enabled
+func EnableServiceWaitHandler(ctx context.Context, a APIClientInterface, projectId string) *wait.AsyncActionHandler[loadbalancer.GetServiceStatusResponse] {
+	handler := wait.ForState(func() (*loadbalancer.GetServiceStatusResponse, error) {
+		return a.GetServiceStatusExecute(ctx, projectId)
+	}, func(s *loadbalancer.GetServiceStatusResponse) (string, error) {
+		if s == nil || s.Status == nil {
+			return FunctionalityStatusUnspecified, nil
+		}
+		return *s.Status, nil
+	}).
+		Success(FunctionalityStatusReady).
+		Failure(FunctionalityStatusDeleting, FunctionalityStatusFailed).
+		Transient(FunctionalityStatusUnspecified, FunctionalityStatusDisabled, FunctionalityStatusUpdating).
+		OnFailure(func(_ *loadbalancer.GetServiceStatusResponse, state string) error {
+			return fmt.Errorf("enabling load balancing failed for project %s, got status %s", projectId, state)
+		}).
+		OnUnexpected(func(_ *loadbalancer.GetServiceStatusResponse, state string) error {
+			return fmt.Errorf("load balancing for project %s has unexpected status %s", projectId, state)
+		}).
+		Handler()
 	handler.SetTimeout(15 * time.Minute)
 	return handler
 }
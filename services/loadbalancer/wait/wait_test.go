@@ -2,11 +2,16 @@ package wait
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/core/wait"
 	"github.com/stackitcloud/stackit-sdk-go/services/loadbalancer"
 )
 
@@ -16,6 +21,8 @@ type apiClientMocked struct {
 	instanceStatus              string
 	instanceIsDeleted           bool
 	instanceGetFails            bool
+	instanceVersion             string
+	instanceErrors              []loadbalancer.LoadBalancerError
 	functionalityStatus         string
 	functionalityStatusGetFails bool
 }
@@ -33,10 +40,17 @@ func (a *apiClientMocked) GetLoadBalancerExecute(_ context.Context, _, _ string)
 		}
 	}
 
-	return &loadbalancer.LoadBalancer{
+	lb := &loadbalancer.LoadBalancer{
 		Name:   &a.instanceName,
 		Status: &a.instanceStatus,
-	}, nil
+	}
+	if a.instanceVersion != "" {
+		lb.Version = &a.instanceVersion
+	}
+	if a.instanceErrors != nil {
+		lb.Errors = &a.instanceErrors
+	}
+	return lb, nil
 }
 func (a *apiClientMocked) GetServiceStatusExecute(_ context.Context, _ string) (*loadbalancer.GetServiceStatusResponse, error) {
 	if a.functionalityStatusGetFails {
@@ -125,6 +139,163 @@ func TestCreateInstanceWaitHandler(t *testing.T) {
 	}
 }
 
+func TestCreateInstanceWaitHandlerErrors(t *testing.T) {
+	instanceName := "foo-bar"
+	apiClient := &apiClientMocked{
+		instanceName:   instanceName,
+		instanceStatus: InstanceStatusError,
+		instanceErrors: []loadbalancer.LoadBalancerError{
+			{Type: utils.Ptr("TYPE_FIP_NOT_CONFIGURED"), Description: utils.Ptr("Floating IP could not be found")},
+		},
+	}
+
+	_, err := CreateLoadBalancerWaitHandler(context.Background(), apiClient, "", instanceName).SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+	var lbErrs *LoadBalancerErrors
+	if !errors.As(err, &lbErrs) {
+		t.Fatalf("handler error = %v, want LoadBalancerErrors", err)
+	}
+	want := &LoadBalancerErrors{
+		Operation:    "create",
+		InstanceName: instanceName,
+		Status:       InstanceStatusError,
+		Errors:       []LoadBalancerError{{Type: "TYPE_FIP_NOT_CONFIGURED", Description: "Floating IP could not be found"}},
+	}
+	if diff := cmp.Diff(lbErrs, want); diff != "" {
+		t.Fatalf("unexpected errors (-got +want): %s", diff)
+	}
+	if !lbErrs.HasType("TYPE_FIP_NOT_CONFIGURED") || lbErrs.HasType("TYPE_UNSPECIFIED") {
+		t.Fatalf("HasType doesn't match the errors %v", lbErrs.Errors)
+	}
+}
+
+func TestUpdateInstanceWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc             string
+		instanceGetFails bool
+		instanceStatus   string
+		instanceVersion  string
+		instanceErrors   []loadbalancer.LoadBalancerError
+		wantErr          bool
+		wantLBErrors     bool
+		wantResp         bool
+	}{
+		{
+			desc:            "update_succeeded",
+			instanceStatus:  InstanceStatusReady,
+			instanceVersion: "v2",
+			wantResp:        true,
+		},
+		{
+			desc:            "version_not_advanced",
+			instanceStatus:  InstanceStatusReady,
+			instanceVersion: "v1",
+			wantErr:         true,
+		},
+		{
+			desc:            "stale_errors",
+			instanceStatus:  InstanceStatusError,
+			instanceVersion: "v1",
+			instanceErrors:  []loadbalancer.LoadBalancerError{{Type: utils.Ptr("TYPE_UNSPECIFIED"), Description: utils.Ptr("old error")}},
+			wantErr:         true,
+		},
+		{
+			desc:            "pending",
+			instanceStatus:  InstanceStatusPending,
+			instanceVersion: "v2",
+			wantErr:         true,
+		},
+		{
+			desc:            "update_failed",
+			instanceStatus:  InstanceStatusError,
+			instanceVersion: "v2",
+			instanceErrors:  []loadbalancer.LoadBalancerError{{Type: utils.Ptr("TYPE_FIP_NOT_CONFIGURED"), Description: utils.Ptr("Floating IP could not be found")}},
+			wantErr:         true,
+			wantLBErrors:    true,
+			wantResp:        true,
+		},
+		{
+			desc:            "update_failed_2",
+			instanceStatus:  InstanceStatusTerminating,
+			instanceVersion: "v2",
+			wantErr:         true,
+			wantResp:        true,
+		},
+		{
+			desc:             "instance_get_fails",
+			instanceGetFails: true,
+			wantErr:          true,
+		},
+	}
+	for _, tt := range tests {
+		for _, handlerFunc := range []func(context.Context, APIClientInterface, string, string, string) *wait.AsyncActionHandler[loadbalancer.LoadBalancer]{
+			UpdateLoadBalancerWaitHandler,
+			UpdateTargetPoolWaitHandler,
+		} {
+			t.Run(tt.desc, func(t *testing.T) {
+				instanceName := "foo-bar"
+
+				apiClient := &apiClientMocked{
+					instanceName:     instanceName,
+					instanceStatus:   tt.instanceStatus,
+					instanceVersion:  tt.instanceVersion,
+					instanceErrors:   tt.instanceErrors,
+					instanceGetFails: tt.instanceGetFails,
+				}
+
+				handler := handlerFunc(context.Background(), apiClient, "", instanceName, "v1")
+
+				gotRes, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+				}
+				var lbErrs *LoadBalancerErrors
+				if errors.As(err, &lbErrs) != tt.wantLBErrors {
+					t.Fatalf("handler error = %v, want LoadBalancerErrors %t", err, tt.wantLBErrors)
+				}
+				if (gotRes != nil) != tt.wantResp {
+					t.Fatalf("handler gotRes = %v, wantResp %t", gotRes, tt.wantResp)
+				}
+			})
+		}
+	}
+}
+
+func TestIsStaleVersionError(t *testing.T) {
+	tests := []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{
+			desc: "conflict",
+			err:  fmt.Errorf("update: %w", &oapierror.GenericOpenAPIError{StatusCode: http.StatusConflict}),
+			want: true,
+		},
+		{
+			desc: "precondition_failed",
+			err:  &oapierror.GenericOpenAPIError{StatusCode: http.StatusPreconditionFailed},
+			want: true,
+		},
+		{
+			desc: "bad_request",
+			err:  &oapierror.GenericOpenAPIError{StatusCode: http.StatusBadRequest},
+		},
+		{
+			desc: "other",
+			err:  fmt.Errorf("other"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := IsStaleVersionError(tt.err); got != tt.want {
+				t.Fatalf("IsStaleVersionError = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDeleteInstanceWaitHandler(t *testing.T) {
 	tests := []struct {
 		desc              string
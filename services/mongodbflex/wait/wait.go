@@ -3,11 +3,9 @@ package wait
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"sort"
 	"time"
 
-	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
 	"github.com/stackitcloud/stackit-sdk-go/core/wait"
 	"github.com/stackitcloud/stackit-sdk-go/services/mongodbflex"
 )
@@ -33,29 +31,17 @@ type APIClientInstanceInterface interface {
 
 // CreateInstanceWaitHandler will wait for instance creation
 func CreateInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[mongodbflex.GetInstanceResponse] {
-	handler := wait.New(func() (waitFinished bool, response *mongodbflex.GetInstanceResponse, err error) {
-		s, err := a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s == nil || s.Item == nil || s.Item.Id == nil || *s.Item.Id != instanceId || s.Item.Status == nil {
-			return false, nil, nil
-		}
-		switch *s.Item.Status {
-		default:
-			return true, s, fmt.Errorf("instance with id %s has unexpected status %s", instanceId, *s.Item.Status)
-		case InstanceStateEmpty:
-			return false, nil, nil
-		case InstanceStateProcessing:
-			return false, nil, nil
-		case InstanceStateUnknown:
-			return false, nil, nil
-		case InstanceStateSuccess:
-			return true, s, nil
-		case InstanceStateFailed:
-			return true, s, fmt.Errorf("create failed for instance with id %s", instanceId)
-		}
-	})
+	handler := wait.ForState(getInstance(ctx, a, projectId, instanceId), instanceState(instanceId)).
+		Success(InstanceStateSuccess).
+		Failure(InstanceStateFailed).
+		Transient(InstanceStateEmpty, InstanceStateProcessing, InstanceStateUnknown).
+		OnFailure(func(_ *mongodbflex.GetInstanceResponse, _ string) error {
+			return fmt.Errorf("create failed for instance with id %s", instanceId)
+		}).
+		OnUnexpected(func(_ *mongodbflex.GetInstanceResponse, state string) error {
+			return fmt.Errorf("instance with id %s has unexpected status %s", instanceId, state)
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	handler.SetSleepBeforeWait(5 * time.Second)
 	return handler
@@ -112,29 +98,17 @@ func RestoreInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterfac
 
 // UpdateInstanceWaitHandler will wait for instance update
 func UpdateInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[mongodbflex.GetInstanceResponse] {
-	handler := wait.New(func() (waitFinished bool, response *mongodbflex.GetInstanceResponse, err error) {
-		s, err := a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s == nil || s.Item == nil || s.Item.Id == nil || *s.Item.Id != instanceId || s.Item.Status == nil {
-			return false, nil, nil
-		}
-		switch *s.Item.Status {
-		default:
-			return true, s, fmt.Errorf("instance with id %s has unexpected status %s", instanceId, *s.Item.Status)
-		case InstanceStateEmpty:
-			return false, nil, nil
-		case InstanceStateProcessing:
-			return false, nil, nil
-		case InstanceStateUnknown:
-			return false, nil, nil
-		case InstanceStateSuccess:
-			return true, s, nil
-		case InstanceStateFailed:
-			return true, s, fmt.Errorf("update failed for instance with id %s", instanceId)
-		}
-	})
+	handler := wait.ForState(getInstance(ctx, a, projectId, instanceId), instanceState(instanceId)).
+		Success(InstanceStateSuccess).
+		Failure(InstanceStateFailed).
+		Transient(InstanceStateEmpty, InstanceStateProcessing, InstanceStateUnknown).
+		OnFailure(func(_ *mongodbflex.GetInstanceResponse, _ string) error {
+			return fmt.Errorf("update failed for instance with id %s", instanceId)
+		}).
+		OnUnexpected(func(_ *mongodbflex.GetInstanceResponse, state string) error {
+			return fmt.Errorf("instance with id %s has unexpected status %s", instanceId, state)
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
@@ -146,20 +120,28 @@ func PartialUpdateInstanceWaitHandler(ctx context.Context, a APIClientInstanceIn
 
 // DeleteInstanceWaitHandler will wait for instance deletion
 func DeleteInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError")
-		}
-		if oapiErr.StatusCode != http.StatusNotFound {
-			return false, nil, err
-		}
-		return true, nil, nil
-	})
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetInstanceExecute(ctx, projectId, instanceId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess().
+		Handler()
 	handler.SetTimeout(15 * time.Minute)
 	return handler
 }
+
+func getInstance(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) wait.GetFunc[mongodbflex.GetInstanceResponse] {
+	return func() (*mongodbflex.GetInstanceResponse, error) {
+		return a.GetInstanceExecute(ctx, projectId, instanceId)
+	}
+}
+
+// instanceState returns the status of the instance, or an empty state while the response is incomplete or for another instance
+func instanceState(instanceId string) wait.StateFunc[mongodbflex.GetInstanceResponse] {
+	return func(s *mongodbflex.GetInstanceResponse) (string, error) {
+		if s == nil || s.Item == nil || s.Item.Id == nil || *s.Item.Id != instanceId || s.Item.Status == nil {
+			return InstanceStateEmpty, nil
+		}
+		return *s.Item.Status, nil
+	}
+}
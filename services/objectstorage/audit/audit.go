@@ -0,0 +1,266 @@
+// Package audit reports the access keys of all credentials groups of a project by expiration status and enforces
+// a policy on them, deleting the access keys violating it.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+)
+
+// Status is the expiration status of an access key
+type Status string
+
+const (
+	StatusValid    Status = "valid"
+	StatusExpiring Status = "expiring"
+	StatusExpired  Status = "expired"
+	StatusNoExpiry Status = "no_expiry"
+)
+
+// Violation is a rule of the Policy an access key violates
+type Violation string
+
+const (
+	ViolationExpired         Violation = "expired"
+	ViolationMaxLifetime     Violation = "max_lifetime"
+	ViolationMaxKeysPerGroup Violation = "max_keys_per_group"
+)
+
+// Policy are the rules the access keys are checked against. Rules with zero values are not checked.
+type Policy struct {
+	// ExpiringWithin is the window before the expiration in which access keys are reported as expiring
+	ExpiringWithin time.Duration
+	// MaxLifetime is the longest an access key may remain valid. As the API doesn't return the creation time of
+	// access keys, it is checked against their remaining validity; access keys without expiration always violate it.
+	MaxLifetime time.Duration
+	// MaxKeysPerGroup is the maximum number of access keys in a credentials group. The access keys exceeding it are
+	// the expired ones first, then the ones expiring the soonest.
+	MaxKeysPerGroup int
+	// DisallowExpired makes expired access keys violate the policy, so they are deleted when it is enforced
+	DisallowExpired bool
+}
+
+// Options configures an audit
+type Options struct {
+	Policy Policy
+	// Enforce deletes the access keys violating the policy
+	Enforce bool
+}
+
+// KeyReport is the result of the audit of an access key
+type KeyReport struct {
+	CredentialsGroupId   string      `json:"credentialsGroupId"`
+	CredentialsGroupName string      `json:"credentialsGroupName"`
+	KeyId                string      `json:"keyId"`
+	DisplayName          string      `json:"displayName"`
+	Expires              *time.Time  `json:"expires,omitempty"`
+	Status               Status      `json:"status"`
+	Violations           []Violation `json:"violations,omitempty"`
+	Deleted              bool        `json:"deleted"`
+	DeleteError          string      `json:"deleteError,omitempty"`
+}
+
+// Report is the result of the audit of a project
+type Report struct {
+	ProjectId string      `json:"projectId"`
+	AuditedAt time.Time   `json:"auditedAt"`
+	Enforced  bool        `json:"enforced"`
+	Keys      []KeyReport `json:"keys"`
+}
+
+// Violating returns the reports of the access keys violating the policy
+func (r *Report) Violating() []KeyReport {
+	keys := []KeyReport{}
+	for _, key := range r.Keys {
+		if len(key.Violations) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// WithStatus returns the reports of the access keys with the given status
+func (r *Report) WithStatus(status Status) []KeyReport {
+	keys := []KeyReport{}
+	for _, key := range r.Keys {
+		if key.Status == status {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// JSON renders the report as indented JSON
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// apiClient is the part of the Object Storage API used by this package
+type apiClient interface {
+	ListCredentialsGroupsExecute(ctx context.Context, projectId string) (*objectstorage.ListCredentialsGroupsResponse, error)
+	listAccessKeys(ctx context.Context, projectId, credentialsGroupId string) (*objectstorage.ListAccessKeysResponse, error)
+	deleteAccessKey(ctx context.Context, projectId, credentialsGroupId, keyId string) error
+}
+
+type apiClientAdapter struct {
+	*objectstorage.APIClient
+}
+
+func (a apiClientAdapter) listAccessKeys(ctx context.Context, projectId, credentialsGroupId string) (*objectstorage.ListAccessKeysResponse, error) {
+	return a.ListAccessKeys(ctx, projectId).CredentialsGroup(credentialsGroupId).Execute()
+}
+
+func (a apiClientAdapter) deleteAccessKey(ctx context.Context, projectId, credentialsGroupId, keyId string) error {
+	_, err := a.DeleteAccessKey(ctx, projectId, keyId).CredentialsGroup(credentialsGroupId).Execute()
+	return err
+}
+
+// Audit lists the access keys of all credentials groups of the project and checks them against the policy.
+// If the policy is enforced, the violating access keys are deleted; failed deletions are recorded in the report
+// and an error is returned along with it.
+func Audit(ctx context.Context, client *objectstorage.APIClient, projectId string, opts Options) (*Report, error) {
+	return audit(ctx, apiClientAdapter{client}, projectId, opts, time.Now())
+}
+
+func audit(ctx context.Context, a apiClient, projectId string, opts Options, now time.Time) (*Report, error) {
+	groupsResp, err := a.ListCredentialsGroupsExecute(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("list credentials groups: %w", err)
+	}
+	groups := []objectstorage.CredentialsGroup{}
+	if groupsResp.CredentialsGroups != nil {
+		groups = *groupsResp.CredentialsGroups
+	}
+
+	report := &Report{ProjectId: projectId, AuditedAt: now, Enforced: opts.Enforce, Keys: []KeyReport{}}
+	for _, group := range groups {
+		groupId := valueOf(group.CredentialsGroupId)
+		keysResp, err := a.listAccessKeys(ctx, projectId, groupId)
+		if err != nil {
+			return nil, fmt.Errorf("list access keys of credentials group %s: %w", groupId, err)
+		}
+		keys := []KeyReport{}
+		if keysResp.AccessKeys != nil {
+			for i := range *keysResp.AccessKeys {
+				keyReport, err := checkKey(&(*keysResp.AccessKeys)[i], &opts.Policy, now)
+				if err != nil {
+					return nil, fmt.Errorf("credentials group %s: %w", groupId, err)
+				}
+				keyReport.CredentialsGroupId = groupId
+				keyReport.CredentialsGroupName = valueOf(group.DisplayName)
+				keys = append(keys, *keyReport)
+			}
+		}
+		checkKeysPerGroup(keys, &opts.Policy)
+		report.Keys = append(report.Keys, keys...)
+	}
+
+	if !opts.Enforce {
+		return report, nil
+	}
+	failed := []string{}
+	for i := range report.Keys {
+		key := &report.Keys[i]
+		if len(key.Violations) == 0 {
+			continue
+		}
+		if err := a.deleteAccessKey(ctx, projectId, key.CredentialsGroupId, key.KeyId); err != nil {
+			key.DeleteError = err.Error()
+			failed = append(failed, key.KeyId)
+			continue
+		}
+		key.Deleted = true
+	}
+	if len(failed) > 0 {
+		return report, fmt.Errorf("delete access keys %s failed", strings.Join(failed, ", "))
+	}
+	return report, nil
+}
+
+// checkKey returns the report of the access key with its status and the violations of the policy that don't depend on
+// the other access keys of its credentials group
+func checkKey(key *objectstorage.AccessKey, policy *Policy, now time.Time) (*KeyReport, error) {
+	keyReport := &KeyReport{
+		KeyId:       valueOf(key.KeyId),
+		DisplayName: valueOf(key.DisplayName),
+		Violations:  []Violation{},
+	}
+	expires, err := parseExpires(valueOf(key.Expires))
+	if err != nil {
+		return nil, fmt.Errorf("access key %s: %w", keyReport.KeyId, err)
+	}
+	keyReport.Expires = expires
+
+	switch {
+	case expires == nil:
+		keyReport.Status = StatusNoExpiry
+	case !now.Before(*expires):
+		keyReport.Status = StatusExpired
+	case policy.ExpiringWithin > 0 && expires.Sub(now) <= policy.ExpiringWithin:
+		keyReport.Status = StatusExpiring
+	default:
+		keyReport.Status = StatusValid
+	}
+
+	if keyReport.Status == StatusExpired && policy.DisallowExpired {
+		keyReport.Violations = append(keyReport.Violations, ViolationExpired)
+	}
+	if policy.MaxLifetime > 0 && (expires == nil || expires.Sub(now) > policy.MaxLifetime) {
+		keyReport.Violations = append(keyReport.Violations, ViolationMaxLifetime)
+	}
+	return keyReport, nil
+}
+
+// checkKeysPerGroup adds the violation of the maximum number of access keys to the reports of the access keys of a
+// credentials group exceeding it. The access keys already violating the policy are not counted, as they are deleted anyway.
+func checkKeysPerGroup(keys []KeyReport, policy *Policy) {
+	if policy.MaxKeysPerGroup <= 0 {
+		return
+	}
+	remaining := []*KeyReport{}
+	for i := range keys {
+		if len(keys[i].Violations) == 0 {
+			remaining = append(remaining, &keys[i])
+		}
+	}
+	if len(remaining) <= policy.MaxKeysPerGroup {
+		return
+	}
+	// Access keys expiring the soonest come first, access keys without expiration last
+	sort.SliceStable(remaining, func(i, j int) bool {
+		ei, ej := remaining[i].Expires, remaining[j].Expires
+		if ei == nil || ej == nil {
+			return ej == nil && ei != nil
+		}
+		return ei.Before(*ej)
+	})
+	for _, key := range remaining[:len(remaining)-policy.MaxKeysPerGroup] {
+		key.Violations = append(key.Violations, ViolationMaxKeysPerGroup)
+	}
+}
+
+// parseExpires returns the expiration of an access key, or nil if it doesn't expire
+func parseExpires(expires string) (*time.Time, error) {
+	if expires == "" || strings.EqualFold(expires, "never") {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, expires)
+	if err != nil {
+		return nil, fmt.Errorf("parse expiration: %w", err)
+	}
+	return &t, nil
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,278 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+)
+
+var now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+type apiClientMocked struct {
+	keys        map[string][]objectstorage.AccessKey
+	listFails   bool
+	deleteFails bool
+	deleted     []string
+}
+
+func (a *apiClientMocked) ListCredentialsGroupsExecute(_ context.Context, _ string) (*objectstorage.ListCredentialsGroupsResponse, error) {
+	return &objectstorage.ListCredentialsGroupsResponse{CredentialsGroups: &[]objectstorage.CredentialsGroup{
+		{CredentialsGroupId: utils.Ptr("default"), DisplayName: utils.Ptr("Default")},
+		{CredentialsGroupId: utils.Ptr("ci"), DisplayName: utils.Ptr("CI")},
+	}}, nil
+}
+
+func (a *apiClientMocked) listAccessKeys(_ context.Context, _, credentialsGroupId string) (*objectstorage.ListAccessKeysResponse, error) {
+	if a.listFails {
+		return nil, fmt.Errorf("list fails")
+	}
+	keys := a.keys[credentialsGroupId]
+	return &objectstorage.ListAccessKeysResponse{AccessKeys: &keys}, nil
+}
+
+func (a *apiClientMocked) deleteAccessKey(_ context.Context, _, credentialsGroupId, keyId string) error {
+	if a.deleteFails {
+		return fmt.Errorf("delete fails")
+	}
+	a.deleted = append(a.deleted, fmt.Sprintf("%s/%s", credentialsGroupId, keyId))
+	return nil
+}
+
+func fixtureKey(keyId, expires string) objectstorage.AccessKey {
+	return objectstorage.AccessKey{KeyId: utils.Ptr(keyId), DisplayName: utils.Ptr(keyId), Expires: utils.Ptr(expires)}
+}
+
+func fixtureKeys() map[string][]objectstorage.AccessKey {
+	return map[string][]objectstorage.AccessKey{
+		"default": {
+			fixtureKey("expired", "2024-05-01T00:00:00Z"),
+			fixtureKey("expiring", "2024-06-03T00:00:00.000Z"),
+			fixtureKey("valid", "2024-08-01T00:00:00Z"),
+			fixtureKey("long", "2025-06-01T00:00:00Z"),
+			fixtureKey("never", ""),
+		},
+		"ci": {
+			fixtureKey("ci-1", "2024-07-01T00:00:00Z"),
+			fixtureKey("ci-2", "2024-07-02T00:00:00Z"),
+			fixtureKey("ci-3", "2024-06-15T00:00:00Z"),
+		},
+	}
+}
+
+func statuses(report *Report) map[string]Status {
+	got := map[string]Status{}
+	for _, key := range report.Keys {
+		got[key.KeyId] = key.Status
+	}
+	return got
+}
+
+func violations(report *Report) map[string][]Violation {
+	got := map[string][]Violation{}
+	for _, key := range report.Violating() {
+		got[key.KeyId] = key.Violations
+	}
+	return got
+}
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		desc           string
+		policy         Policy
+		wantViolations map[string][]Violation
+	}{
+		{
+			desc:           "report_only",
+			policy:         Policy{ExpiringWithin: 7 * 24 * time.Hour},
+			wantViolations: map[string][]Violation{},
+		},
+		{
+			desc: "max_lifetime",
+			policy: Policy{
+				ExpiringWithin:  7 * 24 * time.Hour,
+				MaxLifetime:     90 * 24 * time.Hour,
+				DisallowExpired: true,
+			},
+			wantViolations: map[string][]Violation{
+				"expired": {ViolationExpired},
+				"long":    {ViolationMaxLifetime},
+				"never":   {ViolationMaxLifetime},
+			},
+		},
+		{
+			desc:   "max_keys_per_group",
+			policy: Policy{ExpiringWithin: 7 * 24 * time.Hour, MaxKeysPerGroup: 2},
+			wantViolations: map[string][]Violation{
+				"expired":  {ViolationMaxKeysPerGroup},
+				"expiring": {ViolationMaxKeysPerGroup},
+				"valid":    {ViolationMaxKeysPerGroup},
+				"ci-3":     {ViolationMaxKeysPerGroup},
+			},
+		},
+		{
+			desc:   "max_keys_per_group_after_other_violations",
+			policy: Policy{MaxKeysPerGroup: 2, MaxLifetime: 90 * 24 * time.Hour, DisallowExpired: true},
+			wantViolations: map[string][]Violation{
+				"expired": {ViolationExpired},
+				"long":    {ViolationMaxLifetime},
+				"never":   {ViolationMaxLifetime},
+				"ci-3":    {ViolationMaxKeysPerGroup},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{keys: fixtureKeys()}
+			report, err := audit(context.Background(), a, "pid", Options{Policy: tt.policy}, now)
+			if err != nil {
+				t.Fatalf("audit: %v", err)
+			}
+			if diff := cmp.Diff(violations(report), tt.wantViolations); diff != "" {
+				t.Fatalf("unexpected violations (-got +want): %s", diff)
+			}
+			if len(a.deleted) != 0 {
+				t.Fatalf("access keys deleted without enforcing the policy: %v", a.deleted)
+			}
+		})
+	}
+}
+
+func TestAuditStatus(t *testing.T) {
+	a := &apiClientMocked{keys: fixtureKeys()}
+	report, err := audit(context.Background(), a, "pid", Options{Policy: Policy{ExpiringWithin: 7 * 24 * time.Hour}}, now)
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	want := map[string]Status{
+		"expired":  StatusExpired,
+		"expiring": StatusExpiring,
+		"valid":    StatusValid,
+		"long":     StatusValid,
+		"never":    StatusNoExpiry,
+		"ci-1":     StatusValid,
+		"ci-2":     StatusValid,
+		"ci-3":     StatusValid,
+	}
+	if diff := cmp.Diff(statuses(report), want); diff != "" {
+		t.Fatalf("unexpected statuses (-got +want): %s", diff)
+	}
+	if got := len(report.WithStatus(StatusValid)); got != 5 {
+		t.Fatalf("%d valid access keys, want 5", got)
+	}
+}
+
+func TestAuditEnforce(t *testing.T) {
+	policy := Policy{MaxLifetime: 90 * 24 * time.Hour, DisallowExpired: true}
+	tests := []struct {
+		desc        string
+		deleteFails bool
+		wantDeleted []string
+		wantErr     bool
+	}{
+		{
+			desc:        "ok",
+			wantDeleted: []string{"default/expired", "default/long", "default/never"},
+		},
+		{
+			desc:        "delete_fails",
+			deleteFails: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{keys: fixtureKeys(), deleteFails: tt.deleteFails}
+			report, err := audit(context.Background(), a, "pid", Options{Policy: policy, Enforce: true}, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("audit error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if report == nil {
+				t.Fatalf("audit returned no report")
+			}
+			if diff := cmp.Diff(a.deleted, tt.wantDeleted); diff != "" {
+				t.Fatalf("unexpected deletions (-got +want): %s", diff)
+			}
+			for _, key := range report.Violating() {
+				if key.Deleted == tt.deleteFails || (key.DeleteError != "") != tt.deleteFails {
+					t.Fatalf("unexpected deletion result of access key %s: %+v", key.KeyId, key)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		a    *apiClientMocked
+	}{
+		{
+			desc: "list_fails",
+			a:    &apiClientMocked{listFails: true},
+		},
+		{
+			desc: "invalid_expiration",
+			a: &apiClientMocked{keys: map[string][]objectstorage.AccessKey{
+				"default": {fixtureKey("key", "tomorrow")},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if _, err := audit(context.Background(), tt.a, "pid", Options{}, now); err == nil {
+				t.Fatalf("audit succeeded")
+			}
+		})
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	expires := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	report := &Report{
+		ProjectId: "pid",
+		AuditedAt: now,
+		Enforced:  true,
+		Keys: []KeyReport{{
+			CredentialsGroupId:   "default",
+			CredentialsGroupName: "Default",
+			KeyId:                "key",
+			DisplayName:          "key",
+			Expires:              &expires,
+			Status:               StatusValid,
+			Violations:           []Violation{ViolationMaxKeysPerGroup},
+			Deleted:              true,
+		}},
+	}
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	got := map[string]interface{}{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal report: %v", err)
+	}
+	want := map[string]interface{}{
+		"projectId": "pid",
+		"auditedAt": "2024-06-01T00:00:00Z",
+		"enforced":  true,
+		"keys": []interface{}{map[string]interface{}{
+			"credentialsGroupId":   "default",
+			"credentialsGroupName": "Default",
+			"keyId":                "key",
+			"displayName":          "key",
+			"expires":              "2024-07-01T00:00:00Z",
+			"status":               "valid",
+			"violations":           []interface{}{"max_keys_per_group"},
+			"deleted":              true,
+		}},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected JSON (-got +want): %s", diff)
+	}
+}
@@ -0,0 +1,171 @@
+// Package provision ensures that Object Storage resources exist in a project, creating them only if they are missing and
+// waiting until they can be used. The helpers are idempotent and tolerate concurrent creations of the same resource.
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	corewait "github.com/stackitcloud/stackit-sdk-go/core/wait"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage/wait"
+)
+
+// apiClient is the part of the Object Storage API used by this package.
+type apiClient interface {
+	wait.APIClientBucketInterface
+	wait.APIClientServiceInterface
+	wait.APIClientCredentialsGroupInterface
+	EnableServiceExecute(ctx context.Context, projectId string) (*objectstorage.ProjectStatus, error)
+	CreateBucketExecute(ctx context.Context, projectId, bucketName string) (*objectstorage.CreateBucketResponse, error)
+	createCredentialsGroup(ctx context.Context, projectId, displayName string) (*objectstorage.CreateCredentialsGroupResponse, error)
+}
+
+type apiClientAdapter struct {
+	*objectstorage.APIClient
+}
+
+func (a apiClientAdapter) createCredentialsGroup(ctx context.Context, projectId, displayName string) (*objectstorage.CreateCredentialsGroupResponse, error) {
+	payload := objectstorage.CreateCredentialsGroupPayload{DisplayName: &displayName}
+	return a.CreateCredentialsGroup(ctx, projectId).CreateCredentialsGroupPayload(payload).Execute()
+}
+
+// EnsureService enables Object Storage in the project if it isn't enabled yet, and waits until it is
+func EnsureService(ctx context.Context, client *objectstorage.APIClient, projectId string) (*objectstorage.ProjectStatus, error) {
+	return ensureService(ctx, apiClientAdapter{client}, projectId)
+}
+
+func ensureService(ctx context.Context, a apiClient, projectId string) (*objectstorage.ProjectStatus, error) {
+	status, err := a.GetServiceStatusExecute(ctx, projectId)
+	if err == nil {
+		return status, nil
+	}
+	if !isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("get service status: %w", err)
+	}
+	if _, err := a.EnableServiceExecute(ctx, projectId); err != nil && !isStatus(err, http.StatusConflict) {
+		return nil, fmt.Errorf("enable service: %w", err)
+	}
+	status, err = wait.EnableServiceWaitHandler(ctx, a, projectId).WaitWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for service enablement: %w", err)
+	}
+	return status, nil
+}
+
+// BucketNameTakenError is returned by EnsureBucket if the bucket name is taken, e.g. by a bucket in another project
+type BucketNameTakenError struct {
+	BucketName string
+}
+
+func (e *BucketNameTakenError) Error() string {
+	return fmt.Sprintf("bucket name %s is already taken", e.BucketName)
+}
+
+// EnsureBucket creates the bucket if it doesn't exist in the project yet, and waits until it can be used.
+// If the creation conflicts and the bucket isn't found in the project right after, the name is taken by another project
+// and a *BucketNameTakenError is returned.
+func EnsureBucket(ctx context.Context, client *objectstorage.APIClient, projectId, bucketName string) (*objectstorage.Bucket, error) {
+	return ensureBucket(ctx, apiClientAdapter{client}, projectId, bucketName)
+}
+
+func ensureBucket(ctx context.Context, a apiClient, projectId, bucketName string) (*objectstorage.Bucket, error) {
+	resp, err := a.GetBucketExecute(ctx, projectId, bucketName)
+	if err == nil {
+		return resp.Bucket, nil
+	}
+	if !isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("get bucket %s: %w", bucketName, err)
+	}
+	_, err = a.CreateBucketExecute(ctx, projectId, bucketName)
+	switch {
+	case isStatus(err, http.StatusConflict):
+		// The bucket was created concurrently in the project, or its name is taken elsewhere
+		resp, err = a.GetBucketExecute(ctx, projectId, bucketName)
+		if isStatus(err, http.StatusNotFound) {
+			return nil, &BucketNameTakenError{BucketName: bucketName}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get bucket %s: %w", bucketName, err)
+		}
+		return resp.Bucket, nil
+	case err != nil:
+		return nil, fmt.Errorf("create bucket %s: %w", bucketName, err)
+	}
+	resp, err = wait.CreateBucketWaitHandler(ctx, a, projectId, bucketName).WaitWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for bucket %s: %w", bucketName, err)
+	}
+	return resp.Bucket, nil
+}
+
+// EnsureCredentialsGroup returns the credentials group with the display name, creating it if the project has none.
+// It waits until the credentials group is listed, so that access keys can be created in it.
+func EnsureCredentialsGroup(ctx context.Context, client *objectstorage.APIClient, projectId, displayName string) (*objectstorage.CredentialsGroup, error) {
+	return ensureCredentialsGroup(ctx, apiClientAdapter{client}, projectId, displayName)
+}
+
+func ensureCredentialsGroup(ctx context.Context, a apiClient, projectId, displayName string) (*objectstorage.CredentialsGroup, error) {
+	group, err := findCredentialsGroupByName(ctx, a, projectId, displayName)
+	if err != nil {
+		return nil, fmt.Errorf("list credentials groups: %w", err)
+	}
+	if group != nil {
+		return group, nil
+	}
+
+	resp, err := a.createCredentialsGroup(ctx, projectId, displayName)
+	switch {
+	case isStatus(err, http.StatusConflict):
+		// The credentials group is being created concurrently, its id is only known once it is listed
+		group, err = credentialsGroupByNameWaitHandler(ctx, a, projectId, displayName).WaitWithContext(ctx)
+	case err != nil:
+		return nil, fmt.Errorf("create credentials group %s: %w", displayName, err)
+	case resp.CredentialsGroup == nil || resp.CredentialsGroup.CredentialsGroupId == nil:
+		return nil, fmt.Errorf("create credentials group %s: the response is missing the credentials group id", displayName)
+	default:
+		group, err = wait.CreateCredentialsGroupWaitHandler(ctx, a, projectId, *resp.CredentialsGroup.CredentialsGroupId).WaitWithContext(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wait for credentials group %s: %w", displayName, err)
+	}
+	return group, nil
+}
+
+func credentialsGroupByNameWaitHandler(ctx context.Context, a apiClient, projectId, displayName string) *corewait.AsyncActionHandler[objectstorage.CredentialsGroup] {
+	handler := corewait.New(func() (waitFinished bool, response *objectstorage.CredentialsGroup, err error) {
+		group, err := findCredentialsGroupByName(ctx, a, projectId, displayName)
+		if err != nil {
+			return false, nil, err
+		}
+		return group != nil, group, nil
+	})
+	handler.SetTimeout(1 * time.Minute)
+	return handler
+}
+
+func findCredentialsGroupByName(ctx context.Context, a apiClient, projectId, displayName string) (*objectstorage.CredentialsGroup, error) {
+	resp, err := a.ListCredentialsGroupsExecute(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	if resp.CredentialsGroups == nil {
+		return nil, nil
+	}
+	for i := range *resp.CredentialsGroups {
+		group := &(*resp.CredentialsGroups)[i]
+		if group.DisplayName != nil && *group.DisplayName == displayName {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+func isStatus(err error, statusCode int) bool {
+	var oapiErr *oapierror.GenericOpenAPIError
+	return errors.As(err, &oapiErr) && oapiErr.StatusCode == statusCode
+}
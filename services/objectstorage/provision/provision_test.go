@@ -0,0 +1,272 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+)
+
+type apiClientMocked struct {
+	serviceEnabled bool
+	buckets        map[string]bool
+	groups         []objectstorage.CredentialsGroup
+	// conflict makes creations fail with a conflict, while the resource is created concurrently
+	conflict    bool
+	createFails bool
+	// nameTaken makes bucket creations fail with a conflict, without the bucket being created in the project
+	nameTaken bool
+	calls     []string
+}
+
+func (a *apiClientMocked) create(call string) error {
+	a.calls = append(a.calls, call)
+	if a.createFails {
+		return &oapierror.GenericOpenAPIError{StatusCode: http.StatusBadRequest}
+	}
+	if a.conflict {
+		return &oapierror.GenericOpenAPIError{StatusCode: http.StatusConflict}
+	}
+	return nil
+}
+
+func (a *apiClientMocked) GetServiceStatusExecute(_ context.Context, projectId string) (*objectstorage.ProjectStatus, error) {
+	if !a.serviceEnabled {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusNotFound}
+	}
+	return &objectstorage.ProjectStatus{Project: utils.Ptr(projectId)}, nil
+}
+
+func (a *apiClientMocked) EnableServiceExecute(_ context.Context, projectId string) (*objectstorage.ProjectStatus, error) {
+	err := a.create("enable service")
+	if err != nil && !a.conflict {
+		return nil, err
+	}
+	a.serviceEnabled = true
+	if err != nil {
+		return nil, err
+	}
+	return &objectstorage.ProjectStatus{Project: utils.Ptr(projectId)}, nil
+}
+
+func (a *apiClientMocked) GetBucketExecute(_ context.Context, projectId, bucketName string) (*objectstorage.GetBucketResponse, error) {
+	if !a.buckets[bucketName] {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusNotFound}
+	}
+	return &objectstorage.GetBucketResponse{
+		Project: utils.Ptr(projectId),
+		Bucket:  &objectstorage.Bucket{Name: utils.Ptr(bucketName)},
+	}, nil
+}
+
+func (a *apiClientMocked) CreateBucketExecute(_ context.Context, projectId, bucketName string) (*objectstorage.CreateBucketResponse, error) {
+	err := a.create("create bucket " + bucketName)
+	if a.nameTaken {
+		return nil, &oapierror.GenericOpenAPIError{StatusCode: http.StatusConflict}
+	}
+	if err != nil && !a.conflict {
+		return nil, err
+	}
+	a.buckets[bucketName] = true
+	if err != nil {
+		return nil, err
+	}
+	return &objectstorage.CreateBucketResponse{Project: utils.Ptr(projectId), Bucket: utils.Ptr(bucketName)}, nil
+}
+
+func (a *apiClientMocked) ListCredentialsGroupsExecute(_ context.Context, _ string) (*objectstorage.ListCredentialsGroupsResponse, error) {
+	groups := append([]objectstorage.CredentialsGroup{}, a.groups...)
+	return &objectstorage.ListCredentialsGroupsResponse{CredentialsGroups: &groups}, nil
+}
+
+func (a *apiClientMocked) createCredentialsGroup(_ context.Context, _, displayName string) (*objectstorage.CreateCredentialsGroupResponse, error) {
+	err := a.create("create credentials group " + displayName)
+	if err != nil && !a.conflict {
+		return nil, err
+	}
+	group := objectstorage.CredentialsGroup{
+		CredentialsGroupId: utils.Ptr(fmt.Sprintf("gid-%d", len(a.groups))),
+		DisplayName:        utils.Ptr(displayName),
+	}
+	a.groups = append(a.groups, group)
+	if err != nil {
+		return nil, err
+	}
+	return &objectstorage.CreateCredentialsGroupResponse{CredentialsGroup: &group}, nil
+}
+
+func TestEnsureService(t *testing.T) {
+	tests := []struct {
+		desc           string
+		serviceEnabled bool
+		conflict       bool
+		createFails    bool
+		wantCalls      []string
+		wantErr        bool
+	}{
+		{
+			desc:           "enabled",
+			serviceEnabled: true,
+		},
+		{
+			desc:      "enable",
+			wantCalls: []string{"enable service"},
+		},
+		{
+			desc:      "conflict",
+			conflict:  true,
+			wantCalls: []string{"enable service"},
+		},
+		{
+			desc:        "enable_fails",
+			createFails: true,
+			wantCalls:   []string{"enable service"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{serviceEnabled: tt.serviceEnabled, conflict: tt.conflict, createFails: tt.createFails}
+			status, err := ensureService(context.Background(), a, "pid")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureService error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (status == nil || *status.Project != "pid") {
+				t.Fatalf("ensureService returned status %v", status)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestEnsureBucket(t *testing.T) {
+	tests := []struct {
+		desc        string
+		buckets     map[string]bool
+		conflict    bool
+		createFails bool
+		nameTaken   bool
+		wantCalls   []string
+		wantErr     bool
+		wantTaken   bool
+	}{
+		{
+			desc:    "exists",
+			buckets: map[string]bool{"bucket": true},
+		},
+		{
+			desc:      "create",
+			buckets:   map[string]bool{},
+			wantCalls: []string{"create bucket bucket"},
+		},
+		{
+			desc:      "conflict",
+			buckets:   map[string]bool{},
+			conflict:  true,
+			wantCalls: []string{"create bucket bucket"},
+		},
+		{
+			desc:      "name_taken",
+			buckets:   map[string]bool{},
+			nameTaken: true,
+			wantCalls: []string{"create bucket bucket"},
+			wantErr:   true,
+			wantTaken: true,
+		},
+		{
+			desc:        "create_fails",
+			buckets:     map[string]bool{},
+			createFails: true,
+			wantCalls:   []string{"create bucket bucket"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{buckets: tt.buckets, conflict: tt.conflict, createFails: tt.createFails, nameTaken: tt.nameTaken}
+			bucket, err := ensureBucket(context.Background(), a, "pid", "bucket")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureBucket error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var takenErr *BucketNameTakenError
+			if errors.As(err, &takenErr) != tt.wantTaken {
+				t.Fatalf("ensureBucket error = %v, want BucketNameTakenError %v", err, tt.wantTaken)
+			}
+			if !tt.wantErr && (bucket == nil || *bucket.Name != "bucket") {
+				t.Fatalf("ensureBucket returned bucket %v", bucket)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestEnsureCredentialsGroup(t *testing.T) {
+	existing := []objectstorage.CredentialsGroup{
+		{CredentialsGroupId: utils.Ptr("default"), DisplayName: utils.Ptr("default")},
+		{CredentialsGroupId: utils.Ptr("ci"), DisplayName: utils.Ptr("ci")},
+	}
+	tests := []struct {
+		desc        string
+		conflict    bool
+		createFails bool
+		name        string
+		want        *objectstorage.CredentialsGroup
+		wantCalls   []string
+		wantErr     bool
+	}{
+		{
+			desc: "exists",
+			name: "ci",
+			want: &existing[1],
+		},
+		{
+			desc:      "create",
+			name:      "new",
+			want:      &objectstorage.CredentialsGroup{CredentialsGroupId: utils.Ptr("gid-2"), DisplayName: utils.Ptr("new")},
+			wantCalls: []string{"create credentials group new"},
+		},
+		{
+			desc:      "conflict",
+			name:      "new",
+			conflict:  true,
+			want:      &objectstorage.CredentialsGroup{CredentialsGroupId: utils.Ptr("gid-2"), DisplayName: utils.Ptr("new")},
+			wantCalls: []string{"create credentials group new"},
+		},
+		{
+			desc:        "create_fails",
+			name:        "new",
+			createFails: true,
+			wantCalls:   []string{"create credentials group new"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{
+				groups:      append([]objectstorage.CredentialsGroup{}, existing...),
+				conflict:    tt.conflict,
+				createFails: tt.createFails,
+			}
+			got, err := ensureCredentialsGroup(context.Background(), a, "pid", tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureCredentialsGroup error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected credentials group (-got +want): %s", diff)
+			}
+			if diff := cmp.Diff(a.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+		})
+	}
+}
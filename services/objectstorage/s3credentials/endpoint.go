@@ -0,0 +1,77 @@
+package s3credentials
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+)
+
+// S3ServiceId is the id of the S3 service in the AWS SDK, for which the endpoint resolver returns the bucket endpoint
+const S3ServiceId = "S3"
+
+// Endpoint is the S3 endpoint of a bucket
+type Endpoint struct {
+	// URL is the URL of the S3 API, without the bucket name
+	URL    string
+	Region string
+}
+
+// BucketEndpoint returns the S3 endpoint of the bucket, derived from its path-style URL
+func BucketEndpoint(bucket *objectstorage.Bucket) (*Endpoint, error) {
+	if bucket == nil || bucket.Name == nil || bucket.UrlPathStyle == nil || bucket.Region == nil {
+		return nil, fmt.Errorf("bucket is missing the name, the region or the path-style URL")
+	}
+	u, err := url.Parse(*bucket.UrlPathStyle)
+	if err != nil {
+		return nil, fmt.Errorf("parse path-style URL: %w", err)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(path, "/"+*bucket.Name) {
+		return nil, fmt.Errorf("path-style URL %s doesn't end with the bucket name %s", *bucket.UrlPathStyle, *bucket.Name)
+	}
+	u.Path = strings.TrimSuffix(path, "/"+*bucket.Name)
+	return &Endpoint{URL: u.String(), Region: *bucket.Region}, nil
+}
+
+// Resolver returns an endpoint resolver pointing S3 clients to the endpoint. Requests use path-style addressing,
+// as the host name of the endpoint is immutable. Other services are resolved by the default resolver of the SDK.
+func (e *Endpoint) Resolver() aws.EndpointResolverWithOptions {
+	return aws.EndpointResolverWithOptionsFunc(func(service, _ string, _ ...interface{}) (aws.Endpoint, error) {
+		if service != S3ServiceId {
+			return aws.Endpoint{}, &aws.EndpointNotFoundError{}
+		}
+		return aws.Endpoint{
+			URL:               e.URL,
+			SigningRegion:     e.Region,
+			HostnameImmutable: true,
+			Source:            aws.EndpointSourceCustom,
+		}, nil
+	})
+}
+
+// NewConfig returns the AWS configuration for S3 clients accessing the bucket with credentials of the provider,
+// to be passed to s3.NewFromConfig. The credentials are cached until the access key is due for rotation.
+func NewConfig(ctx context.Context, client *objectstorage.APIClient, projectId, bucketName string, provider *Provider) (aws.Config, error) {
+	resp, err := client.GetBucketExecute(ctx, projectId, bucketName)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("get bucket: %w", err)
+	}
+	endpoint, err := BucketEndpoint(resp.Bucket)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	return endpoint.Config(provider), nil
+}
+
+// Config returns the AWS configuration for S3 clients accessing the endpoint with credentials of the provider
+func (e *Endpoint) Config(provider aws.CredentialsProvider) aws.Config {
+	return aws.Config{
+		Region:                      e.Region,
+		Credentials:                 aws.NewCredentialsCache(provider),
+		EndpointResolverWithOptions: e.Resolver(),
+	}
+}
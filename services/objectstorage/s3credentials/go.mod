@@ -0,0 +1,16 @@
+module github.com/stackitcloud/stackit-sdk-go/services/objectstorage/s3credentials
+
+go 1.18
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.21.2
+	github.com/google/go-cmp v0.6.0
+	github.com/stackitcloud/stackit-sdk-go/core v0.12.0
+	github.com/stackitcloud/stackit-sdk-go/services/objectstorage v0.9.0
+)
+
+require (
+	github.com/aws/smithy-go v1.15.0 // indirect
+	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+)
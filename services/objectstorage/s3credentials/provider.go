@@ -0,0 +1,248 @@
+// Package s3credentials connects S3 clients of the AWS SDK for Go v2 to STACKIT Object Storage.
+// It provides an aws.CredentialsProvider backed by access keys of a credentials group, which are created on demand and
+// rotated before they expire, and an endpoint resolver derived from the bucket metadata.
+package s3credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+)
+
+// CredentialsSource is the source of the credentials returned by the Provider
+const CredentialsSource = "STACKITObjectStorageProvider"
+
+// Defaults of the Options
+const (
+	DefaultRotationWindow = 1 * time.Hour
+	DefaultGracePeriod    = 15 * time.Minute
+)
+
+// Options configures the access keys managed by a Provider
+type Options struct {
+	// CredentialsGroup is the id of the credentials group the access keys are created in.
+	// The default credentials group of the project is used if empty.
+	CredentialsGroup string
+	// KeyLifetime is the lifetime of the created access keys. They don't expire if zero.
+	KeyLifetime time.Duration
+	// RotationWindow is the time before the expiration of an access key at which it is replaced by a new one.
+	// It must be shorter than KeyLifetime. If zero, DefaultRotationWindow is used, or half of KeyLifetime if it isn't longer than that.
+	RotationWindow time.Duration
+	// GracePeriod is the time a replaced access key is kept, to let requests signed with it finish,
+	// DefaultGracePeriod if zero
+	GracePeriod time.Duration
+}
+
+// Provider implements aws.CredentialsProvider with access keys of STACKIT Object Storage.
+// It should be wrapped in an aws.CredentialsCache, which calls Retrieve again when the access key is due for rotation.
+type Provider struct {
+	client    apiClient
+	projectId string
+	opts      Options
+	now       func() time.Time
+
+	mu      sync.Mutex
+	current *accessKey
+	retired []retiredAccessKey
+}
+
+var _ aws.CredentialsProvider = &Provider{}
+
+type accessKey struct {
+	keyId           string
+	accessKey       string
+	secretAccessKey string
+	// expires is zero if the access key doesn't expire
+	expires time.Time
+}
+
+type retiredAccessKey struct {
+	keyId       string
+	deleteAfter time.Time
+}
+
+// apiClient is the part of the Object Storage API used by this package
+type apiClient interface {
+	createAccessKey(ctx context.Context, projectId, credentialsGroup string, payload objectstorage.CreateAccessKeyPayload) (*objectstorage.CreateAccessKeyResponse, error)
+	deleteAccessKey(ctx context.Context, projectId, credentialsGroup, keyId string) error
+}
+
+type apiClientAdapter struct {
+	*objectstorage.APIClient
+}
+
+func (a apiClientAdapter) createAccessKey(ctx context.Context, projectId, credentialsGroup string, payload objectstorage.CreateAccessKeyPayload) (*objectstorage.CreateAccessKeyResponse, error) {
+	req := a.CreateAccessKey(ctx, projectId).CreateAccessKeyPayload(payload)
+	if credentialsGroup != "" {
+		req = req.CredentialsGroup(credentialsGroup)
+	}
+	return req.Execute()
+}
+
+func (a apiClientAdapter) deleteAccessKey(ctx context.Context, projectId, credentialsGroup, keyId string) error {
+	req := a.DeleteAccessKey(ctx, projectId, keyId)
+	if credentialsGroup != "" {
+		req = req.CredentialsGroup(credentialsGroup)
+	}
+	_, err := req.Execute()
+	return err
+}
+
+// NewProvider returns a Provider creating access keys in the project. No access key is created until credentials are retrieved.
+// It returns an error if the options are invalid, e.g. a rotation window that isn't shorter than the key lifetime,
+// which would replace the access key on every retrieval.
+func NewProvider(client *objectstorage.APIClient, projectId string, opts Options) (*Provider, error) {
+	return newProvider(apiClientAdapter{client}, projectId, opts)
+}
+
+func newProvider(a apiClient, projectId string, opts Options) (*Provider, error) {
+	if opts.KeyLifetime < 0 {
+		return nil, fmt.Errorf("key lifetime %s is negative", opts.KeyLifetime)
+	}
+	if opts.RotationWindow < 0 {
+		return nil, fmt.Errorf("rotation window %s is negative", opts.RotationWindow)
+	}
+	if opts.RotationWindow == 0 {
+		opts.RotationWindow = DefaultRotationWindow
+		if opts.KeyLifetime > 0 && opts.KeyLifetime <= DefaultRotationWindow {
+			opts.RotationWindow = opts.KeyLifetime / 2
+		}
+	}
+	if opts.KeyLifetime > 0 && opts.RotationWindow >= opts.KeyLifetime {
+		return nil, fmt.Errorf("rotation window %s must be shorter than the key lifetime %s", opts.RotationWindow, opts.KeyLifetime)
+	}
+	if opts.GracePeriod == 0 {
+		opts.GracePeriod = DefaultGracePeriod
+	}
+	return &Provider{
+		client:    a,
+		projectId: projectId,
+		opts:      opts,
+		now:       time.Now,
+	}, nil
+}
+
+// Retrieve returns the credentials of the current access key, creating a new one if there is none yet or if it is due for rotation.
+// Replaced access keys are deleted once their grace period is over.
+// The returned credentials expire when the access key is due for rotation.
+func (p *Provider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	// Failing deletions don't prevent the credentials from being returned, they are retried on the next call
+	_ = p.deleteRetired(ctx, now, false)
+
+	if p.current == nil || p.dueForRotation(p.current, now) {
+		key, err := p.createAccessKey(ctx, now)
+		if err != nil {
+			return aws.Credentials{}, err
+		}
+		if p.current != nil {
+			p.retired = append(p.retired, retiredAccessKey{keyId: p.current.keyId, deleteAfter: now.Add(p.opts.GracePeriod)})
+		}
+		p.current = key
+	}
+
+	creds := aws.Credentials{
+		AccessKeyID:     p.current.accessKey,
+		SecretAccessKey: p.current.secretAccessKey,
+		Source:          CredentialsSource,
+	}
+	if !p.current.expires.IsZero() {
+		creds.CanExpire = true
+		creds.Expires = p.current.expires.Add(-p.opts.RotationWindow)
+	}
+	return creds, nil
+}
+
+// Close deletes the access keys created by the provider, including the current one.
+// Credentials retrieved afterwards are backed by a new access key.
+func (p *Provider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.current != nil {
+		p.retired = append(p.retired, retiredAccessKey{keyId: p.current.keyId})
+		p.current = nil
+	}
+	return p.deleteRetired(ctx, p.now(), true)
+}
+
+func (p *Provider) dueForRotation(key *accessKey, now time.Time) bool {
+	return !key.expires.IsZero() && !now.Before(key.expires.Add(-p.opts.RotationWindow))
+}
+
+func (p *Provider) createAccessKey(ctx context.Context, now time.Time) (*accessKey, error) {
+	payload := objectstorage.CreateAccessKeyPayload{}
+	if p.opts.KeyLifetime > 0 {
+		expires := now.Add(p.opts.KeyLifetime)
+		payload.Expires = &expires
+	}
+	resp, err := p.client.createAccessKey(ctx, p.projectId, p.opts.CredentialsGroup, payload)
+	if err != nil {
+		return nil, fmt.Errorf("create access key: %w", err)
+	}
+	if resp.KeyId == nil || resp.AccessKey == nil || resp.SecretAccessKey == nil {
+		return nil, fmt.Errorf("create access key: response is missing the key id or the access key")
+	}
+	key := &accessKey{
+		keyId:           *resp.KeyId,
+		accessKey:       *resp.AccessKey,
+		secretAccessKey: *resp.SecretAccessKey,
+	}
+	if resp.Expires != nil && *resp.Expires != "" {
+		key.expires, err = time.Parse(time.RFC3339Nano, *resp.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("parse expiration of access key %s: %w", key.keyId, err)
+		}
+	}
+	return key, nil
+}
+
+// deleteRetired deletes the retired access keys whose grace period is over, or all of them if force is set.
+// Access keys failing to be deleted are kept to be retried; the errors are returned joined.
+func (p *Provider) deleteRetired(ctx context.Context, now time.Time, force bool) error {
+	var errs []error
+	retired := []retiredAccessKey{}
+	for _, key := range p.retired {
+		if !force && now.Before(key.deleteAfter) {
+			retired = append(retired, key)
+			continue
+		}
+		err := p.client.deleteAccessKey(ctx, p.projectId, p.opts.CredentialsGroup, key.keyId)
+		if err != nil && !isNotFound(err) {
+			errs = append(errs, fmt.Errorf("delete access key %s: %w", key.keyId, err))
+			retired = append(retired, key)
+		}
+	}
+	p.retired = retired
+	return joinErrors(errs)
+}
+
+func isNotFound(err error) bool {
+	var oapiErr *oapierror.GenericOpenAPIError
+	return errors.As(err, &oapiErr) && oapiErr.StatusCode == http.StatusNotFound
+}
+
+// joinErrors returns the errors as one, or nil if there are none
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	msg := errs[0].Error()
+	for _, err := range errs[1:] {
+		msg += "; " + err.Error()
+	}
+	return errors.New(msg)
+}
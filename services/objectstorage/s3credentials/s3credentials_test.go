@@ -0,0 +1,321 @@
+package s3credentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
+)
+
+type apiClientMocked struct {
+	keys        int
+	createFails bool
+	deleteFails bool
+	deleteGone  bool
+	calls       []string
+}
+
+func (a *apiClientMocked) createAccessKey(_ context.Context, _, credentialsGroup string, payload objectstorage.CreateAccessKeyPayload) (*objectstorage.CreateAccessKeyResponse, error) {
+	if a.createFails {
+		return nil, fmt.Errorf("create fails")
+	}
+	a.keys++
+	keyId := fmt.Sprintf("key-%d", a.keys)
+	a.calls = append(a.calls, fmt.Sprintf("create %s in %s", keyId, credentialsGroup))
+	resp := &objectstorage.CreateAccessKeyResponse{
+		KeyId:           utils.Ptr(keyId),
+		AccessKey:       utils.Ptr("access-" + keyId),
+		SecretAccessKey: utils.Ptr("secret-" + keyId),
+	}
+	if payload.Expires != nil {
+		resp.Expires = utils.Ptr(payload.Expires.Format(time.RFC3339Nano))
+	}
+	return resp, nil
+}
+
+func (a *apiClientMocked) deleteAccessKey(_ context.Context, _, credentialsGroup, keyId string) error {
+	a.calls = append(a.calls, fmt.Sprintf("delete %s in %s", keyId, credentialsGroup))
+	if a.deleteFails {
+		return fmt.Errorf("delete fails")
+	}
+	if a.deleteGone {
+		return &oapierror.GenericOpenAPIError{StatusCode: http.StatusNotFound}
+	}
+	return nil
+}
+
+func mustNewProvider(t *testing.T, a apiClient, opts Options) *Provider {
+	t.Helper()
+	p, err := newProvider(a, "pid", opts)
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+	return p
+}
+
+func TestNewProviderOptions(t *testing.T) {
+	tests := []struct {
+		desc               string
+		opts               Options
+		wantRotationWindow time.Duration
+		wantErr            bool
+	}{
+		{
+			desc:               "defaults",
+			wantRotationWindow: DefaultRotationWindow,
+		},
+		{
+			desc:               "long_lifetime",
+			opts:               Options{KeyLifetime: 24 * time.Hour},
+			wantRotationWindow: DefaultRotationWindow,
+		},
+		{
+			desc:               "short_lifetime",
+			opts:               Options{KeyLifetime: 30 * time.Minute},
+			wantRotationWindow: 15 * time.Minute,
+		},
+		{
+			desc:               "lifetime_of_default_window",
+			opts:               Options{KeyLifetime: DefaultRotationWindow},
+			wantRotationWindow: DefaultRotationWindow / 2,
+		},
+		{
+			desc:               "custom_window",
+			opts:               Options{KeyLifetime: 30 * time.Minute, RotationWindow: 10 * time.Minute},
+			wantRotationWindow: 10 * time.Minute,
+		},
+		{
+			desc:    "window_equal_to_lifetime",
+			opts:    Options{KeyLifetime: 2 * time.Hour, RotationWindow: 2 * time.Hour},
+			wantErr: true,
+		},
+		{
+			desc:    "window_longer_than_lifetime",
+			opts:    Options{KeyLifetime: 30 * time.Minute, RotationWindow: time.Hour},
+			wantErr: true,
+		},
+		{
+			desc:    "negative_lifetime",
+			opts:    Options{KeyLifetime: -time.Hour},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			p, err := newProvider(&apiClientMocked{}, "pid", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newProvider error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.opts.RotationWindow != tt.wantRotationWindow {
+				t.Fatalf("rotation window = %s, want %s", p.opts.RotationWindow, tt.wantRotationWindow)
+			}
+		})
+	}
+}
+
+func TestProviderShortLifetime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	a := &apiClientMocked{}
+	p := mustNewProvider(t, a, Options{KeyLifetime: 30 * time.Minute})
+	p.now = func() time.Time { return now }
+
+	// The access key is reused until half of its lifetime is over, instead of being replaced on every retrieval
+	for _, elapsed := range []time.Duration{0, 10 * time.Minute, 15 * time.Minute} {
+		now = start.Add(elapsed)
+		if _, err := p.Retrieve(context.Background()); err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+	}
+	if diff := cmp.Diff(a.calls, []string{"create key-1 in ", "create key-2 in "}); diff != "" {
+		t.Fatalf("unexpected calls (-got +want): %s", diff)
+	}
+}
+
+func TestProviderRotation(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	a := &apiClientMocked{}
+	p := mustNewProvider(t, a, Options{CredentialsGroup: "group", KeyLifetime: 24 * time.Hour})
+	p.now = func() time.Time { return now }
+
+	retrieve := func(wantKey string, wantExpires time.Time) {
+		t.Helper()
+		creds, err := p.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+		want := aws.Credentials{
+			AccessKeyID:     "access-" + wantKey,
+			SecretAccessKey: "secret-" + wantKey,
+			Source:          CredentialsSource,
+			CanExpire:       true,
+			Expires:         wantExpires,
+		}
+		if diff := cmp.Diff(creds, want); diff != "" {
+			t.Fatalf("unexpected credentials (-got +want): %s", diff)
+		}
+	}
+
+	// The first key is created on demand and reused until it is due for rotation
+	retrieve("key-1", start.Add(23*time.Hour))
+	now = start.Add(22 * time.Hour)
+	retrieve("key-1", start.Add(23*time.Hour))
+
+	// The key is rotated within the rotation window, the old key is kept during the grace period
+	now = start.Add(23 * time.Hour)
+	retrieve("key-2", now.Add(23*time.Hour))
+	now = now.Add(10 * time.Minute)
+	retrieve("key-2", start.Add(46*time.Hour))
+	now = now.Add(5 * time.Minute)
+	retrieve("key-2", start.Add(46*time.Hour))
+
+	wantCalls := []string{"create key-1 in group", "create key-2 in group", "delete key-1 in group"}
+	if diff := cmp.Diff(a.calls, wantCalls); diff != "" {
+		t.Fatalf("unexpected calls (-got +want): %s", diff)
+	}
+
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	wantCalls = append(wantCalls, "delete key-2 in group")
+	if diff := cmp.Diff(a.calls, wantCalls); diff != "" {
+		t.Fatalf("unexpected calls after Close (-got +want): %s", diff)
+	}
+}
+
+func TestProviderWithoutExpiration(t *testing.T) {
+	a := &apiClientMocked{}
+	p := mustNewProvider(t, a, Options{})
+	for i := 0; i < 2; i++ {
+		creds, err := p.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+		if creds.CanExpire || creds.AccessKeyID != "access-key-1" {
+			t.Fatalf("unexpected credentials %+v", creds)
+		}
+	}
+	if diff := cmp.Diff(a.calls, []string{"create key-1 in "}); diff != "" {
+		t.Fatalf("unexpected calls (-got +want): %s", diff)
+	}
+}
+
+func TestProviderClose(t *testing.T) {
+	tests := []struct {
+		desc        string
+		deleteFails bool
+		deleteGone  bool
+		wantErr     bool
+	}{
+		{desc: "ok"},
+		{desc: "already_deleted", deleteGone: true},
+		{desc: "delete_fails", deleteFails: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := &apiClientMocked{deleteFails: tt.deleteFails, deleteGone: tt.deleteGone}
+			p := mustNewProvider(t, a, Options{})
+			if _, err := p.Retrieve(context.Background()); err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			err := p.Close(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Close error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(p.retired); (got == 1) != tt.wantErr {
+				t.Fatalf("%d access keys left to delete", got)
+			}
+		})
+	}
+}
+
+func TestProviderCreateFails(t *testing.T) {
+	p := mustNewProvider(t, &apiClientMocked{createFails: true}, Options{})
+	if _, err := p.Retrieve(context.Background()); err == nil {
+		t.Fatalf("Retrieve succeeded")
+	}
+}
+
+func TestBucketEndpoint(t *testing.T) {
+	tests := []struct {
+		desc    string
+		bucket  *objectstorage.Bucket
+		want    *Endpoint
+		wantErr bool
+	}{
+		{
+			desc: "ok",
+			bucket: &objectstorage.Bucket{
+				Name:         utils.Ptr("my-bucket"),
+				Region:       utils.Ptr("eu01"),
+				UrlPathStyle: utils.Ptr("https://object.storage.eu01.onstackit.cloud/my-bucket"),
+			},
+			want: &Endpoint{URL: "https://object.storage.eu01.onstackit.cloud", Region: "eu01"},
+		},
+		{
+			desc: "trailing_slash",
+			bucket: &objectstorage.Bucket{
+				Name:         utils.Ptr("my-bucket"),
+				Region:       utils.Ptr("eu01"),
+				UrlPathStyle: utils.Ptr("https://object.storage.eu01.onstackit.cloud/my-bucket/"),
+			},
+			want: &Endpoint{URL: "https://object.storage.eu01.onstackit.cloud", Region: "eu01"},
+		},
+		{
+			desc: "other_bucket",
+			bucket: &objectstorage.Bucket{
+				Name:         utils.Ptr("my-bucket"),
+				Region:       utils.Ptr("eu01"),
+				UrlPathStyle: utils.Ptr("https://object.storage.eu01.onstackit.cloud/other-bucket"),
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "missing_url",
+			bucket:  &objectstorage.Bucket{Name: utils.Ptr("my-bucket"), Region: utils.Ptr("eu01")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := BucketEndpoint(tt.bucket)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BucketEndpoint error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("unexpected endpoint (-got +want): %s", diff)
+			}
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	e := &Endpoint{URL: "https://object.storage.eu01.onstackit.cloud", Region: "eu01"}
+	got, err := e.Resolver().ResolveEndpoint(S3ServiceId, "eu01")
+	if err != nil {
+		t.Fatalf("ResolveEndpoint: %v", err)
+	}
+	want := aws.Endpoint{
+		URL:               "https://object.storage.eu01.onstackit.cloud",
+		SigningRegion:     "eu01",
+		HostnameImmutable: true,
+		Source:            aws.EndpointSourceCustom,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected endpoint (-got +want): %s", diff)
+	}
+	if _, err := e.Resolver().ResolveEndpoint("STS", "eu01"); err == nil {
+		t.Fatalf("ResolveEndpoint of another service succeeded")
+	}
+}
@@ -2,7 +2,7 @@ package wait
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"time"
 
@@ -11,40 +11,135 @@ import (
 	"github.com/stackitcloud/stackit-sdk-go/services/objectstorage"
 )
 
+// States reported by propagationState, they aren't returned by the API
+const (
+	statePropagated    = "PROPAGATED"
+	stateNotPropagated = "NOT_PROPAGATED"
+)
+
 // Interface needed for tests
 type APIClientBucketInterface interface {
 	GetBucketExecute(ctx context.Context, projectId string, bucketName string) (*objectstorage.GetBucketResponse, error)
 }
 
+// Interface needed for tests
+type APIClientServiceInterface interface {
+	GetServiceStatusExecute(ctx context.Context, projectId string) (*objectstorage.ProjectStatus, error)
+}
+
+// Interface needed for tests
+type APIClientCredentialsGroupInterface interface {
+	ListCredentialsGroupsExecute(ctx context.Context, projectId string) (*objectstorage.ListCredentialsGroupsResponse, error)
+}
+
 // CreateBucketWaitHandler will wait for bucket creation
 func CreateBucketWaitHandler(ctx context.Context, a APIClientBucketInterface, projectId, bucketName string) *wait.AsyncActionHandler[objectstorage.GetBucketResponse] {
-	handler := wait.New(func() (waitFinished bool, response *objectstorage.GetBucketResponse, err error) {
+	handler := wait.ForState(func() (*objectstorage.GetBucketResponse, error) {
 		s, err := a.GetBucketExecute(ctx, projectId, bucketName)
-		if err != nil {
-			return false, nil, err
+		// The bucket isn't found until its creation has propagated
+		if isNotFound(err) {
+			return nil, nil
 		}
-		return true, s, nil
-	})
+		return s, err
+	}, propagationState[objectstorage.GetBucketResponse]).
+		Success(statePropagated).
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
 
 // DeleteBucketWaitHandler will wait for bucket deletion
 func DeleteBucketWaitHandler(ctx context.Context, a APIClientBucketInterface, projectId, bucketName string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetBucketExecute(ctx, projectId, bucketName)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, fmt.Errorf("could not convert error to GenericOpenApiError")
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetBucketExecute(ctx, projectId, bucketName)
+		return nil, err
+	}, nil).
+		GoneIsSuccess().
+		Handler()
+	handler.SetTimeout(1 * time.Minute)
+	return handler
+}
+
+// EnableServiceWaitHandler will wait for the service to be enabled in the project
+func EnableServiceWaitHandler(ctx context.Context, a APIClientServiceInterface, projectId string) *wait.AsyncActionHandler[objectstorage.ProjectStatus] {
+	handler := wait.ForState(func() (*objectstorage.ProjectStatus, error) {
+		s, err := a.GetServiceStatusExecute(ctx, projectId)
+		// The project isn't found until the service enablement has propagated
+		if isNotFound(err) {
+			return nil, nil
 		}
-		if oapiErr.StatusCode != http.StatusNotFound {
-			return false, nil, err
+		return s, err
+	}, propagationState[objectstorage.ProjectStatus]).
+		Success(statePropagated).
+		Handler()
+	handler.SetTimeout(1 * time.Minute)
+	return handler
+}
+
+// DisableServiceWaitHandler will wait for the service to be disabled in the project
+func DisableServiceWaitHandler(ctx context.Context, a APIClientServiceInterface, projectId string) *wait.AsyncActionHandler[struct{}] {
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetServiceStatusExecute(ctx, projectId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess().
+		Handler()
+	handler.SetTimeout(1 * time.Minute)
+	return handler
+}
+
+// CreateCredentialsGroupWaitHandler will wait for the credentials group to be listed, after which access keys can be created in it
+func CreateCredentialsGroupWaitHandler(ctx context.Context, a APIClientCredentialsGroupInterface, projectId, credentialsGroupId string) *wait.AsyncActionHandler[objectstorage.CredentialsGroup] {
+	handler := wait.ForState(func() (*objectstorage.CredentialsGroup, error) {
+		return findCredentialsGroup(ctx, a, projectId, credentialsGroupId)
+	}, propagationState[objectstorage.CredentialsGroup]).
+		Success(statePropagated).
+		Handler()
+	handler.SetTimeout(1 * time.Minute)
+	return handler
+}
+
+// DeleteCredentialsGroupWaitHandler will wait for the credentials group to be no longer listed
+func DeleteCredentialsGroupWaitHandler(ctx context.Context, a APIClientCredentialsGroupInterface, projectId, credentialsGroupId string) *wait.AsyncActionHandler[struct{}] {
+	handler := wait.ForState(func() (*struct{}, error) {
+		group, err := findCredentialsGroup(ctx, a, projectId, credentialsGroupId)
+		if group == nil {
+			return nil, err
 		}
-		return true, nil, nil
-	})
+		return &struct{}{}, err
+	}, propagationState[struct{}]).
+		Success(stateNotPropagated).
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
+
+// propagationState returns whether the resource is found, the get functions return no resource while it isn't
+func propagationState[T any](res *T) (string, error) {
+	if res == nil {
+		return stateNotPropagated, nil
+	}
+	return statePropagated, nil
+}
+
+func findCredentialsGroup(ctx context.Context, a APIClientCredentialsGroupInterface, projectId, credentialsGroupId string) (*objectstorage.CredentialsGroup, error) {
+	resp, err := a.ListCredentialsGroupsExecute(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	if resp.CredentialsGroups == nil {
+		return nil, nil
+	}
+	for i := range *resp.CredentialsGroups {
+		group := &(*resp.CredentialsGroups)[i]
+		if group.CredentialsGroupId != nil && *group.CredentialsGroupId == credentialsGroupId {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+func isNotFound(err error) bool {
+	var oapiErr *oapierror.GenericOpenAPIError
+	return errors.As(err, &oapiErr) && oapiErr.StatusCode == http.StatusNotFound
+}
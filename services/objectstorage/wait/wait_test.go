@@ -2,6 +2,7 @@ package wait
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -34,10 +35,11 @@ func (a *apiClientBucketMocked) GetBucketExecute(_ context.Context, _, _ string)
 
 func TestCreateBucketWaitHandler(t *testing.T) {
 	tests := []struct {
-		desc           string
-		bucketGetFails bool
-		wantErr        bool
-		wantResp       bool
+		desc            string
+		bucketGetFails  bool
+		bucketIsMissing bool
+		wantErr         bool
+		wantResp        bool
 	}{
 		{
 			desc:           "create_succeeded",
@@ -51,11 +53,18 @@ func TestCreateBucketWaitHandler(t *testing.T) {
 			wantErr:        true,
 			wantResp:       false,
 		},
+		{
+			desc:            "timeout",
+			bucketIsMissing: true,
+			wantErr:         true,
+			wantResp:        false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			apiClient := &apiClientBucketMocked{
-				bucketGetFails: tt.bucketGetFails,
+				bucketIsDeleted: tt.bucketIsMissing,
+				bucketGetFails:  tt.bucketGetFails,
 			}
 
 			var wantRes *objectstorage.GetBucketResponse
@@ -111,3 +120,231 @@ func TestDeleteBucketWaitHandler(t *testing.T) {
 		})
 	}
 }
+
+// Used for testing service operations
+type apiClientServiceMocked struct {
+	serviceIsDisabled bool
+	serviceGetFails   bool
+}
+
+func (a *apiClientServiceMocked) GetServiceStatusExecute(_ context.Context, projectId string) (*objectstorage.ProjectStatus, error) {
+	if a.serviceGetFails {
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: 500,
+		}
+	}
+
+	if a.serviceIsDisabled {
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: 404,
+		}
+	}
+
+	return &objectstorage.ProjectStatus{Project: &projectId}, nil
+}
+
+func TestEnableServiceWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc              string
+		serviceIsDisabled bool
+		serviceGetFails   bool
+		wantErr           bool
+		wantResp          bool
+	}{
+		{
+			desc:     "enable_succeeded",
+			wantErr:  false,
+			wantResp: true,
+		},
+		{
+			desc:            "get_fails",
+			serviceGetFails: true,
+			wantErr:         true,
+			wantResp:        false,
+		},
+		{
+			desc:              "timeout",
+			serviceIsDisabled: true,
+			wantErr:           true,
+			wantResp:          false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientServiceMocked{
+				serviceIsDisabled: tt.serviceIsDisabled,
+				serviceGetFails:   tt.serviceGetFails,
+			}
+
+			var wantRes *objectstorage.ProjectStatus
+			if tt.wantResp {
+				projectId := "pid"
+				wantRes = &objectstorage.ProjectStatus{Project: &projectId}
+			}
+
+			handler := EnableServiceWaitHandler(context.Background(), apiClient, "pid")
+
+			gotRes, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(gotRes, wantRes) {
+				t.Fatalf("handler gotRes = %v, want %v", gotRes, wantRes)
+			}
+		})
+	}
+}
+
+func TestDisableServiceWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc              string
+		serviceIsDisabled bool
+		serviceGetFails   bool
+		wantErr           bool
+	}{
+		{
+			desc:              "disable_succeeded",
+			serviceIsDisabled: true,
+			wantErr:           false,
+		},
+		{
+			desc:            "get_fails",
+			serviceGetFails: true,
+			wantErr:         true,
+		},
+		{
+			desc:    "timeout",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientServiceMocked{
+				serviceIsDisabled: tt.serviceIsDisabled,
+				serviceGetFails:   tt.serviceGetFails,
+			}
+
+			handler := DisableServiceWaitHandler(context.Background(), apiClient, "pid")
+
+			_, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Used for testing credentials group operations
+type apiClientCredentialsGroupMocked struct {
+	credentialsGroupIsListed bool
+	listFails                bool
+}
+
+func (a *apiClientCredentialsGroupMocked) ListCredentialsGroupsExecute(_ context.Context, _ string) (*objectstorage.ListCredentialsGroupsResponse, error) {
+	if a.listFails {
+		return nil, fmt.Errorf("list fails")
+	}
+
+	otherId := "other"
+	groups := []objectstorage.CredentialsGroup{{CredentialsGroupId: &otherId}}
+	if a.credentialsGroupIsListed {
+		groupId := "gid"
+		groups = append(groups, objectstorage.CredentialsGroup{CredentialsGroupId: &groupId})
+	}
+	return &objectstorage.ListCredentialsGroupsResponse{CredentialsGroups: &groups}, nil
+}
+
+func TestCreateCredentialsGroupWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc                     string
+		credentialsGroupIsListed bool
+		listFails                bool
+		wantErr                  bool
+		wantResp                 bool
+	}{
+		{
+			desc:                     "create_succeeded",
+			credentialsGroupIsListed: true,
+			wantErr:                  false,
+			wantResp:                 true,
+		},
+		{
+			desc:      "list_fails",
+			listFails: true,
+			wantErr:   true,
+			wantResp:  false,
+		},
+		{
+			desc:     "timeout",
+			wantErr:  true,
+			wantResp: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientCredentialsGroupMocked{
+				credentialsGroupIsListed: tt.credentialsGroupIsListed,
+				listFails:                tt.listFails,
+			}
+
+			var wantRes *objectstorage.CredentialsGroup
+			if tt.wantResp {
+				groupId := "gid"
+				wantRes = &objectstorage.CredentialsGroup{CredentialsGroupId: &groupId}
+			}
+
+			handler := CreateCredentialsGroupWaitHandler(context.Background(), apiClient, "pid", "gid")
+
+			gotRes, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(gotRes, wantRes) {
+				t.Fatalf("handler gotRes = %v, want %v", gotRes, wantRes)
+			}
+		})
+	}
+}
+
+func TestDeleteCredentialsGroupWaitHandler(t *testing.T) {
+	tests := []struct {
+		desc                     string
+		credentialsGroupIsListed bool
+		listFails                bool
+		wantErr                  bool
+	}{
+		{
+			desc:    "delete_succeeded",
+			wantErr: false,
+		},
+		{
+			desc:      "list_fails",
+			listFails: true,
+			wantErr:   true,
+		},
+		{
+			desc:                     "timeout",
+			credentialsGroupIsListed: true,
+			wantErr:                  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientCredentialsGroupMocked{
+				credentialsGroupIsListed: tt.credentialsGroupIsListed,
+				listFails:                tt.listFails,
+			}
+
+			handler := DeleteCredentialsGroupWaitHandler(context.Background(), apiClient, "pid", "gid")
+
+			_, err := handler.SetTimeout(10 * time.Millisecond).WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
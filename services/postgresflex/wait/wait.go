@@ -80,27 +80,24 @@ func CreateInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface
 
 // PartialUpdateInstanceWaitHandler will wait for instance update
 func PartialUpdateInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[postgresflex.InstanceResponse] {
-	handler := wait.New(func() (waitFinished bool, response *postgresflex.InstanceResponse, err error) {
-		s, err := a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
+	handler := wait.ForState(func() (*postgresflex.InstanceResponse, error) {
+		return a.GetInstanceExecute(ctx, projectId, instanceId)
+	}, func(s *postgresflex.InstanceResponse) (string, error) {
 		if s == nil || s.Item == nil || s.Item.Id == nil || *s.Item.Id != instanceId || s.Item.Status == nil {
-			return false, nil, nil
-		}
-		switch *s.Item.Status {
-		default:
-			return true, s, fmt.Errorf("instance with id %s has unexpected status %s", instanceId, *s.Item.Status)
-		case InstanceStateEmpty:
-			return false, nil, nil
-		case InstanceStateProgressing:
-			return false, nil, nil
-		case InstanceStateSuccess:
-			return true, s, nil
-		case InstanceStateFailed:
-			return true, s, fmt.Errorf("update failed for instance with id %s", instanceId)
-		}
-	})
+			return InstanceStateEmpty, nil
+		}
+		return *s.Item.Status, nil
+	}).
+		Success(InstanceStateSuccess).
+		Failure(InstanceStateFailed).
+		Transient(InstanceStateEmpty, InstanceStateProgressing).
+		OnFailure(func(_ *postgresflex.InstanceResponse, _ string) error {
+			return fmt.Errorf("update failed for instance with id %s", instanceId)
+		}).
+		OnUnexpected(func(_ *postgresflex.InstanceResponse, state string) error {
+			return fmt.Errorf("instance with id %s has unexpected status %s", instanceId, state)
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
@@ -130,40 +127,24 @@ func DeleteInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface
 
 // ForceDeleteInstanceWaitHandler will wait for instance deletion
 func ForceDeleteInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, err
-		}
-		if oapiErr.StatusCode != 404 {
-			return false, nil, err
-		}
-		return true, nil, nil
-	})
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetInstanceExecute(ctx, projectId, instanceId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess().
+		Handler()
 	handler.SetTimeout(15 * time.Minute)
 	return handler
 }
 
 // DeleteUserWaitHandler will wait for delete
 func DeleteUserWaitHandler(ctx context.Context, a APIClientUserInterface, projectId, instanceId, userId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetUserExecute(ctx, projectId, instanceId, userId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, err
-		}
-		if oapiErr.StatusCode != 404 {
-			return false, nil, err
-		}
-		return true, nil, nil
-	})
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetUserExecute(ctx, projectId, instanceId, userId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess().
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
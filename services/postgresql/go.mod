@@ -5,7 +5,7 @@ go 1.18
 
 require (
 	github.com/google/go-cmp v0.6.0
-	github.com/stackitcloud/stackit-sdk-go/core v0.12.0
+	github.com/stackitcloud/stackit-sdk-go/core v0.13.0
 )
 
 require (
@@ -20,6 +20,12 @@ const (
 	InstanceTypeDelete   = "delete"
 )
 
+// States of the credentials reported by the stateFn of CreateCredentialsWaitHandler, they aren't returned by the API
+const (
+	credentialsStateCreating = "creating"
+	credentialsStateCreated  = "created"
+)
+
 // Interface needed for tests
 type APIClientInstanceInterface interface {
 	GetInstanceExecute(ctx context.Context, projectId, instanceId string) (*postgresql.Instance, error)
@@ -32,44 +38,26 @@ type APIClientCredentialsInterface interface {
 
 // CreateInstanceWaitHandler will wait for instance creation
 func CreateInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[postgresql.Instance] {
-	handler := wait.New(func() (waitFinished bool, response *postgresql.Instance, err error) {
-		s, err := a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.InstanceId == nil || s.LastOperation == nil || s.LastOperation.Type == nil || s.LastOperation.State == nil {
-			return false, nil, fmt.Errorf("create failed for instance with id %s. The response is not valid: the instance id, the last operation type or the state are missing", instanceId)
-		}
-		if *s.InstanceId == instanceId && *s.LastOperation.Type == InstanceTypeCreate && *s.LastOperation.State == InstanceStateSuccess {
-			return true, s, nil
-		}
-		if *s.InstanceId == instanceId && *s.LastOperation.Type == InstanceTypeCreate && *s.LastOperation.State == InstanceStateFailed {
-			return true, s, fmt.Errorf("create failed for instance with id %s: %s", instanceId, *s.LastOperation.Description)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getInstance(ctx, a, projectId, instanceId), lastOperationState(InstanceTypeCreate, instanceId)).
+		Success(InstanceStateSuccess).
+		Failure(InstanceStateFailed).
+		OnFailure(func(s *postgresql.Instance, _ string) error {
+			return fmt.Errorf("create failed for instance with id %s: %s", instanceId, *s.LastOperation.Description)
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
 
 // PartialUpdateInstanceWaitHandler will wait for instance update
 func PartialUpdateInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) *wait.AsyncActionHandler[postgresql.Instance] {
-	handler := wait.New(func() (waitFinished bool, response *postgresql.Instance, err error) {
-		s, err := a.GetInstanceExecute(ctx, projectId, instanceId)
-		if err != nil {
-			return false, nil, err
-		}
-		if s.InstanceId == nil || s.LastOperation == nil || s.LastOperation.Type == nil || s.LastOperation.State == nil {
-			return false, nil, fmt.Errorf("update failed for instance with id %s. The response is not valid: the instance id, the last operation type or the state are missing", instanceId)
-		}
-		if *s.InstanceId == instanceId && *s.LastOperation.Type == InstanceTypeUpdate && *s.LastOperation.State == InstanceStateSuccess {
-			return true, s, nil
-		}
-		if *s.InstanceId == instanceId && *s.LastOperation.Type == InstanceTypeUpdate && *s.LastOperation.State == InstanceStateFailed {
-			return true, s, fmt.Errorf("update failed for instance with id %s: %s", instanceId, *s.LastOperation.Description)
-		}
-		return false, nil, nil
-	})
+	handler := wait.ForState(getInstance(ctx, a, projectId, instanceId), lastOperationState(InstanceTypeUpdate, instanceId)).
+		Success(InstanceStateSuccess).
+		Failure(InstanceStateFailed).
+		OnFailure(func(s *postgresql.Instance, _ string) error {
+			return fmt.Errorf("update failed for instance with id %s: %s", instanceId, *s.LastOperation.Description)
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
@@ -108,44 +96,54 @@ func DeleteInstanceWaitHandler(ctx context.Context, a APIClientInstanceInterface
 
 // CreateCredentialsWaitHandler will wait for credentials creation
 func CreateCredentialsWaitHandler(ctx context.Context, a APIClientCredentialsInterface, projectId, instanceId, credentialsId string) *wait.AsyncActionHandler[postgresql.CredentialsResponse] {
-	handler := wait.New(func() (waitFinished bool, response *postgresql.CredentialsResponse, err error) {
+	handler := wait.ForState(func() (*postgresql.CredentialsResponse, error) {
 		s, err := a.GetCredentialsExecute(ctx, projectId, instanceId, credentialsId)
-		if err != nil {
-			oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-			if !ok {
-				return false, nil, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError")
-			}
-			// If the request returns 404, the credentials have not been created yet
-			if oapiErr.StatusCode == http.StatusNotFound {
-				return false, nil, nil
-			}
-			return false, nil, err
-		}
-		if *s.Id == credentialsId {
-			return true, s, nil
-		}
-		return false, nil, nil
-	})
+		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
+		// If the request returns 404, the credentials have not been created yet
+		if ok && oapiErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return s, err
+	}, func(s *postgresql.CredentialsResponse) (string, error) {
+		if s != nil && s.Id != nil && *s.Id == credentialsId {
+			return credentialsStateCreated, nil
+		}
+		return credentialsStateCreating, nil
+	}).
+		Success(credentialsStateCreated).
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
 
 // DeleteCredentialsWaitHandler will wait for credentials deletion
 func DeleteCredentialsWaitHandler(ctx context.Context, a APIClientCredentialsInterface, projectId, instanceId, credentialsId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetCredentialsExecute(ctx, projectId, instanceId, credentialsId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError")
-		}
-		if oapiErr.StatusCode != http.StatusNotFound && oapiErr.StatusCode != http.StatusGone {
-			return false, nil, err
-		}
-		return true, nil, nil
-	})
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetCredentialsExecute(ctx, projectId, instanceId, credentialsId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess(http.StatusNotFound, http.StatusGone).
+		Handler()
 	handler.SetTimeout(1 * time.Minute)
 	return handler
 }
+
+func getInstance(ctx context.Context, a APIClientInstanceInterface, projectId, instanceId string) wait.GetFunc[postgresql.Instance] {
+	return func() (*postgresql.Instance, error) {
+		return a.GetInstanceExecute(ctx, projectId, instanceId)
+	}
+}
+
+// lastOperationState returns the state of the last operation of the instance,
+// or an empty state while the last operation is of another type or the response is for another instance
+func lastOperationState(operationType, instanceId string) wait.StateFunc[postgresql.Instance] {
+	return func(s *postgresql.Instance) (string, error) {
+		if s.InstanceId == nil || s.LastOperation == nil || s.LastOperation.Type == nil || s.LastOperation.State == nil {
+			return "", fmt.Errorf("%s failed for instance with id %s. The response is not valid: the instance id, the last operation type or the state are missing", operationType, instanceId)
+		}
+		if *s.InstanceId != instanceId || *s.LastOperation.Type != operationType {
+			return "", nil
+		}
+		return *s.LastOperation.State, nil
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
 	"github.com/stackitcloud/stackit-sdk-go/core/wait"
 	"github.com/stackitcloud/stackit-sdk-go/services/resourcemanager"
 )
@@ -23,22 +22,23 @@ type APIClientInterface interface {
 
 // CreateProjectWaitHandler will wait for project creation
 func CreateProjectWaitHandler(ctx context.Context, a APIClientInterface, containerId string) *wait.AsyncActionHandler[resourcemanager.ProjectResponseWithParents] {
-	handler := wait.New(func() (waitFinished bool, response *resourcemanager.ProjectResponseWithParents, err error) {
-		p, err := a.GetProjectExecute(ctx, containerId)
-		if err != nil {
-			return false, nil, err
-		}
+	handler := wait.ForState(func() (*resourcemanager.ProjectResponseWithParents, error) {
+		return a.GetProjectExecute(ctx, containerId)
+	}, func(p *resourcemanager.ProjectResponseWithParents) (string, error) {
 		if p.ContainerId == nil || p.LifecycleState == nil {
-			return false, nil, fmt.Errorf("creation failed: response invalid for container id %s. Container id or LifeCycleState missing", containerId)
-		}
-		if *p.ContainerId == containerId && *p.LifecycleState == ActiveState {
-			return true, p, nil
+			return "", fmt.Errorf("creation failed: response invalid for container id %s. Container id or LifeCycleState missing", containerId)
 		}
-		if *p.ContainerId == containerId && *p.LifecycleState == CreatingState {
-			return false, nil, nil
+		if *p.ContainerId != containerId {
+			return "", fmt.Errorf("creation failed: received project with container id %s, want %s", *p.ContainerId, containerId)
 		}
-		return true, p, fmt.Errorf("creation failed: received project state '%s'", *p.LifecycleState)
-	})
+		return string(*p.LifecycleState), nil
+	}).
+		Success(string(ActiveState)).
+		Transient(string(CreatingState)).
+		OnUnexpected(func(_ *resourcemanager.ProjectResponseWithParents, state string) error {
+			return fmt.Errorf("creation failed: received project state '%s'", state)
+		}).
+		Handler()
 	handler.SetSleepBeforeWait(1 * time.Minute)
 	handler.SetTimeout(45 * time.Minute)
 	return handler
@@ -46,20 +46,12 @@ func CreateProjectWaitHandler(ctx context.Context, a APIClientInterface, contain
 
 // DeleteProjectWaitHandler will wait for project deletion
 func DeleteProjectWaitHandler(ctx context.Context, a APIClientInterface, containerId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetProjectExecute(ctx, containerId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError")
-		}
-		if oapiErr.StatusCode == http.StatusNotFound || oapiErr.StatusCode == http.StatusForbidden {
-			return true, nil, nil
-		}
-		return false, nil, err
-	})
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetProjectExecute(ctx, containerId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess(http.StatusNotFound, http.StatusForbidden).
+		Handler()
 	handler.SetTimeout(15 * time.Minute)
 	return handler
 }
@@ -0,0 +1,196 @@
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corewait "github.com/stackitcloud/stackit-sdk-go/core/wait"
+	"github.com/stackitcloud/stackit-sdk-go/services/ske"
+	"github.com/stackitcloud/stackit-sdk-go/services/ske/wait"
+)
+
+// DefaultSleepBeforeWait is the default duration to sleep after starting or completing the rotation, before waiting for the phase to finish
+const DefaultSleepBeforeWait = 10 * time.Second
+
+// States reported by phaseChange, they aren't returned by the API
+const (
+	statePhaseChanged   = "PHASE_CHANGED"
+	statePhaseUnchanged = "PHASE_UNCHANGED"
+)
+
+// Interfaces needed for tests
+type APIClientInterface interface {
+	wait.APIClientClusterInterface
+	StartCredentialsRotationExecute(ctx context.Context, projectId, clusterName string) (map[string]interface{}, error)
+	CompleteCredentialsRotationExecute(ctx context.Context, projectId, clusterName string) (map[string]interface{}, error)
+	CreateKubeconfigExecute(ctx context.Context, projectId, clusterName string) (*ske.Kubeconfig, error)
+}
+
+// DistributeFunc is called once the new credentials are prepared, with a kubeconfig that already uses them.
+// It must hand the kubeconfig to every consumer of the cluster before returning, since the old credentials
+// are revoked afterwards. When resuming a rotation it may be called again, so it should be idempotent.
+type DistributeFunc func(ctx context.Context, kubeconfig *ske.Kubeconfig) error
+
+// Workflow drives a cluster credentials rotation through all of its phases.
+// The next step is always derived from the cluster's CredentialsRotationState.Phase, so a workflow that was
+// interrupted (e.g. because the process restarted) can be picked up again by creating a new Workflow for the same cluster.
+type Workflow struct {
+	client          APIClientInterface
+	projectId       string
+	clusterName     string
+	distribute      DistributeFunc
+	throttle        time.Duration
+	timeout         time.Duration
+	sleepBeforeWait time.Duration
+}
+
+// NewWorkflow initializes a Workflow for the given cluster
+func NewWorkflow(a APIClientInterface, projectId, clusterName string, distribute DistributeFunc) *Workflow {
+	return &Workflow{
+		client:          a,
+		projectId:       projectId,
+		clusterName:     clusterName,
+		distribute:      distribute,
+		sleepBeforeWait: DefaultSleepBeforeWait,
+	}
+}
+
+// SetThrottle sets the time interval between each check while waiting for a phase to finish.
+// If not set, the default of the underlying wait handlers is used.
+func (w *Workflow) SetThrottle(d time.Duration) *Workflow {
+	w.throttle = d
+	return w
+}
+
+// SetTimeout sets the wait timeout of each phase.
+// If not set, the default of the underlying wait handlers is used.
+func (w *Workflow) SetTimeout(d time.Duration) *Workflow {
+	w.timeout = d
+	return w
+}
+
+// SetSleepBeforeWait sets the duration to sleep after starting or completing the rotation, before waiting for the phase to finish,
+// DefaultSleepBeforeWait if not set. The workflow waits anyway for the phase of the cluster to change after the request.
+func (w *Workflow) SetSleepBeforeWait(d time.Duration) *Workflow {
+	w.sleepBeforeWait = d
+	return w
+}
+
+// Run rotates the cluster credentials.
+// If a rotation is already in progress it is resumed, otherwise a new one is started.
+func (w *Workflow) Run(ctx context.Context) (*ske.Cluster, error) {
+	return w.run(ctx, true)
+}
+
+// Resume continues a rotation that is already in progress.
+// If there is no rotation in progress, it returns the cluster without changing it.
+func (w *Workflow) Resume(ctx context.Context) (*ske.Cluster, error) {
+	return w.run(ctx, false)
+}
+
+func (w *Workflow) run(ctx context.Context, startNew bool) (*ske.Cluster, error) {
+	if w.distribute == nil {
+		return nil, fmt.Errorf("distribute function can't be nil")
+	}
+
+	cluster, err := w.client.GetClusterExecute(ctx, w.projectId, w.clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("get cluster: %w", err)
+	}
+
+	phase := Phase(cluster)
+	switch phase {
+	case "", wait.CredentialsRotationStateNever, wait.CredentialsRotationStateCompleted:
+		if !startNew {
+			return cluster, nil
+		}
+		_, err = w.client.StartCredentialsRotationExecute(ctx, w.projectId, w.clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("start credentials rotation: %w", err)
+		}
+		cluster, err = w.waitForPhaseChange(ctx, phase)
+		if err != nil {
+			return cluster, fmt.Errorf("wait for credentials rotation to start: %w", err)
+		}
+		fallthrough
+	case wait.CredentialsRotationStatePreparing:
+		handler := wait.StartCredentialsRotationWaitHandler(ctx, w.client, w.projectId, w.clusterName)
+		cluster, err = w.configure(handler).WaitWithContext(ctx)
+		if err != nil {
+			return cluster, fmt.Errorf("wait for credentials rotation to be prepared: %w", err)
+		}
+		fallthrough
+	case wait.CredentialsRotationStatePrepared:
+		kubeconfig, err := w.client.CreateKubeconfigExecute(ctx, w.projectId, w.clusterName)
+		if err != nil {
+			return cluster, fmt.Errorf("create kubeconfig: %w", err)
+		}
+		err = w.distribute(ctx, kubeconfig)
+		if err != nil {
+			return cluster, fmt.Errorf("distribute kubeconfig: %w", err)
+		}
+		_, err = w.client.CompleteCredentialsRotationExecute(ctx, w.projectId, w.clusterName)
+		if err != nil {
+			return cluster, fmt.Errorf("complete credentials rotation: %w", err)
+		}
+		cluster, err = w.waitForPhaseChange(ctx, wait.CredentialsRotationStatePrepared)
+		if err != nil {
+			return cluster, fmt.Errorf("wait for credentials rotation completion to start: %w", err)
+		}
+		fallthrough
+	case wait.CredentialsRotationStateCompleting:
+		handler := wait.CompleteCredentialsRotationWaitHandler(ctx, w.client, w.projectId, w.clusterName)
+		cluster, err = w.configure(handler).WaitWithContext(ctx)
+		if err != nil {
+			return cluster, fmt.Errorf("wait for credentials rotation to be completed: %w", err)
+		}
+		return cluster, nil
+	default:
+		return cluster, fmt.Errorf("unexpected credentials rotation phase %s", phase)
+	}
+}
+
+// waitForPhaseChange sleeps for the configured duration and waits for the phase of the cluster to move on from the given one,
+// as it may lag behind the request that changes it
+func (w *Workflow) waitForPhaseChange(ctx context.Context, phase string) (*ske.Cluster, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(w.sleepBeforeWait):
+	}
+	handler := corewait.ForState(func() (*ske.Cluster, error) {
+		return w.client.GetClusterExecute(ctx, w.projectId, w.clusterName)
+	}, phaseChange(phase)).
+		Success(statePhaseChanged).
+		Handler()
+	return w.configure(handler).WaitWithContext(ctx)
+}
+
+// phaseChange returns whether the credentials rotation phase of the cluster is another one than the given phase
+func phaseChange(phase string) corewait.StateFunc[ske.Cluster] {
+	return func(cluster *ske.Cluster) (string, error) {
+		if Phase(cluster) == phase {
+			return statePhaseUnchanged, nil
+		}
+		return statePhaseChanged, nil
+	}
+}
+
+func (w *Workflow) configure(h *corewait.AsyncActionHandler[ske.Cluster]) *corewait.AsyncActionHandler[ske.Cluster] {
+	if w.throttle != 0 {
+		h.SetThrottle(w.throttle)
+	}
+	if w.timeout != 0 {
+		h.SetTimeout(w.timeout)
+	}
+	return h
+}
+
+// Phase returns the credentials rotation phase of the cluster, or an empty string if the cluster doesn't report one.
+func Phase(cluster *ske.Cluster) string {
+	if cluster == nil || cluster.Status == nil || cluster.Status.CredentialsRotation == nil || cluster.Status.CredentialsRotation.Phase == nil {
+		return ""
+	}
+	return *cluster.Status.CredentialsRotation.Phase
+}
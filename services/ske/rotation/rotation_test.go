@@ -0,0 +1,276 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/ske"
+	"github.com/stackitcloud/stackit-sdk-go/services/ske/wait"
+)
+
+// Used for testing credentials rotation operations
+type apiClientMocked struct {
+	phase         string
+	nextPhase     string
+	startFails    bool
+	completeFails bool
+	// number of calls to GetClusterExecute after starting or completing the rotation that still return the previous phase
+	lag         int
+	laggedCalls int
+	laggedPhase string
+	calls       []string
+}
+
+func (a *apiClientMocked) GetClusterExecute(_ context.Context, _, _ string) (*ske.Cluster, error) {
+	if a.laggedCalls > 0 {
+		a.laggedCalls--
+		if a.laggedCalls == 0 {
+			defer func() { a.phase = a.laggedPhase }()
+		}
+	}
+	cluster := &ske.Cluster{
+		Name: utils.Ptr("cluster"),
+		Status: &ske.ClusterStatus{
+			CredentialsRotation: &ske.CredentialsRotationState{
+				Phase: utils.Ptr(a.phase),
+			},
+		},
+	}
+	if a.nextPhase != "" {
+		a.phase = a.nextPhase
+		a.nextPhase = ""
+	}
+	return cluster, nil
+}
+
+func (a *apiClientMocked) ListClustersExecute(_ context.Context, _ string) (*ske.ListClustersResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *apiClientMocked) StartCredentialsRotationExecute(_ context.Context, _, _ string) (map[string]interface{}, error) {
+	a.calls = append(a.calls, "start")
+	if a.startFails {
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	a.setPhase(wait.CredentialsRotationStatePrepared)
+	return map[string]interface{}{}, nil
+}
+
+func (a *apiClientMocked) CompleteCredentialsRotationExecute(_ context.Context, _, _ string) (map[string]interface{}, error) {
+	a.calls = append(a.calls, "complete")
+	if a.completeFails {
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	a.setPhase(wait.CredentialsRotationStateCompleted)
+	return map[string]interface{}{}, nil
+}
+
+// setPhase sets the phase of the cluster, after the configured lag
+func (a *apiClientMocked) setPhase(phase string) {
+	if a.lag == 0 {
+		a.phase = phase
+		return
+	}
+	a.laggedCalls = a.lag
+	a.laggedPhase = phase
+}
+
+func (a *apiClientMocked) CreateKubeconfigExecute(_ context.Context, _, _ string) (*ske.Kubeconfig, error) {
+	a.calls = append(a.calls, "kubeconfig")
+	return &ske.Kubeconfig{
+		Kubeconfig: utils.Ptr("kubeconfig"),
+	}, nil
+}
+
+func TestWorkflow(t *testing.T) {
+	tests := []struct {
+		desc           string
+		phase          string
+		nextPhase      string
+		resumeOnly     bool
+		startFails     bool
+		completeFails  bool
+		lag            int
+		distributeFail bool
+		wantErr        bool
+		wantCalls      []string
+		wantPhase      string
+	}{
+		{
+			desc:      "new_rotation",
+			phase:     wait.CredentialsRotationStateNever,
+			wantErr:   false,
+			wantCalls: []string{"start", "kubeconfig", "distribute", "complete"},
+			wantPhase: wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:      "new_rotation_with_lagging_phase",
+			phase:     wait.CredentialsRotationStateNever,
+			lag:       3,
+			wantErr:   false,
+			wantCalls: []string{"start", "kubeconfig", "distribute", "complete"},
+			wantPhase: wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:      "new_rotation_after_previous_one",
+			phase:     wait.CredentialsRotationStateCompleted,
+			wantErr:   false,
+			wantCalls: []string{"start", "kubeconfig", "distribute", "complete"},
+			wantPhase: wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:      "resume_prepared",
+			phase:     wait.CredentialsRotationStatePrepared,
+			wantErr:   false,
+			wantCalls: []string{"kubeconfig", "distribute", "complete"},
+			wantPhase: wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:       "resume_only_prepared",
+			phase:      wait.CredentialsRotationStatePrepared,
+			resumeOnly: true,
+			wantErr:    false,
+			wantCalls:  []string{"kubeconfig", "distribute", "complete"},
+			wantPhase:  wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:      "resume_preparing",
+			phase:     wait.CredentialsRotationStatePreparing,
+			nextPhase: wait.CredentialsRotationStatePrepared,
+			wantErr:   false,
+			wantCalls: []string{"kubeconfig", "distribute", "complete"},
+			wantPhase: wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:      "resume_completing",
+			phase:     wait.CredentialsRotationStateCompleting,
+			nextPhase: wait.CredentialsRotationStateCompleted,
+			wantErr:   false,
+			wantCalls: nil,
+			wantPhase: wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:      "timeout",
+			phase:     wait.CredentialsRotationStateCompleting,
+			wantErr:   true,
+			wantCalls: nil,
+			wantPhase: wait.CredentialsRotationStateCompleting,
+		},
+		{
+			desc:       "resume_only_nothing_in_progress",
+			phase:      wait.CredentialsRotationStateCompleted,
+			resumeOnly: true,
+			wantErr:    false,
+			wantCalls:  nil,
+			wantPhase:  wait.CredentialsRotationStateCompleted,
+		},
+		{
+			desc:       "start_fails",
+			phase:      wait.CredentialsRotationStateNever,
+			startFails: true,
+			wantErr:    true,
+			wantCalls:  []string{"start"},
+			wantPhase:  wait.CredentialsRotationStateNever,
+		},
+		{
+			desc:           "distribute_fails",
+			phase:          wait.CredentialsRotationStateNever,
+			distributeFail: true,
+			wantErr:        true,
+			wantCalls:      []string{"start", "kubeconfig", "distribute"},
+			wantPhase:      wait.CredentialsRotationStatePrepared,
+		},
+		{
+			desc:          "complete_fails",
+			phase:         wait.CredentialsRotationStatePrepared,
+			completeFails: true,
+			wantErr:       true,
+			wantCalls:     []string{"kubeconfig", "distribute", "complete"},
+			wantPhase:     wait.CredentialsRotationStatePrepared,
+		},
+		{
+			desc:      "unexpected_phase",
+			phase:     "ANOTHER PHASE",
+			wantErr:   true,
+			wantCalls: nil,
+			wantPhase: "ANOTHER PHASE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientMocked{
+				phase:         tt.phase,
+				nextPhase:     tt.nextPhase,
+				startFails:    tt.startFails,
+				completeFails: tt.completeFails,
+				lag:           tt.lag,
+			}
+			distribute := func(_ context.Context, kubeconfig *ske.Kubeconfig) error {
+				apiClient.calls = append(apiClient.calls, "distribute")
+				if *kubeconfig.Kubeconfig != "kubeconfig" {
+					t.Fatalf("distributed unexpected kubeconfig %q", *kubeconfig.Kubeconfig)
+				}
+				if tt.distributeFail {
+					return fmt.Errorf("distribution failed")
+				}
+				return nil
+			}
+
+			workflow := NewWorkflow(apiClient, "pid", "cluster", distribute).
+				SetThrottle(time.Millisecond).
+				SetTimeout(10 * time.Millisecond).
+				SetSleepBeforeWait(time.Millisecond)
+
+			var err error
+			if tt.resumeOnly {
+				_, err = workflow.Resume(context.Background())
+			} else {
+				_, err = workflow.Run(context.Background())
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("workflow error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(apiClient.calls, tt.wantCalls); diff != "" {
+				t.Fatalf("unexpected calls (-got +want): %s", diff)
+			}
+			if apiClient.phase != tt.wantPhase {
+				t.Fatalf("phase = %s, want %s", apiClient.phase, tt.wantPhase)
+			}
+		})
+	}
+}
+
+func TestWorkflowCanceledBeforeWait(t *testing.T) {
+	apiClient := &apiClientMocked{phase: wait.CredentialsRotationStateNever}
+	workflow := NewWorkflow(apiClient, "pid", "cluster", func(_ context.Context, _ *ske.Kubeconfig) error { return nil }).
+		SetSleepBeforeWait(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := workflow.Run(ctx)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("workflow error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("workflow didn't stop once the context was done")
+	}
+}
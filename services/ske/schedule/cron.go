@@ -0,0 +1,215 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of days searched for the next or previous occurrence of a cron expression.
+// Five years are enough to find an occurrence of any valid expression, including the ones that only match on February 29th.
+const cronSearchDays = 5 * 366
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var weekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField  = cronField{name: "minute", min: 0, max: 59}
+	hourField    = cronField{name: "hour", min: 0, max: 23}
+	dayField     = cronField{name: "day of month", min: 1, max: 31}
+	monthField   = cronField{name: "month", min: 1, max: 12, names: monthNames}
+	weekdayField = cronField{name: "day of week", min: 0, max: 7, names: weekdayNames}
+)
+
+// Cron is a parsed cron expression in the standard five field format ("minute hour day-of-month month day-of-week"),
+// as used by the SKE hibernation schedules.
+type Cron struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	// Whether the day of month or day of week fields are unrestricted.
+	// As in standard cron, if both are restricted a day matches if it matches either of them.
+	daysStar     bool
+	weekdaysStar bool
+}
+
+// ParseCron parses a cron expression in the standard five field format.
+// Each field supports "*", single values, ranges ("1-5"), lists ("1,3,5") and steps ("*/15", "0-30/10", "5/10" from 5 to the maximum).
+// Months and days of week can also be given by their three letter english names ("JAN", "MON").
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, found %d", expr, len(fields))
+	}
+
+	c := &Cron{}
+	var err error
+	if c.minutes, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if c.hours, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if c.days, err = parseCronField(fields[2], dayField); err != nil {
+		return nil, err
+	}
+	if c.months, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if c.weekdays, err = parseCronField(fields[4], weekdayField); err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 mean Sunday
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+	c.daysStar = fields[2] == "*" || fields[2] == "?"
+	c.weekdaysStar = fields[4] == "*" || fields[4] == "?"
+	return c, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rangePart, step, hasStep := part, 1, false
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart, hasStep = part[:i], true
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			low, high = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if low, err = parseCronValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, part)
+			}
+		default:
+			var err error
+			if low, err = parseCronValue(rangePart, f); err != nil {
+				return 0, err
+			}
+			high = low
+			// "5/10" means "starting at 5, every 10", up to the maximum of the field
+			if hasStep {
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", s, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d] in %s field", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+func (c *Cron) matchesDay(date time.Time) bool {
+	if c.months&(1<<uint(date.Month())) == 0 {
+		return false
+	}
+	dayMatches := c.days&(1<<uint(date.Day())) != 0
+	weekdayMatches := c.weekdays&(1<<uint(date.Weekday())) != 0
+	if c.daysStar || c.weekdaysStar {
+		return dayMatches && weekdayMatches
+	}
+	return dayMatches || weekdayMatches
+}
+
+// occurrencesOn returns the occurrences of the expression on the given calendar day in loc, in ascending order.
+// Times that don't exist on that day because of a daylight saving time change are skipped.
+func (c *Cron) occurrencesOn(date time.Time, loc *time.Location) []time.Time {
+	if !c.matchesDay(date) {
+		return nil
+	}
+	var times []time.Time
+	for h := 0; h <= hourField.max; h++ {
+		if c.hours&(1<<uint(h)) == 0 {
+			continue
+		}
+		for m := 0; m <= minuteField.max; m++ {
+			if c.minutes&(1<<uint(m)) == 0 {
+				continue
+			}
+			t := time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, loc)
+			if t.Hour() != h || t.Minute() != m {
+				continue
+			}
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// Next returns the first occurrence of the expression in loc that is strictly after t.
+// It returns the zero time if there is none.
+func (c *Cron) Next(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	first := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < cronSearchDays; i++ {
+		for _, o := range c.occurrencesOn(first.AddDate(0, 0, i), loc) {
+			if o.After(t) {
+				return o
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the last occurrence of the expression in loc that is at or before t.
+// It returns the zero time if there is none.
+func (c *Cron) Prev(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	first := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < cronSearchDays; i++ {
+		occurrences := c.occurrencesOn(first.AddDate(0, 0, -i), loc)
+		for j := len(occurrences) - 1; j >= 0; j-- {
+			if !occurrences[j].After(t) {
+				return occurrences[j]
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,195 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		desc    string
+		expr    string
+		wantErr bool
+	}{
+		{
+			desc:    "every_minute",
+			expr:    "* * * * *",
+			wantErr: false,
+		},
+		{
+			desc:    "lists_ranges_and_steps",
+			expr:    "0,30 8-18/2 1-15 */3 1-5",
+			wantErr: false,
+		},
+		{
+			desc:    "names",
+			expr:    "0 18 * jan-MAR MON-FRI",
+			wantErr: false,
+		},
+		{
+			desc:    "sunday_as_7",
+			expr:    "0 0 * * 7",
+			wantErr: false,
+		},
+		{
+			desc:    "too_few_fields",
+			expr:    "0 18 * *",
+			wantErr: true,
+		},
+		{
+			desc:    "out_of_range",
+			expr:    "60 18 * * *",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_range",
+			expr:    "0 18-8 * * *",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_step",
+			expr:    "*/0 * * * *",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_name",
+			expr:    "0 0 * * FOO",
+			wantErr: true,
+		},
+		{
+			desc:    "empty",
+			expr:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCron error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		desc  string
+		field string
+		want  []int
+	}{
+		{
+			desc:  "value",
+			field: "5",
+			want:  []int{5},
+		},
+		{
+			desc:  "range_with_step",
+			field: "10-20/5",
+			want:  []int{10, 15, 20},
+		},
+		{
+			desc:  "value_with_step",
+			field: "40/7",
+			want:  []int{40, 47, 54},
+		},
+		{
+			desc:  "value_with_step_1",
+			field: "55/1",
+			want:  []int{55, 56, 57, 58, 59},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := parseCronField(tt.field, minuteField)
+			if err != nil {
+				t.Fatalf("parseCronField: %v", err)
+			}
+			var want uint64
+			for _, v := range tt.want {
+				want |= 1 << uint(v)
+			}
+			if got != want {
+				t.Fatalf("parseCronField = %b, want %b", got, want)
+			}
+		})
+	}
+}
+
+func TestCronNextPrev(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	tests := []struct {
+		desc     string
+		expr     string
+		loc      *time.Location
+		t        time.Time
+		wantNext time.Time
+		wantPrev time.Time
+	}{
+		{
+			desc:     "weekdays_evening",
+			expr:     "0 18 * * 1-5",
+			loc:      time.UTC,
+			t:        time.Date(2024, 6, 7, 19, 0, 0, 0, time.UTC), // Friday
+			wantNext: time.Date(2024, 6, 10, 18, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 6, 7, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "exact_occurrence",
+			expr:     "30 6 * * *",
+			loc:      time.UTC,
+			t:        time.Date(2024, 6, 7, 6, 30, 0, 0, time.UTC),
+			wantNext: time.Date(2024, 6, 8, 6, 30, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 6, 7, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			desc:     "timezone",
+			expr:     "0 18 * * *",
+			loc:      berlin,
+			t:        time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2024, 6, 7, 16, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 6, 6, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "day_of_month_or_day_of_week",
+			expr:     "0 0 1 * SUN",
+			loc:      time.UTC,
+			t:        time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC), // Sunday
+			wantNext: time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "leap_day",
+			expr:     "0 0 29 2 *",
+			loc:      time.UTC,
+			t:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "skips_daylight_saving_gap",
+			expr:     "30 2 * * *",
+			loc:      berlin,
+			t:        time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2024, 4, 1, 0, 30, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 3, 30, 1, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			if got := c.Next(tt.t, tt.loc); !got.Equal(tt.wantNext) {
+				t.Fatalf("Next = %s, want %s", got, tt.wantNext)
+			}
+			if got := c.Prev(tt.t, tt.loc); !got.Equal(tt.wantPrev) {
+				t.Fatalf("Prev = %s, want %s", got, tt.wantPrev)
+			}
+		})
+	}
+}
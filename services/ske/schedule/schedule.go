@@ -0,0 +1,328 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/services/ske"
+)
+
+// Layouts accepted for the start and end of a maintenance time window, e.g. "0000-01-01T03:00:00+02:00" or "03:00:00+02:00"
+var timeWindowLayouts = []string{time.RFC3339, "15:04:05Z07:00"}
+
+// Window is a period of time, starting at Start (inclusive) and ending at End (exclusive).
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t is within the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Overlaps reports whether the window shares any period of time with o.
+func (w Window) Overlaps(o Window) bool {
+	return w.Start.Before(o.End) && o.Start.Before(w.End)
+}
+
+// Overlap describes two schedules whose windows overlap.
+type Overlap struct {
+	// First and Second identify the overlapping schedules, e.g. "hibernation schedule 0" or "maintenance window"
+	First  string
+	Second string
+	// Window is the period of time covered by both schedules
+	Window Window
+}
+
+type hibernationSchedule struct {
+	start *Cron
+	end   *Cron
+	loc   *time.Location
+}
+
+func parseHibernationSchedule(s *ske.HibernationSchedule) (*hibernationSchedule, error) {
+	if s == nil {
+		return nil, fmt.Errorf("hibernation schedule is nil")
+	}
+	if s.Start == nil || s.End == nil {
+		return nil, fmt.Errorf("hibernation schedule start and end must be set")
+	}
+	start, err := ParseCron(*s.Start)
+	if err != nil {
+		return nil, fmt.Errorf("parse hibernation start: %w", err)
+	}
+	end, err := ParseCron(*s.End)
+	if err != nil {
+		return nil, fmt.Errorf("parse hibernation end: %w", err)
+	}
+	loc := time.UTC
+	if s.Timezone != nil && *s.Timezone != "" {
+		loc, err = time.LoadLocation(*s.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("load hibernation timezone: %w", err)
+		}
+	}
+	return &hibernationSchedule{start: start, end: end, loc: loc}, nil
+}
+
+// window returns the hibernation window that starts at start.
+func (h *hibernationSchedule) window(start time.Time) (Window, error) {
+	end := h.end.Next(start, h.loc)
+	if end.IsZero() {
+		return Window{}, fmt.Errorf("hibernation end never occurs after %s", start)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// windows returns the hibernation windows that overlap with [from, to).
+func (h *hibernationSchedule) windows(from, to time.Time) ([]Window, error) {
+	windows := []Window{}
+	start := h.start.Prev(from, h.loc)
+	if start.IsZero() {
+		start = h.start.Next(from, h.loc)
+	}
+	for !start.IsZero() && start.Before(to) {
+		w, err := h.window(start)
+		if err != nil {
+			return nil, err
+		}
+		if w.End.After(from) {
+			windows = append(windows, w)
+		}
+		start = h.start.Next(start, h.loc)
+	}
+	return windows, nil
+}
+
+// ValidateHibernationSchedule checks that the start and end of the schedule are valid cron expressions and that its timezone is known.
+func ValidateHibernationSchedule(s *ske.HibernationSchedule) error {
+	_, err := parseHibernationSchedule(s)
+	return err
+}
+
+// NextHibernation returns the first hibernation window of the schedule that starts after t.
+func NextHibernation(s *ske.HibernationSchedule, t time.Time) (Window, error) {
+	h, err := parseHibernationSchedule(s)
+	if err != nil {
+		return Window{}, err
+	}
+	start := h.start.Next(t, h.loc)
+	if start.IsZero() {
+		return Window{}, fmt.Errorf("hibernation start never occurs after %s", t)
+	}
+	return h.window(start)
+}
+
+// NextWakeUp returns the first time after t at which the schedule wakes the cluster up.
+func NextWakeUp(s *ske.HibernationSchedule, t time.Time) (time.Time, error) {
+	h, err := parseHibernationSchedule(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	end := h.end.Next(t, h.loc)
+	if end.IsZero() {
+		return time.Time{}, fmt.Errorf("hibernation end never occurs after %s", t)
+	}
+	return end, nil
+}
+
+// IsHibernated reports whether any of the schedules is supposed to keep the cluster hibernated at t.
+func IsHibernated(hibernation *ske.Hibernation, t time.Time) (bool, error) {
+	if hibernation == nil || hibernation.Schedules == nil {
+		return false, nil
+	}
+	for i := range *hibernation.Schedules {
+		h, err := parseHibernationSchedule(&(*hibernation.Schedules)[i])
+		if err != nil {
+			return false, fmt.Errorf("hibernation schedule %d: %w", i, err)
+		}
+		start := h.start.Prev(t, h.loc)
+		if start.IsZero() {
+			continue
+		}
+		end := h.end.Prev(t, h.loc)
+		if end.IsZero() || start.After(end) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HibernationWindows returns the windows in which the cluster is supposed to be hibernated that overlap with [from, to), sorted by start time.
+// Windows of different schedules are returned separately, even if they overlap.
+func HibernationWindows(hibernation *ske.Hibernation, from, to time.Time) ([]Window, error) {
+	windows := []Window{}
+	if hibernation == nil || hibernation.Schedules == nil {
+		return windows, nil
+	}
+	for i := range *hibernation.Schedules {
+		h, err := parseHibernationSchedule(&(*hibernation.Schedules)[i])
+		if err != nil {
+			return nil, fmt.Errorf("hibernation schedule %d: %w", i, err)
+		}
+		w, err := h.windows(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("hibernation schedule %d: %w", i, err)
+		}
+		windows = append(windows, w...)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows, nil
+}
+
+type timeWindow struct {
+	start    time.Time
+	duration time.Duration
+}
+
+func parseTimeOfDay(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timeWindowLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func parseTimeWindow(w *ske.TimeWindow) (*timeWindow, error) {
+	if w == nil {
+		return nil, fmt.Errorf("maintenance time window is nil")
+	}
+	if w.Start == nil || w.End == nil {
+		return nil, fmt.Errorf("maintenance time window start and end must be set")
+	}
+	start, err := parseTimeOfDay(*w.Start)
+	if err != nil {
+		return nil, fmt.Errorf("parse maintenance window start: %w", err)
+	}
+	end, err := parseTimeOfDay(*w.End)
+	if err != nil {
+		return nil, fmt.Errorf("parse maintenance window end: %w", err)
+	}
+
+	// Only the time of day is relevant, the window repeats every day
+	day := 24 * time.Hour
+	startOfDay := secondOfDayUTC(start)
+	duration := (secondOfDayUTC(end) - startOfDay + day) % day
+	if duration == 0 {
+		return nil, fmt.Errorf("maintenance window start and end must be different")
+	}
+	return &timeWindow{start: start, duration: duration}, nil
+}
+
+func secondOfDayUTC(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// windows returns the maintenance windows that overlap with [from, to).
+func (w *timeWindow) windows(from, to time.Time) []Window {
+	windows := []Window{}
+	loc := w.start.Location()
+	from = from.In(loc)
+	// Start on the previous day, its window may still be ongoing
+	day := time.Date(from.Year(), from.Month(), from.Day()-1, w.start.Hour(), w.start.Minute(), w.start.Second(), 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		window := Window{Start: day, End: day.Add(w.duration)}
+		if window.End.After(from) {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// ValidateMaintenanceWindow checks that the start and end of the time window are valid times of day.
+func ValidateMaintenanceWindow(w *ske.TimeWindow) error {
+	_, err := parseTimeWindow(w)
+	return err
+}
+
+// NextMaintenanceWindow returns the first maintenance window that hasn't ended at t.
+// If t is within a maintenance window, that window is returned.
+func NextMaintenanceWindow(w *ske.TimeWindow, t time.Time) (Window, error) {
+	tw, err := parseTimeWindow(w)
+	if err != nil {
+		return Window{}, err
+	}
+	// A window at most lasts a day, so there is always one within two days
+	return tw.windows(t, t.Add(48*time.Hour))[0], nil
+}
+
+// MaintenanceWindows returns the maintenance windows that overlap with [from, to), sorted by start time.
+func MaintenanceWindows(w *ske.TimeWindow, from, to time.Time) ([]Window, error) {
+	tw, err := parseTimeWindow(w)
+	if err != nil {
+		return nil, err
+	}
+	return tw.windows(from, to), nil
+}
+
+// Overlaps returns the overlaps between the hibernation schedules and the maintenance window of the cluster in [from, to).
+// Windows that overlap are reported for each pair of hibernation schedules and for each hibernation schedule and the maintenance window.
+func Overlaps(cluster *ske.Cluster, from, to time.Time) ([]Overlap, error) {
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster is nil")
+	}
+	type source struct {
+		name    string
+		windows []Window
+	}
+	sources := []source{}
+
+	if cluster.Hibernation != nil && cluster.Hibernation.Schedules != nil {
+		for i := range *cluster.Hibernation.Schedules {
+			h, err := parseHibernationSchedule(&(*cluster.Hibernation.Schedules)[i])
+			if err != nil {
+				return nil, fmt.Errorf("hibernation schedule %d: %w", i, err)
+			}
+			windows, err := h.windows(from, to)
+			if err != nil {
+				return nil, fmt.Errorf("hibernation schedule %d: %w", i, err)
+			}
+			sources = append(sources, source{name: fmt.Sprintf("hibernation schedule %d", i), windows: windows})
+		}
+	}
+	if cluster.Maintenance != nil && cluster.Maintenance.TimeWindow != nil {
+		tw, err := parseTimeWindow(cluster.Maintenance.TimeWindow)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source{name: "maintenance window", windows: tw.windows(from, to)})
+	}
+
+	overlaps := []Overlap{}
+	for i := range sources {
+		for j := i + 1; j < len(sources); j++ {
+			for _, a := range sources[i].windows {
+				for _, b := range sources[j].windows {
+					if !a.Overlaps(b) {
+						continue
+					}
+					overlaps = append(overlaps, Overlap{
+						First:  sources[i].name,
+						Second: sources[j].name,
+						Window: intersection(a, b),
+					})
+				}
+			}
+		}
+	}
+	sort.SliceStable(overlaps, func(i, j int) bool { return overlaps[i].Window.Start.Before(overlaps[j].Window.Start) })
+	return overlaps, nil
+}
+
+func intersection(a, b Window) Window {
+	w := a
+	if b.Start.After(w.Start) {
+		w.Start = b.Start
+	}
+	if b.End.Before(w.End) {
+		w.End = b.End
+	}
+	return w
+}
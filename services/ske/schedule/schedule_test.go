@@ -0,0 +1,301 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
+	"github.com/stackitcloud/stackit-sdk-go/services/ske"
+)
+
+func fixtureHibernationSchedule(start, end string) ske.HibernationSchedule {
+	return ske.HibernationSchedule{
+		Start:    utils.Ptr(start),
+		End:      utils.Ptr(end),
+		Timezone: utils.Ptr("Europe/Berlin"),
+	}
+}
+
+func TestValidateHibernationSchedule(t *testing.T) {
+	tests := []struct {
+		desc     string
+		schedule *ske.HibernationSchedule
+		wantErr  bool
+	}{
+		{
+			desc:     "ok",
+			schedule: utils.Ptr(fixtureHibernationSchedule("0 18 * * 1-5", "0 8 * * 1-5")),
+			wantErr:  false,
+		},
+		{
+			desc: "no_timezone",
+			schedule: &ske.HibernationSchedule{
+				Start: utils.Ptr("0 18 * * *"),
+				End:   utils.Ptr("0 8 * * *"),
+			},
+			wantErr: false,
+		},
+		{
+			desc:     "invalid_start",
+			schedule: utils.Ptr(fixtureHibernationSchedule("0 25 * * *", "0 8 * * *")),
+			wantErr:  true,
+		},
+		{
+			desc:     "invalid_end",
+			schedule: utils.Ptr(fixtureHibernationSchedule("0 18 * * *", "0 8 * *")),
+			wantErr:  true,
+		},
+		{
+			desc: "invalid_timezone",
+			schedule: &ske.HibernationSchedule{
+				Start:    utils.Ptr("0 18 * * *"),
+				End:      utils.Ptr("0 8 * * *"),
+				Timezone: utils.Ptr("Mars/Olympus_Mons"),
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "missing_end",
+			schedule: &ske.HibernationSchedule{Start: utils.Ptr("0 18 * * *")},
+			wantErr:  true,
+		},
+		{
+			desc:     "nil",
+			schedule: nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := ValidateHibernationSchedule(tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateHibernationSchedule error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNextHibernationAndWakeUp(t *testing.T) {
+	schedule := fixtureHibernationSchedule("0 18 * * 1-5", "0 8 * * 1-5")
+	now := time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC) // Friday
+
+	gotWindow, err := NextHibernation(&schedule, now)
+	if err != nil {
+		t.Fatalf("NextHibernation: %v", err)
+	}
+	wantWindow := Window{
+		Start: time.Date(2024, 6, 7, 16, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC),
+	}
+	if !gotWindow.Start.Equal(wantWindow.Start) || !gotWindow.End.Equal(wantWindow.End) {
+		t.Fatalf("NextHibernation = %+v, want %+v", gotWindow, wantWindow)
+	}
+
+	gotWakeUp, err := NextWakeUp(&schedule, now)
+	if err != nil {
+		t.Fatalf("NextWakeUp: %v", err)
+	}
+	wantWakeUp := time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC)
+	if !gotWakeUp.Equal(wantWakeUp) {
+		t.Fatalf("NextWakeUp = %s, want %s", gotWakeUp, wantWakeUp)
+	}
+}
+
+func TestIsHibernated(t *testing.T) {
+	hibernation := &ske.Hibernation{
+		Schedules: &[]ske.HibernationSchedule{
+			fixtureHibernationSchedule("0 18 * * 1-5", "0 8 * * 1-5"),
+		},
+	}
+	tests := []struct {
+		desc        string
+		hibernation *ske.Hibernation
+		t           time.Time
+		want        bool
+		wantErr     bool
+	}{
+		{
+			desc:        "working_hours",
+			hibernation: hibernation,
+			t:           time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC),
+			want:        false,
+		},
+		{
+			desc:        "night",
+			hibernation: hibernation,
+			t:           time.Date(2024, 6, 6, 23, 0, 0, 0, time.UTC),
+			want:        true,
+		},
+		{
+			desc:        "weekend",
+			hibernation: hibernation,
+			t:           time.Date(2024, 6, 9, 12, 0, 0, 0, time.UTC),
+			want:        true,
+		},
+		{
+			desc:        "at_wake_up",
+			hibernation: hibernation,
+			t:           time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC),
+			want:        false,
+		},
+		{
+			desc:        "no_hibernation",
+			hibernation: nil,
+			t:           time.Date(2024, 6, 9, 12, 0, 0, 0, time.UTC),
+			want:        false,
+		},
+		{
+			desc: "invalid_schedule",
+			hibernation: &ske.Hibernation{
+				Schedules: &[]ske.HibernationSchedule{
+					fixtureHibernationSchedule("invalid", "0 8 * * 1-5"),
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := IsHibernated(tt.hibernation, tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsHibernated error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("IsHibernated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	tests := []struct {
+		desc       string
+		timeWindow *ske.TimeWindow
+		t          time.Time
+		wantNext   Window
+		wantErr    bool
+	}{
+		{
+			desc: "rfc3339",
+			timeWindow: &ske.TimeWindow{
+				Start: utils.Ptr("0000-01-01T03:00:00+02:00"),
+				End:   utils.Ptr("0000-01-01T05:00:00+02:00"),
+			},
+			t: time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC),
+			wantNext: Window{
+				Start: time.Date(2024, 6, 8, 1, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc: "time_of_day",
+			timeWindow: &ske.TimeWindow{
+				Start: utils.Ptr("23:00:00Z"),
+				End:   utils.Ptr("01:00:00Z"),
+			},
+			t: time.Date(2024, 6, 7, 0, 30, 0, 0, time.UTC),
+			wantNext: Window{
+				Start: time.Date(2024, 6, 6, 23, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 6, 7, 1, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc: "invalid_start",
+			timeWindow: &ske.TimeWindow{
+				Start: utils.Ptr("3am"),
+				End:   utils.Ptr("05:00:00Z"),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "empty_window",
+			timeWindow: &ske.TimeWindow{
+				Start: utils.Ptr("05:00:00Z"),
+				End:   utils.Ptr("07:00:00+02:00"),
+			},
+			wantErr: true,
+		},
+		{
+			desc:       "nil",
+			timeWindow: nil,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := ValidateMaintenanceWindow(tt.timeWindow)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateMaintenanceWindow error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, err := NextMaintenanceWindow(tt.timeWindow, tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NextMaintenanceWindow error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Start.Equal(tt.wantNext.Start) || !got.End.Equal(tt.wantNext.End) {
+				t.Fatalf("NextMaintenanceWindow = %+v, want %+v", got, tt.wantNext)
+			}
+
+			windows, err := MaintenanceWindows(tt.timeWindow, tt.t, tt.t.Add(72*time.Hour))
+			if err != nil {
+				t.Fatalf("MaintenanceWindows: %v", err)
+			}
+			if len(windows) != 3 && len(windows) != 4 {
+				t.Fatalf("MaintenanceWindows returned %d windows for 3 days", len(windows))
+			}
+			if !windows[0].Start.Equal(tt.wantNext.Start) {
+				t.Fatalf("first maintenance window = %+v, want %+v", windows[0], tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	from := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC) // Monday
+	to := from.Add(24 * time.Hour)
+	cluster := &ske.Cluster{
+		Hibernation: &ske.Hibernation{
+			Schedules: &[]ske.HibernationSchedule{
+				{
+					Start: utils.Ptr("0 22 * * *"),
+					End:   utils.Ptr("0 6 * * *"),
+				},
+				{
+					Start: utils.Ptr("0 12 * * 1"),
+					End:   utils.Ptr("0 13 * * 1"),
+				},
+			},
+		},
+		Maintenance: &ske.Maintenance{
+			TimeWindow: &ske.TimeWindow{
+				Start: utils.Ptr("0000-01-01T04:00:00Z"),
+				End:   utils.Ptr("0000-01-01T07:00:00Z"),
+			},
+		},
+	}
+
+	got, err := Overlaps(cluster, from, to)
+	if err != nil {
+		t.Fatalf("Overlaps: %v", err)
+	}
+	want := []Overlap{
+		{
+			First:  "hibernation schedule 0",
+			Second: "maintenance window",
+			Window: Window{
+				Start: time.Date(2024, 6, 10, 4, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("unexpected overlaps (-got +want): %s", diff)
+	}
+
+	if _, err := Overlaps(nil, from, to); err == nil {
+		t.Fatalf("Overlaps of a nil cluster succeeded")
+	}
+}
@@ -2,11 +2,12 @@ package wait
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
-	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	"github.com/stackitcloud/stackit-sdk-go/core/utils"
 	"github.com/stackitcloud/stackit-sdk-go/core/wait"
 	"github.com/stackitcloud/stackit-sdk-go/services/ske"
 )
@@ -19,13 +20,62 @@ const (
 	StateCreated                       = "STATE_CREATED"
 	StateUnhealthy                     = "STATE_UNHEALTHY"
 	StateReconciling                   = "STATE_RECONCILING"
+	StateHibernating                   = "STATE_HIBERNATING"
+	StateWakingUp                      = "STATE_WAKINGUP"
+	CredentialsRotationStateNever      = "NEVER"
 	CredentialsRotationStatePreparing  = "PREPARING"
 	CredentialsRotationStatePrepared   = "PREPARED"
 	CredentialsRotationStateCompleting = "COMPLETING"
 	CredentialsRotationStateCompleted  = "COMPLETED"
 	InvalidArgusInstanceErrorCode      = "SKE_ARGUS_INSTANCE_NOT_FOUND"
+	UnspecifiedErrorCode               = "SKE_UNSPECIFIED"
+	TemporaryAuthErrorCode             = "SKE_TMP_AUTH_ERROR"
+	QuotaExceededErrorCode             = "SKE_QUOTA_EXCEEDED"
+	RateLimitsErrorCode                = "SKE_RATE_LIMITS"
+	InfraErrorCode                     = "SKE_INFRA_ERROR"
+	RemainingResourcesErrorCode        = "SKE_REMAINING_RESOURCES"
+	ConfigurationProblemErrorCode      = "SKE_CONFIGURATION_PROBLEM"
+	UnreadyNodesErrorCode              = "SKE_UNREADY_NODES"
+	APIServerErrorCode                 = "SKE_API_SERVER_ERROR"
 )
 
+// States reported by the stateFn of the wait handlers only, they aren't returned by the SKE API
+const (
+	// stateUnhealthyInvalidArgusInstance is StateUnhealthy caused by an invalid argus instance id, in which the cluster is usable
+	stateUnhealthyInvalidArgusInstance = "STATE_UNHEALTHY_INVALID_ARGUS_INSTANCE"
+	// stateClusterGone is the state of a cluster that isn't listed anymore
+	stateClusterGone = "STATE_GONE"
+)
+
+// Error codes reported by SKE while the system is recovering on its own
+var temporaryErrorCodes = []string{TemporaryAuthErrorCode, RateLimitsErrorCode}
+
+// DefaultOperationStartTimeout is how long the wait handlers for triggered cluster operations (hibernate, wake up, maintenance, reconcile)
+// wait for the operation to become visible in the cluster state before returning ErrOperationNotStarted, unless set with SetOperationStartTimeout.
+const DefaultOperationStartTimeout = 5 * time.Minute
+
+// ErrOperationNotStarted is returned when a triggered cluster operation didn't start within the operation start timeout
+var ErrOperationNotStarted = errors.New("operation not started")
+
+// ClusterError is returned when the cluster reports an error in its status while waiting
+type ClusterError struct {
+	State   string
+	Code    string
+	Message string
+	Details string
+}
+
+func (e *ClusterError) Error() string {
+	msg := fmt.Sprintf("cluster in state %s reported error %s", e.State, e.Code)
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	if e.Details != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Details)
+	}
+	return msg
+}
+
 type APIClientProjectInterface interface {
 	GetServiceStatusExecute(ctx context.Context, projectId string) (*ske.ProjectResponse, error)
 }
@@ -37,166 +87,342 @@ type APIClientClusterInterface interface {
 
 // CreateOrUpdateClusterWaitHandler will wait for cluster creation or update
 func CreateOrUpdateClusterWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string) *wait.AsyncActionHandler[ske.Cluster] {
-	handler := wait.New(func() (waitFinished bool, response *ske.Cluster, err error) {
-		s, err := a.GetClusterExecute(ctx, projectId, name)
-		if err != nil {
-			return false, nil, err
+	handler := wait.ForState(func() (*ske.Cluster, error) {
+		return a.GetClusterExecute(ctx, projectId, name)
+	}, func(s *ske.Cluster) (string, error) {
+		if s.Status == nil || s.Status.Aggregated == nil {
+			return "", fmt.Errorf("cluster %s has no status", name)
 		}
-		state := *s.Status.Aggregated
+		state := string(*s.Status.Aggregated)
 
 		// The state "STATE_UNHEALTHY" (aka "Impaired" in the portal) could be temporarily occur during cluster creation and the system is recovering usually, so it is not considered as a failed state here.
 		// -- alignment meeting with SKE team on 4.8.23
 		// The exception is when providing an invalid argus instance id, in that case the cluster will stay as "Impaired" until the SKE team solves it, but it is still usable.
-		if state == StateUnhealthy && s.Status.Error != nil && s.Status.Error.Message != nil && *s.Status.Error.Code == InvalidArgusInstanceErrorCode {
-			return true, s, nil
+		if state == StateUnhealthy && s.Status.Error != nil && s.Status.Error.Message != nil && s.Status.Error.Code != nil && *s.Status.Error.Code == InvalidArgusInstanceErrorCode {
+			return stateUnhealthyInvalidArgusInstance, nil
 		}
-
-		if state == StateHealthy || state == StateHibernated {
-			return true, s, nil
-		}
-
-		if state == StateFailed {
-			return true, s, fmt.Errorf("create failed")
-		}
-
-		return false, nil, nil
-	})
+		return state, nil
+	}).
+		Success(StateHealthy, StateHibernated, stateUnhealthyInvalidArgusInstance).
+		Failure(StateFailed).
+		OnFailure(func(_ *ske.Cluster, _ string) error {
+			return fmt.Errorf("create failed")
+		}).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
 
 // DeleteClusterWaitHandler will wait for cluster deletion
 func DeleteClusterWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string) *wait.AsyncActionHandler[ske.ListClustersResponse] {
-	handler := wait.New(func() (waitFinished bool, response *ske.ListClustersResponse, err error) {
-		s, err := a.ListClustersExecute(ctx, projectId)
-		if err != nil {
-			return false, nil, err
+	handler := wait.ForState(func() (*ske.ListClustersResponse, error) {
+		return a.ListClustersExecute(ctx, projectId)
+	}, func(s *ske.ListClustersResponse) (string, error) {
+		if s.Items == nil {
+			return stateClusterGone, nil
 		}
 		items := *s.Items
 		for i := range items {
 			n := items[i].Name
 			if n != nil && *n == name {
-				return false, nil, nil
+				return StateDeleting, nil
 			}
 		}
-		return true, s, nil
-	})
+		return stateClusterGone, nil
+	}).
+		Success(stateClusterGone).
+		Handler()
 	handler.SetTimeout(45 * time.Minute)
 	return handler
 }
 
 // EnableServiceWaitHandler will wait for service enablement
 func EnableServiceWaitHandler(ctx context.Context, a APIClientProjectInterface, projectId string) *wait.AsyncActionHandler[ske.ProjectResponse] {
-	handler := wait.New(func() (waitFinished bool, response *ske.ProjectResponse, err error) {
-		s, err := a.GetServiceStatusExecute(ctx, projectId)
-		if err != nil {
-			return false, nil, err
-		}
-		state := *s.State
-		switch state {
-		case StateDeleting, StateFailed:
-			return false, nil, fmt.Errorf("received state: %s for project Id: %s", state, projectId)
-		case StateCreated:
-			return true, s, nil
+	handler := wait.ForState(func() (*ske.ProjectResponse, error) {
+		return a.GetServiceStatusExecute(ctx, projectId)
+	}, func(s *ske.ProjectResponse) (string, error) {
+		if s.State == nil {
+			return "", fmt.Errorf("service status of project %s has no state", projectId)
 		}
-		return false, nil, nil
-	})
+		return string(*s.State), nil
+	}).
+		Success(StateCreated).
+		Failure(StateDeleting, StateFailed).
+		OnFailure(func(_ *ske.ProjectResponse, state string) error {
+			return fmt.Errorf("received state: %s for project Id: %s", state, projectId)
+		}).
+		Handler()
 	handler.SetTimeout(15 * time.Minute)
 	return handler
 }
 
 // DisableServiceWaitHandler will wait for service disablement
 func DisableServiceWaitHandler(ctx context.Context, a APIClientProjectInterface, projectId string) *wait.AsyncActionHandler[struct{}] {
-	handler := wait.New(func() (waitFinished bool, response *struct{}, err error) {
-		_, err = a.GetServiceStatusExecute(ctx, projectId)
-		if err == nil {
-			return false, nil, nil
-		}
-		oapiErr, ok := err.(*oapierror.GenericOpenAPIError) //nolint:errorlint //complaining that error.As should be used to catch wrapped errors, but this error should not be wrapped
-		if !ok {
-			return false, nil, fmt.Errorf("could not convert error to oapierror.GenericOpenAPIError in delete wait.AsyncHandler, %w", err)
-		}
-		if oapiErr.StatusCode == http.StatusNotFound || oapiErr.StatusCode == http.StatusForbidden {
-			return true, nil, nil
-		}
-		return false, nil, err
-	})
+	handler := wait.ForState(func() (*struct{}, error) {
+		_, err := a.GetServiceStatusExecute(ctx, projectId)
+		return nil, err
+	}, nil).
+		GoneIsSuccess(http.StatusNotFound, http.StatusForbidden).
+		Handler()
 	handler.SetTimeout(15 * time.Minute)
 	return handler
 }
 
 // RotateCredentialsWaitHandler will wait for credentials rotation
 func RotateCredentialsWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, clusterName string) *wait.AsyncActionHandler[ske.Cluster] {
-	handler := wait.New(func() (waitFinished bool, response *ske.Cluster, err error) {
-		s, err := a.GetClusterExecute(ctx, projectId, clusterName)
-		if err != nil {
-			return false, nil, err
-		}
-		state := *s.Status.Aggregated
+	handler := wait.ForState(getCluster(ctx, a, projectId, clusterName), clusterState).
+		Success(StateHealthy, StateHibernated).
+		Failure(StateFailed).
+		Transient(StateReconciling).
+		OnFailure(func(_ *ske.Cluster, _ string) error {
+			return fmt.Errorf("credentials rotation failed")
+		}).
+		OnUnexpected(func(_ *ske.Cluster, state string) error {
+			return fmt.Errorf("unexpected state %s while waiting for cluster reconciliation", state)
+		}).
+		Handler()
+	handler.SetTimeout(10 * time.Minute)
+	return handler
+}
 
-		if state == StateHealthy || state == StateHibernated {
-			return true, s, nil
-		}
+// StartCredentialsRotationWaitHandler will wait for credentials rotation
+func StartCredentialsRotationWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, clusterName string) *wait.AsyncActionHandler[ske.Cluster] {
+	handler := wait.ForState(getCluster(ctx, a, projectId, clusterName), credentialsRotationPhase).
+		Success(CredentialsRotationStatePrepared).
+		Transient(CredentialsRotationStatePreparing).
+		OnUnexpected(func(_ *ske.Cluster, state string) error {
+			return fmt.Errorf("unexpected status %s while waiting for cluster credentials rotation to be prepared", state)
+		}).
+		Handler()
+	handler.SetTimeout(45 * time.Minute)
+	return handler
+}
 
-		if state == StateReconciling {
-			return false, nil, nil
-		}
+// CompleteCredentialsRotationWaitHandler will wait for credentials rotation
+func CompleteCredentialsRotationWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, clusterName string) *wait.AsyncActionHandler[ske.Cluster] {
+	handler := wait.ForState(getCluster(ctx, a, projectId, clusterName), credentialsRotationPhase).
+		Success(CredentialsRotationStateCompleted).
+		Transient(CredentialsRotationStateCompleting).
+		OnUnexpected(func(_ *ske.Cluster, state string) error {
+			return fmt.Errorf("unexpected status %s while waiting for cluster credentials rotation to be completed", state)
+		}).
+		Handler()
+	handler.SetTimeout(45 * time.Minute)
+	return handler
+}
 
-		if state == StateFailed {
-			return true, s, fmt.Errorf("credentials rotation failed")
-		}
+func getCluster(ctx context.Context, a APIClientClusterInterface, projectId, name string) wait.GetFunc[ske.Cluster] {
+	return func() (*ske.Cluster, error) {
+		return a.GetClusterExecute(ctx, projectId, name)
+	}
+}
 
-		return true, s, fmt.Errorf("unexpected state %s while waiting for cluster reconciliation", state)
-	})
+func clusterState(s *ske.Cluster) (string, error) {
+	if s.Status == nil || s.Status.Aggregated == nil {
+		return "", fmt.Errorf("cluster state missing")
+	}
+	return string(*s.Status.Aggregated), nil
+}
 
-	handler.SetTimeout(10 * time.Minute)
-	return handler
+func credentialsRotationPhase(s *ske.Cluster) (string, error) {
+	if s.Status == nil || s.Status.CredentialsRotation == nil || s.Status.CredentialsRotation.Phase == nil {
+		return "", fmt.Errorf("cluster credentials rotation phase missing")
+	}
+	return *s.Status.CredentialsRotation.Phase, nil
 }
 
-// StartCredentialsRotationWaitHandler will wait for credentials rotation
-func StartCredentialsRotationWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, clusterName string) *wait.AsyncActionHandler[ske.Cluster] {
-	handler := wait.New(func() (waitFinished bool, response *ske.Cluster, err error) {
-		s, err := a.GetClusterExecute(ctx, projectId, clusterName)
-		if err != nil {
-			return false, nil, err
-		}
-		state := *s.Status.CredentialsRotation.Phase
+// triggeredOperation describes how a triggered cluster operation shows in the aggregated cluster state
+type triggeredOperation struct {
+	name string
+	// States while the operation is running
+	inProgressStates []string
+	// States once the operation is finished
+	finishedStates []string
+	// Whether one of the in progress states must be seen before a finished state counts.
+	// Needed for operations that finish in the same state they started from.
+	mustSeeInProgress bool
+}
 
-		if state == CredentialsRotationStatePrepared {
-			return true, s, nil
-		}
+// OperationWaitHandler waits for a triggered cluster operation to finish.
+// The handler can be reused, each wait starts over from the current cluster state.
+type OperationWaitHandler struct {
+	ctx               context.Context
+	client            APIClientClusterInterface
+	projectId         string
+	name              string
+	op                triggeredOperation
+	throttle          time.Duration
+	timeout           time.Duration
+	sleepBeforeWait   time.Duration
+	tempErrRetryLimit int
+	startTimeout      time.Duration
+}
 
-		if state == CredentialsRotationStatePreparing {
-			return false, nil, nil
-		}
+func triggeredOperationWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string, op triggeredOperation) *OperationWaitHandler {
+	return &OperationWaitHandler{
+		ctx:          ctx,
+		client:       a,
+		projectId:    projectId,
+		name:         name,
+		op:           op,
+		timeout:      45 * time.Minute,
+		startTimeout: DefaultOperationStartTimeout,
+	}
+}
 
-		return true, s, fmt.Errorf("unexpected status %s while waiting for cluster credentials rotation to be prepared", state)
-	})
+// SetThrottle sets the time interval between each check of the cluster state.
+func (h *OperationWaitHandler) SetThrottle(d time.Duration) *OperationWaitHandler {
+	h.throttle = d
+	return h
+}
 
-	handler.SetTimeout(45 * time.Minute)
-	return handler
+// SetTimeout sets the duration for wait timeout.
+func (h *OperationWaitHandler) SetTimeout(d time.Duration) *OperationWaitHandler {
+	h.timeout = d
+	return h
 }
 
-// CompleteCredentialsRotationWaitHandler will wait for credentials rotation
-func CompleteCredentialsRotationWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, clusterName string) *wait.AsyncActionHandler[ske.Cluster] {
-	handler := wait.New(func() (waitFinished bool, response *ske.Cluster, err error) {
-		s, err := a.GetClusterExecute(ctx, projectId, clusterName)
+// SetSleepBeforeWait sets the duration for sleep before wait.
+func (h *OperationWaitHandler) SetSleepBeforeWait(d time.Duration) *OperationWaitHandler {
+	h.sleepBeforeWait = d
+	return h
+}
+
+// SetTempErrRetryLimit sets the retry limit if a temporary error is found.
+func (h *OperationWaitHandler) SetTempErrRetryLimit(l int) *OperationWaitHandler {
+	h.tempErrRetryLimit = l
+	return h
+}
+
+// SetOperationStartTimeout sets how long to wait for the operation to become visible in the cluster state
+// before returning ErrOperationNotStarted, DefaultOperationStartTimeout if not set.
+func (h *OperationWaitHandler) SetOperationStartTimeout(d time.Duration) *OperationWaitHandler {
+	h.startTimeout = d
+	return h
+}
+
+// WaitWithContext starts the wait until there's an error or the operation is finished
+func (h *OperationWaitHandler) WaitWithContext(ctx context.Context) (*ske.Cluster, error) {
+	handler := wait.New(h.check()).
+		SetTimeout(h.timeout).
+		SetSleepBeforeWait(h.sleepBeforeWait)
+	if h.throttle != 0 {
+		handler.SetThrottle(h.throttle)
+	}
+	if h.tempErrRetryLimit != 0 {
+		handler.SetTempErrRetryLimit(h.tempErrRetryLimit)
+	}
+	return handler.WaitWithContext(ctx)
+}
+
+// check returns the check of a single wait, which tracks whether the operation was seen in progress
+func (h *OperationWaitHandler) check() wait.AsyncActionCheck[ske.Cluster] {
+	op := h.op
+	started := false
+	var firstCheck time.Time
+	return func() (waitFinished bool, response *ske.Cluster, err error) {
+		if firstCheck.IsZero() {
+			firstCheck = time.Now()
+		}
+		s, err := h.client.GetClusterExecute(h.ctx, h.projectId, h.name)
 		if err != nil {
 			return false, nil, err
 		}
-		state := *s.Status.CredentialsRotation.Phase
+		if s.Status == nil || s.Status.Aggregated == nil {
+			return false, nil, fmt.Errorf("%s failed for cluster %s. The response is not valid: the status is missing", op.name, h.name)
+		}
+		state := string(*s.Status.Aggregated)
 
-		if state == CredentialsRotationStateCompleted {
+		if utils.Contains(op.inProgressStates, state) {
+			started = true
+			return false, nil, nil
+		}
+		if utils.Contains(op.finishedStates, state) && (started || !op.mustSeeInProgress) {
 			return true, s, nil
 		}
 
-		if state == CredentialsRotationStateCompleting {
+		switch state {
+		case StateFailed:
+			if clusterErr := clusterError(s); clusterErr != nil {
+				return true, s, fmt.Errorf("%s failed: %w", op.name, clusterErr)
+			}
+			return true, s, fmt.Errorf("%s failed", op.name)
+		case StateUnhealthy:
+			// The cluster may recover on its own, unless it reports a permanent error
+			clusterErr := clusterError(s)
+			if clusterErr != nil && clusterErr.Code != InvalidArgusInstanceErrorCode && !utils.Contains(temporaryErrorCodes, clusterErr.Code) {
+				return true, s, fmt.Errorf("%s failed: %w", op.name, clusterErr)
+			}
+		}
+
+		if started {
+			if !utils.Contains(op.finishedStates, state) && state != StateUnhealthy {
+				return true, s, fmt.Errorf("unexpected state %s while waiting for %s", state, op.name)
+			}
 			return false, nil, nil
 		}
+		if time.Since(firstCheck) > h.startTimeout {
+			return true, s, fmt.Errorf("%w: %s, cluster is in state %s", ErrOperationNotStarted, op.name, state)
+		}
+		return false, nil, nil
+	}
+}
+
+func clusterError(s *ske.Cluster) *ClusterError {
+	if s.Status == nil || s.Status.Error == nil || s.Status.Error.Code == nil {
+		return nil
+	}
+	e := &ClusterError{
+		Code: *s.Status.Error.Code,
+	}
+	if s.Status.Aggregated != nil {
+		e.State = string(*s.Status.Aggregated)
+	}
+	if s.Status.Error.Message != nil {
+		e.Message = *s.Status.Error.Message
+	}
+	if s.Status.Error.Details != nil {
+		e.Details = *s.Status.Error.Details
+	}
+	return e
+}
 
-		return true, s, fmt.Errorf("unexpected status %s while waiting for cluster credentials rotation to be completed", state)
+// HibernateClusterWaitHandler will wait for cluster hibernation, triggered with TriggerHibernate
+func HibernateClusterWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string) *OperationWaitHandler {
+	return triggeredOperationWaitHandler(ctx, a, projectId, name, triggeredOperation{
+		name:             "hibernation",
+		inProgressStates: []string{StateHibernating},
+		finishedStates:   []string{StateHibernated},
 	})
+}
 
-	handler.SetTimeout(45 * time.Minute)
-	return handler
+// WakeUpClusterWaitHandler will wait for a hibernated cluster to wake up
+func WakeUpClusterWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string) *OperationWaitHandler {
+	return triggeredOperationWaitHandler(ctx, a, projectId, name, triggeredOperation{
+		name:             "wake up",
+		inProgressStates: []string{StateWakingUp},
+		finishedStates:   []string{StateHealthy},
+	})
+}
+
+// MaintenanceClusterWaitHandler will wait for cluster maintenance, triggered with TriggerMaintenance.
+// The maintenance must be seen in progress: if it finishes between two checks, ErrOperationNotStarted is returned after the operation start timeout.
+func MaintenanceClusterWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string) *OperationWaitHandler {
+	return triggeredOperationWaitHandler(ctx, a, projectId, name, triggeredOperation{
+		name:              "maintenance",
+		inProgressStates:  []string{StateReconciling},
+		finishedStates:    []string{StateHealthy, StateHibernated},
+		mustSeeInProgress: true,
+	})
+}
+
+// ReconcileClusterWaitHandler will wait for cluster reconciliation, triggered with TriggerReconcile.
+// The reconciliation must be seen in progress: if it finishes between two checks, ErrOperationNotStarted is returned after the operation start timeout.
+func ReconcileClusterWaitHandler(ctx context.Context, a APIClientClusterInterface, projectId, name string) *OperationWaitHandler {
+	return triggeredOperationWaitHandler(ctx, a, projectId, name, triggeredOperation{
+		name:              "reconciliation",
+		inProgressStates:  []string{StateReconciling},
+		finishedStates:    []string{StateHealthy, StateHibernated},
+		mustSeeInProgress: true,
+	})
 }
@@ -2,6 +2,7 @@ package wait
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -371,3 +372,215 @@ func TestRotateCredentialsWaitHandler(t *testing.T) {
 		})
 	}
 }
+
+// Used for testing triggered cluster operations, returns the states one after the other and then keeps the last one
+type apiClientClusterSequenceMocked struct {
+	getFails  bool
+	states    []string
+	errorCode string
+	calls     int
+}
+
+func (a *apiClientClusterSequenceMocked) GetClusterExecute(_ context.Context, _, _ string) (*ske.Cluster, error) {
+	if a.getFails {
+		return nil, &oapierror.GenericOpenAPIError{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	i := a.calls
+	if i >= len(a.states) {
+		i = len(a.states) - 1
+	}
+	a.calls++
+	rs := ske.ClusterStatusState(a.states[i])
+	cluster := &ske.Cluster{
+		Name: utils.Ptr("cluster"),
+		Status: &ske.ClusterStatus{
+			Aggregated: &rs,
+		},
+	}
+	if a.errorCode != "" {
+		cluster.Status.Error = &ske.RuntimeError{
+			Code:    utils.Ptr(a.errorCode),
+			Message: utils.Ptr("error message"),
+		}
+	}
+	return cluster, nil
+}
+
+func (a *apiClientClusterSequenceMocked) ListClustersExecute(_ context.Context, _ string) (*ske.ListClustersResponse, error) {
+	return nil, &oapierror.GenericOpenAPIError{
+		StatusCode: http.StatusNotImplemented,
+	}
+}
+
+func TestTriggeredOperationWaitHandlers(t *testing.T) {
+	tests := []struct {
+		desc             string
+		handler          func(ctx context.Context, a APIClientClusterInterface, projectId, name string) *OperationWaitHandler
+		getFails         bool
+		states           []string
+		errorCode        string
+		wantErr          bool
+		wantNotStarted   bool
+		wantClusterError bool
+		wantResp         bool
+	}{
+		{
+			desc:     "hibernate_succeeded",
+			handler:  HibernateClusterWaitHandler,
+			states:   []string{StateHealthy, StateHibernating, StateHibernated},
+			wantErr:  false,
+			wantResp: true,
+		},
+		{
+			desc:     "hibernate_already_hibernated",
+			handler:  HibernateClusterWaitHandler,
+			states:   []string{StateHibernated},
+			wantErr:  false,
+			wantResp: true,
+		},
+		{
+			desc:     "hibernate_aborted",
+			handler:  HibernateClusterWaitHandler,
+			states:   []string{StateHibernating, StateHealthy},
+			wantErr:  true,
+			wantResp: true,
+		},
+		{
+			desc:           "hibernate_not_started",
+			handler:        HibernateClusterWaitHandler,
+			states:         []string{StateHealthy},
+			wantErr:        true,
+			wantNotStarted: true,
+			wantResp:       true,
+		},
+		{
+			desc:     "wake_up_succeeded",
+			handler:  WakeUpClusterWaitHandler,
+			states:   []string{StateHibernated, StateWakingUp, StateHealthy},
+			wantErr:  false,
+			wantResp: true,
+		},
+		{
+			desc:     "reconcile_succeeded",
+			handler:  ReconcileClusterWaitHandler,
+			states:   []string{StateHealthy, StateReconciling, StateReconciling, StateHealthy},
+			wantErr:  false,
+			wantResp: true,
+		},
+		{
+			desc:           "reconcile_not_started",
+			handler:        ReconcileClusterWaitHandler,
+			states:         []string{StateHealthy},
+			wantErr:        true,
+			wantNotStarted: true,
+			wantResp:       true,
+		},
+		{
+			desc:     "reconcile_temporarily_unhealthy",
+			handler:  ReconcileClusterWaitHandler,
+			states:   []string{StateReconciling, StateUnhealthy, StateHealthy},
+			wantErr:  false,
+			wantResp: true,
+		},
+		{
+			desc:      "reconcile_recovering_error",
+			handler:   ReconcileClusterWaitHandler,
+			states:    []string{StateReconciling, StateUnhealthy, StateHealthy},
+			errorCode: RateLimitsErrorCode,
+			wantErr:   false,
+			wantResp:  true,
+		},
+		{
+			desc:             "maintenance_unhealthy_with_error",
+			handler:          MaintenanceClusterWaitHandler,
+			states:           []string{StateReconciling, StateUnhealthy},
+			errorCode:        QuotaExceededErrorCode,
+			wantErr:          true,
+			wantClusterError: true,
+			wantResp:         true,
+		},
+		{
+			desc:             "maintenance_failed",
+			handler:          MaintenanceClusterWaitHandler,
+			states:           []string{StateReconciling, StateFailed},
+			errorCode:        InfraErrorCode,
+			wantErr:          true,
+			wantClusterError: true,
+			wantResp:         true,
+		},
+		{
+			desc:     "get_fails",
+			handler:  ReconcileClusterWaitHandler,
+			getFails: true,
+			wantErr:  true,
+			wantResp: false,
+		},
+		{
+			desc:     "timeout",
+			handler:  HibernateClusterWaitHandler,
+			states:   []string{StateHibernating},
+			wantErr:  true,
+			wantResp: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiClient := &apiClientClusterSequenceMocked{
+				getFails:  tt.getFails,
+				states:    tt.states,
+				errorCode: tt.errorCode,
+			}
+
+			handler := tt.handler(context.Background(), apiClient, "", "cluster")
+
+			gotRes, err := handler.
+				SetThrottle(time.Millisecond).
+				SetTimeout(20 * time.Millisecond).
+				SetOperationStartTimeout(5 * time.Millisecond).
+				WaitWithContext(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrOperationNotStarted) != tt.wantNotStarted {
+				t.Fatalf("handler error = %v, wantNotStarted %v", err, tt.wantNotStarted)
+			}
+			var clusterErr *ClusterError
+			if errors.As(err, &clusterErr) != tt.wantClusterError {
+				t.Fatalf("handler error = %v, wantClusterError %v", err, tt.wantClusterError)
+			}
+			if tt.wantClusterError && clusterErr.Code != tt.errorCode {
+				t.Fatalf("cluster error code = %s, want %s", clusterErr.Code, tt.errorCode)
+			}
+			if (gotRes != nil) != tt.wantResp {
+				t.Fatalf("handler gotRes = %+v, wantResp %v", gotRes, tt.wantResp)
+			}
+		})
+	}
+}
+
+func TestTriggeredOperationWaitHandlerReused(t *testing.T) {
+	apiClient := &apiClientClusterSequenceMocked{
+		states: []string{StateReconciling, StateHealthy},
+	}
+	handler := ReconcileClusterWaitHandler(context.Background(), apiClient, "", "cluster").
+		SetThrottle(time.Millisecond).
+		SetTimeout(20 * time.Millisecond).
+		SetOperationStartTimeout(5 * time.Millisecond)
+
+	if _, err := handler.WaitWithContext(context.Background()); err != nil {
+		t.Fatalf("first wait failed: %v", err)
+	}
+
+	// The second reconciliation must be seen in progress again before the healthy state counts
+	apiClient.states = []string{StateHealthy, StateReconciling, StateHealthy}
+	apiClient.calls = 0
+	if _, err := handler.WaitWithContext(context.Background()); err != nil {
+		t.Fatalf("second wait failed: %v", err)
+	}
+	if apiClient.calls != len(apiClient.states) {
+		t.Fatalf("second wait finished after %d checks, want %d", apiClient.calls, len(apiClient.states))
+	}
+}